@@ -527,7 +527,7 @@ var _ RoamAPI = (*Client)(nil)
 
 // ListPages returns pages modified today
 func (c *Client) ListPages(modifiedToday bool, limit int) ([][]interface{}, error) {
-	results, err := c.Query(roamdb.QueryListPages(modifiedToday, time.Now()))
+	results, err := c.Query(roamdb.QueryListPages(roamdb.PageFilter{ModifiedToday: modifiedToday}, time.Now()))
 	if err != nil {
 		return nil, err
 	}
@@ -675,11 +675,10 @@ func (c *LocalClient) ExecuteBatch(batch *BatchBuilder) error {
 			if !ok {
 				return fmt.Errorf("batch action %d: invalid page data", i)
 			}
-			title, ok := page["title"].(string)
-			if !ok {
+			if _, ok := page["title"].(string); !ok {
 				return fmt.Errorf("batch action %d: missing page title", i)
 			}
-			if err := c.CreatePage(title); err != nil {
+			if _, err := c.call("data.page.create", map[string]interface{}{"page": withoutTempID(page)}); err != nil {
 				return err
 			}
 		case "create-block":
@@ -691,16 +690,22 @@ func (c *LocalClient) ExecuteBatch(batch *BatchBuilder) error {
 			if !ok {
 				return fmt.Errorf("batch action %d: invalid location data", i)
 			}
-			content := ""
-			if s, ok := block["string"].(string); ok {
-				content = s
+			block = withoutTempID(block)
+			if _, ok := block["string"].(string); !ok {
+				block["string"] = ""
 			}
-			parentUID := ""
-			if p, ok := location["parent-uid"].(string); ok {
-				parentUID = p
+			parentUID, err := c.batchParentUID(location)
+			if err != nil {
+				return fmt.Errorf("batch action %d: %w", i, err)
 			}
-			order := location["order"]
-			if err := c.CreateBlock(parentUID, content, order); err != nil {
+			args := map[string]interface{}{
+				"location": map[string]interface{}{
+					"parent-uid": parentUID,
+					"order":      location["order"],
+				},
+				"block": block,
+			}
+			if _, err := c.call("data.block.create", args); err != nil {
 				return err
 			}
 		case "update-block":
@@ -708,12 +713,21 @@ func (c *LocalClient) ExecuteBatch(batch *BatchBuilder) error {
 			if !ok {
 				return fmt.Errorf("batch action %d: invalid block data", i)
 			}
-			uid, ok := block["uid"].(string)
-			if !ok {
+			if _, ok := block["uid"].(string); !ok {
 				return fmt.Errorf("batch action %d: missing block uid", i)
 			}
-			content, _ := block["string"].(string)
-			if err := c.UpdateBlock(uid, content); err != nil {
+			if _, err := c.call("data.block.update", map[string]interface{}{"block": block}); err != nil {
+				return err
+			}
+		case "update-page":
+			page, ok := action["page"].(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("batch action %d: invalid page data", i)
+			}
+			if _, ok := page["uid"].(string); !ok {
+				return fmt.Errorf("batch action %d: missing page uid", i)
+			}
+			if _, err := c.call("data.page.update", map[string]interface{}{"page": page}); err != nil {
 				return err
 			}
 		case "move-block":
@@ -729,9 +743,9 @@ func (c *LocalClient) ExecuteBatch(batch *BatchBuilder) error {
 			if !ok {
 				return fmt.Errorf("batch action %d: missing block uid", i)
 			}
-			parentUID, ok := location["parent-uid"].(string)
-			if !ok {
-				return fmt.Errorf("batch action %d: missing parent-uid", i)
+			parentUID, err := c.batchParentUID(location)
+			if err != nil {
+				return fmt.Errorf("batch action %d: %w", i, err)
 			}
 			order := location["order"]
 			if err := c.MoveBlock(uid, parentUID, order); err != nil {
@@ -768,6 +782,39 @@ func (c *LocalClient) ExecuteBatch(batch *BatchBuilder) error {
 	return nil
 }
 
+// batchParentUID resolves a batch action's location to a parent uid. The
+// Local API only takes parent uids, so page-title and daily-note locations
+// are looked up first, creating the page if needed.
+func (c *LocalClient) batchParentUID(location map[string]interface{}) (string, error) {
+	if uid, ok := location["parent-uid"].(string); ok {
+		return uid, nil
+	}
+	switch title := location["page-title"].(type) {
+	case string:
+		return c.resolveLocationToParentUID(Location{PageTitle: title})
+	case map[string]string:
+		return c.resolveLocationToParentUID(Location{DailyNoteDate: title["daily-note-page"]})
+	case map[string]interface{}:
+		date, _ := title["daily-note-page"].(string)
+		return c.resolveLocationToParentUID(Location{DailyNoteDate: date})
+	}
+	return "", fmt.Errorf("missing parent-uid")
+}
+
+// withoutTempID returns a copy of an action's page or block map without a
+// tempid uid. The Local API runs actions one at a time, so it can only be
+// given explicit uids; without one it assigns its own.
+func withoutTempID(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if _, isString := v.(string); k == "uid" && !isString {
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
 // GetPageByTitle retrieves a page by its title
 func (c *LocalClient) GetPageByTitle(title string) (json.RawMessage, error) {
 	query := roamdb.QueryPageByTitle(title)
@@ -819,7 +866,7 @@ func (c *LocalClient) SearchBlocks(text string, limit int) ([][]interface{}, err
 
 // ListPages returns pages, optionally filtered by modification date
 func (c *LocalClient) ListPages(modifiedToday bool, limit int) ([][]interface{}, error) {
-	results, err := c.Query(roamdb.QueryListPages(modifiedToday, time.Now()))
+	results, err := c.Query(roamdb.QueryListPages(roamdb.PageFilter{ModifiedToday: modifiedToday}, time.Now()))
 	if err != nil {
 		return nil, err
 	}
@@ -1385,3 +1385,80 @@ func createTempPortFile(t *testing.T, port int) string {
 
 	return portFile
 }
+
+func TestLocalClient_ExecuteBatchForwardsUIDs(t *testing.T) {
+	var received []localRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req localRequest
+		json.Unmarshal(body, &req)
+		received = append(received, req)
+		json.NewEncoder(w).Encode(localResponse{Success: true})
+	}))
+	defer server.Close()
+
+	port := extractPort(t, server.URL)
+	portFile := createTempPortFile(t, port)
+	defer os.Remove(portFile)
+
+	client, _ := NewLocalClient("test-graph")
+	heading := 2
+	batch := NewBatchBuilder()
+	page := batch.CreatePage(PageOptions{Title: "Imported", UID: "page-uid"})
+	batch.CreateBlock(Location{ParentUID: page, Order: 0}, BlockOptions{Content: "Kept", UID: "block-uid", Heading: &heading})
+	batch.CreateBlock(Location{ParentUID: page, Order: 1}, BlockOptions{Content: "New"})
+	if err := client.ExecuteBatch(batch); err != nil {
+		t.Fatalf("ExecuteBatch failed: %v", err)
+	}
+
+	if len(received) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(received))
+	}
+	pageMap := received[0].Args[0].(map[string]interface{})["page"].(map[string]interface{})
+	if pageMap["uid"] != "page-uid" || pageMap["title"] != "Imported" {
+		t.Errorf("unexpected page args: %v", pageMap)
+	}
+	kept := received[1].Args[0].(map[string]interface{})["block"].(map[string]interface{})
+	if kept["uid"] != "block-uid" || kept["heading"] != float64(2) {
+		t.Errorf("unexpected block args: %v", kept)
+	}
+	fresh := received[2].Args[0].(map[string]interface{})["block"].(map[string]interface{})
+	if _, ok := fresh["uid"]; ok {
+		t.Errorf("tempid uid should not be sent to the Local API: %v", fresh)
+	}
+}
+
+func TestLocalClient_ExecuteBatchResolvesPageTitle(t *testing.T) {
+	var received []localRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req localRequest
+		json.Unmarshal(body, &req)
+		received = append(received, req)
+		resp := localResponse{Success: true}
+		if req.Action == "data.q" {
+			resp.Result = json.RawMessage(`[["page-uid"]]`)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	port := extractPort(t, server.URL)
+	portFile := createTempPortFile(t, port)
+	defer os.Remove(portFile)
+
+	client, _ := NewLocalClient("test-graph")
+	batch := NewBatchBuilder()
+	batch.CreateBlock(Location{PageTitle: "Notes", Order: "last"}, BlockOptions{Content: "Hello", UID: "block-uid"})
+	if err := client.ExecuteBatch(batch); err != nil {
+		t.Fatalf("ExecuteBatch failed: %v", err)
+	}
+
+	if len(received) != 2 || received[0].Action != "data.q" {
+		t.Fatalf("expected a page lookup and a create, got %+v", received)
+	}
+	location := received[1].Args[0].(map[string]interface{})["location"].(map[string]interface{})
+	if location["parent-uid"] != "page-uid" {
+		t.Errorf("unexpected location: %v", location)
+	}
+}
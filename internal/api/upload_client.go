@@ -0,0 +1,95 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// UploadTimeout is the default timeout for file uploads.
+const UploadTimeout = 2 * time.Minute
+
+// UploadClient uploads files to a user-configured HTTP endpoint, for graphs
+// reached through the cloud API, which has no file upload of its own.
+//
+// Files are POSTed as multipart/form-data in a "file" field. The endpoint
+// answers with the file's URL, either as plain text or as a JSON object
+// with a "url" field.
+type UploadClient struct {
+	endpoint   string
+	token      string
+	httpClient *http.Client
+}
+
+// NewUploadClient returns a client for an upload endpoint. A non-empty token
+// is sent as a bearer token.
+func NewUploadClient(endpoint, token string) *UploadClient {
+	return &UploadClient{
+		endpoint:   endpoint,
+		token:      token,
+		httpClient: &http.Client{Timeout: UploadTimeout},
+	}
+}
+
+// UploadFile uploads a file and returns its URL.
+func (c *UploadClient) UploadFile(filename string, data []byte) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload form: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", fmt.Errorf("failed to create upload form: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return "", fmt.Errorf("failed to create upload form: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, c.endpoint, &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if resp.StatusCode == http.StatusUnauthorized {
+			return "", AuthenticationError{Message: "upload endpoint rejected the token"}
+		}
+		return "", fmt.Errorf("upload error (status %d): %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	text := strings.TrimSpace(string(respBody))
+	if strings.HasPrefix(text, "{") {
+		var result struct {
+			URL string `json:"url"`
+		}
+		if err := json.Unmarshal(respBody, &result); err != nil {
+			return "", fmt.Errorf("failed to parse upload response: %w", err)
+		}
+		text = result.URL
+	}
+	if text == "" {
+		return "", fmt.Errorf("upload endpoint did not return a URL")
+	}
+	return text, nil
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUploadClient_UploadFile(t *testing.T) {
+	reply := `{"url": "https://files.example/pic.png"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("Expected Authorization header, got %q", r.Header.Get("Authorization"))
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("Expected file field: %v", err)
+		}
+		data, _ := io.ReadAll(file)
+		if header.Filename != "pic.png" || string(data) != "PNG" {
+			t.Errorf("Unexpected upload %q: %q", header.Filename, data)
+		}
+		w.Write([]byte(reply))
+	}))
+	defer server.Close()
+
+	client := NewUploadClient(server.URL, "secret")
+	url, err := client.UploadFile("pic.png", []byte("PNG"))
+	if err != nil || url != "https://files.example/pic.png" {
+		t.Fatalf("UploadFile = %q, %v", url, err)
+	}
+
+	reply = "https://files.example/plain.png\n"
+	if url, err := client.UploadFile("pic.png", []byte("PNG")); err != nil || url != "https://files.example/plain.png" {
+		t.Fatalf("plain text reply: %q, %v", url, err)
+	}
+
+	reply = `{}`
+	if _, err := client.UploadFile("pic.png", []byte("PNG")); err == nil {
+		t.Fatal("Expected error for a reply without a URL")
+	}
+}
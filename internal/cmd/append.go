@@ -10,6 +10,7 @@ import (
 
 	"github.com/salmonumbrella/roam-cli/internal/api"
 	"github.com/salmonumbrella/roam-cli/internal/config"
+	"github.com/salmonumbrella/roam-cli/internal/mdblocks"
 )
 
 var appendCmd = &cobra.Command{
@@ -21,7 +22,9 @@ This command is designed for encrypted graphs and does not require
 the Roam desktop app to be running.
 
 Blocks can be provided via --content flag or piped from stdin.
-Use indentation (2 spaces) to create nested blocks.
+Each line becomes a block, nested under the nearest less-indented line
+above it. List markers are dropped, "# " lines become headings, "- [ ]"
+items become TODOs and fenced code stays in one block.
 Use --json or --file to send a raw Append API payload with full control.
 
 Examples:
@@ -50,6 +53,7 @@ var (
 	appendDailyNote bool
 	appendDate      string
 	appendContent   string
+	appendIndent    int
 )
 
 func runAppend(cmd *cobra.Command, args []string) error {
@@ -82,7 +86,7 @@ func runAppend(cmd *cobra.Command, args []string) error {
 	}
 
 	// Parse content into blocks
-	blocks := parseIndentedBlocks(content)
+	blocks := parseIndentedBlocks(content, appendIndent)
 
 	// Get credentials
 	cfg, err := loadConfigFromFlag()
@@ -179,42 +183,26 @@ func getAPITokenForAppend(cfg *config.Config) string {
 	return ""
 }
 
-// parseIndentedBlocks parses indented text into nested AppendBlocks
-func parseIndentedBlocks(content string) []api.AppendBlock {
-	lines := strings.Split(content, "\n")
-	entries := make([]markdownEntry, 0, len(lines))
-
-	for _, line := range lines {
-		if strings.TrimSpace(line) == "" {
-			continue
-		}
-
-		// Count leading spaces (2 spaces = 1 level)
-		indent := 0
-		for _, ch := range line {
-			if ch == ' ' {
-				indent++
-			} else if ch == '\t' {
-				indent += 2
-			} else {
-				break
-			}
-		}
-		level := indent / 2
-		entries = append(entries, markdownEntry{level: level, content: strings.TrimSpace(line)})
-	}
-
-	blocks := buildMarkdownTree(entries)
+// parseIndentedBlocks parses indented text into nested AppendBlocks, one
+// block per line, counting tabs as indent columns (0 for the default).
+func parseIndentedBlocks(content string, indent int) []api.AppendBlock {
+	blocks, _ := mdblocks.Parse(content, mdblocks.Options{Outline: true, Indent: indent})
 	return markdownToAppendBlocks(blocks)
 }
 
-func markdownToAppendBlocks(blocks []*MarkdownBlock) []api.AppendBlock {
+func markdownToAppendBlocks(blocks []*mdblocks.Block) []api.AppendBlock {
 	result := make([]api.AppendBlock, 0, len(blocks))
 	for _, block := range blocks {
-		result = append(result, api.AppendBlock{
-			String:   block.Content,
-			Children: markdownToAppendBlocks(block.Children),
-		})
+		b := api.AppendBlock{
+			String:           block.String,
+			ChildrenViewType: block.ViewType,
+			Children:         markdownToAppendBlocks(block.Children),
+		}
+		if block.Heading > 0 {
+			heading := block.Heading
+			b.Heading = &heading
+		}
+		result = append(result, b)
 	}
 	return result
 }
@@ -235,4 +223,5 @@ func init() {
 	appendCmd.Flags().BoolVar(&appendDailyNote, "daily-note", false, "Append to daily note")
 	appendCmd.Flags().StringVar(&appendDate, "date", "", "Daily note date (MM-DD-YYYY, default: today)")
 	appendCmd.Flags().StringVarP(&appendContent, "content", "c", "", "Content to append")
+	appendCmd.Flags().IntVar(&appendIndent, "indent", mdblocks.DefaultIndent, "Columns a tab counts as when nesting lines")
 }
@@ -1,19 +1,21 @@
 package cmd
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
-	"regexp"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/mdblocks"
 	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+	"github.com/salmonumbrella/roam-cli/internal/upload"
 )
 
 // BatchAction represents a single action in a batch operation
@@ -577,9 +579,20 @@ var importCmd = &cobra.Command{
 	Long: `Import a markdown file into your Roam graph.
 
 The markdown file is parsed and converted into Roam's block hierarchy:
-- Headings become top-level blocks
-- Nested bullet lists become nested blocks
-- Paragraphs become individual blocks
+- Headings become heading blocks with the content below them nested
+  underneath, up to the next heading of the same or a higher level
+- Nested lists become nested blocks; numbered lists are shown numbered
+- "- [ ]" and "- [x]" items become {{[[TODO]]}} and {{[[DONE]]}}
+- Paragraphs, blockquotes and fenced code blocks become one block each
+- Tables become {{table}} blocks
+
+Tabs count as --indent columns when comparing list indentation.
+
+With --upload-assets, local images and files linked from the markdown
+(relative to the markdown file) are uploaded and the links rewritten to
+the uploaded URLs. Uploads go through the Local API, or with the cloud API
+to the endpoint in ROAM_UPLOAD_URL or the upload_url config key. Files are
+cached by content hash, so importing the same file again reuses its URL.
 
 You can specify a target page (created if it doesn't exist) or a parent block.
 
@@ -593,6 +606,9 @@ Examples:
   # Import at the beginning of a page
   roam import notes.md --page "My Page" --order first
 
+  # Upload the images the notes link to
+  roam import notes.md --page "Imported Notes" --upload-assets
+
   # Preview without importing
   roam import notes.md --page "Test" --dry-run`,
 	Args: cobra.ExactArgs(1),
@@ -604,13 +620,16 @@ var (
 	importParent string
 	importOrder  string
 	importDryRun bool
+	importIndent int
+	importUpload bool
 )
 
 // MarkdownBlock represents a parsed markdown block
 type MarkdownBlock struct {
 	Content  string
+	Heading  int
+	ViewType string
 	Children []*MarkdownBlock
-	Level    int
 }
 
 func runImport(cmd *cobra.Command, args []string) error {
@@ -623,7 +642,8 @@ func runImport(cmd *cobra.Command, args []string) error {
 	}
 
 	// Parse markdown into block hierarchy
-	blocks := parseMarkdown(string(content))
+	markdown := string(content)
+	blocks, viewType := parseMarkdown(markdown, importIndent)
 
 	if len(blocks) == 0 {
 		return fmt.Errorf("no content found in markdown file")
@@ -638,6 +658,12 @@ func runImport(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("cannot use both --page and --parent flags")
 	}
 
+	// Parse order
+	order, err := parseImportOrder(importOrder)
+	if err != nil {
+		return err
+	}
+
 	// Dry run mode
 	if importDryRun {
 		return dryRunImport(blocks, importPage, importParent)
@@ -645,10 +671,15 @@ func runImport(cmd *cobra.Command, args []string) error {
 
 	client := GetClient()
 
-	// Parse order
-	order, err := parseImportOrder(importOrder)
-	if err != nil {
-		return err
+	// Upload linked files last, once the import is known to go ahead, and
+	// parse the rewritten links.
+	var uploads *upload.Session
+	if importUpload {
+		markdown, uploads, err = uploadMarkdownAssets(client, markdown, filepath.Dir(filePath), stderrFromContext(cmd.Context()))
+		if err != nil {
+			return err
+		}
+		blocks, viewType = parseMarkdown(markdown, importIndent)
 	}
 
 	var (
@@ -658,7 +689,7 @@ func runImport(cmd *cobra.Command, args []string) error {
 	)
 
 	if _, ok := client.(*api.Client); ok {
-		count, parentUID, pageCreated, err = importWithBatch(client, blocks, order)
+		count, parentUID, pageCreated, err = importWithBatch(client, blocks, order, viewType)
 		if err != nil {
 			return fmt.Errorf("import failed: %w", err)
 		}
@@ -671,6 +702,9 @@ func runImport(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("import failed: %w", err)
 		}
+		if err := setParentViewType(client, parentUID, importPage != "", viewType); err != nil {
+			return err
+		}
 	} else {
 		parentUID, pageCreated, err = resolveImportParent(client, false)
 		if err != nil {
@@ -680,6 +714,9 @@ func runImport(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("import failed: %w", err)
 		}
+		if err := setParentViewType(client, parentUID, importPage != "", viewType); err != nil {
+			return err
+		}
 	}
 
 	// Output result
@@ -694,10 +731,17 @@ func runImport(cmd *cobra.Command, args []string) error {
 		if parentUID != "" && !pageCreated {
 			result["parent"] = parentUID
 		}
+		if uploads != nil {
+			result["files_uploaded"] = uploads.Uploaded
+			result["files_reused"] = uploads.Reused
+		}
 		return printStructured(result)
 	}
 
 	fmt.Printf("Successfully imported %d blocks\n", count)
+	if uploads != nil && uploads.Uploaded+uploads.Reused > 0 {
+		fmt.Printf("Files: %d uploaded, %d already uploaded\n", uploads.Uploaded, uploads.Reused)
+	}
 	if importPage != "" {
 		fmt.Printf("Target page: %s\n", importPage)
 	}
@@ -708,53 +752,52 @@ func runImport(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func parseMarkdown(content string) []*MarkdownBlock {
-	var entries []markdownEntry
-	scanner := bufio.NewScanner(strings.NewReader(content))
-
-	// Regex patterns
-	bulletPattern := regexp.MustCompile(`^(\s*)[-*+]\s+(.*)`)
-	headingPattern := regexp.MustCompile(`^(#{1,6})\s+(.*)`)
-	numberedPattern := regexp.MustCompile(`^(\s*)\d+\.\s+(.*)`)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// Skip empty lines
-		if strings.TrimSpace(line) == "" {
-			continue
-		}
-
-		var entry markdownEntry
-		var level int
-
-		// Check for headings
-		if matches := headingPattern.FindStringSubmatch(line); matches != nil {
-			level = 0 // Headings are top-level
-			entry = markdownEntry{level: level, content: matches[2]}
-		} else if matches := bulletPattern.FindStringSubmatch(line); matches != nil {
-			// Calculate level from indentation (2 spaces per level)
-			indent := len(matches[1])
-			level = indent / 2
-			entry = markdownEntry{level: level, content: matches[2]}
-		} else if matches := numberedPattern.FindStringSubmatch(line); matches != nil {
-			// Handle numbered lists
-			indent := len(matches[1])
-			level = indent / 2
-			entry = markdownEntry{level: level, content: matches[2]}
-		} else {
-			// Plain paragraph - treat as top-level block
-			level = 0
-			entry = markdownEntry{level: level, content: strings.TrimSpace(line)}
-		}
+// parseMarkdown converts Markdown into blocks with the shared converter,
+// counting tabs as indent columns (0 for the default). It also returns the
+// view type for the top-level blocks.
+func parseMarkdown(content string, indent int) ([]*MarkdownBlock, string) {
+	parsed, viewType := mdblocks.Parse(content, mdblocks.Options{Indent: indent})
+	return markdownBlocks(parsed), viewType
+}
 
-		if entry.content == "" {
-			continue
+func markdownBlocks(parsed []*mdblocks.Block) []*MarkdownBlock {
+	blocks := make([]*MarkdownBlock, len(parsed))
+	for i, b := range parsed {
+		blocks[i] = &MarkdownBlock{
+			Content:  b.String,
+			Heading:  b.Heading,
+			ViewType: b.ViewType,
+			Children: markdownBlocks(b.Children),
 		}
-		entries = append(entries, entry)
 	}
+	return blocks
+}
+
+// options returns the block options to create a parsed block with.
+func (b *MarkdownBlock) options() api.BlockOptions {
+	opts := api.BlockOptions{Content: b.Content, ChildrenViewType: b.ViewType}
+	if b.Heading > 0 {
+		heading := b.Heading
+		opts.Heading = &heading
+	}
+	return opts
+}
 
-	return buildMarkdownTree(entries)
+// siblingOrder returns the order for the i-th of a run of sibling blocks
+// placed from start, keeping them together and in order.
+func siblingOrder(start interface{}, i int) interface{} {
+	switch start := start.(type) {
+	case int:
+		return start + i
+	case string:
+		if start == "first" {
+			return i
+		}
+	}
+	if i == 0 && start != nil {
+		return start
+	}
+	return "last"
 }
 
 func dryRunImport(blocks []*MarkdownBlock, page, parent string) error {
@@ -783,7 +826,11 @@ func countMarkdownBlocks(blocks []*MarkdownBlock) int {
 func printMarkdownBlocks(blocks []*MarkdownBlock, indent int) {
 	prefix := strings.Repeat("  ", indent)
 	for _, block := range blocks {
-		fmt.Printf("%s- %s\n", prefix, truncateString(block.Content, 60))
+		content := block.Content
+		if block.Heading > 0 {
+			content = strings.Repeat("#", block.Heading) + " " + content
+		}
+		fmt.Printf("%s- %s\n", prefix, truncateString(content, 60))
 		if len(block.Children) > 0 {
 			printMarkdownBlocks(block.Children, indent+1)
 		}
@@ -794,16 +841,8 @@ func importBlocks(client api.RoamAPI, parentUID string, blocks []*MarkdownBlock,
 	count := 0
 
 	for i, block := range blocks {
-		// Determine order for this block
-		var order interface{}
-		if i == 0 && startOrder != nil {
-			order = startOrder
-		} else {
-			order = "last"
-		}
-
 		// Create the block
-		if err := client.CreateBlock(parentUID, block.Content, order); err != nil {
+		if err := client.CreateBlock(parentUID, block.Content, siblingOrder(startOrder, i)); err != nil {
 			return count, fmt.Errorf("failed to create block: %w", err)
 		}
 		count++
@@ -893,7 +932,7 @@ func resolveImportParent(client api.RoamAPI, createInBatch bool) (string, bool,
 	return page.UID, false, nil
 }
 
-func importWithBatch(client api.RoamAPI, blocks []*MarkdownBlock, order interface{}) (int, string, bool, error) {
+func importWithBatch(client api.RoamAPI, blocks []*MarkdownBlock, order interface{}, viewType string) (int, string, bool, error) {
 	parentUID, createdPage, err := resolveImportParent(client, true)
 	if err != nil {
 		return 0, "", false, err
@@ -901,7 +940,9 @@ func importWithBatch(client api.RoamAPI, blocks []*MarkdownBlock, order interfac
 
 	batch := api.NewBatchBuilder()
 	if createdPage {
-		parentUID = batch.CreatePage(api.PageOptions{Title: importPage})
+		parentUID = batch.CreatePage(api.PageOptions{Title: importPage, ChildrenViewType: viewType})
+	} else {
+		addParentViewType(batch, parentUID, importPage != "", viewType)
 	}
 
 	count := buildBatchBlocks(batch, parentUID, blocks, order)
@@ -912,20 +953,93 @@ func importWithBatch(client api.RoamAPI, blocks []*MarkdownBlock, order interfac
 	return count, parentUID, createdPage, nil
 }
 
+// addParentViewType adds the action showing the children of uid, a page
+// when isPage, with viewType, the view type of a top-level Markdown list.
+// It adds nothing for a plain list.
+func addParentViewType(batch *api.BatchBuilder, uid string, isPage bool, viewType string) {
+	switch {
+	case viewType == "":
+	case isPage:
+		batch.UpdatePage(uid, api.PageOptions{ChildrenViewType: viewType})
+	default:
+		batch.UpdateBlock(uid, api.BlockOptions{ChildrenViewType: viewType})
+	}
+}
+
+// setParentViewType is addParentViewType for clients written to one call
+// at a time.
+func setParentViewType(client api.RoamAPI, uid string, isPage bool, viewType string) error {
+	var err error
+	switch {
+	case viewType == "":
+	case isPage:
+		err = client.UpdatePageWithOptions(uid, api.PageOptions{ChildrenViewType: viewType})
+	default:
+		err = client.UpdateBlockWithOptions(uid, api.BlockOptions{ChildrenViewType: viewType})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set view type: %w", err)
+	}
+	return nil
+}
+
+// addLocationViewType adds the action showing the children of the page or
+// block loc adds blocks under with viewType. A page named by title or date
+// that does not exist yet is created in the batch with the view type, and
+// the returned location points at it.
+func addLocationViewType(client api.RoamAPI, batch *api.BatchBuilder, loc api.Location, viewType string) (api.Location, error) {
+	if viewType == "" {
+		return loc, nil
+	}
+	if loc.ParentUID != "" {
+		addParentViewType(batch, loc.ParentUID, false, viewType)
+		return loc, nil
+	}
+	title, uid := loc.PageTitle, ""
+	if loc.DailyNoteDate != "" {
+		t, err := time.Parse("01-02-2006", loc.DailyNoteDate)
+		if err != nil {
+			return loc, fmt.Errorf("invalid daily note date %q (expected MM-DD-YYYY)", loc.DailyNoteDate)
+		}
+		title, uid = formatDailyNoteTitle(t), loc.DailyNoteDate
+	}
+	raw, err := client.GetPageByTitle(title)
+	switch {
+	case err == nil:
+		page, err := roamdb.ParsePage(raw)
+		if err != nil {
+			return loc, fmt.Errorf("failed to parse page: %w", err)
+		}
+		uid = page.UID
+		addParentViewType(batch, uid, true, viewType)
+	case isNotFound(err):
+		if uid == "" {
+			uid = roamdb.NewUID()
+		}
+		batch.CreatePage(api.PageOptions{Title: title, UID: uid, ChildrenViewType: viewType})
+	default:
+		return loc, fmt.Errorf("failed to get page: %w", err)
+	}
+	return api.Location{ParentUID: uid, Order: loc.Order}, nil
+}
+
 func buildBatchBlocks(batch *api.BatchBuilder, parentUID string, blocks []*MarkdownBlock, startOrder interface{}) int {
+	return buildBatchBlocksAt(batch, api.Location{ParentUID: parentUID, Order: startOrder}, blocks)
+}
+
+// buildBatchBlocksAt adds create actions for a block tree at a location,
+// placing the top-level blocks together from loc.Order.
+func buildBatchBlocksAt(batch *api.BatchBuilder, loc api.Location, blocks []*MarkdownBlock) int {
 	count := 0
 
 	for i, block := range blocks {
-		order := interface{}("last")
-		if i == 0 && startOrder != nil {
-			order = startOrder
-		}
-
-		ref := batch.CreateBlock(api.Location{ParentUID: parentUID, Order: order}, api.BlockOptions{Content: block.Content})
+		at := loc
+		at.Order = siblingOrder(loc.Order, i)
+		ref := batch.CreateBlock(at, block.options())
 		count++
 
 		if len(block.Children) > 0 {
-			count += buildBatchBlocks(batch, ref, block.Children, "last")
+			count += buildBatchBlocksAt(batch, api.Location{ParentUID: ref, Order: "last"}, block.Children)
 		}
 	}
 
@@ -936,12 +1050,7 @@ func importBlocksLocal(client *api.LocalClient, parentUID string, blocks []*Mark
 	count := 0
 
 	for i, block := range blocks {
-		order := interface{}("last")
-		if i == 0 && startOrder != nil {
-			order = startOrder
-		}
-
-		uid, err := client.CreateBlockAndGetUID(parentUID, block.Content, order)
+		uid, err := client.CreateBlockAtLocationAndGetUID(api.Location{ParentUID: parentUID, Order: siblingOrder(startOrder, i)}, block.options())
 		if err != nil {
 			return count, fmt.Errorf("failed to create block: %w", err)
 		}
@@ -972,4 +1081,6 @@ func init() {
 	importCmd.Flags().StringVar(&importParent, "parent", "", "Parent block UID")
 	importCmd.Flags().StringVar(&importOrder, "order", "last", "Position: number, 'first', or 'last'")
 	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Preview import without executing")
+	importCmd.Flags().BoolVar(&importUpload, "upload-assets", false, "Upload local images and files the markdown links to")
+	importCmd.Flags().IntVar(&importIndent, "indent", mdblocks.DefaultIndent, "Columns a tab counts as when nesting list items")
 }
@@ -151,7 +151,7 @@ func TestParseImportOrder(t *testing.T) {
 
 func TestParseMarkdownAndImportBlocks(t *testing.T) {
 	markdown := "# Heading\n- Bullet\n  - Child\n1. Numbered\n"
-	blocks := parseMarkdown(markdown)
+	blocks, _ := parseMarkdown(markdown, 0)
 	if len(blocks) == 0 {
 		t.Fatal("expected parsed blocks")
 	}
@@ -127,3 +127,57 @@ func TestRunImportExecute(t *testing.T) {
 		t.Fatalf("runImport failed: %v", err)
 	}
 }
+
+func TestImportSetsNumberedViewOnTarget(t *testing.T) {
+	var viewType string
+	fake := &fakeClient{
+		GetPageByTitleFunc: func(title string) (json.RawMessage, error) {
+			return json.RawMessage(`{"node/title":"Test","block/uid":"page-uid"}`), nil
+		},
+		CreateBlockFunc: func(parentUID, content string, order interface{}) error { return nil },
+		UpdatePageWithOptionsFunc: func(uid string, opts api.PageOptions) error {
+			if uid != "page-uid" {
+				t.Fatalf("unexpected page %q", uid)
+			}
+			viewType = opts.ChildrenViewType
+			return nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+
+	_, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(importCmd)
+
+	filePath := filepath.Join(t.TempDir(), "steps.md")
+	if err := os.WriteFile(filePath, []byte("1. one\n2. two\n"), 0o644); err != nil {
+		t.Fatalf("write markdown: %v", err)
+	}
+	importPage = "Test"
+	defer func() { importPage = "" }()
+
+	if err := runImport(importCmd, []string{filePath}); err != nil {
+		t.Fatalf("runImport failed: %v", err)
+	}
+	if viewType != "numbered" {
+		t.Fatalf("expected numbered view on the page, got %q", viewType)
+	}
+
+	// A page created in the batch takes the view with it.
+	fake.GetPageByTitleFunc = func(title string) (json.RawMessage, error) {
+		return nil, api.NotFoundError{Message: "page not found"}
+	}
+	var actions []map[string]interface{}
+	fake.ExecuteBatchFunc = func(b *api.BatchBuilder) error {
+		actions = b.Build()
+		return nil
+	}
+	blocks, viewType := parseMarkdown("1. one\n2. two\n", 0)
+	if _, _, _, err := importWithBatch(fake, blocks, "last", viewType); err != nil {
+		t.Fatalf("importWithBatch failed: %v", err)
+	}
+	if page := actions[0]["page"].(map[string]interface{}); page["children-view-type"] != "numbered" {
+		t.Fatalf("unexpected create-page action: %v", actions[0])
+	}
+}
@@ -11,8 +11,13 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/edn"
+	"github.com/salmonumbrella/roam-cli/internal/mdblocks"
 	"github.com/salmonumbrella/roam-cli/internal/output"
+	"github.com/salmonumbrella/roam-cli/internal/render"
 	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+	"github.com/salmonumbrella/roam-cli/internal/trash"
+	"github.com/salmonumbrella/roam-cli/internal/upload"
 )
 
 var blockCmd = &cobra.Command{
@@ -41,15 +46,26 @@ var blockGetCmd = &cobra.Command{
 The UID is the 9-character alphanumeric identifier that Roam assigns to each block.
 You can find a block's UID by right-clicking on it and selecting "Copy block ref".
 
-Use --depth to control how many levels of child blocks to retrieve.`,
+Use --depth to control how many levels of child blocks to retrieve, and
+--render to write the block and those children in another format.
+
+` + renderFormatsHelp + `
+
+` + resolveRefsHelp + `
+`,
 	Example: `  roam block get abc123def
   roam block get abc123def --depth 3
+  roam block get abc123def --depth 5 --render markdown
+  roam block get abc123def --depth 5 --resolve-refs
   roam block get abc123def --output json`,
 	Args: cobra.ExactArgs(1),
 	RunE: runBlockGet,
 }
 
-var blockGetDepth int
+var (
+	blockGetDepth  int
+	blockGetRender string
+)
 
 func runBlockGet(cmd *cobra.Command, args []string) error {
 	uid := args[0]
@@ -62,7 +78,12 @@ func runBlockGet(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get block: %w", err)
 	}
 
-	if structuredOutputRequested() {
+	resolver, err := refResolverFromFlags(cmd, client)
+	if err != nil {
+		return err
+	}
+
+	if structuredOutputRequested() && resolver == nil {
 		if err := printRawStructured(data); err != nil {
 			fmt.Println(string(data))
 		}
@@ -74,15 +95,41 @@ func runBlockGet(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	doc := render.FromBlock(block)
+	if resolver != nil {
+		resolved, err := resolver.ResolveBlocks([]roamdb.Block{*block})
+		if err != nil {
+			return err
+		}
+		block = &resolved[0]
+		doc = render.FromBlock(block)
+		doc.Footnotes = resolver.Footnotes
+		if structuredOutputRequested() {
+			return printStructured(resolvedBlock{Block: block, Footnotes: resolver.Footnotes})
+		}
+	}
+
+	if !textRenderRequested(blockGetRender) {
+		return renderDocument(stdoutFromContext(cmd.Context()), blockGetRender, data, doc)
+	}
 	printBlockText(*block, 0)
+	printFootnotes(doc.Footnotes)
 	return nil
 }
 
 func buildBlockSelector(depth int) string {
+	return edn.Marshal(blockSelector(depth))
+}
+
+// blockSelector is the pull pattern for a block and depth levels of
+// children: [*], [* {:block/children [*]}], ...
+func blockSelector(depth int) edn.Vector {
 	if depth <= 0 {
-		return "[*]"
+		return edn.Vector{edn.Symbol("*")}
 	}
-	return fmt.Sprintf("[* {:block/children %s}]", buildBlockSelector(depth-1))
+	children := &edn.Map{}
+	children.Set(edn.Keyword("block/children"), blockSelector(depth-1))
+	return edn.Vector{edn.Symbol("*"), children}
 }
 
 func printBlockText(block roamdb.Block, indent int) {
@@ -114,10 +161,17 @@ var blockCreateCmd = &cobra.Command{
 	Long: `Create a new block under a parent block or page.
 
 The parent UID can be either a block UID or a page UID.
-Order can be a number (0-indexed position) or "first"/"last".`,
+Order can be a number (0-indexed position) or "first"/"last".
+
+With --template, the template's blocks are created at the location instead,
+or under the new block when --content is given too.
+
+` + templateHelp,
 	Example: `  roam block create --parent abc123 --content "New block"
   roam block create --parent abc123 --content "First child" --order first
-  roam block create --parent abc123 --content "Third child" --order 2`,
+  roam block create --parent abc123 --content "Third child" --order 2
+  roam block create --daily-note 10-16-2026 --template "Daily Review"
+  roam block create --page-title "Projects" --content "Alpha" --template xyz789 --var owner=Sam`,
 	RunE: runBlockCreate,
 }
 
@@ -134,6 +188,8 @@ var (
 	blockCreateViewType     string
 	blockCreateChildrenView string
 	blockCreateProps        string
+	blockCreateTemplate     string
+	blockCreateVars         []string
 )
 
 var (
@@ -143,18 +199,30 @@ var (
 	blockFromMarkdownOrder     string
 	blockFromMarkdownContent   string
 	blockFromMarkdownFile      string
+	blockFromMarkdownIndent    int
+	blockFromMarkdownUpload    bool
 )
 
 var blockFromMarkdownCmd = &cobra.Command{
 	Use:   "from-markdown",
-	Short: "Insert blocks from markdown (local only)",
-	Long: `Parse a markdown string into blocks and insert them at a location using the Local API.
+	Short: "Insert blocks from markdown",
+	Long: `Parse a markdown string into blocks and insert them at a location.
+
+With the Local API, Roam's own markdown importer parses the markdown. With
+the cloud API, it is converted like the import command does and the blocks
+are created in one batch.
+
+With --upload-assets, local images and files the markdown links to are
+uploaded first (see 'roam import --help') and the links rewritten. Relative
+links resolve against the --markdown-file directory, or the working
+directory for inline or piped markdown.
 
 Examples:
   roam block from-markdown --parent abc123 --markdown "# Title\n- Item"
   roam block from-markdown --page-title "My Page" --order first --markdown-file notes.md
   roam block from-markdown --page-title "Notes" --markdown-file -
-  cat notes.md | roam block from-markdown --daily-note 01-14-2026`,
+  cat notes.md | roam block from-markdown --daily-note 01-14-2026
+  roam block from-markdown --page-title "Trip" --markdown-file trip/notes.md --upload-assets`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		markdown, err := readMarkdownFromFlags(blockFromMarkdownFile, blockFromMarkdownContent, cmd.InOrStdin())
 		if err != nil {
@@ -191,11 +259,6 @@ Examples:
 			}
 		}
 
-		localClient, ok := GetClient().(*api.LocalClient)
-		if !ok {
-			return fmt.Errorf("block from-markdown requires the Local API (encrypted graph)")
-		}
-
 		loc := api.Location{Order: order}
 		if blockFromMarkdownParent != "" {
 			loc.ParentUID = blockFromMarkdownParent
@@ -205,6 +268,33 @@ Examples:
 			loc.DailyNoteDate = blockFromMarkdownDailyNote
 		}
 
+		client := GetClient()
+		var uploads *upload.Session
+		if blockFromMarkdownUpload {
+			markdown, uploads, err = uploadMarkdownAssets(client, markdown, markdownDir(blockFromMarkdownFile), stderrFromContext(cmd.Context()))
+			if err != nil {
+				return err
+			}
+		}
+
+		localClient, ok := client.(*api.LocalClient)
+		if !ok {
+			blocks, viewType := parseMarkdown(markdown, blockFromMarkdownIndent)
+			if len(blocks) == 0 {
+				return fmt.Errorf("no content found in markdown")
+			}
+			batch := api.NewBatchBuilder()
+			at, err := addLocationViewType(client, batch, loc, viewType)
+			if err != nil {
+				return err
+			}
+			buildBatchBlocksAt(batch, at, blocks)
+			if err := client.ExecuteBatch(batch); err != nil {
+				return fmt.Errorf("failed to insert markdown blocks: %w", err)
+			}
+			goto blockCreated
+		}
+
 		if err := localClient.CreateBlocksFromMarkdownAtLocation(loc, markdown); err != nil {
 			var localErr api.LocalAPIError
 			if errors.As(err, &localErr) && localErr.IsResponseTimeout() {
@@ -231,16 +321,23 @@ Examples:
 			if loc.DailyNoteDate != "" {
 				result["daily_note"] = loc.DailyNoteDate
 			}
+			if uploads != nil {
+				result["files_uploaded"] = uploads.Uploaded
+				result["files_reused"] = uploads.Reused
+			}
 			return printStructured(result)
 		}
 
 		fmt.Println("Inserted markdown blocks.")
+		if uploads != nil && uploads.Uploaded+uploads.Reused > 0 {
+			fmt.Printf("Files: %d uploaded, %d already uploaded\n", uploads.Uploaded, uploads.Reused)
+		}
 		return nil
 	},
 }
 
 func runBlockCreate(cmd *cobra.Command, args []string) error {
-	if blockCreateContent == "" {
+	if blockCreateContent == "" && blockCreateTemplate == "" {
 		return fmt.Errorf("--content flag is required")
 	}
 
@@ -301,6 +398,11 @@ func runBlockCreate(cmd *cobra.Command, args []string) error {
 		opts.Heading = &blockCreateHeading
 	}
 
+	if blockCreateTemplate != "" {
+		loc := api.Location{ParentUID: blockCreateParent, PageTitle: blockCreatePageTitle, DailyNoteDate: blockCreateDailyNote, Order: order}
+		return createBlocksFromTemplate(client, loc, opts, blockCreateTemplate, blockCreateVars)
+	}
+
 	// Build location and create block
 	var locationDesc string
 
@@ -532,15 +634,23 @@ blockMoved:
 	return nil
 }
 
+var blockDeleteNoTrash bool
+
 // Delete block command
 var blockDeleteCmd = &cobra.Command{
 	Use:   "delete <uid>",
 	Short: "Delete a block",
 	Long: `Delete a block and all its children.
 
-This operation is irreversible. Use --yes to skip the confirmation prompt.`,
+Without --yes the command only shows what would be deleted: the number of
+blocks in the subtree and how many other blocks reference the block or its
+children. With --yes the block is saved with its children to the local
+trash and then deleted; see 'roam trash restore'. If the block can't be
+read for the trash, nothing is deleted; --no-trash skips the trash and the
+impact report.`,
 	Example: `  roam block delete abc123
-  roam block delete abc123 --yes`,
+  roam block delete abc123 --yes
+  roam block delete abc123 --yes --no-trash`,
 	Args: cobra.ExactArgs(1),
 	RunE: runBlockDelete,
 }
@@ -549,34 +659,34 @@ func runBlockDelete(cmd *cobra.Command, args []string) error {
 	uid := args[0]
 	client := GetClient()
 
-	if !output.YesFromContext(cmd.Context()) {
-		// Try to get block info first to show what will be deleted
-		data, err := client.GetBlockByUID(uid)
-		if err != nil {
-			return fmt.Errorf("failed to get block: %w", err)
-		}
-
-		block, err := roamdb.ParseBlock(data)
-		if err != nil {
-			return fmt.Errorf("failed to parse block data: %w", err)
-		}
-
-		content := block.String
-		if len(content) > 50 {
-			content = content[:50] + "..."
-		}
+	entry, referencing, err := deleteTrashEntry(client, trash.KindBlock, uid, blockDeleteNoTrash, cmd.ErrOrStderr())
+	if err != nil {
+		return err
+	}
 
+	if !output.YesFromContext(cmd.Context()) {
 		errOut := cmd.ErrOrStderr()
 		fmt.Fprintf(errOut, "Are you sure you want to delete this block?\n")
 		fmt.Fprintf(errOut, "  UID: %s\n", uid)
-		if content != "" {
-			fmt.Fprintf(errOut, "  Content: %s\n", content)
+		if entry != nil {
+			printDeleteImpact(errOut, entry)
+			fmt.Fprintf(errOut, "\nThe block is saved to the local trash first. Use --yes to confirm.\n")
+		} else {
+			fmt.Fprintf(errOut, "\nThe block is not saved to the trash. Use --yes to confirm.\n")
 		}
-		fmt.Fprintf(errOut, "\nThis action cannot be undone. Use --yes to confirm.\n")
 		return nil
 	}
 
+	var trashDir string
+	if entry != nil {
+		if trashDir, err = saveToTrash(entry); err != nil {
+			return err
+		}
+	}
 	if err := client.DeleteBlock(uid); err != nil {
+		if entry != nil {
+			discardTrashEntry(trashDir, entry)
+		}
 		return fmt.Errorf("failed to delete block: %w", err)
 	}
 
@@ -586,9 +696,17 @@ func runBlockDelete(cmd *cobra.Command, args []string) error {
 			"uid":     uid,
 			"deleted": true,
 		}
+		if entry != nil {
+			result["blocks"] = entry.Blocks
+			result["referenced_by"] = referencing
+			result["trash_id"] = entry.ID
+		}
 		return printStructured(result)
 	} else {
 		fmt.Printf("Block %s deleted successfully\n", uid)
+		if entry != nil {
+			fmt.Printf("Saved to trash: %s\n", entry.ID)
+		}
 	}
 
 	return nil
@@ -713,6 +831,8 @@ func init() {
 	// Get command
 	blockCmd.AddCommand(blockGetCmd)
 	blockGetCmd.Flags().IntVar(&blockGetDepth, "depth", 0, "Number of child levels to retrieve (0 = no children)")
+	blockGetCmd.Flags().StringVarP(&blockGetRender, "render", "f", "text", renderFlagUsage)
+	addResolveRefsFlags(blockGetCmd)
 
 	// Create command
 	blockCmd.AddCommand(blockCreateCmd)
@@ -728,7 +848,8 @@ func init() {
 	blockCreateCmd.Flags().StringVar(&blockCreateViewType, "view-type", "", "Block view: bullet, numbered, document")
 	blockCreateCmd.Flags().StringVar(&blockCreateChildrenView, "children-view", "", "Children view: bullet, numbered, document")
 	blockCreateCmd.Flags().StringVar(&blockCreateProps, "props", "", "Block props as JSON object")
-	_ = blockCreateCmd.MarkFlagRequired("content")
+	blockCreateCmd.Flags().StringVar(&blockCreateTemplate, "template", "", "Copy a template: page title, roam/templates name or block uid")
+	blockCreateCmd.Flags().StringArrayVar(&blockCreateVars, "var", nil, "Template variable as name=value (repeatable)")
 
 	// From-markdown command
 	blockCmd.AddCommand(blockFromMarkdownCmd)
@@ -738,6 +859,8 @@ func init() {
 	blockFromMarkdownCmd.Flags().StringVar(&blockFromMarkdownOrder, "order", "last", "Position: number, 'first', or 'last'")
 	blockFromMarkdownCmd.Flags().StringVar(&blockFromMarkdownContent, "markdown", "", "Markdown content as a string")
 	blockFromMarkdownCmd.Flags().StringVar(&blockFromMarkdownFile, "markdown-file", "", "Markdown file path (or - for stdin)")
+	blockFromMarkdownCmd.Flags().BoolVar(&blockFromMarkdownUpload, "upload-assets", false, "Upload local images and files the markdown links to")
+	blockFromMarkdownCmd.Flags().IntVar(&blockFromMarkdownIndent, "indent", mdblocks.DefaultIndent, "Columns a tab counts as when nesting list items (cloud only)")
 
 	// Update command
 	blockCmd.AddCommand(blockUpdateCmd)
@@ -758,4 +881,5 @@ func init() {
 
 	// Delete command
 	blockCmd.AddCommand(blockDeleteCmd)
+	blockDeleteCmd.Flags().BoolVar(&blockDeleteNoTrash, "no-trash", false, "Delete without saving the block to the local trash")
 }
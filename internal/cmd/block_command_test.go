@@ -163,6 +163,9 @@ func TestRunBlockMove(t *testing.T) {
 func TestRunBlockDeleteStructured(t *testing.T) {
 	var deleted string
 	fake := &fakeClient{
+		GetBlockByUIDFunc: func(uid string) (json.RawMessage, error) {
+			return json.RawMessage(`{"block/string":"bye","block/uid":"uid-3"}`), nil
+		},
 		DeleteBlockFunc: func(uid string) error {
 			deleted = uid
 			return nil
@@ -170,6 +173,7 @@ func TestRunBlockDeleteStructured(t *testing.T) {
 	}
 	restoreClient := withTestClient(t, fake)
 	defer restoreClient()
+	withTestTrash(t)
 
 	_, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
 	defer restoreCtx()
@@ -263,8 +267,57 @@ func TestBlockFromMarkdownLocal(t *testing.T) {
 	}
 }
 
-func TestBlockFromMarkdownRequiresLocal(t *testing.T) {
-	fake := &fakeClient{}
+func TestBlockFromMarkdownUsesBatchOnCloud(t *testing.T) {
+	var actions []map[string]interface{}
+	fake := &fakeClient{
+		ExecuteBatchFunc: func(b *api.BatchBuilder) error {
+			actions = b.Build()
+			return nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+
+	_, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(blockFromMarkdownCmd)
+
+	blockFromMarkdownPageTitle = "Notes"
+	blockFromMarkdownOrder = "first"
+	blockFromMarkdownContent = "## Heading\n- [ ] Item\n\nAfter"
+	defer func() {
+		blockFromMarkdownPageTitle = ""
+		blockFromMarkdownOrder = "last"
+		blockFromMarkdownContent = ""
+	}()
+
+	if err := blockFromMarkdownCmd.RunE(blockFromMarkdownCmd, nil); err != nil {
+		t.Fatalf("from-markdown failed: %v", err)
+	}
+	if len(actions) != 3 {
+		t.Fatalf("expected 3 actions, got %d", len(actions))
+	}
+	loc := actions[0]["location"].(map[string]interface{})
+	block := actions[0]["block"].(map[string]interface{})
+	if loc["page-title"] != "Notes" || loc["order"] != 0 || block["heading"] != 2 {
+		t.Fatalf("unexpected heading action: %v", actions[0])
+	}
+	if block := actions[1]["block"].(map[string]interface{}); block["string"] != "{{[[TODO]]}} Item" {
+		t.Fatalf("unexpected task block: %v", block)
+	}
+}
+
+func TestBlockFromMarkdownKeepsNumberedListOnCloud(t *testing.T) {
+	var actions []map[string]interface{}
+	fake := &fakeClient{
+		GetPageByTitleFunc: func(title string) (json.RawMessage, error) {
+			return nil, api.NotFoundError{Message: "page not found"}
+		},
+		ExecuteBatchFunc: func(b *api.BatchBuilder) error {
+			actions = b.Build()
+			return nil
+		},
+	}
 	restoreClient := withTestClient(t, fake)
 	defer restoreClient()
 
@@ -273,14 +326,35 @@ func TestBlockFromMarkdownRequiresLocal(t *testing.T) {
 	setCmdContext(blockFromMarkdownCmd)
 
 	blockFromMarkdownParent = "parent-uid"
-	blockFromMarkdownContent = "- Item"
+	blockFromMarkdownContent = "1. one\n2. two"
 	defer func() {
 		blockFromMarkdownParent = ""
+		blockFromMarkdownPageTitle = ""
 		blockFromMarkdownContent = ""
 	}()
 
-	if err := blockFromMarkdownCmd.RunE(blockFromMarkdownCmd, nil); err == nil {
-		t.Fatalf("expected error for non-local client")
+	if err := blockFromMarkdownCmd.RunE(blockFromMarkdownCmd, nil); err != nil {
+		t.Fatalf("from-markdown failed: %v", err)
+	}
+	if len(actions) != 3 || actions[0]["action"] != "update-block" {
+		t.Fatalf("expected the parent updated first, got %v", actions)
+	}
+	if block := actions[0]["block"].(map[string]interface{}); block["uid"] != "parent-uid" || block["children-view-type"] != "numbered" {
+		t.Fatalf("unexpected parent update: %v", block)
+	}
+
+	// A page that does not exist yet is created with the view.
+	blockFromMarkdownParent = ""
+	blockFromMarkdownPageTitle = "Steps"
+	if err := blockFromMarkdownCmd.RunE(blockFromMarkdownCmd, nil); err != nil {
+		t.Fatalf("from-markdown failed: %v", err)
+	}
+	page := actions[0]["page"].(map[string]interface{})
+	if actions[0]["action"] != "create-page" || page["title"] != "Steps" || page["children-view-type"] != "numbered" {
+		t.Fatalf("unexpected page action: %v", actions[0])
+	}
+	if loc := actions[1]["location"].(map[string]interface{}); loc["parent-uid"] != page["uid"] {
+		t.Fatalf("expected blocks under the new page, got %v", loc)
 	}
 }
 
@@ -37,6 +37,7 @@ var configShowCmd = &cobra.Command{
 		fmt.Printf("  token: %s\n", maskToken(cfg.Token))
 		fmt.Printf("  keyring_backend: %s\n", cfg.KeyringBackend)
 		fmt.Printf("  output_format: %s\n", cfg.OutputFormat)
+		fmt.Printf("  upload_url: %s\n", cfg.UploadURL)
 		return nil
 	},
 }
@@ -88,6 +89,7 @@ func supportedConfigKeys() []string {
 		"token",
 		"keyring_backend",
 		"output_format",
+		"upload_url",
 	}
 }
 
@@ -103,6 +105,8 @@ func applyConfigValue(cfg *config.Config, key, value string) error {
 		cfg.KeyringBackend = value
 	case "output_format":
 		cfg.OutputFormat = value
+	case "upload_url":
+		cfg.UploadURL = value
 	default:
 		return fmt.Errorf("unknown config key: %s", key)
 	}
@@ -121,6 +125,8 @@ func clearConfigValue(cfg *config.Config, key string) error {
 		cfg.KeyringBackend = ""
 	case "output_format":
 		cfg.OutputFormat = ""
+	case "upload_url":
+		cfg.UploadURL = ""
 	default:
 		return fmt.Errorf("unknown config key: %s", key)
 	}
@@ -211,5 +217,6 @@ func configOutput(cfg *config.Config) map[string]interface{} {
 		"token_set":       cfg.Token != "",
 		"keyring_backend": cfg.KeyringBackend,
 		"output_format":   cfg.OutputFormat,
+		"upload_url":      cfg.UploadURL,
 	}
 }
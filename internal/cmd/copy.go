@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// copyDepthHelp explains --depth for block copy and page duplicate.
+const copyDepthHelp = "Levels of children to copy (-1 = all)"
+
+var (
+	blockCopyParent string
+	blockCopyPage   string
+	blockCopyDaily  string
+	blockCopyOrder  string
+	blockCopyDepth  int
+	blockCopyAsRefs bool
+
+	pageDuplicateDepth  int
+	pageDuplicateAsRefs bool
+)
+
+// CopyResult summarizes a block copy or page duplicate.
+type CopyResult struct {
+	Source string `json:"source"`
+	// UIDs are the uids of the top-level copies.
+	UIDs         []string               `json:"uids,omitempty"`
+	Title        string                 `json:"title,omitempty"`
+	PageUID      string                 `json:"page_uid,omitempty"`
+	Target       map[string]interface{} `json:"target,omitempty"`
+	BlocksCopied int                    `json:"blocks_copied"`
+	AsRefs       bool                   `json:"as_refs,omitempty"`
+}
+
+var blockCopyCmd = &cobra.Command{
+	Use:   "copy <uid>",
+	Short: "Copy a block and its children to a new location",
+	Long: `Copy a block and its children under a new parent.
+
+The copies get new uids and keep the originals' headings, props, text
+alignment, view types and open state. With --as-refs each copy holds a
+((uid)) reference to its original instead of its text, so the copy stays
+in step with the source. --depth limits how many levels of children are
+copied.
+
+With the cloud API the whole copy is created in one batch; with the Local
+API the blocks are created one at a time.`,
+	Example: `  roam block copy abc123 --parent def456
+  roam block copy abc123 --page-title "Archive" --order first
+  roam block copy abc123 --daily-note 10-18-2026 --as-refs
+  roam block copy abc123 --parent def456 --depth 1 -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBlockCopy,
+}
+
+var pageDuplicateCmd = &cobra.Command{
+	Use:   "duplicate <title> <new-title>",
+	Short: "Copy a page and all its blocks to a new page",
+	Long: `Create a new page holding a copy of every block on a page.
+
+The new page takes the source's children view type, and the copied blocks
+get new uids and keep their attributes, as with 'roam block copy'. It fails
+if a page titled <new-title> already exists.`,
+	Example: `  roam page duplicate "Project Alpha" "Project Beta"
+  roam page duplicate "Weekly Review" "Weekly Review (refs)" --as-refs
+  roam page duplicate "Handbook" "Handbook outline" --depth 0`,
+	Args: cobra.ExactArgs(2),
+	RunE: runPageDuplicate,
+}
+
+func init() {
+	blockCopyCmd.Flags().StringVar(&blockCopyParent, "parent", "", "Parent block or page UID")
+	blockCopyCmd.Flags().StringVar(&blockCopyPage, "page-title", "", "Copy to page title (creates if not exists)")
+	blockCopyCmd.Flags().StringVar(&blockCopyDaily, "daily-note", "", "Copy to daily note by date (MM-DD-YYYY)")
+	blockCopyCmd.Flags().StringVar(&blockCopyOrder, "order", "last", "Position: number, 'first', or 'last'")
+	blockCopyCmd.Flags().IntVar(&blockCopyDepth, "depth", -1, copyDepthHelp)
+	blockCopyCmd.Flags().BoolVar(&blockCopyAsRefs, "as-refs", false, "Create ((uid)) references to the originals instead of copying text")
+	blockCmd.AddCommand(blockCopyCmd)
+
+	pageDuplicateCmd.Flags().IntVar(&pageDuplicateDepth, "depth", -1, copyDepthHelp)
+	pageDuplicateCmd.Flags().BoolVar(&pageDuplicateAsRefs, "as-refs", false, "Create ((uid)) references to the originals instead of copying text")
+	pageCmd.AddCommand(pageDuplicateCmd)
+}
+
+func runBlockCopy(cmd *cobra.Command, args []string) error {
+	uid := args[0]
+
+	targets := 0
+	for _, target := range []string{blockCopyParent, blockCopyPage, blockCopyDaily} {
+		if target != "" {
+			targets++
+		}
+	}
+	if targets == 0 {
+		return fmt.Errorf("one target is required (--parent, --page-title, or --daily-note)")
+	}
+	if targets > 1 {
+		return fmt.Errorf("only one target is allowed (--parent, --page-title, or --daily-note)")
+	}
+
+	var order interface{} = blockCopyOrder
+	if blockCopyOrder != "first" && blockCopyOrder != "last" {
+		n, err := strconv.Atoi(blockCopyOrder)
+		if err != nil {
+			return fmt.Errorf("invalid order value: %s (must be a number, 'first', or 'last')", blockCopyOrder)
+		}
+		order = n
+	}
+	loc := api.Location{ParentUID: blockCopyParent, PageTitle: blockCopyPage, DailyNoteDate: blockCopyDaily, Order: order}
+
+	client := GetClient()
+	raw, err := client.GetBlockByUID(uid)
+	if err != nil {
+		return fmt.Errorf("failed to get block: %w", err)
+	}
+	block, err := roamdb.ParseBlock(raw)
+	if err != nil {
+		return fmt.Errorf("failed to parse block: %w", err)
+	}
+
+	result := CopyResult{Source: uid, Target: loc.ToMap(), AsRefs: blockCopyAsRefs}
+	blocks := []roamdb.Block{*block}
+	if localClient, ok := client.(*api.LocalClient); ok {
+		result.UIDs, result.BlocksCopied, err = copyBlocksLocal(localClient, loc, blocks, blockCopyDepth, blockCopyAsRefs)
+	} else {
+		batch := api.NewBatchBuilder()
+		result.UIDs, result.BlocksCopied = addCopyBlocks(batch, loc, blocks, blockCopyDepth, blockCopyAsRefs)
+		err = client.ExecuteBatch(batch)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to copy block: %w", err)
+	}
+
+	if structuredOutputRequested() {
+		return printStructured(result)
+	}
+	fmt.Printf("Copied %d blocks from %s\n", result.BlocksCopied, uid)
+	if len(result.UIDs) > 0 {
+		fmt.Printf("New UID: %s\n", result.UIDs[0])
+	}
+	return nil
+}
+
+func runPageDuplicate(cmd *cobra.Command, args []string) error {
+	title, newTitle := args[0], args[1]
+	client := GetClient()
+
+	raw, err := client.GetPageByTitle(title)
+	if err != nil {
+		if isNotFound(err) {
+			return fmt.Errorf("page not found: %s", title)
+		}
+		return fmt.Errorf("failed to get page: %w", err)
+	}
+	page, err := roamdb.ParsePage(raw)
+	if err != nil {
+		return fmt.Errorf("failed to parse page: %w", err)
+	}
+	if _, err := client.GetPageByTitle(newTitle); err == nil {
+		return fmt.Errorf("page already exists: %s", newTitle)
+	} else if !isNotFound(err) {
+		return fmt.Errorf("failed to check existing page: %w", err)
+	}
+
+	opts := api.PageOptions{Title: newTitle, UID: roamdb.NewUID(), ChildrenViewType: page.ViewType}
+	result := CopyResult{Source: title, Title: newTitle, PageUID: opts.UID, AsRefs: pageDuplicateAsRefs}
+	loc := api.Location{ParentUID: opts.UID, Order: "last"}
+	depth := pageDuplicateDepth
+	if localClient, ok := client.(*api.LocalClient); ok {
+		if err := localClient.CreatePageWithOptions(opts); err != nil {
+			return fmt.Errorf("failed to create page: %w", err)
+		}
+		result.UIDs, result.BlocksCopied, err = copyBlocksLocal(localClient, loc, page.Children, depth, pageDuplicateAsRefs)
+	} else {
+		batch := api.NewBatchBuilder()
+		batch.CreatePage(opts)
+		result.UIDs, result.BlocksCopied = addCopyBlocks(batch, loc, page.Children, depth, pageDuplicateAsRefs)
+		err = client.ExecuteBatch(batch)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to duplicate page: %w", err)
+	}
+
+	if structuredOutputRequested() {
+		return printStructured(result)
+	}
+	fmt.Printf("Duplicated %s as %s (%d blocks)\n", title, newTitle, result.BlocksCopied)
+	return nil
+}
+
+// copyOptions returns the options that create a copy of b: its content, or
+// a ((ref)) to it with asRefs, and its attributes.
+func copyOptions(b *roamdb.Block, asRefs bool) api.BlockOptions {
+	opts := exportBlockOptions(b)
+	if asRefs && b.UID != "" {
+		opts.Content = "((" + b.UID + "))"
+	}
+	return opts
+}
+
+// addCopyBlocks adds create actions for copies of blocks and depth levels
+// of their children (all with a negative depth), placing the top-level
+// copies together from loc.Order. Each copy gets a new uid, so the copies
+// can be reported as the Local API reports them. It returns the uids of the
+// top-level copies and the number of blocks copied.
+func addCopyBlocks(batch *api.BatchBuilder, loc api.Location, blocks []roamdb.Block, depth int, asRefs bool) ([]string, int) {
+	var uids []string
+	count := 0
+	for i := range blocks {
+		at := loc
+		at.Order = siblingOrder(loc.Order, i)
+		opts := copyOptions(&blocks[i], asRefs)
+		opts.UID = roamdb.NewUID()
+		batch.CreateBlock(at, opts)
+		uids = append(uids, opts.UID)
+		count++
+		if depth != 0 {
+			_, n := addCopyBlocks(batch, api.Location{ParentUID: opts.UID, Order: "last"}, blocks[i].Children, depth-1, asRefs)
+			count += n
+		}
+	}
+	return uids, count
+}
+
+// copyBlocksLocal creates copies like addCopyBlocks, one block at a time,
+// placing children under the uid the Local API returns for their parent's
+// copy. It returns the uids of the top-level copies and the number of
+// blocks copied.
+func copyBlocksLocal(client *api.LocalClient, loc api.Location, blocks []roamdb.Block, depth int, asRefs bool) ([]string, int, error) {
+	var uids []string
+	count := 0
+	for i := range blocks {
+		at := loc
+		at.Order = siblingOrder(loc.Order, i)
+		uid, err := client.CreateBlockAtLocationAndGetUID(at, copyOptions(&blocks[i], asRefs))
+		if err != nil {
+			return uids, count, err
+		}
+		uids = append(uids, uid)
+		count++
+		if depth != 0 {
+			_, n, err := copyBlocksLocal(client, api.Location{ParentUID: uid, Order: "last"}, blocks[i].Children, depth-1, asRefs)
+			count += n
+			if err != nil {
+				return uids, count, err
+			}
+		}
+	}
+	return uids, count, nil
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/output"
+)
+
+const copySource = `{"block/uid":"src","block/string":"Plan","block/heading":2,"children/view-type":"numbered","block/text-align":"center","block/children":[
+	{"block/uid":"c1","block/string":"step one","block/order":0,"block/children":[
+		{"block/uid":"g1","block/string":"detail","block/order":0}]},
+	{"block/uid":"c2","block/string":"step two","block/order":1,"block/props":{"k":"v"}}]}`
+
+func resetBlockCopyFlags() {
+	blockCopyParent, blockCopyPage, blockCopyDaily = "", "", ""
+	blockCopyOrder, blockCopyDepth, blockCopyAsRefs = "last", -1, false
+}
+
+func TestBlockCopyBatchesWithNewUIDs(t *testing.T) {
+	var actions []map[string]interface{}
+	client := &fakeClient{
+		GetBlockByUIDFunc: func(uid string) (json.RawMessage, error) {
+			return json.RawMessage(copySource), nil
+		},
+		ExecuteBatchFunc: func(b *api.BatchBuilder) error {
+			actions = b.Build()
+			return nil
+		},
+	}
+	restoreClient := withTestClient(t, client)
+	defer restoreClient()
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(blockCopyCmd)
+	defer resetBlockCopyFlags()
+
+	blockCopyParent = "dest"
+	blockCopyDepth = 1
+	if err := runBlockCopy(blockCopyCmd, []string{"src"}); err != nil {
+		t.Fatalf("block copy failed: %v", err)
+	}
+	if len(actions) != 3 {
+		t.Fatalf("expected the block and two children, got %v", actions)
+	}
+	root := actions[0]["block"].(map[string]interface{})
+	rootUID, _ := root["uid"].(string)
+	if rootUID == "" || rootUID == "src" || root["string"] != "Plan" || root["heading"] != 2 || root["children-view-type"] != "numbered" || root["text-align"] != "center" {
+		t.Fatalf("unexpected root copy: %v", root)
+	}
+	if actions[0]["location"].(map[string]interface{})["parent-uid"] != "dest" {
+		t.Fatalf("unexpected root location: %v", actions[0])
+	}
+	for _, a := range actions[1:] {
+		if a["location"].(map[string]interface{})["parent-uid"] != rootUID {
+			t.Fatalf("child not placed under the root copy: %v", a)
+		}
+	}
+	if actions[2]["block"].(map[string]interface{})["props"].(map[string]interface{})["k"] != "v" {
+		t.Fatalf("props not copied: %v", actions[2])
+	}
+
+	var result CopyResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if result.BlocksCopied != 3 || result.Source != "src" || len(result.UIDs) != 1 || result.UIDs[0] != rootUID {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestBlockCopyLocalAsRefs(t *testing.T) {
+	var created []map[string]interface{}
+	localClient := newTestLocalClient(t, func(req localRequest) localResponse {
+		switch req.Action {
+		case "data.q":
+			return localResponse{Success: true, Result: json.RawMessage(`[[1]]`)}
+		case "data.pull":
+			return localResponse{Success: true, Result: json.RawMessage(copySource)}
+		case "data.block.create":
+			args := req.Args[0].(map[string]interface{})
+			created = append(created, args)
+			return localResponse{Success: true, Result: json.RawMessage(fmt.Sprintf(`{"uid":"new%d"}`, len(created)))}
+		}
+		return localResponse{Success: true}
+	})
+	restoreClient := withTestClient(t, localClient)
+	defer restoreClient()
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(blockCopyCmd)
+	defer resetBlockCopyFlags()
+
+	blockCopyParent = "dest"
+	blockCopyAsRefs = true
+	if err := runBlockCopy(blockCopyCmd, []string{"src"}); err != nil {
+		t.Fatalf("block copy failed: %v", err)
+	}
+	if len(created) != 4 {
+		t.Fatalf("expected 4 creates, got %d", len(created))
+	}
+	wantParents := []string{"dest", "new1", "new2", "new1"}
+	wantStrings := []string{"((src))", "((c1))", "((g1))", "((c2))"}
+	for i, args := range created {
+		block := args["block"].(map[string]interface{})
+		parent := args["location"].(map[string]interface{})["parent-uid"]
+		if parent != wantParents[i] || block["string"] != wantStrings[i] {
+			t.Fatalf("create %d: parent %v, string %v", i, parent, block["string"])
+		}
+	}
+
+	var result CopyResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if len(result.UIDs) != 1 || result.UIDs[0] != "new1" || result.BlocksCopied != 4 || !result.AsRefs {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestPageDuplicate(t *testing.T) {
+	var actions []map[string]interface{}
+	client := &fakeClient{
+		GetPageByTitleFunc: func(title string) (json.RawMessage, error) {
+			if title == "Alpha" {
+				return json.RawMessage(`{"node/title":"Alpha","block/uid":"pa","children/view-type":"document","block/children":[` + copySource + `]}`), nil
+			}
+			return nil, api.NotFoundError{Message: "page not found"}
+		},
+		ExecuteBatchFunc: func(b *api.BatchBuilder) error {
+			actions = b.Build()
+			return nil
+		},
+	}
+	restoreClient := withTestClient(t, client)
+	defer restoreClient()
+	_, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(pageDuplicateCmd)
+
+	if err := runPageDuplicate(pageDuplicateCmd, []string{"Alpha", "Beta"}); err != nil {
+		t.Fatalf("page duplicate failed: %v", err)
+	}
+	if len(actions) != 5 || actions[0]["action"] != "create-page" {
+		t.Fatalf("unexpected actions: %v", actions)
+	}
+	page := actions[0]["page"].(map[string]interface{})
+	if page["title"] != "Beta" || page["children-view-type"] != "document" {
+		t.Fatalf("unexpected page: %v", page)
+	}
+	if actions[1]["location"].(map[string]interface{})["parent-uid"] != page["uid"] {
+		t.Fatalf("blocks not placed on the new page: %v", actions[1])
+	}
+
+	if err := runPageDuplicate(pageDuplicateCmd, []string{"Alpha", "Alpha"}); err == nil {
+		t.Fatal("expected an error for an existing title")
+	}
+}
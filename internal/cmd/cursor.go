@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/output"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// cursorStart may be passed as --cursor to request the first page in cursor
+// mode.
+const cursorStart = "start"
+
+const (
+	// eidScanWindow is the initial number of entity ids scanned per query.
+	eidScanWindow = 25000
+	// eidScanMaxWindow caps the window as it grows over sparse stretches.
+	eidScanMaxWindow = 1 << 22
+)
+
+// pageCursor is the decoded form of a --cursor token. Tokens are opaque to
+// users: base64url-encoded JSON.
+type pageCursor struct {
+	// Kind names the command family the cursor belongs to.
+	Kind string `json:"k"`
+	// After is the entity id to resume after, for entity-id scans.
+	After int64 `json:"a,omitempty"`
+	// Offset is the index to resume at, for offset paging.
+	Offset int `json:"o,omitempty"`
+	// Hash fingerprints the query so a cursor is not reused for another one.
+	Hash string `json:"h"`
+}
+
+func encodeCursor(c pageCursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor parses token and checks it belongs to the query identified by
+// kind and hash. An empty token or "start" yields the zero cursor.
+func decodeCursor(token, kind, hash string) (pageCursor, error) {
+	token = strings.TrimSpace(token)
+	if token == "" || token == cursorStart {
+		return pageCursor{Kind: kind, Hash: hash}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return pageCursor{}, fmt.Errorf("invalid --cursor: not a cursor token")
+	}
+	var c pageCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return pageCursor{}, fmt.Errorf("invalid --cursor: not a cursor token")
+	}
+	if c.Kind != kind || c.Hash != hash {
+		return pageCursor{}, fmt.Errorf("invalid --cursor: cursor does not match this query")
+	}
+	if c.After < 0 || c.Offset < 0 {
+		return pageCursor{}, fmt.Errorf("invalid --cursor: out of range")
+	}
+	return c, nil
+}
+
+// cursorFingerprint identifies a command invocation by its path, arguments
+// and flags, ignoring the flags that only choose which page to show.
+func cursorFingerprint(cmd *cobra.Command, args []string) string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, cmd.CommandPath())
+	for _, arg := range args {
+		_, _ = io.WriteString(h, "\x00"+arg)
+	}
+	cmd.LocalNonPersistentFlags().Visit(func(f *pflag.Flag) {
+		switch f.Name {
+		case "cursor", "all", "page", "limit", "context", "children":
+			return
+		}
+		_, _ = io.WriteString(h, "\x00--"+f.Name+"="+f.Value.String())
+	})
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// addCursorFlags registers --cursor and --all on cmd.
+func addCursorFlags(cmd *cobra.Command, cursor *string, all *bool) {
+	cmd.Flags().StringVar(cursor, "cursor", "", `Resume after a previous page ("start" for the first page)`)
+	cmd.Flags().BoolVar(all, "all", false, "Return every result, streaming with --output ndjson")
+}
+
+// streamingRequested reports whether results should be written as they are
+// produced rather than collected first.
+func streamingRequested() bool {
+	return GetOutputFormat() == output.FormatNDJSON
+}
+
+// writeNextCursor ends an ndjson stream with a {"next_cursor": ...} line
+// when there are more results.
+func writeNextCursor(stream *output.Stream, next string) error {
+	if next == "" {
+		return nil
+	}
+	return stream.Write(map[string]string{"next_cursor": next})
+}
+
+// newOutputStream opens an ndjson stream on the command's stdout.
+func newOutputStream() (*output.Stream, error) {
+	ctx := currentContext()
+	return output.NewStream(ctx, stdoutFromContext(ctx))
+}
+
+// eidScan pages through a query in windows of entity ids, so each request
+// scans only part of the graph and results come back in a stable order.
+// fetch returns the rows of one window; eidCol locates each row's entity id.
+type eidScan struct {
+	fetch  func(r roamdb.EIDRange) ([][]interface{}, error)
+	eidCol int
+	max    int64
+	window int64
+}
+
+// run emits, in entity id order and in batches, the rows with an id above
+// after until limit rows (0 for no limit) have been emitted. It returns the
+// id to resume after and whether more rows follow.
+func (s *eidScan) run(after int64, limit int, emit func(rows [][]interface{}) error) (int64, bool, error) {
+	window := s.window
+	if window <= 0 {
+		window = eidScanWindow
+	}
+	emitted := 0
+	for after < s.max {
+		upto := min(after+window, s.max)
+		rows, err := s.fetch(roamdb.EIDRange{After: after, Upto: upto})
+		if err != nil {
+			return after, false, err
+		}
+		sortRowsByEID(rows, s.eidCol)
+
+		if limit > 0 && emitted+len(rows) > limit {
+			if n := limit - emitted; n > 0 {
+				if err := emit(rows[:n]); err != nil {
+					return after, false, err
+				}
+				after = rowEID(rows[n-1], s.eidCol)
+			}
+			return after, true, nil
+		}
+		if len(rows) > 0 {
+			if err := emit(rows); err != nil {
+				return after, false, err
+			}
+		}
+		emitted += len(rows)
+		after = upto
+		if limit > 0 && emitted == limit {
+			return after, after < s.max, nil
+		}
+
+		// Widen the window over sparse stretches of the graph.
+		target := limit - emitted
+		if limit <= 0 {
+			target = 500
+		}
+		if len(rows) < target {
+			window = min(window*2, eidScanMaxWindow)
+		}
+	}
+	return after, false, nil
+}
+
+// queryMaxEID returns the largest entity id in the graph.
+func queryMaxEID(client api.RoamAPI) (int64, error) {
+	rows, err := client.Query(roamdb.QueryMaxEID())
+	if err != nil {
+		return 0, fmt.Errorf("failed to read graph size: %w", err)
+	}
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return 0, nil
+	}
+	return rowEID(rows[0], 0), nil
+}
+
+// sortRowsByEID orders rows by the entity id in column col. Rows without an
+// id keep their relative order ahead of the rest.
+func sortRowsByEID(rows [][]interface{}, col int) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rowEID(rows[i], col) < rowEID(rows[j], col)
+	})
+}
+
+func rowEID(row []interface{}, col int) int64 {
+	if col >= len(row) {
+		return 0
+	}
+	eid, _ := intFromAny(row[col])
+	return int64(eid)
+}
+
+// offsetPage resolves --cursor/--all for a fully fetched result set paged by
+// offset. It returns the slice bounds and the cursor for the next page.
+func offsetPage(token string, all bool, limit, total int, kind, hash string) (int, int, string, error) {
+	c, err := decodeCursor(token, kind, hash)
+	if err != nil {
+		return 0, 0, "", err
+	}
+	start := min(c.Offset, total)
+	if all || limit <= 0 {
+		return start, total, "", nil
+	}
+	end := min(start+limit, total)
+	next := ""
+	if end < total {
+		next = encodeCursor(pageCursor{Kind: kind, Offset: end, Hash: hash})
+	}
+	return start, end, next, nil
+}
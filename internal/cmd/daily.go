@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/render"
 	"github.com/salmonumbrella/roam-cli/internal/roamdb"
 )
 
@@ -69,10 +70,17 @@ var dailyGetCmd = &cobra.Command{
 	Short: "Get a daily note",
 	Long: `Get the contents of a daily note page.
 
-By default, retrieves today's daily note. Use --date to specify a different date.`,
+By default, retrieves today's daily note. Use --date to specify a different date
+and --render to write it in another format.
+
+` + renderFormatsHelp + `
+`,
 	Example: `  # Get today's daily note
   roam daily get
 
+  # Render a note as Org
+  roam daily get --date 2025-01-15 --render org
+
   # Get daily note for a specific date
   roam daily get --date 2025-01-15
   roam daily get --date "January 15, 2025"`,
@@ -110,6 +118,11 @@ By default, retrieves today's daily note. Use --date to specify a different date
 			return fmt.Errorf("failed to parse page data: %w", err)
 		}
 
+		renderFormat, _ := cmd.Flags().GetString("render")
+		if !textRenderRequested(renderFormat) {
+			return renderDocument(stdoutFromContext(cmd.Context()), renderFormat, pageData, render.FromPage(page))
+		}
+
 		fmt.Printf("Daily Note: %s\n", pageTitle)
 		fmt.Println(strings.Repeat("-", 40))
 		printBlockTree(page.Children, 0)
@@ -289,12 +302,17 @@ var dailyContextCmd = &cobra.Command{
 	Long: `Get recent daily notes content for context.
 
 This command retrieves the content of recent daily notes, which can be useful
-for providing context to AI assistants or reviewing recent activity.`,
+for providing context to AI assistants or reviewing recent activity.
+
+` + resolveRefsHelp,
 	Example: `  # Get last 3 days of daily notes (default)
   roam daily context
 
   # Get last 7 days of daily notes
-  roam daily context --days 7`,
+  roam daily context --days 7
+
+  # Inline referenced and embedded blocks
+  roam daily context --days 7 --resolve-refs -o json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client := GetClient()
 		days, _ := cmd.Flags().GetInt("days")
@@ -303,6 +321,11 @@ for providing context to AI assistants or reviewing recent activity.`,
 			days = 3
 		}
 
+		resolver, err := refResolverFromFlags(cmd, client)
+		if err != nil {
+			return err
+		}
+
 		type dailyNote struct {
 			Date    string       `json:"date"`
 			Title   string       `json:"title"`
@@ -325,6 +348,11 @@ for providing context to AI assistants or reviewing recent activity.`,
 			if err != nil {
 				continue
 			}
+			if resolver != nil {
+				if page.Children, err = resolver.ResolveBlocks(page.Children); err != nil {
+					return err
+				}
+			}
 
 			note := dailyNote{
 				Date:    targetDate.Format("2006-01-02"),
@@ -335,6 +363,9 @@ for providing context to AI assistants or reviewing recent activity.`,
 		}
 
 		if structuredOutputRequested() {
+			if resolver != nil && len(resolver.Footnotes) > 0 {
+				return printStructured(map[string]interface{}{"notes": notes, "footnotes": resolver.Footnotes})
+			}
 			return printStructured(notes)
 		}
 
@@ -349,6 +380,9 @@ for providing context to AI assistants or reviewing recent activity.`,
 				printBlockTree(note.Content.Children, 0)
 			}
 		}
+		if resolver != nil {
+			printFootnotes(resolver.Footnotes)
+		}
 
 		return nil
 	},
@@ -468,9 +502,11 @@ func formatCategories(text, categories string) string {
 func init() {
 	// Daily command flags
 	dailyGetCmd.Flags().String("date", "", "Date for the daily note (default: today)")
+	dailyGetCmd.Flags().StringP("render", "f", "text", renderFlagUsage)
 	dailyAddCmd.Flags().String("date", "", "Date for the daily note (default: today)")
 	dailyAddCmd.Flags().String("heading", "", "Heading to nest content under")
 	dailyContextCmd.Flags().Int("days", 3, "Number of days to include in context")
+	addResolveRefsFlags(dailyContextCmd)
 
 	// Remember command flags
 	rememberCmd.Flags().String("categories", "", "Comma-separated categories/tags to add")
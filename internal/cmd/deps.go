@@ -2,9 +2,15 @@ package cmd
 
 import (
 	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 
 	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/config"
 	"github.com/salmonumbrella/roam-cli/internal/secrets"
+	"github.com/salmonumbrella/roam-cli/internal/upload"
 )
 
 var (
@@ -14,4 +20,38 @@ var (
 	newLocalClientFunc     = func(graphName string) (localAPI, error) {
 		return api.NewLocalClient(graphName)
 	}
+	uploadCachePath = func() (string, error) {
+		dir, err := config.ConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, upload.CacheFile), nil
+	}
+	// runEditor opens a file in $VISUAL or $EDITOR (vi, or notepad on
+	// Windows, if neither is set) and waits for it to exit.
+	runEditor = func(path string) error {
+		editor := os.Getenv("VISUAL")
+		if editor == "" {
+			editor = os.Getenv("EDITOR")
+		}
+		cmd := editorCommand(runtime.GOOS, editor, path)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		return cmd.Run()
+	}
 )
+
+// editorCommand builds the command opening path in editor, which may carry
+// arguments ("code --wait"). It runs through the platform's shell.
+func editorCommand(goos, editor, path string) *exec.Cmd {
+	if goos == "windows" {
+		if editor == "" {
+			editor = "notepad"
+		}
+		args := append([]string{"/C"}, strings.Fields(editor)...)
+		return exec.Command("cmd", append(args, path)...)
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	return exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+}
@@ -26,7 +26,7 @@ func effectiveErrorFormat(ctx context.Context) string {
 	format := strings.ToLower(strings.TrimSpace(ErrorFormatFromContext(ctx)))
 	if format == "" || format == "auto" {
 		switch output.FormatFromContext(ctx) {
-		case output.FormatJSON, output.FormatNDJSON:
+		case output.FormatJSON, output.FormatNDJSON, output.FormatEDN:
 			return "json"
 		case output.FormatYAML:
 			return "yaml"
@@ -67,6 +67,12 @@ func TestEffectiveErrorFormat(t *testing.T) {
 			outputFormat: output.FormatNDJSON,
 			want:         "json",
 		},
+		{
+			name:         "auto with edn output",
+			errorFormat:  "auto",
+			outputFormat: output.FormatEDN,
+			want:         "json",
+		},
 		{
 			name:         "auto with yaml output",
 			errorFormat:  "auto",
@@ -0,0 +1,354 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/export"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// exportPullBatch is how many pages one pull-many request fetches.
+const exportPullBatch = 50
+
+const exportPageSelector = "[* {:block/children ...}]"
+
+// assetHTTPClient downloads attachments when the client can't (overridable
+// in tests).
+var assetHTTPClient = &http.Client{Timeout: 2 * time.Minute}
+
+var (
+	exportFormat   string
+	exportOut      string
+	exportFull     bool
+	exportNoAssets bool
+)
+
+// ExportResult summarizes an export run.
+type ExportResult struct {
+	Out       string `json:"out"`
+	Pages     int    `json:"pages"`
+	Written   int    `json:"written"`
+	Unchanged int    `json:"unchanged"`
+	Removed   int    `json:"removed"`
+	Assets    int    `json:"assets"`
+	// AssetErrors lists attachments that could not be downloaded; their
+	// links are left pointing at Roam.
+	AssetErrors []string `json:"asset_errors,omitempty"`
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the whole graph to a directory of Markdown files",
+	Long: `Export every page in the graph to a directory of Markdown files.
+
+Layout:
+  <Title>.md               one file per page; characters filesystems reject
+                           become _ and namespaces (Projects/Roam) become
+                           subdirectories
+  journals/YYYY-MM-DD.md   daily notes
+  assets/                  attachments hosted by Roam, with links rewritten
+                           to the local copies
+
+Each file starts with YAML front matter holding the page title, uid,
+timestamps and any top-level "Name:: value" attribute blocks, with the
+attribute blocks' uids under "uids". Blocks are
+written as a nested list, each followed by a <!-- uid: ... --> comment so
+the file can be matched back to the graph.
+
+Attachments are downloaded through the Local API when the graph uses it,
+and over HTTPS otherwise.
+
+The directory keeps a manifest (.roam-export.json). Later runs only rewrite
+pages edited since the previous export, or whose blocks were added,
+deleted or moved, and remove the files of pages that were deleted or
+renamed. Use --full to rewrite everything.`,
+	Example: `  # Export to ./vault
+  roam export --out ./vault
+
+  # Nightly refresh: only pages edited since the last run are rewritten
+  roam export --format markdown --out ./vault -o json
+
+  # Rewrite every page and skip attachments
+  roam export --out ./vault --full --no-assets`,
+	Args: cobra.NoArgs,
+	RunE: runExport,
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, "format", "markdown", "Export format (markdown)")
+	exportCmd.Flags().StringVar(&exportOut, "out", "", "Directory to export to (required)")
+	exportCmd.Flags().BoolVar(&exportFull, "full", false, "Rewrite every page, ignoring the previous export")
+	exportCmd.Flags().BoolVar(&exportNoAssets, "no-assets", false, "Leave attachment links pointing at Roam instead of downloading them")
+	rootCmd.AddCommand(exportCmd)
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	if f := strings.ToLower(strings.TrimSpace(exportFormat)); f != "markdown" && f != "md" {
+		return fmt.Errorf("unsupported --format %q (expected markdown)", exportFormat)
+	}
+	if strings.TrimSpace(exportOut) == "" {
+		return fmt.Errorf("--out is required")
+	}
+	if err := os.MkdirAll(exportOut, 0o755); err != nil {
+		return fmt.Errorf("creating export directory: %w", err)
+	}
+
+	client := GetClient()
+	manifest := export.NewManifest(graphName)
+	if !exportFull {
+		var err error
+		if manifest, err = export.LoadManifest(exportOut, graphName); err != nil {
+			return err
+		}
+	}
+
+	pages, current, err := exportListPages(client)
+	if err != nil {
+		return err
+	}
+	paths := export.AssignPaths(pages)
+	result := ExportResult{Out: exportOut, Pages: len(pages)}
+
+	// Remove deleted and renamed pages first, so a new page can take over a
+	// freed path.
+	for uid, prev := range manifest.Pages {
+		if paths[uid] == prev.Path {
+			continue
+		}
+		if err := export.RemoveFile(exportOut, prev.Path); err != nil {
+			return err
+		}
+		delete(manifest.Pages, uid)
+		if _, exists := paths[uid]; !exists {
+			result.Removed++
+		}
+	}
+
+	var stale []export.PageRef
+	for _, p := range pages {
+		prev, ok := manifest.Pages[p.UID]
+		now := current[p.UID]
+		if ok && now.EditTime <= prev.EditTime && now.Blocks == prev.Blocks && exportFileExists(prev.Path) {
+			result.Unchanged++
+			continue
+		}
+		stale = append(stale, p)
+	}
+
+	exporter := &pageExporter{
+		client:   client,
+		manifest: manifest,
+		paths:    paths,
+		current:  current,
+		result:   &result,
+		stderr:   stderrFromContext(cmd.Context()),
+	}
+	writeErr := exporter.write(stale)
+
+	manifest.ExportedAt = time.Now().UTC()
+	if err := manifest.Save(exportOut); err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+
+	if structuredOutputRequested() {
+		return printStructured(result)
+	}
+	fmt.Printf("Exported %d pages to %s", result.Written, exportOut)
+	details := []string{fmt.Sprintf("%d unchanged", result.Unchanged)}
+	if result.Removed > 0 {
+		details = append(details, fmt.Sprintf("%d removed", result.Removed))
+	}
+	if result.Assets > 0 {
+		details = append(details, fmt.Sprintf("%d assets downloaded", result.Assets))
+	}
+	if len(result.AssetErrors) > 0 {
+		details = append(details, fmt.Sprintf("%d assets failed", len(result.AssetErrors)))
+	}
+	fmt.Printf(" (%s)\n", strings.Join(details, ", "))
+	return nil
+}
+
+// exportListPages returns every page, with the latest edit time of the page
+// or any of its blocks and the hash of its blocks.
+func exportListPages(client api.RoamAPI) ([]export.PageRef, map[string]export.ManifestPage, error) {
+	rows, err := client.Query(roamdb.QueryPagesEditTime())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list pages: %w", err)
+	}
+	pages := make([]export.PageRef, 0, len(rows))
+	current := make(map[string]export.ManifestPage, len(rows))
+	for _, row := range rows {
+		if len(row) < 3 {
+			continue
+		}
+		uid := fmt.Sprintf("%v", row[1])
+		edit, _ := intFromAny(row[2])
+		pages = append(pages, export.PageRef{Title: fmt.Sprintf("%v", row[0]), UID: uid})
+		current[uid] = export.ManifestPage{EditTime: int64(edit)}
+	}
+
+	rows, err = client.Query(roamdb.QueryPageBlocks())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read page blocks: %w", err)
+	}
+	blocks := map[string][]string{}
+	for _, row := range rows {
+		if len(row) < 5 {
+			continue
+		}
+		uid := fmt.Sprintf("%v", row[0])
+		page, ok := current[uid]
+		if !ok {
+			continue
+		}
+		order, _ := intFromAny(row[3])
+		blocks[uid] = append(blocks[uid], fmt.Sprintf("%v %v %d", row[1], row[2], order))
+		if edit, _ := intFromAny(row[4]); int64(edit) > page.EditTime {
+			page.EditTime = int64(edit)
+			current[uid] = page
+		}
+	}
+	for uid, page := range current {
+		page.Blocks = export.BlocksHash(blocks[uid])
+		current[uid] = page
+	}
+	sort.Slice(pages, func(i, j int) bool { return pages[i].Title < pages[j].Title })
+	return pages, current, nil
+}
+
+func exportFileExists(rel string) bool {
+	_, err := os.Stat(filepath.Join(exportOut, filepath.FromSlash(rel)))
+	return err == nil
+}
+
+// pageExporter pulls pages and writes their files, recording each in the
+// manifest as it goes so an interrupted run keeps its progress.
+type pageExporter struct {
+	client   api.RoamAPI
+	manifest *export.Manifest
+	paths    map[string]string
+	current  map[string]export.ManifestPage
+	result   *ExportResult
+	stderr   io.Writer
+}
+
+func (e *pageExporter) write(pages []export.PageRef) error {
+	for start := 0; start < len(pages); start += exportPullBatch {
+		batch := pages[start:min(start+exportPullBatch, len(pages))]
+		eids := make([]interface{}, len(batch))
+		for i, p := range batch {
+			eids[i] = []interface{}{":block/uid", p.UID}
+		}
+		raw, err := pullMany(e.client, eids, exportPageSelector)
+		if err != nil {
+			return fmt.Errorf("failed to pull pages: %w", err)
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return fmt.Errorf("failed to parse pull-many response: %w", err)
+		}
+		for _, item := range items {
+			if len(item) == 0 || string(item) == "null" {
+				continue
+			}
+			page, err := roamdb.ParsePage(item)
+			if err != nil {
+				return err
+			}
+			if page.UID == "" {
+				continue
+			}
+			if err := e.writePage(page); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *pageExporter) writePage(page *roamdb.Page) error {
+	file, ok := e.paths[page.UID]
+	if !ok {
+		file = export.PagePath(page.Title, page.UID)
+	}
+	if !exportNoAssets {
+		links := map[string]string{}
+		for _, u := range export.AssetURLs(page.Children) {
+			asset, err := e.asset(u)
+			if err != nil {
+				e.result.AssetErrors = append(e.result.AssetErrors, u)
+				fmt.Fprintf(e.stderr, "Warning: could not download %s: %v\n", u, err)
+				continue
+			}
+			links[u] = export.RelativeTo(file, asset)
+		}
+		page.Children = export.RewriteLinks(page.Children, links)
+	}
+
+	content, err := export.PageMarkdown(page)
+	if err != nil {
+		return fmt.Errorf("exporting %q: %w", page.Title, err)
+	}
+	if err := export.WriteFile(exportOut, file, []byte(content)); err != nil {
+		return err
+	}
+	entry := e.current[page.UID]
+	entry.Title, entry.Path = page.Title, file
+	e.manifest.Pages[page.UID] = entry
+	e.result.Written++
+	return nil
+}
+
+// asset returns the path of a downloaded attachment within the export,
+// downloading it unless an earlier run already did.
+func (e *pageExporter) asset(u string) (string, error) {
+	if rel, ok := e.manifest.Assets[u]; ok && exportFileExists(rel) {
+		return rel, nil
+	}
+	data, err := downloadAsset(e.client, u)
+	if err != nil {
+		return "", err
+	}
+	rel := path.Join(export.AssetsDir, export.AssetName(u))
+	if err := export.WriteFile(exportOut, rel, data); err != nil {
+		return "", err
+	}
+	e.manifest.Assets[u] = rel
+	e.result.Assets++
+	return rel, nil
+}
+
+// downloadAsset fetches an attachment through the client when it can
+// download files (the Local API decrypts files of encrypted graphs), and
+// over HTTPS otherwise.
+func downloadAsset(client api.RoamAPI, u string) ([]byte, error) {
+	if d, ok := client.(interface {
+		DownloadFile(url string) ([]byte, error)
+	}); ok {
+		return d.DownloadFile(u)
+	}
+	resp, err := assetHTTPClient.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/roam-cli/internal/export"
+	"github.com/salmonumbrella/roam-cli/internal/output"
+)
+
+// downloadingClient is a fakeClient that can download files, like the
+// Local API client.
+type downloadingClient struct {
+	*fakeClient
+	files map[string][]byte
+}
+
+func (c *downloadingClient) DownloadFile(url string) ([]byte, error) {
+	data, ok := c.files[url]
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+	return data, nil
+}
+
+func TestExportMarkdownIncremental(t *testing.T) {
+	img := "https://firebasestorage.googleapis.com/v0/b/app/o/imgs%2Fpic.png?alt=media"
+	pageEdit := map[string]int64{"p1": 100, "01-15-2025": 100, "p3": 100}
+	titles := map[string]string{"p1": "Projects/Roam", "01-15-2025": "January 15th, 2025", "p3": "Gone"}
+	// moved lists blocks moved onto another page, as [uid page] pairs.
+	var moved [][2]string
+	var pulled []string
+
+	fake := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			var rows [][]interface{}
+			for uid, title := range titles {
+				if strings.Contains(query, "?parent-uid") {
+					rows = append(rows, []interface{}{uid, uid + "-b", uid, float64(0), float64(pageEdit[uid])})
+				} else {
+					rows = append(rows, []interface{}{title, uid, float64(0)})
+				}
+			}
+			if strings.Contains(query, "?parent-uid") {
+				for _, m := range moved {
+					rows = append(rows, []interface{}{m[1], m[0], m[1], float64(1), float64(0)})
+				}
+			}
+			return rows, nil
+		},
+		PullManyFunc: func(eids []interface{}, selector string) (json.RawMessage, error) {
+			var items []string
+			for _, eid := range eids {
+				uid := eid.([]interface{})[1].(string)
+				pulled = append(pulled, uid)
+				items = append(items, fmt.Sprintf(`{"node/title":%q,"block/uid":%q,"block/children":[
+					{"block/uid":"%s-b","block/string":"see ![](%s)"}]}`, titles[uid], uid, uid, img))
+			}
+			return json.RawMessage("[" + strings.Join(items, ",") + "]"), nil
+		},
+	}
+	client := &downloadingClient{fakeClient: fake, files: map[string][]byte{img: []byte("PNG")}}
+	restoreClient := withTestClient(t, client)
+	defer restoreClient()
+
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(exportCmd)
+
+	dir := t.TempDir()
+	exportOut = dir
+	defer func() { exportOut = "" }()
+
+	var result ExportResult
+	run := func() {
+		t.Helper()
+		out.Reset()
+		pulled = nil
+		if err := runExport(exportCmd, nil); err != nil {
+			t.Fatalf("export failed: %v", err)
+		}
+		result = ExportResult{}
+		if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+			t.Fatalf("failed to parse output: %v\n%s", err, out.String())
+		}
+	}
+
+	run()
+	if result.Written != 3 || result.Assets != 1 {
+		t.Fatalf("unexpected first run: %+v", result)
+	}
+	page, err := os.ReadFile(filepath.Join(dir, "Projects", "Roam.md"))
+	if err != nil {
+		t.Fatalf("namespaced page not written: %v", err)
+	}
+	asset := "assets/" + export.AssetName(img)
+	if !strings.Contains(string(page), "title: Projects/Roam\n") ||
+		!strings.Contains(string(page), "- see ![](../"+asset+") <!-- uid: p1-b -->") {
+		t.Fatalf("unexpected page file:\n%s", page)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "journals", "2025-01-15.md")); err != nil {
+		t.Fatalf("daily note not in journals: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(asset))); err != nil || string(data) != "PNG" {
+		t.Fatalf("asset not downloaded: %q, %v", data, err)
+	}
+
+	// Second run: one page edited, one deleted.
+	pageEdit["p1"] = 200
+	delete(titles, "p3")
+	run()
+	if result.Written != 1 || result.Unchanged != 1 || result.Removed != 1 || result.Assets != 0 {
+		t.Fatalf("unexpected incremental run: %+v", result)
+	}
+	if len(pulled) != 1 || pulled[0] != "p1" {
+		t.Fatalf("expected only the edited page to be pulled, got %v", pulled)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Gone.md")); !os.IsNotExist(err) {
+		t.Fatalf("deleted page file still present: %v", err)
+	}
+
+	// Third run: a block moved between pages changes no edit time, but
+	// both pages are rewritten.
+	moved = [][2]string{{"x1", "p1"}}
+	run()
+	moved = [][2]string{{"x1", "01-15-2025"}}
+	run()
+	if result.Written != 2 || result.Unchanged != 0 {
+		t.Fatalf("expected both pages of a moved block to be rewritten: %+v", result)
+	}
+
+	exportFormat = "docx"
+	defer func() { exportFormat = "markdown" }()
+	if err := runExport(exportCmd, nil); err == nil || !strings.Contains(err.Error(), "expected markdown") {
+		t.Fatalf("expected format error, got %v", err)
+	}
+}
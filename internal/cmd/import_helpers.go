@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// lookupChunk is how many titles or uids one lookup query takes.
+const lookupChunk = 1000
+
+// existingPageUIDs returns the uids of the pages whose titles are already
+// in the graph, by title.
+func existingPageUIDs(client api.RoamAPI, titles []string) (map[string]string, error) {
+	existing := map[string]string{}
+	for start := 0; start < len(titles); start += lookupChunk {
+		rows, err := client.Query(roamdb.QueryPageUIDsByTitle(), titles[start:min(start+lookupChunk, len(titles))])
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up existing pages: %w", err)
+		}
+		for _, row := range rows {
+			if len(row) < 2 {
+				continue
+			}
+			existing[fmt.Sprintf("%v", row[0])] = fmt.Sprintf("%v", row[1])
+		}
+	}
+	return existing, nil
+}
+
+// existingUIDs returns which of uids already belong to a page or block.
+func existingUIDs(client api.RoamAPI, uids []string) (map[string]bool, error) {
+	existing := map[string]bool{}
+	for start := 0; start < len(uids); start += lookupChunk {
+		rows, err := client.Query(roamdb.QueryExistingUIDs(), uids[start:min(start+lookupChunk, len(uids))])
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up existing blocks: %w", err)
+		}
+		for _, row := range rows {
+			if len(row) > 0 {
+				existing[fmt.Sprintf("%v", row[0])] = true
+			}
+		}
+	}
+	return existing, nil
+}
@@ -0,0 +1,386 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/markup"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// importRoamChunk is how many actions one batch request carries.
+const importRoamChunk = 250
+
+// UID collision modes.
+const (
+	collisionSkip      = "skip"
+	collisionOverwrite = "overwrite"
+	collisionRemap     = "remap"
+)
+
+var (
+	importRoamPages     string
+	importRoamCollision string
+	importRoamDryRun    bool
+)
+
+// RoamImportResult summarizes an import-roam run.
+type RoamImportResult struct {
+	File           string `json:"file"`
+	OnCollision    string `json:"on_collision"`
+	DryRun         bool   `json:"dry_run,omitempty"`
+	PagesCreated   int    `json:"pages_created"`
+	PagesUpdated   int    `json:"pages_updated"`
+	PagesSkipped   int    `json:"pages_skipped"`
+	BlocksCreated  int    `json:"blocks_created"`
+	BlocksUpdated  int    `json:"blocks_updated"`
+	BlocksSkipped  int    `json:"blocks_skipped"`
+	BlocksRemapped int    `json:"blocks_remapped"`
+	Batches        int    `json:"batches"`
+	// MissingPages lists --pages titles that are not in the export.
+	MissingPages []string `json:"missing_pages,omitempty"`
+}
+
+var importRoamCmd = &cobra.Command{
+	Use:   "import-roam <export.json|export.edn>",
+	Short: "Import a Roam JSON or EDN export, keeping its uids",
+	Long: `Import pages from a Roam graph export into the current graph.
+
+Both export formats Roam offers are read: JSON (an array of pages) and EDN
+(a #datascript/DB). Pages and block trees are recreated with their
+original uids, headings, view types and props, so block refs between the
+imported pages keep working. Actions are sent in batches of 250.
+
+Pages are matched by title. A page whose title is already in the graph is
+skipped with --on-collision skip; with overwrite or remap its blocks are
+imported into the existing page. A new page whose uid is taken gets a new
+uid unless collisions are skipped.
+
+Blocks whose uid is already in the graph are handled by --on-collision:
+  skip       leave the existing block alone and skip the imported one and
+             its children (default)
+  overwrite  update the existing block with the imported content and move
+             it into place
+  remap      import the block under a new uid, rewriting ((refs)) and
+             embeds of it in the imported blocks`,
+	Example: `  # Import a whole export
+  roam import-roam export.json
+
+  # Import two pages, giving colliding blocks new uids
+  roam import-roam export.edn --pages "Projects,Reading List" --on-collision remap
+
+  # See what would happen
+  roam import-roam export.json --on-collision overwrite --dry-run -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImportRoam,
+}
+
+func init() {
+	importRoamCmd.Flags().StringVar(&importRoamPages, "pages", "", "Comma-separated page titles to import (default: all)")
+	importRoamCmd.Flags().StringVar(&importRoamCollision, "on-collision", collisionSkip, "What to do with uids already in the graph: skip|overwrite|remap")
+	importRoamCmd.Flags().BoolVar(&importRoamDryRun, "dry-run", false, "Report what would be imported without writing")
+	rootCmd.AddCommand(importRoamCmd)
+}
+
+func runImportRoam(cmd *cobra.Command, args []string) error {
+	mode := strings.ToLower(strings.TrimSpace(importRoamCollision))
+	switch mode {
+	case collisionSkip, collisionOverwrite, collisionRemap:
+	default:
+		return fmt.Errorf("invalid --on-collision %q (expected skip|overwrite|remap)", importRoamCollision)
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read export: %w", err)
+	}
+	pages, err := roamdb.ParseExport(data)
+	if err != nil {
+		return err
+	}
+	result := RoamImportResult{File: args[0], OnCollision: mode, DryRun: importRoamDryRun}
+	pages, result.MissingPages = filterExportPages(pages, importRoamPages)
+	if len(pages) == 0 {
+		return fmt.Errorf("no pages to import")
+	}
+	assignExportUIDs(pages)
+
+	client := GetClient()
+	titles := make([]string, len(pages))
+	var uids []string
+	for i, p := range pages {
+		titles[i] = p.Title
+		uids = append(uids, p.UID)
+		walkExportBlocks(p.Children, func(b *roamdb.Block) { uids = append(uids, b.UID) })
+	}
+	existingPages, err := existingPageUIDs(client, titles)
+	if err != nil {
+		return err
+	}
+	taken, err := existingUIDs(client, uids)
+	if err != nil {
+		return err
+	}
+
+	im := &roamImporter{
+		client: client,
+		mode:   mode,
+		taken:  taken,
+		remap:  map[string]string{},
+		result: &result,
+		batch:  api.NewBatchBuilder(),
+	}
+	im.plan(pages, existingPages)
+	if !importRoamDryRun {
+		if err := im.execute(); err != nil {
+			return err
+		}
+	}
+
+	if structuredOutputRequested() {
+		return printStructured(result)
+	}
+	printRoamImport(stdoutFromContext(cmd.Context()), result)
+	return nil
+}
+
+// filterExportPages keeps the pages named in a comma-separated list (all
+// pages when the list is empty) and returns the names not found.
+func filterExportPages(pages []roamdb.Page, list string) ([]roamdb.Page, []string) {
+	if strings.TrimSpace(list) == "" {
+		return pages, nil
+	}
+	wanted := map[string]bool{}
+	var order []string
+	for _, title := range strings.Split(list, ",") {
+		if title = strings.TrimSpace(title); title != "" && !wanted[title] {
+			wanted[title] = true
+			order = append(order, title)
+		}
+	}
+	var kept []roamdb.Page
+	found := map[string]bool{}
+	for _, p := range pages {
+		if wanted[p.Title] {
+			kept = append(kept, p)
+			found[p.Title] = true
+		}
+	}
+	var missing []string
+	for _, title := range order {
+		if !found[title] {
+			missing = append(missing, title)
+		}
+	}
+	return kept, missing
+}
+
+// assignExportUIDs gives uids to pages and blocks exported without one:
+// daily notes get their MM-DD-YYYY uid, everything else a random one.
+func assignExportUIDs(pages []roamdb.Page) {
+	for i := range pages {
+		p := &pages[i]
+		if p.UID == "" {
+			if date, ok := roamdb.ParseDailyNoteTitle(p.Title); ok {
+				p.UID = roamdb.DailyNoteUID(date)
+			} else {
+				p.UID = roamdb.NewUID()
+			}
+		}
+		walkExportBlocks(p.Children, func(b *roamdb.Block) {
+			if b.UID == "" {
+				b.UID = roamdb.NewUID()
+			}
+		})
+	}
+}
+
+func walkExportBlocks(blocks []roamdb.Block, fn func(*roamdb.Block)) {
+	for i := range blocks {
+		fn(&blocks[i])
+		walkExportBlocks(blocks[i].Children, fn)
+	}
+}
+
+// roamImport is one planned write.
+// roamImport is one planned write: a page to create, or with update the
+// view type to set on the existing page parent, or a block to create or
+// update under parent.
+type roamImport struct {
+	page   *roamdb.Page
+	block  *roamdb.Block
+	parent string
+	order  interface{}
+	update bool
+}
+
+// roamImporter plans the writes for an export, then sends them in chunked
+// batches. Every page and block is written with an explicit uid, so later
+// chunks can refer to entities created by earlier ones.
+type roamImporter struct {
+	client api.RoamAPI
+	mode   string
+	taken  map[string]bool
+	// remap maps colliding uids to the new uids they are imported under.
+	remap  map[string]string
+	result *RoamImportResult
+	writes []roamImport
+	batch  *api.BatchBuilder
+}
+
+func (im *roamImporter) plan(pages []roamdb.Page, existing map[string]string) {
+	for i := range pages {
+		p := &pages[i]
+		if uid, ok := existing[p.Title]; ok {
+			if im.mode == collisionSkip {
+				im.result.PagesSkipped++
+				im.result.BlocksSkipped += countExportBlocks(p.Children)
+				continue
+			}
+			im.result.PagesUpdated++
+			if p.ViewType != "" {
+				im.writes = append(im.writes, roamImport{page: p, parent: uid, update: true})
+			}
+			var order interface{}
+			if im.mode == collisionRemap {
+				order = "last"
+			}
+			im.planBlocks(p.Children, uid, order)
+			continue
+		}
+		if im.taken[p.UID] {
+			if im.mode == collisionSkip {
+				im.result.PagesSkipped++
+				im.result.BlocksSkipped += countExportBlocks(p.Children)
+				continue
+			}
+			p.UID = roamdb.NewUID()
+		}
+		im.result.PagesCreated++
+		im.writes = append(im.writes, roamImport{page: p})
+		im.planBlocks(p.Children, p.UID, nil)
+	}
+
+	if len(im.remap) > 0 {
+		for _, w := range im.writes {
+			if w.block != nil {
+				w.block.String = markup.RemapBlockRefs(w.block.String, im.remap)
+			}
+		}
+	}
+}
+
+// planBlocks plans the writes for a block tree under parent. A nil order
+// places blocks by their position.
+func (im *roamImporter) planBlocks(blocks []roamdb.Block, parent string, order interface{}) {
+	pos := 0
+	for i := range blocks {
+		b := &blocks[i]
+		w := roamImport{block: b, parent: parent, order: order}
+		if order == nil {
+			w.order = pos
+		}
+		if im.taken[b.UID] {
+			switch im.mode {
+			case collisionSkip:
+				im.result.BlocksSkipped += 1 + countExportBlocks(b.Children)
+				continue
+			case collisionOverwrite:
+				w.update = true
+			case collisionRemap:
+				uid := roamdb.NewUID()
+				im.remap[b.UID] = uid
+				b.UID = uid
+				im.result.BlocksRemapped++
+			}
+		}
+		if w.update {
+			im.result.BlocksUpdated++
+		} else {
+			im.result.BlocksCreated++
+		}
+		im.writes = append(im.writes, w)
+		pos++
+		im.planBlocks(b.Children, b.UID, nil)
+	}
+}
+
+func countExportBlocks(blocks []roamdb.Block) int {
+	n := 0
+	walkExportBlocks(blocks, func(*roamdb.Block) { n++ })
+	return n
+}
+
+func (im *roamImporter) execute() error {
+	for _, w := range im.writes {
+		switch {
+		case w.page != nil && w.update:
+			im.batch.UpdatePage(w.parent, api.PageOptions{ChildrenViewType: w.page.ViewType})
+		case w.page != nil:
+			im.batch.CreatePage(api.PageOptions{Title: w.page.Title, UID: w.page.UID, ChildrenViewType: w.page.ViewType})
+		case w.update:
+			im.batch.UpdateBlock(w.block.UID, exportBlockOptions(w.block))
+			im.batch.MoveBlock(w.block.UID, api.Location{ParentUID: w.parent, Order: w.order})
+		default:
+			opts := exportBlockOptions(w.block)
+			opts.UID = w.block.UID
+			im.batch.CreateBlock(api.Location{ParentUID: w.parent, Order: w.order}, opts)
+		}
+		if len(im.batch.Build()) >= importRoamChunk {
+			if err := im.flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return im.flush()
+}
+
+func (im *roamImporter) flush() error {
+	if len(im.batch.Build()) == 0 {
+		return nil
+	}
+	if err := im.client.ExecuteBatch(im.batch); err != nil {
+		return fmt.Errorf("import failed after %d batches: %w", im.result.Batches, err)
+	}
+	im.result.Batches++
+	im.batch = api.NewBatchBuilder()
+	return nil
+}
+
+func exportBlockOptions(b *roamdb.Block) api.BlockOptions {
+	opts := api.BlockOptions{
+		Content:          b.String,
+		Open:             b.Open,
+		TextAlign:        b.TextAlign,
+		ChildrenViewType: b.ViewType,
+		BlockViewType:    b.BlockViewType,
+		Props:            b.Props,
+	}
+	if b.Heading > 0 {
+		heading := b.Heading
+		opts.Heading = &heading
+	}
+	return opts
+}
+
+func printRoamImport(w io.Writer, result RoamImportResult) {
+	verb := "Imported"
+	if result.DryRun {
+		verb = "Would import"
+	}
+	fmt.Fprintf(w, "%s from %s (on collision: %s)\n", verb, result.File, result.OnCollision)
+	fmt.Fprintf(w, "Pages:  %d created, %d updated, %d skipped\n", result.PagesCreated, result.PagesUpdated, result.PagesSkipped)
+	fmt.Fprintf(w, "Blocks: %d created, %d updated, %d skipped", result.BlocksCreated, result.BlocksUpdated, result.BlocksSkipped)
+	if result.BlocksRemapped > 0 {
+		fmt.Fprintf(w, " (%d under new uids)", result.BlocksRemapped)
+	}
+	fmt.Fprintln(w)
+	if len(result.MissingPages) > 0 {
+		fmt.Fprintf(w, "Not in export: %s\n", strings.Join(result.MissingPages, ", "))
+	}
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/output"
+)
+
+func TestImportRoamCollisions(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "export.json")
+	export := `[
+		{"title":"Alpha","uid":"pa","children":[
+			{"string":"see ((b2))","uid":"b1","heading":1,"children":[{"string":"child","uid":"b3"}]},
+			{"string":"target","uid":"b2","children-view-type":"numbered"}]},
+		{"title":"Beta","uid":"pb","children-view-type":"document","children":[{"string":"beta","uid":"b4"}]},
+		{"title":"Gamma","uid":"pg","children":[]}]`
+	if err := os.WriteFile(file, []byte(export), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var batches [][]map[string]interface{}
+	client := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			if strings.Contains(query, "?title ...") {
+				return [][]interface{}{{"Beta", "existing-beta"}}, nil
+			}
+			return [][]interface{}{{"b2"}}, nil
+		},
+		ExecuteBatchFunc: func(b *api.BatchBuilder) error {
+			batches = append(batches, b.Build())
+			return nil
+		},
+	}
+	restoreClient := withTestClient(t, client)
+	defer restoreClient()
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(importRoamCmd)
+	defer func() {
+		importRoamCollision, importRoamPages, importRoamDryRun = collisionSkip, "", false
+	}()
+
+	run := func() RoamImportResult {
+		t.Helper()
+		out.Reset()
+		batches = nil
+		if err := runImportRoam(importRoamCmd, []string{file}); err != nil {
+			t.Fatalf("import failed: %v", err)
+		}
+		var result RoamImportResult
+		if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+			t.Fatalf("failed to parse output: %v\n%s", err, out.String())
+		}
+		return result
+	}
+
+	// skip: the existing page and the colliding block are left out.
+	importRoamPages = "Alpha, Beta, Missing"
+	result := run()
+	if result.PagesCreated != 1 || result.PagesSkipped != 1 || result.BlocksCreated != 2 || result.BlocksSkipped != 2 {
+		t.Fatalf("unexpected skip result: %+v", result)
+	}
+	if len(result.MissingPages) != 1 || result.MissingPages[0] != "Missing" {
+		t.Fatalf("missing page not reported: %v", result.MissingPages)
+	}
+
+	// remap: b2 gets a new uid and the ref to it follows.
+	importRoamPages = ""
+	importRoamCollision = collisionRemap
+	result = run()
+	if result.PagesCreated != 2 || result.PagesUpdated != 1 || result.BlocksCreated != 4 || result.BlocksRemapped != 1 {
+		t.Fatalf("unexpected remap result: %+v", result)
+	}
+	if len(batches) != 1 {
+		t.Fatalf("expected one batch, got %d", len(batches))
+	}
+	blocks := map[string]map[string]interface{}{}
+	locations := map[string]map[string]interface{}{}
+	var newUID string
+	for _, a := range batches[0] {
+		if a["action"] != "create-block" {
+			continue
+		}
+		block := a["block"].(map[string]interface{})
+		uid := block["uid"].(string)
+		blocks[uid] = block
+		locations[uid] = a["location"].(map[string]interface{})
+		if block["string"] == "target" {
+			newUID = uid
+		}
+	}
+	if newUID == "" || newUID == "b2" {
+		t.Fatalf("colliding block not remapped: %v", blocks)
+	}
+	if blocks["b1"]["string"] != "see (("+newUID+"))" || blocks["b1"]["heading"] != 1 {
+		t.Fatalf("ref not rewritten: %v", blocks["b1"])
+	}
+	if blocks[newUID]["children-view-type"] != "numbered" {
+		t.Fatalf("view type not kept: %v", blocks[newUID])
+	}
+	var pageUpdate map[string]interface{}
+	for _, a := range batches[0] {
+		if a["action"] == "update-page" {
+			pageUpdate = a["page"].(map[string]interface{})
+		}
+	}
+	if pageUpdate["uid"] != "existing-beta" || pageUpdate["children-view-type"] != "document" {
+		t.Fatalf("existing page view type not set: %v", pageUpdate)
+	}
+	if locations["b3"]["parent-uid"] != "b1" || locations["b4"]["parent-uid"] != "existing-beta" || locations["b4"]["order"] != "last" {
+		t.Fatalf("unexpected locations: %v", locations)
+	}
+
+	// overwrite: the colliding block is updated and moved into place.
+	importRoamCollision = collisionOverwrite
+	importRoamDryRun = true
+	result = run()
+	if result.BlocksUpdated != 1 || result.BlocksCreated != 3 || len(batches) != 0 {
+		t.Fatalf("unexpected overwrite dry run: %+v (%d batches)", result, len(batches))
+	}
+
+	importRoamCollision = "merge"
+	if err := runImportRoam(importRoamCmd, []string{file}); err == nil {
+		t.Fatal("expected an error for an invalid collision mode")
+	}
+}
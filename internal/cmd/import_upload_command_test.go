@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/roam-cli/internal/output"
+)
+
+func TestImportUploadsAssetsOnce(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "img"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "notes.md")
+	if err := os.WriteFile(file, []byte("- Trip ![beach](img/beach.png)\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "img", "beach.png"), []byte("PNG"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	prevCachePath := uploadCachePath
+	uploadCachePath = func() (string, error) { return filepath.Join(dir, "cache.json"), nil }
+	defer func() { uploadCachePath = prevCachePath }()
+
+	var created []string
+	client := &uploadingClient{fakeClient: &fakeClient{
+		CreateBlockFunc: func(parentUID, content string, order interface{}) error {
+			created = append(created, content)
+			return nil
+		},
+	}}
+	restoreClient := withTestClient(t, client)
+	defer restoreClient()
+	_, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(importCmd)
+
+	importParent, importUpload = "parent", true
+	defer func() { importParent, importUpload = "", false }()
+
+	for i := 0; i < 2; i++ {
+		if err := runImport(importCmd, []string{file}); err != nil {
+			t.Fatalf("import failed: %v", err)
+		}
+	}
+	if len(client.uploads) != 1 {
+		t.Fatalf("expected one upload, got %v", client.uploads)
+	}
+	want := "Trip ![beach](https://files.example/beach.png)"
+	if len(created) != 2 || created[0] != want || created[1] != want {
+		t.Fatalf("unexpected blocks: %q", created)
+	}
+}
+
+func TestImportUploadRequiresEndpointOnCloud(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(file, []byte("![x](x.png)\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	prevEnvGet, prevConfig := envGet, configFile
+	envGet = func(string) string { return "" }
+	configFile = filepath.Join(t.TempDir(), "config.yaml")
+	defer func() { envGet, configFile = prevEnvGet, prevConfig }()
+
+	restoreClient := withTestClient(t, &fakeClient{})
+	defer restoreClient()
+	_, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(importCmd)
+
+	importParent, importUpload = "parent", true
+	defer func() { importParent, importUpload = "", false }()
+
+	err := runImport(importCmd, []string{file})
+	if err == nil || !strings.Contains(err.Error(), "upload endpoint") {
+		t.Fatalf("expected an upload endpoint error, got %v", err)
+	}
+}
+
+func TestImportValidatesBeforeUploading(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "notes.md")
+	if err := os.WriteFile(file, []byte("- Trip ![beach](beach.png)\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "beach.png"), []byte("PNG"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	prevCachePath := uploadCachePath
+	uploadCachePath = func() (string, error) { return filepath.Join(dir, "cache.json"), nil }
+	defer func() { uploadCachePath = prevCachePath }()
+
+	client := &uploadingClient{fakeClient: &fakeClient{}}
+	restoreClient := withTestClient(t, client)
+	defer restoreClient()
+	_, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(importCmd)
+
+	importUpload = true
+	defer func() { importParent, importOrder, importUpload = "", "last", false }()
+
+	if err := runImport(importCmd, []string{file}); err == nil || !strings.Contains(err.Error(), "--page or --parent") {
+		t.Fatalf("expected a missing target error, got %v", err)
+	}
+	importParent, importOrder = "parent", "middle"
+	if err := runImport(importCmd, []string{file}); err == nil || !strings.Contains(err.Error(), "invalid order") {
+		t.Fatalf("expected an order error, got %v", err)
+	}
+	if len(client.uploads) != 0 {
+		t.Fatalf("expected no uploads for a rejected import, got %v", client.uploads)
+	}
+}
@@ -0,0 +1,398 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/markup"
+	"github.com/salmonumbrella/roam-cli/internal/mdblocks"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+	"github.com/salmonumbrella/roam-cli/internal/upload"
+	"github.com/salmonumbrella/roam-cli/internal/vault"
+)
+
+var (
+	importVaultFlavor string
+	importVaultDryRun bool
+	importVaultUpsert bool
+)
+
+// Vault import page actions.
+const (
+	vaultActionCreate = "create"
+	vaultActionUpdate = "update"
+	vaultActionSkip   = "skip"
+)
+
+// VaultImportPage reports what happens to one vault file.
+type VaultImportPage struct {
+	File   string `json:"file"`
+	Title  string `json:"title"`
+	UID    string `json:"uid"`
+	Action string `json:"action"`
+	Blocks int    `json:"blocks"`
+	Assets int    `json:"assets,omitempty"`
+}
+
+// VaultImportResult summarizes a vault import.
+type VaultImportResult struct {
+	Dir      string            `json:"dir"`
+	Flavor   string            `json:"flavor"`
+	DryRun   bool              `json:"dry_run,omitempty"`
+	Pages    []VaultImportPage `json:"pages"`
+	Created  int               `json:"created"`
+	Updated  int               `json:"updated"`
+	Skipped  int               `json:"skipped"`
+	Blocks   int               `json:"blocks"`
+	Uploaded int               `json:"uploaded"`
+	Reused   int               `json:"reused"`
+	// Warnings lists links that could not be resolved, files that could not
+	// be uploaded and uids that had to be replaced.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+var importVaultCmd = &cobra.Command{
+	Use:   "import-vault <dir>",
+	Short: "Import an Obsidian or Logseq vault",
+	Long: `Import every Markdown file in an Obsidian or Logseq vault as a Roam page.
+
+Conversion:
+  - Each file becomes a page. Obsidian folders and Logseq namespace file
+    names (a___b.md) become namespaced titles (a/b).
+  - Journal files (Obsidian YYYY-MM-DD.md, Logseq journals/YYYY_MM_DD.md)
+    become daily notes.
+  - YAML front matter and Logseq page properties become "key:: value"
+    attribute blocks; tags and aliases are written as page refs.
+  - [[links]] are rewritten to the titles pages are created with,
+    ![[embeds]] become {{embed: ...}} and [[page#^id]] links become block
+    refs.
+  - Logseq id:: properties become block uids, so ((refs)) to those blocks
+    keep working.
+  - Local images and other attachments are uploaded and the links
+    rewritten to the uploaded files. Uploads go through the Local API, or
+    with the cloud API to the endpoint in ROAM_UPLOAD_URL or the
+    upload_url config key, and are cached by content hash like import's.
+
+The flavor is detected from the vault (a logseq/ or .obsidian/ directory)
+when --flavor is not given.
+
+Pages whose title already exists are skipped. With --upsert they are
+updated instead: blocks are matched by uid (derived from the file and the
+block's position, or the Logseq id), updated and moved into place, and
+missing blocks are created. Blocks in Roam that are not in the file are
+left alone.
+
+A Logseq id that is already used by a block elsewhere in the graph is
+imported under a new uid, and the vault's ((refs)) to it are rewritten.
+
+Use --dry-run to see what would be created, updated and skipped without
+writing anything.`,
+	Example: `  # Preview an Obsidian import
+  roam import-vault ~/Notes --flavor obsidian --dry-run
+
+  # Import a Logseq graph
+  roam import-vault ~/logseq-graph --flavor logseq
+
+  # Re-import after editing the vault, updating pages imported before
+  roam import-vault ~/Notes --upsert`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImportVault,
+}
+
+func init() {
+	importVaultCmd.Flags().StringVar(&importVaultFlavor, "flavor", "", "Vault flavor: obsidian|logseq (detected when omitted)")
+	importVaultCmd.Flags().BoolVar(&importVaultDryRun, "dry-run", false, "Report what would be imported without writing")
+	importVaultCmd.Flags().BoolVar(&importVaultUpsert, "upsert", false, "Update pages that already exist instead of skipping them")
+	rootCmd.AddCommand(importVaultCmd)
+}
+
+func runImportVault(cmd *cobra.Command, args []string) error {
+	dir := args[0]
+	flavor := importVaultFlavor
+	if strings.TrimSpace(flavor) == "" {
+		flavor = detectVaultFlavor(dir)
+		if flavor == "" {
+			return fmt.Errorf("cannot tell the vault flavor of %s; pass --flavor obsidian|logseq", dir)
+		}
+	}
+	v, err := vault.Load(dir, flavor)
+	if err != nil {
+		return err
+	}
+
+	client := GetClient()
+	titles := make([]string, len(v.Pages))
+	for i, p := range v.Pages {
+		titles[i] = p.Title
+	}
+	existing, err := existingPageUIDs(client, titles)
+	if err != nil {
+		return err
+	}
+	result := VaultImportResult{Dir: dir, Flavor: v.Flavor, DryRun: importVaultDryRun, Warnings: v.Warnings}
+	for _, p := range v.Pages {
+		action := vaultActionCreate
+		if uid, ok := existing[p.Title]; ok {
+			p.UID = uid
+			action = vaultActionSkip
+			if importVaultUpsert {
+				action = vaultActionUpdate
+			}
+		}
+		assets := 0
+		mdblocks.Walk(p.Blocks, func(b *mdblocks.Block) { assets += len(b.Assets) })
+		result.Pages = append(result.Pages, VaultImportPage{
+			File:   p.File,
+			Title:  p.Title,
+			UID:    p.UID,
+			Action: action,
+			Blocks: mdblocks.Count(p.Blocks),
+			Assets: assets,
+		})
+	}
+
+	if !importVaultDryRun {
+		importer := &vaultImporter{
+			client:   client,
+			result:   &result,
+			uploaded: map[string]string{},
+		}
+		if vaultHasAssets(result.Pages) {
+			if importer.uploads, err = newUploadSession(client, v.Dir); err != nil {
+				importer.warn("attachments not uploaded: %v; links left as is", err)
+			}
+		}
+		err := importer.run(v.Pages)
+		if importer.uploads != nil {
+			result.Uploaded, result.Reused = importer.uploads.Uploaded, importer.uploads.Reused
+			// Keep the URLs of files uploaded before a failure.
+			if saveErr := importer.uploads.Save(); saveErr != nil && err == nil {
+				err = saveErr
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, p := range result.Pages {
+		switch p.Action {
+		case vaultActionCreate:
+			result.Created++
+			result.Blocks += p.Blocks
+		case vaultActionUpdate:
+			result.Updated++
+			result.Blocks += p.Blocks
+		default:
+			result.Skipped++
+		}
+	}
+
+	if structuredOutputRequested() {
+		return printStructured(result)
+	}
+	printVaultImport(stdoutFromContext(cmd.Context()), result)
+	return nil
+}
+
+// vaultHasAssets reports whether any page links to local files.
+func vaultHasAssets(pages []VaultImportPage) bool {
+	for _, p := range pages {
+		if p.Assets > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// detectVaultFlavor guesses a vault's flavor from the app directories in
+// it, returning "" when there are none.
+func detectVaultFlavor(dir string) string {
+	isDir := func(name string) bool {
+		info, err := os.Stat(filepath.Join(dir, name))
+		return err == nil && info.IsDir()
+	}
+	switch {
+	case isDir("logseq"):
+		return vault.Logseq
+	case isDir(".obsidian"):
+		return vault.Obsidian
+	}
+	return ""
+}
+
+// vaultImporter writes vault pages to the graph, one batch per page.
+type vaultImporter struct {
+	client api.RoamAPI
+	result *VaultImportResult
+	// uploads uploads the linked files, or is nil when they can't be
+	// uploaded.
+	uploads *upload.Session
+	// uploaded maps local files to their uploaded URLs, for rewriting the
+	// links to them.
+	uploaded map[string]string
+}
+
+func (im *vaultImporter) run(pages []*vault.Page) error {
+	// Look up every page's uids first, so a block whose uid is taken
+	// elsewhere in the graph gets a new uid before any page that refers
+	// to it is written.
+	existing := make([]map[string]bool, len(pages))
+	remap := map[string]string{}
+	for i, p := range pages {
+		action := im.result.Pages[i].Action
+		if action == vaultActionSkip {
+			continue
+		}
+		taken, err := im.existingUIDs(p, action == vaultActionCreate)
+		if err != nil {
+			return err
+		}
+		existing[i] = taken
+		if action == vaultActionCreate {
+			im.remapTaken(p, taken, remap)
+		}
+	}
+
+	for i, p := range pages {
+		action := im.result.Pages[i].Action
+		if action == vaultActionSkip {
+			continue
+		}
+		if len(remap) > 0 {
+			mdblocks.Walk(p.Blocks, func(b *mdblocks.Block) {
+				b.String = markup.RemapBlockRefs(b.String, remap)
+			})
+		}
+		im.upload(p)
+
+		batch := api.NewBatchBuilder()
+		parent := p.UID
+		if action == vaultActionCreate {
+			opts := api.PageOptions{Title: p.Title, ChildrenViewType: p.ViewType}
+			if !existing[i][p.UID] {
+				opts.UID = p.UID
+			}
+			parent = batch.CreatePage(opts)
+		} else if p.ViewType != "" {
+			batch.UpdatePage(p.UID, api.PageOptions{ChildrenViewType: p.ViewType})
+		}
+		im.addBlocks(batch, parent, p.Blocks, existing[i], action == vaultActionUpdate)
+		if err := im.client.ExecuteBatch(batch); err != nil {
+			return fmt.Errorf("importing %s: %w", p.File, err)
+		}
+	}
+	return nil
+}
+
+// existingUIDs returns which of the page's uids are already taken. When
+// the page is new, the page uid is checked too.
+func (im *vaultImporter) existingUIDs(p *vault.Page, withPage bool) (map[string]bool, error) {
+	var uids []string
+	if withPage {
+		uids = append(uids, p.UID)
+	}
+	mdblocks.Walk(p.Blocks, func(b *mdblocks.Block) { uids = append(uids, b.UID) })
+	return existingUIDs(im.client, uids)
+}
+
+// remapTaken gives the blocks of a new page whose uids belong to blocks
+// elsewhere in the graph new uids, recording them in remap so the vault's
+// ((refs)) to them can be rewritten.
+func (im *vaultImporter) remapTaken(p *vault.Page, taken map[string]bool, remap map[string]string) {
+	mdblocks.Walk(p.Blocks, func(b *mdblocks.Block) {
+		if !taken[b.UID] {
+			return
+		}
+		uid := roamdb.NewUID()
+		im.warn("%s: block uid %s is already in use; imported as %s", p.File, b.UID, uid)
+		remap[b.UID] = uid
+		b.UID = uid
+	})
+}
+
+// addBlocks adds the actions creating (or, when updating, updating and
+// moving) a block tree under parent.
+func (im *vaultImporter) addBlocks(batch *api.BatchBuilder, parent string, blocks []*mdblocks.Block, existing map[string]bool, update bool) {
+	for i, b := range blocks {
+		heading := b.Heading
+		opts := api.BlockOptions{Content: b.String, ChildrenViewType: b.ViewType}
+		if heading > 0 {
+			opts.Heading = &heading
+		}
+		loc := api.Location{ParentUID: parent, Order: i}
+		if existing[b.UID] && update {
+			opts.Heading = &heading
+			batch.UpdateBlock(b.UID, opts)
+			batch.MoveBlock(b.UID, loc)
+		} else {
+			opts.UID = b.UID
+			batch.CreateBlock(loc, opts)
+		}
+		im.addBlocks(batch, b.UID, b.Children, existing, update)
+	}
+}
+
+// upload uploads the local files a page links to and rewrites the links.
+// Without an upload session, the links stay local.
+func (im *vaultImporter) upload(p *vault.Page) {
+	if im.uploads == nil {
+		return
+	}
+	mdblocks.Walk(p.Blocks, func(b *mdblocks.Block) {
+		for _, a := range b.Assets {
+			if _, done := im.uploaded[a.File]; done {
+				continue
+			}
+			url, ok, err := im.uploads.File(a.File)
+			switch {
+			case err != nil:
+				im.warn("%s: %v", p.File, err)
+			case !ok:
+				im.warn("%s: linked file not found: %s", p.File, filepath.Base(a.File))
+			default:
+				im.uploaded[a.File] = url
+			}
+		}
+	})
+	vault.RewriteAssets(p.Blocks, im.uploaded)
+}
+
+func (im *vaultImporter) warn(format string, args ...interface{}) {
+	im.result.Warnings = append(im.result.Warnings, fmt.Sprintf(format, args...))
+}
+
+func printVaultImport(w io.Writer, result VaultImportResult) {
+	if result.DryRun {
+		fmt.Fprintf(w, "Dry run: %s vault %s\n\n", result.Flavor, result.Dir)
+		for _, p := range result.Pages {
+			line := fmt.Sprintf("  %-6s  %s  (%s, %d blocks", p.Action, p.Title, p.File, p.Blocks)
+			if p.Assets > 0 {
+				line += fmt.Sprintf(", %d attachments", p.Assets)
+			}
+			fmt.Fprintln(w, line+")")
+		}
+		fmt.Fprintln(w)
+		printVaultWarnings(w, result.Warnings)
+		fmt.Fprintf(w, "Would create %d pages, update %d and skip %d (%d blocks)\n",
+			result.Created, result.Updated, result.Skipped, result.Blocks)
+		return
+	}
+	printVaultWarnings(w, result.Warnings)
+	fmt.Fprintf(w, "Imported %d blocks: %d pages created, %d updated, %d skipped", result.Blocks, result.Created, result.Updated, result.Skipped)
+	if result.Uploaded > 0 {
+		fmt.Fprintf(w, ", %d files uploaded", result.Uploaded)
+	}
+	fmt.Fprintln(w)
+}
+
+func printVaultWarnings(w io.Writer, warnings []string) {
+	for _, warning := range warnings {
+		fmt.Fprintf(w, "Warning: %s\n", warning)
+	}
+}
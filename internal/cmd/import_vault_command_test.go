@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/output"
+	"github.com/salmonumbrella/roam-cli/internal/vault"
+)
+
+// uploadingClient is a fakeClient that can upload files, like the Local API
+// client.
+type uploadingClient struct {
+	*fakeClient
+	uploads []string
+}
+
+func (c *uploadingClient) UploadFile(filename string, data []byte) (string, error) {
+	c.uploads = append(c.uploads, filename)
+	return "https://files.example/" + filename, nil
+}
+
+func TestImportVaultUpsert(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"logseq/config.edn":      "{}",
+		"pages/New.md":           "- hello ![](../assets/pic.png)\n  id:: 6512bd43-d9ca-4c2e-9b3a-5c0f1e8a7d11\n",
+		"pages/Existing.md":      "- kept\n- added\n",
+		"journals/2025_01_15.md": "- today\n",
+		"assets/pic.png":         "PNG",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	prevCachePath := uploadCachePath
+	uploadCachePath = func() (string, error) { return filepath.Join(t.TempDir(), "cache.json"), nil }
+	defer func() { uploadCachePath = prevCachePath }()
+
+	var existingBlock string
+	var batches []*api.BatchBuilder
+	fake := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			if strings.Contains(query, "?title ...") {
+				return [][]interface{}{{"Existing", "exist-uid"}}, nil
+			}
+			var rows [][]interface{}
+			for _, uid := range args[0].([]string) {
+				if uid == existingBlock {
+					rows = append(rows, []interface{}{uid})
+				}
+			}
+			return rows, nil
+		},
+		ExecuteBatchFunc: func(b *api.BatchBuilder) error {
+			batches = append(batches, b)
+			return nil
+		},
+	}
+	client := &uploadingClient{fakeClient: fake}
+	restoreClient := withTestClient(t, client)
+	defer restoreClient()
+
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(importVaultCmd)
+
+	// Dry run: nothing is written and the existing page is reported as
+	// skipped.
+	importVaultDryRun = true
+	err := runImportVault(importVaultCmd, []string{dir})
+	importVaultDryRun = false
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	var result VaultImportResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse output: %v\n%s", err, out.String())
+	}
+	if len(batches) != 0 || len(client.uploads) != 0 {
+		t.Fatalf("dry run wrote to the graph")
+	}
+	if result.Flavor != "logseq" || result.Created != 2 || result.Skipped != 1 {
+		t.Fatalf("unexpected dry run: %+v", result)
+	}
+
+	// Upsert: the first block of Existing is already there.
+	existingBlock = firstBlockUID(t, dir, "Existing")
+	importVaultUpsert = true
+	defer func() { importVaultUpsert = false }()
+	out.Reset()
+	if err := runImportVault(importVaultCmd, []string{dir}); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	result = VaultImportResult{}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse output: %v\n%s", err, out.String())
+	}
+	if result.Created != 2 || result.Updated != 1 || result.Uploaded != 1 || len(batches) != 3 {
+		t.Fatalf("unexpected import: %+v (%d batches)", result, len(batches))
+	}
+
+	actions := map[string][]map[string]interface{}{}
+	for _, b := range batches {
+		for _, a := range b.Build() {
+			actions[a["action"].(string)] = append(actions[a["action"].(string)], a)
+		}
+	}
+	var blockUIDs, strs []string
+	for _, a := range actions["create-block"] {
+		block := a["block"].(map[string]interface{})
+		blockUIDs = append(blockUIDs, block["uid"].(string))
+		strs = append(strs, block["string"].(string))
+	}
+	if !containsString(blockUIDs, "6512bd43-d9ca-4c2e-9b3a-5c0f1e8a7d11") {
+		t.Fatalf("logseq id not used as uid: %v", blockUIDs)
+	}
+	if !containsString(strs, "hello ![](https://files.example/pic.png)") {
+		t.Fatalf("uploaded image not linked: %v", strs)
+	}
+	var pageUIDs []string
+	for _, a := range actions["create-page"] {
+		pageUIDs = append(pageUIDs, a["page"].(map[string]interface{})["uid"].(string))
+	}
+	if !containsString(pageUIDs, "01-15-2025") {
+		t.Fatalf("daily note not created with its uid: %v", pageUIDs)
+	}
+	if len(actions["update-block"]) != 1 || len(actions["move-block"]) != 1 {
+		t.Fatalf("expected the existing block to be updated and moved: %v", actions)
+	}
+	if loc := actions["move-block"][0]["location"].(map[string]interface{}); loc["parent-uid"] != "exist-uid" {
+		t.Fatalf("existing block moved to the wrong parent: %v", loc)
+	}
+}
+
+func TestImportVaultUploadsToEndpointOnCloud(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, ".obsidian"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Trip.md"), []byte("- beach ![[beach.png]]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "beach.png"), []byte("PNG"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"url":"https://cdn.example/beach.png"}`))
+	}))
+	defer server.Close()
+	prevEnvGet, prevCachePath := envGet, uploadCachePath
+	envGet = func(key string) string {
+		if key == "ROAM_UPLOAD_URL" {
+			return server.URL
+		}
+		return ""
+	}
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+	uploadCachePath = func() (string, error) { return cachePath, nil }
+	defer func() { envGet, uploadCachePath = prevEnvGet, prevCachePath }()
+
+	var strs []string
+	fake := &fakeClient{
+		ExecuteBatchFunc: func(b *api.BatchBuilder) error {
+			for _, a := range b.Build() {
+				if block, ok := a["block"].(map[string]interface{}); ok {
+					strs = append(strs, block["string"].(string))
+				}
+			}
+			return nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(importVaultCmd)
+
+	// The second import finds the file in the upload cache.
+	for i := 0; i < 2; i++ {
+		out.Reset()
+		if err := runImportVault(importVaultCmd, []string{dir}); err != nil {
+			t.Fatalf("import failed: %v", err)
+		}
+	}
+	var result VaultImportResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse output: %v\n%s", err, out.String())
+	}
+	if requests != 1 || result.Uploaded != 0 || result.Reused != 1 || len(result.Warnings) != 0 {
+		t.Fatalf("unexpected uploads: %d requests, %+v", requests, result)
+	}
+	if len(strs) != 2 || strs[1] != "beach ![](https://cdn.example/beach.png)" {
+		t.Fatalf("uploaded image not linked: %q", strs)
+	}
+}
+
+func firstBlockUID(t *testing.T, dir, title string) string {
+	t.Helper()
+	v, err := vault.Load(dir, vault.Logseq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range v.Pages {
+		if p.Title == title {
+			return p.Blocks[0].UID
+		}
+	}
+	t.Fatalf("no page %q", title)
+	return ""
+}
+
+func containsString(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestImportVaultRemapsTakenBlockUIDs(t *testing.T) {
+	dir := t.TempDir()
+	const taken = "6512bd43-d9ca-4c2e-9b3a-5c0f1e8a7d11"
+	files := map[string]string{
+		"logseq/config.edn": "{}",
+		"pages/A.md":        "- see ((" + taken + "))\n",
+		"pages/B.md":        "- target\n  id:: " + taken + "\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var batches []*api.BatchBuilder
+	fake := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			if strings.Contains(query, "?title ...") {
+				return nil, nil
+			}
+			var rows [][]interface{}
+			for _, uid := range args[0].([]string) {
+				if uid == taken {
+					rows = append(rows, []interface{}{uid})
+				}
+			}
+			return rows, nil
+		},
+		ExecuteBatchFunc: func(b *api.BatchBuilder) error {
+			batches = append(batches, b)
+			return nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(importVaultCmd)
+
+	if err := runImportVault(importVaultCmd, []string{dir}); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	blocks := map[string]string{}
+	for _, b := range batches {
+		for _, a := range b.Build() {
+			if a["action"] == "create-block" {
+				block := a["block"].(map[string]interface{})
+				blocks[block["string"].(string)] = block["uid"].(string)
+			}
+		}
+	}
+	uid, ok := blocks["target"]
+	if !ok || uid == taken {
+		t.Fatalf("taken uid not replaced: %v", blocks)
+	}
+	if _, ok := blocks["see (("+uid+"))"]; !ok {
+		t.Fatalf("ref not rewritten to %s: %v", uid, blocks)
+	}
+	if !strings.Contains(out.String(), "already in use") {
+		t.Fatalf("expected a warning: %s", out.String())
+	}
+}
@@ -0,0 +1,386 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/roam-cli/internal/index"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// indexPathFunc resolves the on-disk index location for a graph (overridable in tests).
+var indexPathFunc = index.DefaultPath
+
+// IndexStatus describes the local search index.
+type IndexStatus struct {
+	Graph        string `json:"graph"`
+	Path         string `json:"path"`
+	Blocks       int    `json:"blocks"`
+	Terms        int    `json:"terms"`
+	SizeBytes    int64  `json:"size_bytes,omitempty"`
+	BuiltAt      string `json:"built_at,omitempty"`
+	UpdatedAt    string `json:"updated_at,omitempty"`
+	LastEditTime int64  `json:"last_edit_time,omitempty"`
+	Added        int    `json:"added,omitempty"`
+	Removed      int    `json:"removed,omitempty"`
+}
+
+var indexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Manage the local full-text search index",
+	Long: `Manage a local full-text search index for the current graph.
+
+The index is an inverted index of every block, stored in the user cache
+directory (for example ~/.cache/roam/index/<graph>.gob). Words are stemmed,
+so "planning" also finds "planned", and results are ranked with BM25.
+
+Build the index once, then keep it fresh with 'roam index update', which only
+fetches blocks edited since the last run and drops deleted blocks.
+
+Search it with:
+  roam search --index "quarterly budget"
+  roam search --index '"exact phrase" plan*'`,
+}
+
+var indexBuildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Build the search index from scratch",
+	Long: `Fetch every block in the graph and build the local search index,
+replacing any existing index.
+
+Examples:
+  roam index build
+  roam index build --graph work`,
+	Args: cobra.NoArgs,
+	RunE: runIndexBuild,
+}
+
+var indexUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Incrementally update the search index",
+	Long: `Update the local search index with blocks edited since the last build or
+update, and remove blocks that no longer exist. Builds the index if it does
+not exist yet.
+
+Examples:
+  roam index update`,
+	Args: cobra.NoArgs,
+	RunE: runIndexUpdate,
+}
+
+var indexStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show search index status",
+	Long: `Show where the local search index is stored, how many blocks and terms it
+holds, and when it was last built and updated.
+
+Examples:
+  roam index status
+  roam index status -o json`,
+	Args: cobra.NoArgs,
+	RunE: runIndexStatus,
+}
+
+func init() {
+	indexCmd.AddCommand(indexBuildCmd)
+	indexCmd.AddCommand(indexUpdateCmd)
+	indexCmd.AddCommand(indexStatusCmd)
+	rootCmd.AddCommand(indexCmd)
+}
+
+func runIndexBuild(cmd *cobra.Command, args []string) error {
+	path, err := indexPathFunc(graphName)
+	if err != nil {
+		return err
+	}
+
+	ix := index.New(graphName)
+	added, err := indexFetchBlocks(ix, 0)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	ix.BuiltAt = now
+	ix.UpdatedAt = now
+	if err := ix.Save(path); err != nil {
+		return err
+	}
+
+	return outputIndexStatus(indexStatusFor(ix, path, added, 0), "Built")
+}
+
+func runIndexUpdate(cmd *cobra.Command, args []string) error {
+	path, err := indexPathFunc(graphName)
+	if err != nil {
+		return err
+	}
+
+	ix, err := index.Load(path)
+	if errors.Is(err, index.ErrNotFound) {
+		return runIndexBuild(cmd, args)
+	}
+	if err != nil {
+		return err
+	}
+
+	added, err := indexFetchBlocks(ix, ix.LastEditTime)
+	if err != nil {
+		return err
+	}
+
+	rows, err := GetClient().Query(roamdb.QueryBlockUIDs())
+	if err != nil {
+		return fmt.Errorf("failed to list blocks: %w", err)
+	}
+	keep := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		if len(row) > 0 {
+			keep[fmt.Sprintf("%v", row[0])] = true
+		}
+	}
+	removed := ix.Prune(keep)
+	if err := indexRefreshPageTitles(ix); err != nil {
+		return err
+	}
+
+	ix.UpdatedAt = time.Now()
+	if err := ix.Save(path); err != nil {
+		return err
+	}
+
+	return outputIndexStatus(indexStatusFor(ix, path, added, removed), "Updated")
+}
+
+func runIndexStatus(cmd *cobra.Command, args []string) error {
+	graph, err := resolveIndexGraph(cmd)
+	if err != nil {
+		return err
+	}
+	path, err := indexPathFunc(graph)
+	if err != nil {
+		return err
+	}
+
+	ix, err := index.Load(path)
+	if errors.Is(err, index.ErrNotFound) {
+		return fmt.Errorf("no search index for graph %q; run 'roam index build'", graph)
+	}
+	if err != nil {
+		return err
+	}
+
+	return outputIndexStatus(indexStatusFor(ix, path, 0, 0), "")
+}
+
+// indexRefreshPageTitles updates the page titles of indexed blocks, which
+// change on a page rename without the blocks' edit times changing.
+func indexRefreshPageTitles(ix *index.Index) error {
+	uids := ix.PageUIDs()
+	titles := make(map[string]string, len(uids))
+	for start := 0; start < len(uids); start += lookupChunk {
+		rows, err := GetClient().Query(roamdb.QueryPageTitlesByUID(), uids[start:min(start+lookupChunk, len(uids))])
+		if err != nil {
+			return fmt.Errorf("failed to look up page titles: %w", err)
+		}
+		for _, row := range rows {
+			if len(row) >= 2 {
+				titles[fmt.Sprintf("%v", row[0])] = fmt.Sprintf("%v", row[1])
+			}
+		}
+	}
+	ix.SetPageTitles(titles)
+	return nil
+}
+
+// indexFetchBlocks adds blocks edited at or after since to ix and returns how
+// many were indexed.
+func indexFetchBlocks(ix *index.Index, since int64) (int, error) {
+	rows, err := GetClient().Query(roamdb.QueryIndexBlocks(since))
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch blocks: %w", err)
+	}
+
+	count := 0
+	for _, row := range rows {
+		if len(row) < 5 {
+			continue
+		}
+		editTime, _ := intFromAny(row[4])
+		ix.Add(index.Doc{
+			UID:       fmt.Sprintf("%v", row[0]),
+			Text:      fmt.Sprintf("%v", row[1]),
+			PageTitle: fmt.Sprintf("%v", row[2]),
+			PageUID:   fmt.Sprintf("%v", row[3]),
+			EditTime:  int64(editTime),
+		})
+		count++
+	}
+	return count, nil
+}
+
+// resolveIndexGraph returns the graph name for commands that skip client
+// initialization (such as 'index status').
+func resolveIndexGraph(cmd *cobra.Command) (string, error) {
+	cfg, err := loadConfigFromFlag()
+	if err != nil {
+		return "", formatConfigLoadError(err)
+	}
+	_, graph, _, err := resolveCredentials(cmd, cfg)
+	if err != nil {
+		return "", err
+	}
+	if graph == "" {
+		return "", fmt.Errorf("Graph name required. Set ROAM_GRAPH_NAME or use --graph flag.")
+	}
+	return graph, nil
+}
+
+func indexStatusFor(ix *index.Index, path string, added, removed int) IndexStatus {
+	status := IndexStatus{
+		Graph:        ix.Graph,
+		Path:         path,
+		Blocks:       ix.Len(),
+		Terms:        ix.TermCount(),
+		LastEditTime: ix.LastEditTime,
+		Added:        added,
+		Removed:      removed,
+	}
+	if !ix.BuiltAt.IsZero() {
+		status.BuiltAt = ix.BuiltAt.Format(time.RFC3339)
+	}
+	if !ix.UpdatedAt.IsZero() {
+		status.UpdatedAt = ix.UpdatedAt.Format(time.RFC3339)
+	}
+	if info, err := os.Stat(path); err == nil {
+		status.SizeBytes = info.Size()
+	}
+	return status
+}
+
+func outputIndexStatus(status IndexStatus, action string) error {
+	if structuredOutputRequested() {
+		return printStructured(status)
+	}
+
+	if action != "" {
+		fmt.Printf("%s search index: %d blocks indexed", action, status.Added)
+		if status.Removed > 0 {
+			fmt.Printf(", %d removed", status.Removed)
+		}
+		fmt.Println()
+	}
+	fmt.Printf("Graph:   %s\n", status.Graph)
+	fmt.Printf("Path:    %s\n", status.Path)
+	fmt.Printf("Blocks:  %d\n", status.Blocks)
+	fmt.Printf("Terms:   %d\n", status.Terms)
+	if status.SizeBytes > 0 {
+		fmt.Printf("Size:    %.1f KB\n", float64(status.SizeBytes)/1024)
+	}
+	if status.BuiltAt != "" {
+		fmt.Printf("Built:   %s\n", status.BuiltAt)
+	}
+	if status.UpdatedAt != "" {
+		fmt.Printf("Updated: %s\n", status.UpdatedAt)
+	}
+	if status.LastEditTime > 0 {
+		fmt.Printf("Newest:  %s\n", time.UnixMilli(status.LastEditTime).Format(time.RFC3339))
+	}
+	return nil
+}
+
+// runIndexedSearch answers 'roam search --index' from the local index.
+func runIndexedSearch(cmd *cobra.Command, searchText string) error {
+	for _, name := range []string{"case-sensitive", "regex", "word", "under", "author", "created-since", "created-before"} {
+		if flagChanged(cmd, name) {
+			return fmt.Errorf("--%s is not supported with --index", name)
+		}
+	}
+
+	query, err := index.ParseQuery(searchText)
+	if err != nil {
+		return err
+	}
+	filters, err := searchFiltersFromFlags(time.Now())
+	if err != nil {
+		return err
+	}
+
+	path, err := indexPathFunc(graphName)
+	if err != nil {
+		return err
+	}
+	ix, err := index.Load(path)
+	if errors.Is(err, index.ErrNotFound) {
+		return fmt.Errorf("no search index for graph %q; run 'roam index build'", graphName)
+	}
+	if err != nil {
+		return err
+	}
+
+	hlOpen, hlClose := "**", "**"
+	if !structuredOutputRequested() {
+		hlOpen, hlClose = searchHighlightMarkers()
+	}
+
+	var results []SearchResult
+	for _, hit := range ix.Search(query) {
+		if !indexHitMatchesFilters(hit.Doc, filters) {
+			continue
+		}
+		results = append(results, SearchResult{
+			UID:       hit.Doc.UID,
+			Content:   hit.Doc.Text,
+			PageTitle: hit.Doc.PageTitle,
+			PageUID:   hit.Doc.PageUID,
+			Score:     math.Round(hit.Score*1000) / 1000,
+			Snippet:   hit.Snippet(indexSnippetWidth, hlOpen, hlClose),
+		})
+	}
+
+	// Ranked results are paged by offset; --cursor resumes at the next one.
+	total := len(results)
+	hash := cursorFingerprint(cmd, []string{searchText})
+	if searchCursor != "" || searchAll {
+		start, end, next, err := offsetPage(searchCursor, searchAll, searchLimit, total, "index", hash)
+		if err != nil {
+			return err
+		}
+		return outputSearchResults(searchText, results[start:end], total, start/max(searchLimit, 1)+1, nil, next)
+	}
+	start, end, pageUsed := pageBounds(total, searchPage, searchLimit)
+	next := ""
+	if end < total {
+		next = encodeCursor(pageCursor{Kind: "index", Offset: end, Hash: hash})
+	}
+	return outputSearchResults(searchText, results[start:end], total, pageUsed, nil, next)
+}
+
+const indexSnippetWidth = 120
+
+// indexHitMatchesFilters applies the search filters the index can evaluate.
+func indexHitMatchesFilters(doc *index.Doc, filters roamdb.SearchFilters) bool {
+	if filters.PageTitle != "" && doc.PageTitle != filters.PageTitle {
+		return false
+	}
+	for _, title := range filters.ExcludePages {
+		if title != "" && doc.PageTitle == title {
+			return false
+		}
+	}
+	if ns := strings.TrimSuffix(filters.Namespace, "/"); ns != "" && !strings.HasPrefix(doc.PageTitle, ns+"/") {
+		return false
+	}
+	if filters.EditedSince != 0 && doc.EditTime < filters.EditedSince {
+		return false
+	}
+	if filters.EditedBefore != 0 && doc.EditTime >= filters.EditedBefore {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/roam-cli/internal/output"
+)
+
+func withTestIndexPath(t *testing.T) (string, func()) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "graph.gob")
+	prev := indexPathFunc
+	indexPathFunc = func(string) (string, error) { return path, nil }
+	prevGraph := graphName
+	graphName = "test-graph"
+	return path, func() {
+		indexPathFunc = prev
+		graphName = prevGraph
+	}
+}
+
+func TestIndexBuildUpdateAndSearch(t *testing.T) {
+	_, restorePath := withTestIndexPath(t)
+	defer restorePath()
+
+	blocks := [][]interface{}{
+		{"a", "Planning the quarterly budget", "Finance", "p1", float64(100)},
+		{"b", "Launch checklist", "Launch", "p2", float64(200)},
+	}
+	var queries []string
+	fake := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			queries = append(queries, query)
+			if strings.Contains(query, "?edit-time") {
+				return blocks, nil
+			}
+			if strings.Contains(query, ":node/title ?title") {
+				return [][]interface{}{{"p1", "Money"}, {"p2", "Launch"}}, nil
+			}
+			uids := make([][]interface{}, 0, len(blocks))
+			for _, row := range blocks {
+				uids = append(uids, []interface{}{row[0]})
+			}
+			return uids, nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(indexBuildCmd)
+
+	if err := runIndexBuild(indexBuildCmd, nil); err != nil {
+		t.Fatalf("index build failed: %v", err)
+	}
+	var status IndexStatus
+	if err := json.Unmarshal(out.Bytes(), &status); err != nil {
+		t.Fatalf("failed to parse build output: %v", err)
+	}
+	if status.Blocks != 2 || status.Added != 2 || status.LastEditTime != 200 {
+		t.Fatalf("unexpected build status: %+v", status)
+	}
+
+	// Block b is deleted and c is new.
+	blocks = [][]interface{}{
+		{"a", "Planning the quarterly budget", "Finance", "p1", float64(100)},
+		{"c", "Budget planned for launch", "Launch", "p2", float64(300)},
+	}
+	out.Reset()
+	queries = nil
+	if err := runIndexUpdate(indexUpdateCmd, nil); err != nil {
+		t.Fatalf("index update failed: %v", err)
+	}
+	if len(queries) == 0 || !strings.Contains(queries[0], "[(>= ?edit-time 200)]") {
+		t.Fatalf("expected incremental query, got %v", queries)
+	}
+	status = IndexStatus{}
+	if err := json.Unmarshal(out.Bytes(), &status); err != nil {
+		t.Fatalf("failed to parse update output: %v", err)
+	}
+	if status.Blocks != 2 || status.Removed != 1 {
+		t.Fatalf("unexpected update status: %+v", status)
+	}
+
+	searchPage = 1
+	searchLimit = 50
+	searchFilterPage = "Launch"
+	defer func() { searchFilterPage = "" }()
+	out.Reset()
+	setCmdContext(searchCmd)
+	if err := runIndexedSearch(searchCmd, "plans budget"); err != nil {
+		t.Fatalf("indexed search failed: %v", err)
+	}
+	var parsed SearchOutput
+	if err := json.Unmarshal(out.Bytes(), &parsed); err != nil {
+		t.Fatalf("failed to parse search output: %v", err)
+	}
+	if parsed.Count != 1 || parsed.Results[0].UID != "c" {
+		t.Fatalf("expected only c on Launch, got %+v", parsed.Results)
+	}
+	if parsed.Results[0].Score <= 0 || !strings.Contains(parsed.Results[0].Snippet, "**Budget**") {
+		t.Fatalf("expected score and highlighted snippet, got %+v", parsed.Results[0])
+	}
+
+	// Page p1 was renamed from Finance to Money without editing block a.
+	searchFilterPage = "Money"
+	out.Reset()
+	if err := runIndexedSearch(searchCmd, "quarterly"); err != nil {
+		t.Fatalf("indexed search failed: %v", err)
+	}
+	parsed = SearchOutput{}
+	if err := json.Unmarshal(out.Bytes(), &parsed); err != nil {
+		t.Fatalf("failed to parse search output: %v", err)
+	}
+	if parsed.Count != 1 || parsed.Results[0].UID != "a" {
+		t.Fatalf("expected a under its new page title, got %+v", parsed.Results)
+	}
+}
+
+func TestIndexedSearchRequiresIndex(t *testing.T) {
+	_, restorePath := withTestIndexPath(t)
+	defer restorePath()
+
+	_, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(searchCmd)
+
+	err := runIndexedSearch(searchCmd, "budget")
+	if err == nil || !strings.Contains(err.Error(), "roam index build") {
+		t.Fatalf("expected build hint, got %v", err)
+	}
+}
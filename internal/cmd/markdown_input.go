@@ -3,7 +3,11 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"path/filepath"
 	"strings"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/upload"
 )
 
 func readMarkdownFromFlags(source, content string, stdin io.Reader) (string, error) {
@@ -42,3 +46,73 @@ func readMarkdownFromFlags(source, content string, stdin io.Reader) (string, err
 
 	return "", fmt.Errorf("markdown content required (use --markdown, --markdown-file, or stdin)")
 }
+
+// markdownDir returns the directory relative links in markdown read from
+// source resolve against: the file's directory, or the working directory
+// for inline or piped markdown.
+func markdownDir(source string) string {
+	source = strings.TrimSpace(source)
+	if source == "" || source == "-" {
+		return "."
+	}
+	return filepath.Dir(source)
+}
+
+// uploadMarkdownAssets uploads the local files a Markdown document links to,
+// resolving relative links against dir, and returns the document with the
+// links pointing at the uploaded files. Files go through the Local API when
+// connected to it, otherwise to the configured upload endpoint. Uploads are
+// cached by content hash per graph or endpoint, so importing the same file
+// again reuses its URL.
+func uploadMarkdownAssets(client api.RoamAPI, markdown, dir string, warn io.Writer) (string, *upload.Session, error) {
+	session, err := newUploadSession(client, dir)
+	if err != nil {
+		return "", nil, err
+	}
+	markdown, err = session.Rewrite(markdown)
+	// Keep the URLs of files uploaded before a failure.
+	if saveErr := session.Save(); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	for _, missing := range session.Missing {
+		fmt.Fprintf(warn, "Warning: linked file not found: %s\n", missing)
+	}
+	return markdown, session, nil
+}
+
+// newUploadSession returns an upload session for a client, using the upload
+// cache for its graph or endpoint and resolving relative paths against dir.
+func newUploadSession(client api.RoamAPI, dir string) (*upload.Session, error) {
+	uploader, target, err := assetUploader(client)
+	if err != nil {
+		return nil, err
+	}
+	var cache *upload.Cache
+	if path, err := uploadCachePath(); err == nil {
+		if cache, err = upload.LoadCache(path, target); err != nil {
+			return nil, err
+		}
+	}
+	return upload.NewSession(uploader, cache, dir), nil
+}
+
+// assetUploader returns the uploader for a client and the name its uploads
+// are cached under.
+func assetUploader(client api.RoamAPI) (upload.Uploader, string, error) {
+	if uploader, ok := client.(upload.Uploader); ok {
+		return uploader, "graph:" + client.GraphName(), nil
+	}
+	endpoint := strings.TrimSpace(envGet("ROAM_UPLOAD_URL"))
+	if endpoint == "" {
+		if cfg, err := loadConfigFromFlag(); err == nil {
+			endpoint = strings.TrimSpace(cfg.UploadURL)
+		}
+	}
+	if endpoint == "" {
+		return nil, "", fmt.Errorf("uploading files requires the Local API or an upload endpoint (set ROAM_UPLOAD_URL or 'roam config set upload_url <url>')")
+	}
+	return api.NewUploadClient(endpoint, envGet("ROAM_UPLOAD_TOKEN")), "endpoint:" + endpoint, nil
+}
@@ -36,16 +36,16 @@ func TestVerifyBlockHelpers(t *testing.T) {
 
 func TestSearchOutputText(t *testing.T) {
 	results := []SearchResult{{UID: "u1", Content: "hello", PageTitle: "Page"}}
-	if err := outputSearchResults("q", results, 1, 1); err != nil {
+	if err := outputSearchResults("q", results, 1, 1, nil, ""); err != nil {
 		t.Fatalf("outputSearchResults failed: %v", err)
 	}
-	if err := outputSearchResults("q", nil, 0, 1); err != nil {
+	if err := outputSearchResults("q", nil, 0, 1, nil, ""); err != nil {
 		t.Fatalf("outputSearchResults empty failed: %v", err)
 	}
 }
 
 func TestAppendParsing(t *testing.T) {
-	blocks := parseIndentedBlocks("Parent\n  Child\n")
+	blocks := parseIndentedBlocks("Parent\n  Child\n", 0)
 	if len(blocks) != 1 || len(blocks[0].Children) != 1 {
 		t.Fatalf("unexpected blocks: %+v", blocks)
 	}
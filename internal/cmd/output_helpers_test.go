@@ -20,6 +20,7 @@ func TestStructuredOutputRequested(t *testing.T) {
 		{output.FormatJSON, true},
 		{output.FormatNDJSON, true},
 		{output.FormatYAML, true},
+		{output.FormatEDN, true},
 		{output.FormatTable, false},
 	}
 
@@ -112,6 +113,21 @@ func TestPrintRawStructured_ValidYAML(t *testing.T) {
 	}
 }
 
+func TestPrintRawStructured_EDN(t *testing.T) {
+	out, _, cleanup := withTestContext(t, output.FormatEDN, false)
+	defer cleanup()
+
+	raw := []byte(`{":block/uid":"abc",":block/order":0,":block/children":[{":db/id":12}]}`)
+	if err := printRawStructured(raw); err != nil {
+		t.Fatalf("printRawStructured() error = %v", err)
+	}
+
+	want := "{:block/children [{:db/id 12}] :block/order 0 :block/uid \"abc\"}\n"
+	if out.String() != want {
+		t.Errorf("unexpected EDN output %q", out.String())
+	}
+}
+
 func TestPrintRawStructured_InvalidJSON(t *testing.T) {
 	_, _, cleanup := withTestContext(t, output.FormatJSON, false)
 	defer cleanup()
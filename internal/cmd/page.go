@@ -2,18 +2,22 @@ package cmd
 
 import (
 	"bufio"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/spf13/cobra"
 
 	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/mdblocks"
 	"github.com/salmonumbrella/roam-cli/internal/output"
+	"github.com/salmonumbrella/roam-cli/internal/render"
 	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+	"github.com/salmonumbrella/roam-cli/internal/trash"
 )
 
 var pageCmd = &cobra.Command{
@@ -25,45 +29,25 @@ This command provides subcommands for creating, reading, updating,
 deleting, and listing pages in your graph.`,
 }
 
-// renderMarkdown converts page data to markdown format
-func renderMarkdown(page *roamdb.Page) string {
-	var sb strings.Builder
-	sb.WriteString("# ")
-	sb.WriteString(page.Title)
-	sb.WriteString("\n\n")
-
-	renderBlocksMarkdown(&sb, page.Children, 0)
-	return sb.String()
-}
-
-// renderBlocksMarkdown recursively renders blocks as markdown
-func renderBlocksMarkdown(sb *strings.Builder, blocks []roamdb.Block, depth int) {
-	indent := strings.Repeat("  ", depth)
-	for _, block := range blocks {
-		sb.WriteString(indent)
-		sb.WriteString("- ")
-		sb.WriteString(block.String)
-		sb.WriteString("\n")
-
-		if len(block.Children) > 0 {
-			renderBlocksMarkdown(sb, block.Children, depth+1)
-		}
-	}
-}
-
 // Page get command
 var pageGetCmd = &cobra.Command{
 	Use:   "get <title>",
 	Short: "Get a page by title",
 	Long: `Get a page by its title from your Roam graph.
 
-The page content can be displayed in different formats:
-- raw: Shows the raw JSON response from the API
-- markdown: Renders the page as markdown with nested bullets
+The page content can be rendered in different formats with --render.
+
+` + renderFormatsHelp + `
+
+` + resolveRefsHelp + `
 
 Examples:
   roam page get "Daily Notes"
   roam page get "Project Ideas" --render markdown
+  roam page get "Project Ideas" --render org > project-ideas.org
+  roam page get "Project Ideas" --render html > project-ideas.html
+  roam page get "Project Ideas" --render markdown --resolve-refs
+  roam page get "Project Ideas" --render markdown --resolve-refs=footnote
   roam page get "Meeting Notes" --output json`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -79,8 +63,13 @@ Examples:
 			return fmt.Errorf("failed to get page: %w", err)
 		}
 
+		resolver, err := refResolverFromFlags(cmd, client)
+		if err != nil {
+			return err
+		}
+
 		// Handle output format
-		if structuredOutputRequested() {
+		if structuredOutputRequested() && resolver == nil {
 			if err := printRawStructured(raw); err != nil {
 				return err
 			}
@@ -89,32 +78,28 @@ Examples:
 
 		// Parse and display based on render flag
 		out := stdoutFromContext(cmd.Context())
-		switch renderFormat {
-		case "raw":
-			var prettyJSON map[string]interface{}
-			if err := json.Unmarshal(raw, &prettyJSON); err != nil {
-				return fmt.Errorf("failed to parse response: %w", err)
-			}
-			encoder := json.NewEncoder(out)
-			encoder.SetIndent("", "  ")
-			return encoder.Encode(prettyJSON)
-		case "markdown":
-			page, err := roamdb.ParsePage(raw)
-			if err != nil {
-				return err
-			}
-			fmt.Fprint(out, renderMarkdown(page))
-		default:
-			page, err := roamdb.ParsePage(raw)
-			if err != nil {
+		page, err := roamdb.ParsePage(raw)
+		if err != nil {
+			return err
+		}
+		doc := render.FromPage(page)
+		if resolver != nil {
+			if page.Children, err = resolver.ResolveBlocks(page.Children); err != nil {
 				return err
 			}
-			fmt.Fprintf(out, "Title: %s\n", page.Title)
-			fmt.Fprintf(out, "UID: %s\n", page.UID)
-			if len(page.Children) > 0 {
-				fmt.Fprintf(out, "Blocks: %d\n", countBlocks(page.Children))
+			doc.Blocks, doc.Footnotes = page.Children, resolver.Footnotes
+			if structuredOutputRequested() {
+				return printStructured(resolvedPage{Page: page, Footnotes: resolver.Footnotes})
 			}
 		}
+		if !textRenderRequested(renderFormat) {
+			return renderDocument(out, renderFormat, raw, doc)
+		}
+		fmt.Fprintf(out, "Title: %s\n", page.Title)
+		fmt.Fprintf(out, "UID: %s\n", page.UID)
+		if len(page.Children) > 0 {
+			fmt.Fprintf(out, "Blocks: %d\n", countBlocks(page.Children))
+		}
 
 		return nil
 	},
@@ -136,17 +121,26 @@ var pageCreateCmd = &cobra.Command{
 	Long: `Create a new page in your Roam graph.
 
 If the page already exists, this command will fail.
-Optionally, you can add initial content to the page.
+Optionally, you can add initial content to the page, or copy a template
+into it with --template.
+
+` + templateHelp + `
 
 Examples:
   roam page create "New Project"
-  roam page create "Meeting Notes" --content "Attendees:"`,
+  roam page create "Meeting Notes" --content "Attendees:"
+  roam page create "1:1 Alice 2026-10-16" --template "Templates/1on1" --var person=Alice`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		title := args[0]
 		content, _ := cmd.Flags().GetString("content")
 		childrenView, _ := cmd.Flags().GetString("children-view")
 		uid, _ := cmd.Flags().GetString("uid")
+		templateRef, _ := cmd.Flags().GetString("template")
+		vars, _ := cmd.Flags().GetStringArray("var")
+		if templateRef != "" && content != "" {
+			return fmt.Errorf("--content and --template cannot be used together")
+		}
 
 		client := GetClient()
 
@@ -158,6 +152,9 @@ Examples:
 		if _, ok := err.(api.NotFoundError); !ok {
 			return fmt.Errorf("failed to check existing page: %w", err)
 		}
+		if templateRef != "" {
+			return createPageFromTemplate(client, api.PageOptions{Title: title, UID: uid, ChildrenViewType: childrenView}, templateRef, vars)
+		}
 
 		// Create the page with options
 		opts := api.PageOptions{
@@ -216,14 +213,18 @@ var (
 	pageFromMarkdownFile         string
 	pageFromMarkdownUID          string
 	pageFromMarkdownChildrenView string
+	pageFromMarkdownIndent       int
 )
 
 var pageFromMarkdownCmd = &cobra.Command{
 	Use:   "from-markdown <title>",
-	Short: "Create a new page from markdown (local only)",
-	Long: `Create a new page and populate it by parsing markdown using the Local API.
+	Short: "Create a new page from markdown",
+	Long: `Create a new page and populate it by parsing markdown.
 
-This command uses Roam's markdown importer and will fail if the page already exists.
+With the Local API this uses Roam's markdown importer. With the cloud API
+the markdown is converted like the import command does and the page and its
+blocks are created in one batch. Either way it fails if the page already
+exists.
 
 Examples:
   roam page from-markdown "My New Page" --markdown "# Heading\n- Item"
@@ -238,16 +239,28 @@ Examples:
 			return err
 		}
 
-		localClient, ok := GetClient().(*api.LocalClient)
-		if !ok {
-			return fmt.Errorf("page from-markdown requires the Local API (encrypted graph)")
-		}
-
 		opts := api.PageOptions{
 			Title:            title,
 			UID:              pageFromMarkdownUID,
 			ChildrenViewType: pageFromMarkdownChildrenView,
 		}
+
+		client := GetClient()
+		localClient, ok := client.(*api.LocalClient)
+		if !ok {
+			blocks, viewType := parseMarkdown(markdown, pageFromMarkdownIndent)
+			if opts.ChildrenViewType == "" {
+				opts.ChildrenViewType = viewType
+			}
+			batch := api.NewBatchBuilder()
+			ref := batch.CreatePage(opts)
+			buildBatchBlocks(batch, ref, blocks, "last")
+			if err := client.ExecuteBatch(batch); err != nil {
+				return fmt.Errorf("failed to create page from markdown: %w", err)
+			}
+			goto pageCreated
+		}
+
 		if err := localClient.CreatePageFromMarkdown(opts, markdown); err != nil {
 			var localErr api.LocalAPIError
 			if errors.As(err, &localErr) && localErr.IsResponseTimeout() {
@@ -332,19 +345,38 @@ var pageDeleteCmd = &cobra.Command{
 	Short: "Delete a page",
 	Long: `Delete a page from your Roam graph by its UID.
 
-This action is destructive and cannot be undone. Use the --yes flag
-to skip the confirmation prompt.
+Before asking for confirmation the command shows how many blocks are on
+the page and how many other blocks reference the page or its blocks. The
+page is saved with all its blocks to the local trash before it is
+deleted; see 'roam trash restore'. If the page can't be read for the
+trash, nothing is deleted; --no-trash skips the trash and the impact
+report. Use the --yes flag to skip the
+confirmation prompt.
 
 Examples:
   roam page delete "abc123"
-  roam page delete "abc123" --yes`,
+  roam page delete "abc123" --yes
+  roam page delete "abc123" --yes --no-trash`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		uid := args[0]
 
+		client := GetClient()
+		noTrash, _ := cmd.Flags().GetBool("no-trash")
+		entry, referencing, err := deleteTrashEntry(client, trash.KindPage, uid, noTrash, cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+
 		if !output.YesFromContext(cmd.Context()) {
 			errOut := cmd.ErrOrStderr()
-			fmt.Fprintf(errOut, "Are you sure you want to delete page %s? This cannot be undone.\n", uid)
+			fmt.Fprintf(errOut, "Are you sure you want to delete page %s?\n", uid)
+			if entry != nil {
+				printDeleteImpact(errOut, entry)
+				fmt.Fprintln(errOut, "The page is saved to the local trash first ('roam trash restore').")
+			} else {
+				fmt.Fprintln(errOut, "The page is not saved to the trash.")
+			}
 			fmt.Fprint(errOut, "Type 'yes' to confirm: ")
 			reader := bufio.NewReader(stdinFromContext(cmd.Context()))
 			confirm, _ := reader.ReadString('\n')
@@ -354,7 +386,12 @@ Examples:
 			}
 		}
 
-		client := GetClient()
+		var trashDir string
+		if entry != nil {
+			if trashDir, err = saveToTrash(entry); err != nil {
+				return err
+			}
+		}
 		if err := client.DeletePage(uid); err != nil {
 			var localErr api.LocalAPIError
 			if errors.As(err, &localErr) && localErr.IsResponseTimeout() {
@@ -362,23 +399,51 @@ Examples:
 					goto pageDeleted
 				}
 			}
+			if entry != nil {
+				discardTrashEntry(trashDir, entry)
+			}
 			return fmt.Errorf("failed to delete page: %w", err)
 		}
 	pageDeleted:
 
 		if structuredOutputRequested() {
-			result := map[string]string{
+			result := map[string]interface{}{
 				"status": "deleted",
 				"uid":    uid,
 			}
+			if entry != nil {
+				result["title"] = entry.Title
+				result["blocks"] = entry.Blocks
+				result["referenced_by"] = referencing
+				result["trash_id"] = entry.ID
+			}
 			return printStructured(result)
 		}
 
 		fmt.Printf("Deleted page: %s\n", uid)
+		if entry != nil {
+			fmt.Printf("Saved to trash: %s\n", entry.ID)
+		}
 		return nil
 	},
 }
 
+// PageListItem is one page in 'page list' output.
+type PageListItem struct {
+	Title    string     `json:"title"`
+	UID      string     `json:"uid"`
+	EditTime int64      `json:"edit_time,omitempty"`
+	Stats    *PageStats `json:"stats,omitempty"`
+}
+
+// PageListOutput is the structured output of 'page list' with --cursor or
+// --all.
+type PageListOutput struct {
+	Count      int            `json:"count"`
+	Results    []PageListItem `json:"results"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
 // Page list command
 var pageListCmd = &cobra.Command{
 	Use:   "list",
@@ -388,48 +453,64 @@ var pageListCmd = &cobra.Command{
 By default, lists all pages. Use --modified-today to filter to pages
 modified today. Use --limit to restrict the number of results.
 
+The filters --namespace, --created-since, --edited-since, --orphans (no
+block references the page), --empty (no children) and --daily/--no-daily
+are applied by the query itself and can be combined. --with-stats adds
+each page's block count, word count and reference count.
+
+For large graphs, --cursor pages through pages in creation order, --limit at
+a time, scanning a slice of the graph per request; structured output then
+has {"count", "results", "next_cursor"}. Pass --cursor start for the first
+page and the returned next_cursor for the following ones. --all returns every
+page, written as it is found with --output ndjson. --sort cannot be combined
+with --cursor or --all.
+
 Examples:
   roam page list
   roam page list --modified-today
   roam page list --limit 10 --sort title
+  roam page list --namespace Project --edited-since 7d
+  roam page list --orphans --empty --no-daily
+  roam page list --namespace Project --with-stats --sort title
+  roam page list --limit 500 --cursor start -o json
+  roam page list --all -o ndjson
   roam page list --output json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		modifiedToday, _ := cmd.Flags().GetBool("modified-today")
 		limit, _ := cmd.Flags().GetInt("limit")
 		sortBy, _ := cmd.Flags().GetString("sort")
+		cursor, _ := cmd.Flags().GetString("cursor")
+		all, _ := cmd.Flags().GetBool("all")
+		withStats, _ := cmd.Flags().GetBool("with-stats")
+
+		now := time.Now()
+		filter, err := pageFilterFromFlags(cmd, now)
+		if err != nil {
+			return err
+		}
+
+		if cursor != "" || all {
+			if sortBy != "" {
+				return fmt.Errorf("--sort cannot be combined with --cursor or --all")
+			}
+			return runPageListPaged(cmd, args, filter, withStats, limit, cursor, all)
+		}
 
 		client := GetClient()
-		results, err := client.ListPages(modifiedToday, 0) // Get all, sort/limit locally
+		var results [][]interface{}
+		if filter == (roamdb.PageFilter{ModifiedToday: modifiedToday}) {
+			results, err = client.ListPages(modifiedToday, 0) // Get all, sort/limit locally
+		} else {
+			results, err = client.Query(roamdb.QueryListPages(filter, now))
+		}
 		if err != nil {
 			return fmt.Errorf("failed to list pages: %w", err)
 		}
 
 		// Build page list
-		type pageInfo struct {
-			Title    string `json:"title"`
-			UID      string `json:"uid"`
-			EditTime int64  `json:"edit_time,omitempty"`
-		}
-
-		var pages []pageInfo
+		var pages []PageListItem
 		for _, row := range results {
-			p := pageInfo{}
-			if len(row) > 0 {
-				if title, ok := row[0].(string); ok {
-					p.Title = title
-				}
-			}
-			if len(row) > 1 {
-				if uid, ok := row[1].(string); ok {
-					p.UID = uid
-				}
-			}
-			if len(row) > 2 {
-				if editTime, ok := row[2].(float64); ok {
-					p.EditTime = int64(editTime)
-				}
-			}
-			pages = append(pages, p)
+			pages = append(pages, pageListItemFromRow(row))
 		}
 
 		// Sort results
@@ -453,6 +534,12 @@ Examples:
 			pages = pages[:limit]
 		}
 
+		if withStats {
+			if err := addPageStats(client, pages); err != nil {
+				return err
+			}
+		}
+
 		// Output results
 		if structuredOutputRequested() {
 			return printStructured(pages)
@@ -464,34 +551,167 @@ Examples:
 			return nil
 		}
 
-		ctx := currentContext()
-		printer := output.NewPrinter(stdoutFromContext(ctx), output.FormatTable)
-		var headers []string
-		var rows [][]string
+		return printPageListTable(pages, showModified(filter), withStats)
+	},
+}
 
-		if modifiedToday {
-			headers = []string{"TITLE", "UID", "MODIFIED"}
-			for _, p := range pages {
-				rows = append(rows, []string{
-					truncateString(p.Title, 50),
-					p.UID,
-					formatTimestamp(p.EditTime),
-				})
+// pageListItemFromRow converts a [title uid edit-time ...] row.
+func pageListItemFromRow(row []interface{}) PageListItem {
+	p := PageListItem{}
+	if len(row) > 0 {
+		if title, ok := row[0].(string); ok {
+			p.Title = title
+		}
+	}
+	if len(row) > 1 {
+		if uid, ok := row[1].(string); ok {
+			p.UID = uid
+		}
+	}
+	if len(row) > 2 {
+		if editTime, ok := row[2].(float64); ok {
+			p.EditTime = int64(editTime)
+		}
+	}
+	return p
+}
+
+func printPageListTable(pages []PageListItem, modifiedToday, withStats bool) error {
+	ctx := currentContext()
+	printer := output.NewPrinter(stdoutFromContext(ctx), output.FormatTable)
+	var headers []string
+	var rows [][]string
+
+	if withStats {
+		headers = []string{"TITLE", "UID", "BLOCKS", "WORDS", "REFS"}
+		for _, p := range pages {
+			stats := PageStats{}
+			if p.Stats != nil {
+				stats = *p.Stats
 			}
-		} else {
-			headers = []string{"TITLE", "UID"}
-			for _, p := range pages {
-				rows = append(rows, []string{
-					truncateString(p.Title, 50),
-					p.UID,
-				})
+			rows = append(rows, []string{
+				truncateString(p.Title, 50),
+				p.UID,
+				strconv.Itoa(stats.Blocks),
+				strconv.Itoa(stats.Words),
+				strconv.Itoa(stats.Refs),
+			})
+		}
+	} else if modifiedToday {
+		headers = []string{"TITLE", "UID", "MODIFIED"}
+		for _, p := range pages {
+			rows = append(rows, []string{
+				truncateString(p.Title, 50),
+				p.UID,
+				formatTimestamp(p.EditTime),
+			})
+		}
+	} else {
+		headers = []string{"TITLE", "UID"}
+		for _, p := range pages {
+			rows = append(rows, []string{
+				truncateString(p.Title, 50),
+				p.UID,
+			})
+		}
+	}
+
+	return printer.Print(ctx, output.Table{Headers: headers, Rows: rows})
+}
+
+// runPageListPaged lists pages with --cursor/--all, scanning windows of page
+// entity ids so each request reads only part of the graph.
+func runPageListPaged(cmd *cobra.Command, args []string, filter roamdb.PageFilter, withStats bool, limit int, token string, all bool) error {
+	client := GetClient()
+	hash := cursorFingerprint(cmd, args)
+	cursor, err := decodeCursor(token, "pages", hash)
+	if err != nil {
+		return err
+	}
+	maxEID, err := queryMaxEID(client)
+	if err != nil {
+		return err
+	}
+	if all {
+		limit = 0
+	} else if limit <= 0 {
+		limit = defaultPageListCursorLimit
+	}
+
+	now := time.Now()
+	scan := &eidScan{
+		eidCol: 3,
+		max:    maxEID,
+		fetch: func(r roamdb.EIDRange) ([][]interface{}, error) {
+			rows, err := client.Query(roamdb.QueryListPagesRange(filter, now, r))
+			if err != nil {
+				return nil, fmt.Errorf("failed to list pages: %w", err)
 			}
+			return rows, nil
+		},
+	}
+
+	structured := structuredOutputRequested()
+	var stream *output.Stream
+	if structured && streamingRequested() {
+		if stream, err = newOutputStream(); err != nil {
+			return err
 		}
+	}
 
-		return printer.Print(ctx, output.Table{Headers: headers, Rows: rows})
-	},
+	pages := []PageListItem{}
+	after, more, err := scan.run(cursor.After, limit, func(rows [][]interface{}) error {
+		batch := make([]PageListItem, 0, len(rows))
+		for _, row := range rows {
+			batch = append(batch, pageListItemFromRow(row))
+		}
+		if withStats {
+			if err := addPageStats(client, batch); err != nil {
+				return err
+			}
+		}
+		for _, page := range batch {
+			if stream != nil {
+				if err := stream.Write(page); err != nil {
+					return err
+				}
+				continue
+			}
+			pages = append(pages, page)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	next := ""
+	if more {
+		next = encodeCursor(pageCursor{Kind: "pages", After: after, Hash: hash})
+	}
+	switch {
+	case stream != nil:
+		return writeNextCursor(stream, next)
+	case structured:
+		return printStructured(PageListOutput{Count: len(pages), Results: pages, NextCursor: next})
+	}
+
+	if len(pages) == 0 {
+		fmt.Println("No pages found.")
+		return nil
+	}
+	if err := printPageListTable(pages, showModified(filter), withStats); err != nil {
+		return err
+	}
+	if next != "" {
+		fmt.Printf("\nMore pages: --cursor %s\n", next)
+	}
+	return nil
 }
 
+// defaultPageListCursorLimit is the page size for --cursor without --limit.
+const defaultPageListCursorLimit = 100
+
 // Page rename command
 var pageRenameCmd = &cobra.Command{
 	Use:   "rename <old-title> <new-title>",
@@ -501,14 +721,24 @@ var pageRenameCmd = &cobra.Command{
 This command finds the page by its current title and updates it
 to the new title.
 
+With --cascade every page in the old title's namespace is renamed too, so
+renaming "Project/Alpha" to "Project/Beta" also moves "Project/Alpha/Notes"
+to "Project/Beta/Notes". All new titles are checked for collisions before
+anything is renamed, and the pages are renamed in one batch.
+
 Examples:
   roam page rename "Old Title" "New Title"
-  roam page rename "Draft Notes" "Final Notes"`,
+  roam page rename "Draft Notes" "Final Notes"
+  roam page rename "Project/Alpha" "Project/Beta" --cascade`,
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		oldTitle := args[0]
 		newTitle := args[1]
 
+		if cascade, _ := cmd.Flags().GetBool("cascade"); cascade {
+			return runPageRenameCascade(oldTitle, newTitle)
+		}
+
 		client := GetClient()
 
 		// Get the page by old title
@@ -555,11 +785,15 @@ Examples:
 }
 
 // Helper functions
+
+// truncateString shortens s to at most maxLen characters, ending in "..."
+// when cut. It cuts between runes, so multi-byte characters stay whole.
 func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	if utf8.RuneCountInString(s) <= maxLen {
 		return s
 	}
-	return s[:maxLen-3] + "..."
+	runes := []rune(s)
+	return string(runes[:maxLen-3]) + "..."
 }
 
 func formatTimestamp(ts int64) string {
@@ -612,27 +846,39 @@ func init() {
 	pageCmd.AddCommand(pageRenameCmd)
 
 	// Flags for get command
-	pageGetCmd.Flags().StringP("render", "f", "text", "Render format: text, raw, markdown")
+	pageGetCmd.Flags().StringP("render", "f", "text", renderFlagUsage)
+	addResolveRefsFlags(pageGetCmd)
 
 	// Flags for create command
 	pageCreateCmd.Flags().StringP("content", "c", "", "Initial content for the page")
 	pageCreateCmd.Flags().String("uid", "", "Custom page UID (optional)")
 	pageCreateCmd.Flags().String("children-view", "", "Children view: bullet, numbered, document")
+	pageCreateCmd.Flags().String("template", "", "Copy a template into the page: page title, roam/templates name or block uid")
+	pageCreateCmd.Flags().StringArray("var", nil, "Template variable as name=value (repeatable)")
 
 	// Flags for from-markdown command
 	pageFromMarkdownCmd.Flags().StringVar(&pageFromMarkdownContent, "markdown", "", "Markdown content as a string")
 	pageFromMarkdownCmd.Flags().StringVar(&pageFromMarkdownFile, "markdown-file", "", "Markdown file path (or - for stdin)")
 	pageFromMarkdownCmd.Flags().StringVar(&pageFromMarkdownUID, "uid", "", "Custom page UID (optional)")
 	pageFromMarkdownCmd.Flags().StringVar(&pageFromMarkdownChildrenView, "children-view", "", "Children view: bullet, numbered, document")
+	pageFromMarkdownCmd.Flags().IntVar(&pageFromMarkdownIndent, "indent", mdblocks.DefaultIndent, "Columns a tab counts as when nesting list items (cloud only)")
 
 	// Flags for update command
 	pageUpdateCmd.Flags().StringP("title", "t", "", "New title for the page")
 	pageUpdateCmd.Flags().String("children-view", "", "Children view: bullet, numbered, document")
 
 	// Flags for delete command
+	pageDeleteCmd.Flags().Bool("no-trash", false, "Delete without saving the page to the local trash")
 
 	// Flags for list command
 	pageListCmd.Flags().Bool("modified-today", false, "Only show pages modified today")
 	pageListCmd.Flags().IntP("limit", "l", 0, "Maximum number of pages to return")
 	pageListCmd.Flags().StringP("sort", "s", "", "Sort by: title, modified, uid")
+	pageListCmd.Flags().String("cursor", "", `Resume after a previous page ("start" for the first page)`)
+	pageListCmd.Flags().Bool("all", false, "Return every page, streaming with --output ndjson")
+	addPageFilterFlags(pageListCmd)
+	pageListCmd.Flags().Bool("with-stats", false, "Add block, word and reference counts")
+
+	// Flags for rename command
+	pageRenameCmd.Flags().Bool("cascade", false, "Also rename every page in the old title's namespace")
 }
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// BacklinkGroup holds the references to a page from one other page.
+type BacklinkGroup struct {
+	PageTitle  string         `json:"page_title"`
+	PageUID    string         `json:"page_uid"`
+	References []SearchResult `json:"references"`
+}
+
+// BacklinksOutput is the result of 'page backlinks'.
+type BacklinksOutput struct {
+	Title    string          `json:"title"`
+	UID      string          `json:"uid"`
+	Unlinked bool            `json:"unlinked"`
+	Count    int             `json:"count"`
+	Pages    []BacklinkGroup `json:"pages"`
+}
+
+var (
+	backlinksUnlinked bool
+	backlinksAliases  bool
+)
+
+var pageBacklinksCmd = &cobra.Command{
+	Use:   "backlinks <title>",
+	Short: "List linked or unlinked references to a page",
+	Long: `List the blocks that reference a page, grouped by the page they are on,
+with the breadcrumb path to each block, like the Linked References section in
+Roam.
+
+References come from :block/refs, so [[Title]], #Title, #[[Title]] and
+Title:: attributes all count. References to the page's aliases (listed in an
+Aliases:: block) are included unless --aliases=false. Blocks on the page
+itself are not listed.
+
+With --unlinked, lists blocks that mention the title as plain text (ignoring
+case, on word boundaries) without linking to it, like Unlinked References.
+
+Examples:
+  roam page backlinks "Project Alpha"
+  roam page backlinks "Project Alpha" --unlinked
+  roam page backlinks "Project Alpha" -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPageBacklinks,
+}
+
+func init() {
+	pageCmd.AddCommand(pageBacklinksCmd)
+	pageBacklinksCmd.Flags().BoolVar(&backlinksUnlinked, "unlinked", false, "List plain-text mentions that do not link to the page")
+	pageBacklinksCmd.Flags().BoolVar(&backlinksAliases, "aliases", true, "Include references to the page's aliases")
+}
+
+func runPageBacklinks(cmd *cobra.Command, args []string) error {
+	client := GetClient()
+	title := args[0]
+
+	pageRows, err := client.Query(roamdb.QueryPageUIDByTitle(title))
+	if err != nil {
+		return fmt.Errorf("failed to find page: %w", err)
+	}
+	if len(pageRows) == 0 || len(pageRows[0]) == 0 {
+		return fmt.Errorf("page not found: %s", title)
+	}
+	pageUID := fmt.Sprintf("%v", pageRows[0][0])
+
+	search := roamdb.BlockSearch{Filters: roamdb.SearchFilters{ExcludePages: []string{title}}}
+	mention := roamdb.TextMatch{Term: title, Word: isWordBounded(title)}
+	if backlinksUnlinked {
+		search.Clauses = roamdb.UnlinkedRefClauses(title, mention)
+	} else {
+		search.Clauses = roamdb.PageRefClauses(roamdb.TextMatch{Term: title, CaseSensitive: true}, backlinksAliases)
+	}
+
+	rows, err := client.Query(search.Query(), search.Args()...)
+	if err != nil {
+		return fmt.Errorf("backlinks query failed: %w", err)
+	}
+	re, err := mention.Compile()
+	if err != nil {
+		return err
+	}
+	if backlinksUnlinked {
+		rows = filterSearchRows(rows, re)
+	}
+
+	results := searchResultsFromRows(rows)
+	if err := addSearchContext(client, results, 0); err != nil {
+		return err
+	}
+
+	payload := BacklinksOutput{
+		Title:    title,
+		UID:      pageUID,
+		Unlinked: backlinksUnlinked,
+		Count:    len(results),
+		Pages:    groupBacklinks(results),
+	}
+	if structuredOutputRequested() {
+		return printStructured(payload)
+	}
+
+	kind := "Linked"
+	if backlinksUnlinked {
+		kind = "Unlinked"
+	}
+	if payload.Count == 0 {
+		fmt.Printf("No %s references to %q\n", strings.ToLower(kind), title)
+		return nil
+	}
+	fmt.Printf("%s references to %q: %d\n", kind, title, payload.Count)
+
+	hlOpen, hlClose := searchHighlightMarkers()
+	for _, group := range payload.Pages {
+		fmt.Printf("\n%s\n", group.PageTitle)
+		for _, ref := range group.References {
+			if len(ref.Breadcrumbs) > 0 {
+				crumbs := make([]string, len(ref.Breadcrumbs))
+				for i, crumb := range ref.Breadcrumbs {
+					crumbs[i] = searchExcerpt(crumb, nil, 40)
+				}
+				fmt.Printf("  %s\n", strings.Join(crumbs, " > "))
+			}
+			content := highlightMatches(searchExcerpt(ref.Content, re, searchExcerptWidth), re, hlOpen, hlClose)
+			fmt.Printf("  - [%s] %s\n", ref.UID, content)
+		}
+	}
+	return nil
+}
+
+// groupBacklinks groups references by page, ordered by page title.
+func groupBacklinks(results []SearchResult) []BacklinkGroup {
+	byPage := make(map[string]*BacklinkGroup)
+	var order []string
+	for _, r := range results {
+		group, ok := byPage[r.PageUID]
+		if !ok {
+			group = &BacklinkGroup{PageTitle: r.PageTitle, PageUID: r.PageUID}
+			byPage[r.PageUID] = group
+			order = append(order, r.PageUID)
+		}
+		group.References = append(group.References, r)
+	}
+
+	groups := make([]BacklinkGroup, 0, len(order))
+	for _, uid := range order {
+		group := byPage[uid]
+		sort.SliceStable(group.References, func(i, j int) bool {
+			return group.References[i].UID < group.References[j].UID
+		})
+		groups = append(groups, *group)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return strings.ToLower(groups[i].PageTitle) < strings.ToLower(groups[j].PageTitle)
+	})
+	return groups
+}
+
+// isWordBounded reports whether s starts and ends with an ASCII word
+// character, so \b anchors (ASCII-only in Go and JavaScript) can be placed
+// around it.
+func isWordBounded(s string) bool {
+	first, _ := utf8.DecodeRuneInString(s)
+	last, _ := utf8.DecodeLastRuneInString(s)
+	isWord := func(r rune) bool {
+		return r == '_' || (r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)))
+	}
+	return s != "" && isWord(first) && isWord(last)
+}
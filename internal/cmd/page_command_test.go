@@ -8,6 +8,7 @@ import (
 
 	"github.com/salmonumbrella/roam-cli/internal/api"
 	"github.com/salmonumbrella/roam-cli/internal/output"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
 )
 
 func TestPageGetRenderMarkdown(t *testing.T) {
@@ -80,6 +81,73 @@ func TestPageListStructured(t *testing.T) {
 	}
 }
 
+func TestPageListFiltersAndStats(t *testing.T) {
+	var listQuery string
+	fake := &fakeClient{
+		ListPagesFunc: func(modifiedToday bool, limit int) ([][]interface{}, error) {
+			t.Fatal("filters should go through the page list query")
+			return nil, nil
+		},
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			switch {
+			case strings.Contains(query, "(count ?r)"):
+				return [][]interface{}{{"uid-a", float64(3)}}, nil
+			case strings.Contains(query, ":block/page"):
+				return [][]interface{}{
+					{"uid-a", float64(10), "Read [[Deep Work]] today"},
+					{"uid-a", float64(11), "**done**"},
+				}, nil
+			}
+			listQuery = query
+			return [][]interface{}{{"Project/A", "uid-a", float64(5)}, {"Project/B", "uid-b", float64(6)}}, nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(pageListCmd)
+
+	flags := pageListCmd.Flags()
+	for name, value := range map[string]string{"namespace": "Project", "no-daily": "true", "orphans": "true", "with-stats": "true", "sort": "title"} {
+		if err := flags.Set(name, value); err != nil {
+			t.Fatalf("set %s failed: %v", name, err)
+		}
+	}
+	defer func() {
+		for name, value := range map[string]string{"namespace": "", "no-daily": "false", "orphans": "false", "with-stats": "false", "sort": ""} {
+			_ = flags.Set(name, value)
+		}
+	}()
+
+	if err := pageListCmd.RunE(pageListCmd, []string{}); err != nil {
+		t.Fatalf("page list failed: %v", err)
+	}
+	for _, want := range []string{`"Project/"`, "(not [(re-find ?daily-re ?uid)])", "(not [_ :block/refs ?p])"} {
+		if !strings.Contains(listQuery, want) {
+			t.Fatalf("missing %q in %s", want, listQuery)
+		}
+	}
+
+	var pages []PageListItem
+	if err := json.Unmarshal(out.Bytes(), &pages); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if len(pages) != 2 || pages[0].Stats == nil || *pages[0].Stats != (PageStats{Blocks: 2, Words: 5, Refs: 3}) {
+		t.Fatalf("unexpected stats: %+v", pages)
+	}
+	if pages[1].Stats == nil || *pages[1].Stats != (PageStats{}) {
+		t.Fatalf("expected zero stats for uid-b: %+v", pages[1].Stats)
+	}
+
+	_ = flags.Set("daily", "true")
+	defer func() { _ = flags.Set("daily", "false") }()
+	if err := pageListCmd.RunE(pageListCmd, []string{}); err == nil {
+		t.Fatal("expected an error for --daily with --no-daily")
+	}
+}
+
 func TestPageUpdateStructured(t *testing.T) {
 	var gotUID string
 	var gotTitle string
@@ -165,8 +233,14 @@ func TestPageFromMarkdownLocal(t *testing.T) {
 	}
 }
 
-func TestPageFromMarkdownRequiresLocal(t *testing.T) {
-	fake := &fakeClient{}
+func TestPageFromMarkdownUsesBatchOnCloud(t *testing.T) {
+	var actions []map[string]interface{}
+	fake := &fakeClient{
+		ExecuteBatchFunc: func(b *api.BatchBuilder) error {
+			actions = b.Build()
+			return nil
+		},
+	}
 	restoreClient := withTestClient(t, fake)
 	defer restoreClient()
 
@@ -174,11 +248,18 @@ func TestPageFromMarkdownRequiresLocal(t *testing.T) {
 	defer restoreCtx()
 	setCmdContext(pageFromMarkdownCmd)
 
-	pageFromMarkdownContent = "# Heading"
+	pageFromMarkdownContent = "1. One\n2. Two\n"
 	defer func() { pageFromMarkdownContent = "" }()
 
-	if err := pageFromMarkdownCmd.RunE(pageFromMarkdownCmd, []string{"My Page"}); err == nil {
-		t.Fatalf("expected error for non-local client")
+	if err := pageFromMarkdownCmd.RunE(pageFromMarkdownCmd, []string{"My Page"}); err != nil {
+		t.Fatalf("from-markdown failed: %v", err)
+	}
+	if len(actions) != 3 || actions[0]["action"] != "create-page" {
+		t.Fatalf("unexpected actions: %v", actions)
+	}
+	page := actions[0]["page"].(map[string]interface{})
+	if page["title"] != "My Page" || page["children-view-type"] != "numbered" {
+		t.Fatalf("unexpected page: %v", page)
 	}
 }
 
@@ -221,3 +302,251 @@ func TestPageFromMarkdownEmptyInput(t *testing.T) {
 		t.Fatalf("expected error for empty markdown input")
 	}
 }
+
+func TestPageBacklinksGroupsByPage(t *testing.T) {
+	fake := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			switch {
+			case strings.HasPrefix(query, "[:find ?uid\n") && strings.Contains(query, `[?p :node/title "Alpha"]`):
+				return [][]interface{}{{"alpha-uid"}}, nil
+			case strings.Contains(query, ":block/parents"):
+				return [][]interface{}{{"b2", "b2", "parent", "Notes"}, {"b2", "parent", "p2", ""}}, nil
+			case strings.Contains(query, "[?b :block/refs ?ref-target]"):
+				return [][]interface{}{
+					{"b2", "see [[Alpha]]", "Zeta", "p2"},
+					{"b1", "#Alpha kickoff", "Beta", "p1"},
+					{"b3", "Alpha:: yes", "Zeta", "p2"},
+				}, nil
+			}
+			return nil, nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(pageBacklinksCmd)
+
+	backlinksUnlinked = false
+	backlinksAliases = true
+
+	if err := runPageBacklinks(pageBacklinksCmd, []string{"Alpha"}); err != nil {
+		t.Fatalf("backlinks failed: %v", err)
+	}
+
+	var parsed BacklinksOutput
+	if err := json.Unmarshal(out.Bytes(), &parsed); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if parsed.UID != "alpha-uid" || parsed.Count != 3 || len(parsed.Pages) != 2 {
+		t.Fatalf("unexpected output: %+v", parsed)
+	}
+	if parsed.Pages[0].PageTitle != "Beta" || parsed.Pages[1].PageTitle != "Zeta" {
+		t.Fatalf("expected pages sorted by title, got %+v", parsed.Pages)
+	}
+	zeta := parsed.Pages[1].References
+	if len(zeta) != 2 || zeta[0].UID != "b2" || strings.Join(zeta[0].Breadcrumbs, ">") != "Notes" {
+		t.Fatalf("unexpected Zeta references: %+v", zeta)
+	}
+}
+
+func TestPageBacklinksUnlinked(t *testing.T) {
+	var refQuery string
+	fake := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			switch {
+			case strings.HasPrefix(query, "[:find ?uid\n"):
+				return [][]interface{}{{"alpha-uid"}}, nil
+			case strings.Contains(query, "?string ?page-title"):
+				refQuery = query
+				return [][]interface{}{
+					{"b1", "talked about alpha today", "Log", "p1"},
+					{"b2", "alphabet soup", "Log", "p1"},
+				}, nil
+			}
+			return nil, nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(pageBacklinksCmd)
+
+	backlinksUnlinked = true
+	defer func() { backlinksUnlinked = false }()
+
+	if err := runPageBacklinks(pageBacklinksCmd, []string{"Alpha"}); err != nil {
+		t.Fatalf("unlinked backlinks failed: %v", err)
+	}
+	if !strings.Contains(refQuery, "(not [?b :block/refs ?ref-page])") || !strings.Contains(refQuery, `[(not= ?page-title "Alpha")]`) {
+		t.Fatalf("unexpected unlinked query: %s", refQuery)
+	}
+
+	var parsed BacklinksOutput
+	if err := json.Unmarshal(out.Bytes(), &parsed); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if !parsed.Unlinked || parsed.Count != 1 || parsed.Pages[0].References[0].UID != "b1" {
+		t.Fatalf("expected only the whole-word mention, got %+v", parsed)
+	}
+}
+
+func TestPageBacklinksMissingPage(t *testing.T) {
+	fake := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			return nil, nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+
+	_, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(pageBacklinksCmd)
+
+	err := runPageBacklinks(pageBacklinksCmd, []string{"Nope"})
+	if err == nil || !strings.Contains(err.Error(), "page not found") {
+		t.Fatalf("expected page not found, got %v", err)
+	}
+}
+
+func TestPageListCursorScansEIDWindows(t *testing.T) {
+	var queries []string
+	fake := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			queries = append(queries, query)
+			if query == roamdb.QueryMaxEID() {
+				return [][]interface{}{{float64(100)}}, nil
+			}
+			return [][]interface{}{
+				{"B", "uid-b", float64(0), float64(9)},
+				{"A", "uid-a", float64(5), float64(4)},
+				{"C", "uid-c", float64(0), float64(12)},
+			}, nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(pageListCmd)
+
+	_ = pageListCmd.Flags().Set("limit", "2")
+	_ = pageListCmd.Flags().Set("cursor", "start")
+	defer func() {
+		_ = pageListCmd.Flags().Set("limit", "0")
+		_ = pageListCmd.Flags().Set("cursor", "")
+	}()
+
+	if err := pageListCmd.RunE(pageListCmd, []string{}); err != nil {
+		t.Fatalf("page list failed: %v", err)
+	}
+
+	var parsed PageListOutput
+	if err := json.Unmarshal(out.Bytes(), &parsed); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if parsed.Count != 2 || parsed.Results[0].Title != "A" || parsed.Results[1].Title != "B" {
+		t.Fatalf("expected pages in entity id order, got %+v", parsed)
+	}
+	if parsed.NextCursor == "" {
+		t.Fatal("expected next_cursor")
+	}
+	if len(queries) != 2 || !strings.Contains(queries[1], "[(> ?p 0)]") || !strings.Contains(queries[1], "[(<= ?p 100)]") {
+		t.Fatalf("expected one windowed page query, got %v", queries)
+	}
+
+	_ = pageListCmd.Flags().Set("sort", "title")
+	defer func() { _ = pageListCmd.Flags().Set("sort", "") }()
+	if err := pageListCmd.RunE(pageListCmd, []string{}); err == nil {
+		t.Fatal("expected --sort to be rejected with --cursor")
+	}
+}
+
+func TestBlockGetRenderOrg(t *testing.T) {
+	fake := &fakeClient{
+		PullFunc: func(eid interface{}, selector string) (json.RawMessage, error) {
+			return json.RawMessage(`{"block/string":"{{[[TODO]]}} Root","block/uid":"r","block/children":[{"block/string":"Child","block/uid":"c"}]}`), nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+
+	out, _, restoreCtx := withTestContext(t, output.FormatText, true)
+	defer restoreCtx()
+	setCmdContext(blockGetCmd)
+
+	blockGetRender = "org"
+	defer func() { blockGetRender = "text" }()
+
+	if err := runBlockGet(blockGetCmd, []string{"r"}); err != nil {
+		t.Fatalf("block get failed: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "* TODO Root\n") || !strings.Contains(out.String(), "** Child\n") {
+		t.Fatalf("unexpected org output: %s", out.String())
+	}
+
+	out.Reset()
+	blockGetRender = "docx"
+	if err := runBlockGet(blockGetCmd, []string{"r"}); err == nil || !strings.Contains(err.Error(), "unknown render format") {
+		t.Fatalf("expected unknown format error, got %v", err)
+	}
+}
+
+func TestPageGetResolveRefs(t *testing.T) {
+	var pulled [][]interface{}
+	fake := &fakeClient{
+		GetPageByTitleFunc: func(title string) (json.RawMessage, error) {
+			return json.RawMessage(`{"node/title":"Test","block/uid":"uid","block/children":[
+				{"block/string":"see ((r1))","block/uid":"c1","block/order":0},
+				{"block/string":"{{embed: ((r2))}}","block/uid":"c2","block/order":1}]}`), nil
+		},
+		PullManyFunc: func(eids []interface{}, selector string) (json.RawMessage, error) {
+			pulled = append(pulled, eids)
+			return json.RawMessage(`[
+				{"block/string":"referenced","block/uid":"r1"},
+				{"block/string":"embedded","block/uid":"r2","block/children":[{"block/string":"inner","block/uid":"r3"}]}]`), nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(pageGetCmd)
+
+	if err := pageGetCmd.Flags().Set("resolve-refs", "inline"); err != nil {
+		t.Fatalf("set flag failed: %v", err)
+	}
+	defer func() { _ = pageGetCmd.Flags().Set("resolve-refs", "") }()
+
+	if err := pageGetCmd.RunE(pageGetCmd, []string{"Test"}); err != nil {
+		t.Fatalf("page get failed: %v", err)
+	}
+	if len(pulled) != 1 || len(pulled[0]) != 2 {
+		t.Fatalf("expected one batched pull of 2 blocks, got %v", pulled)
+	}
+
+	var page roamdb.Page
+	if err := json.Unmarshal(out.Bytes(), &page); err != nil {
+		t.Fatalf("failed to parse output: %v\n%s", err, out.String())
+	}
+	if len(page.Children) != 2 || page.Children[0].String != "see referenced" {
+		t.Fatalf("ref not inlined: %+v", page.Children)
+	}
+	embed := page.Children[1]
+	if embed.String != "embedded" || len(embed.Children) != 1 || embed.Children[0].String != "inner" {
+		t.Fatalf("embed not expanded: %+v", embed)
+	}
+
+	if err := pageGetCmd.Flags().Set("resolve-refs", "sideways"); err != nil {
+		t.Fatalf("set flag failed: %v", err)
+	}
+	if err := pageGetCmd.RunE(pageGetCmd, []string{"Test"}); err == nil || !strings.Contains(err.Error(), "inline|footnote") {
+		t.Fatalf("expected invalid style error, got %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/markup"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// PageStats are the --with-stats columns of 'page list'.
+type PageStats struct {
+	Blocks int `json:"blocks"`
+	Words  int `json:"words"`
+	Refs   int `json:"refs"`
+}
+
+// addPageFilterFlags registers the flags read by pageFilterFromFlags.
+func addPageFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().String("namespace", "", `Only pages whose title starts with "<namespace>/"`)
+	cmd.Flags().String("created-since", "", "Only pages created since a date or age (2026-01-01, 7d, 12h)")
+	cmd.Flags().String("edited-since", "", "Only pages edited since a date or age")
+	cmd.Flags().Bool("orphans", false, "Only pages no block references")
+	cmd.Flags().Bool("empty", false, "Only pages without blocks")
+	cmd.Flags().Bool("daily", false, "Only daily note pages")
+	cmd.Flags().Bool("no-daily", false, "Leave out daily note pages")
+}
+
+// pageFilterFromFlags builds the page list query filter from the flags
+// registered by addPageFilterFlags and --modified-today.
+func pageFilterFromFlags(cmd *cobra.Command, now time.Time) (roamdb.PageFilter, error) {
+	flags := cmd.Flags()
+	filter := roamdb.PageFilter{}
+	filter.ModifiedToday, _ = flags.GetBool("modified-today")
+	namespace, _ := flags.GetString("namespace")
+	filter.Namespace = strings.TrimSpace(namespace)
+	filter.Orphans, _ = flags.GetBool("orphans")
+	filter.Empty, _ = flags.GetBool("empty")
+
+	for _, bound := range []struct {
+		flag string
+		dest *int64
+	}{
+		{"created-since", &filter.CreatedSince},
+		{"edited-since", &filter.EditedSince},
+	} {
+		value, _ := flags.GetString(bound.flag)
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		ms, err := parseTimeBound(value, now)
+		if err != nil {
+			return roamdb.PageFilter{}, fmt.Errorf("invalid --%s: %w", bound.flag, err)
+		}
+		*bound.dest = ms
+	}
+
+	daily, _ := flags.GetBool("daily")
+	noDaily, _ := flags.GetBool("no-daily")
+	switch {
+	case daily && noDaily:
+		return roamdb.PageFilter{}, fmt.Errorf("--daily and --no-daily cannot be combined")
+	case daily:
+		filter.Daily = &daily
+	case noDaily:
+		only := false
+		filter.Daily = &only
+	}
+	return filter, nil
+}
+
+// showModified reports whether the page list table shows edit times.
+func showModified(filter roamdb.PageFilter) bool {
+	return filter.ModifiedToday || filter.EditedSince != 0
+}
+
+// addPageStats sets the block, word and reference counts of pages. Words
+// are counted in the blocks' plain text, so [[Page Name]] counts as two.
+func addPageStats(client api.RoamAPI, pages []PageListItem) error {
+	stats := make(map[string]*PageStats, len(pages))
+	uids := make([]string, 0, len(pages))
+	for i := range pages {
+		pages[i].Stats = &PageStats{}
+		stats[pages[i].UID] = pages[i].Stats
+		uids = append(uids, pages[i].UID)
+	}
+
+	for start := 0; start < len(uids); start += lookupChunk {
+		chunk := uids[start:min(start+lookupChunk, len(uids))]
+		rows, err := client.Query(roamdb.QueryPageBlockStrings(), chunk)
+		if err != nil {
+			return fmt.Errorf("failed to count blocks: %w", err)
+		}
+		for _, row := range rows {
+			if len(row) < 3 {
+				continue
+			}
+			if s := stats[fmt.Sprint(row[0])]; s != nil {
+				s.Blocks++
+				s.Words += len(strings.Fields(markup.PlainText(markup.Parse(fmt.Sprint(row[2])))))
+			}
+		}
+
+		rows, err = client.Query(roamdb.QueryPageRefCounts(), chunk)
+		if err != nil {
+			return fmt.Errorf("failed to count references: %w", err)
+		}
+		for _, row := range rows {
+			if len(row) < 2 {
+				continue
+			}
+			if s := stats[fmt.Sprint(row[0])]; s != nil {
+				s.Refs, _ = intFromAny(row[1])
+			}
+		}
+	}
+	return nil
+}
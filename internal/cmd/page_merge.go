@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/markup"
+	"github.com/salmonumbrella/roam-cli/internal/output"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// MergeRewrite is a block whose references are pointed at the merge target.
+type MergeRewrite struct {
+	UID    string `json:"uid"`
+	Page   string `json:"page"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// PageMergeResult summarizes a page merge.
+type PageMergeResult struct {
+	Source      string         `json:"source"`
+	SourceUID   string         `json:"source_uid"`
+	Target      string         `json:"target"`
+	TargetUID   string         `json:"target_uid"`
+	DryRun      bool           `json:"dry_run,omitempty"`
+	BlocksMoved int            `json:"blocks_moved"`
+	Rewritten   []MergeRewrite `json:"rewritten"`
+	AliasAdded  bool           `json:"alias_added,omitempty"`
+}
+
+var (
+	pageMergeAlias  bool
+	pageMergeDryRun bool
+)
+
+var pageMergeCmd = &cobra.Command{
+	Use:   "merge <source> <target>",
+	Short: "Merge one page into another",
+	Long: `Merge a page into another and delete it.
+
+The source page's blocks are moved to the end of the target page, every
+block that references the source ([[source]], #source, #[[source]],
+source:: and [label]([[source]])) is rewritten to reference the target,
+and the source page is deleted, all in one batch. With --alias the target
+also gets an "Aliases:: source" block, or source added to its existing one.
+
+Use --dry-run to list the blocks that would be rewritten without changing
+anything. Without --yes you are asked to confirm.`,
+	Example: `  roam page merge "ML" "Machine Learning" --dry-run
+  roam page merge "ML" "Machine Learning" --alias --yes
+  roam page merge "ML" "Machine Learning" --yes -o json`,
+	Args: cobra.ExactArgs(2),
+	RunE: runPageMerge,
+}
+
+func init() {
+	pageMergeCmd.Flags().BoolVar(&pageMergeAlias, "alias", false, "Add the source title to the target's Aliases:: block")
+	pageMergeCmd.Flags().BoolVar(&pageMergeDryRun, "dry-run", false, "List the changes without applying them")
+	pageCmd.AddCommand(pageMergeCmd)
+}
+
+func runPageMerge(cmd *cobra.Command, args []string) error {
+	sourceTitle, targetTitle := args[0], args[1]
+	if sourceTitle == targetTitle {
+		return fmt.Errorf("source and target are the same page")
+	}
+
+	client := GetClient()
+	source, err := getPageForMerge(client, sourceTitle)
+	if err != nil {
+		return err
+	}
+	target, err := getPageForMerge(client, targetTitle)
+	if err != nil {
+		return err
+	}
+
+	query := roamdb.QuerySearchBlocks(roamdb.PageRefClauses(roamdb.TextMatch{Term: sourceTitle, CaseSensitive: true}, false)...)
+	rows, err := client.Query(query)
+	if err != nil {
+		return fmt.Errorf("failed to find references: %w", err)
+	}
+
+	result := PageMergeResult{
+		Source:      sourceTitle,
+		SourceUID:   source.UID,
+		Target:      targetTitle,
+		TargetUID:   target.UID,
+		DryRun:      pageMergeDryRun,
+		BlocksMoved: len(source.Children),
+		Rewritten:   []MergeRewrite{},
+	}
+	batch := api.NewBatchBuilder()
+	for _, child := range source.Children {
+		batch.MoveBlock(child.UID, api.Location{ParentUID: target.UID, Order: "last"})
+	}
+	for _, row := range rows {
+		if len(row) < 3 {
+			continue
+		}
+		uid, before := fmt.Sprint(row[0]), fmt.Sprint(row[1])
+		after := markup.RenamePageRefs(before, sourceTitle, targetTitle)
+		if after == before {
+			continue
+		}
+		result.Rewritten = append(result.Rewritten, MergeRewrite{UID: uid, Page: fmt.Sprint(row[2]), Before: before, After: after})
+		batch.UpdateBlock(uid, api.BlockOptions{Content: after})
+	}
+	if pageMergeAlias {
+		result.AliasAdded = true
+		addMergeAlias(batch, target, sourceTitle)
+	}
+	batch.DeletePage(source.UID)
+
+	if !pageMergeDryRun {
+		if !output.YesFromContext(cmd.Context()) {
+			errOut := cmd.ErrOrStderr()
+			fmt.Fprintf(errOut, "Merge %q into %q, rewriting %d blocks and deleting %q?\n",
+				sourceTitle, targetTitle, len(result.Rewritten), sourceTitle)
+			fmt.Fprint(errOut, "Type 'yes' to confirm: ")
+			confirm, _ := bufio.NewReader(stdinFromContext(cmd.Context())).ReadString('\n')
+			if strings.TrimSpace(confirm) != "yes" {
+				fmt.Fprintln(errOut, "Aborted.")
+				return nil
+			}
+		}
+		if err := client.ExecuteBatch(batch); err != nil {
+			return fmt.Errorf("failed to merge pages: %w", err)
+		}
+	}
+
+	if structuredOutputRequested() {
+		return printStructured(result)
+	}
+	printPageMerge(stdoutFromContext(cmd.Context()), result)
+	return nil
+}
+
+func getPageForMerge(client api.RoamAPI, title string) (*roamdb.Page, error) {
+	raw, err := client.GetPageByTitle(title)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("page not found: %s", title)
+		}
+		return nil, fmt.Errorf("failed to get page: %w", err)
+	}
+	page, err := roamdb.ParsePage(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse page: %w", err)
+	}
+	return page, nil
+}
+
+// addMergeAlias adds alias to the target's top-level Aliases:: block, or
+// creates one at the top of the page.
+func addMergeAlias(batch *api.BatchBuilder, target *roamdb.Page, alias string) {
+	prefix := roamdb.AliasAttribute + "::"
+	for _, b := range target.Children {
+		if !strings.HasPrefix(b.String, prefix) {
+			continue
+		}
+		value := strings.TrimSpace(strings.TrimPrefix(b.String, prefix))
+		if value == "" {
+			value = alias
+		} else {
+			value += ", " + alias
+		}
+		batch.UpdateBlock(b.UID, api.BlockOptions{Content: prefix + " " + value})
+		return
+	}
+	batch.CreateBlock(api.Location{ParentUID: target.UID, Order: "first"}, api.BlockOptions{Content: prefix + " " + alias})
+}
+
+func printPageMerge(w io.Writer, result PageMergeResult) {
+	verb := "Merged"
+	if result.DryRun {
+		verb = "Dry run - would merge"
+	}
+	fmt.Fprintf(w, "%s %q into %q\n", verb, result.Source, result.Target)
+	fmt.Fprintf(w, "  Blocks moved: %d\n", result.BlocksMoved)
+	fmt.Fprintf(w, "  Blocks rewritten: %d\n", len(result.Rewritten))
+	for _, r := range result.Rewritten {
+		fmt.Fprintf(w, "    %s (%s)\n      - %s\n      + %s\n", r.UID, r.Page, previewLine(r.Before, 70), previewLine(r.After, 70))
+	}
+	if result.AliasAdded {
+		fmt.Fprintf(w, "  Alias added: %s\n", result.Source)
+	}
+}
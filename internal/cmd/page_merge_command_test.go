@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/output"
+)
+
+func mergeTestClient(actions *[]map[string]interface{}) *fakeClient {
+	return &fakeClient{
+		GetPageByTitleFunc: func(title string) (json.RawMessage, error) {
+			switch title {
+			case "ML":
+				return json.RawMessage(`{"node/title":"ML","block/uid":"src","block/children":[
+					{"block/uid":"s1","block/string":"first","block/order":0},
+					{"block/uid":"s2","block/string":"see #ML","block/order":1}]}`), nil
+			case "Machine Learning":
+				return json.RawMessage(`{"node/title":"Machine Learning","block/uid":"tgt","block/children":[
+					{"block/uid":"al","block/string":"Aliases:: AI/ML","block/order":0}]}`), nil
+			}
+			return nil, api.NotFoundError{Message: "page not found"}
+		},
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			if !strings.Contains(query, `"ML"`) {
+				return nil, nil
+			}
+			return [][]interface{}{
+				{"s2", "see #ML", "ML", "src"},
+				{"r1", "ML:: [[ML]] and [[ML/sub]]", "Reading", "p1"},
+			}, nil
+		},
+		ExecuteBatchFunc: func(b *api.BatchBuilder) error {
+			*actions = b.Build()
+			return nil
+		},
+	}
+}
+
+func TestPageMerge(t *testing.T) {
+	var actions []map[string]interface{}
+	restoreClient := withTestClient(t, mergeTestClient(&actions))
+	defer restoreClient()
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(pageMergeCmd)
+
+	pageMergeAlias = true
+	defer func() { pageMergeAlias = false }()
+
+	if err := runPageMerge(pageMergeCmd, []string{"ML", "Machine Learning"}); err != nil {
+		t.Fatalf("page merge failed: %v", err)
+	}
+	var kinds []string
+	for _, a := range actions {
+		kinds = append(kinds, a["action"].(string))
+	}
+	if strings.Join(kinds, ",") != "move-block,move-block,update-block,update-block,update-block,delete-page" {
+		t.Fatalf("unexpected actions: %v", kinds)
+	}
+	if actions[0]["location"].(map[string]interface{})["parent-uid"] != "tgt" {
+		t.Fatalf("unexpected move: %v", actions[0])
+	}
+	rewrite := actions[3]["block"].(map[string]interface{})
+	if rewrite["uid"] != "r1" || rewrite["string"] != "Machine Learning:: [[Machine Learning]] and [[ML/sub]]" {
+		t.Fatalf("unexpected rewrite: %v", rewrite)
+	}
+	alias := actions[4]["block"].(map[string]interface{})
+	if alias["uid"] != "al" || alias["string"] != "Aliases:: AI/ML, ML" {
+		t.Fatalf("unexpected alias update: %v", alias)
+	}
+	if actions[5]["page"].(map[string]interface{})["uid"] != "src" {
+		t.Fatalf("unexpected delete: %v", actions[5])
+	}
+
+	var result PageMergeResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if result.BlocksMoved != 2 || len(result.Rewritten) != 2 || result.Rewritten[0].After != "see #[[Machine Learning]]" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestPageMergeDryRun(t *testing.T) {
+	var actions []map[string]interface{}
+	restoreClient := withTestClient(t, mergeTestClient(&actions))
+	defer restoreClient()
+	out, _, restoreCtx := withTestContext(t, output.FormatText, false)
+	defer restoreCtx()
+	setCmdContext(pageMergeCmd)
+
+	pageMergeDryRun = true
+	defer func() { pageMergeDryRun = false }()
+
+	if err := runPageMerge(pageMergeCmd, []string{"ML", "Machine Learning"}); err != nil {
+		t.Fatalf("page merge failed: %v", err)
+	}
+	if actions != nil {
+		t.Fatalf("dry run wrote: %v", actions)
+	}
+	text := out.String()
+	if !strings.Contains(text, "Blocks rewritten: 2") || !strings.Contains(text, "r1 (Reading)") {
+		t.Fatalf("unexpected output:\n%s", text)
+	}
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// PageRename maps one page's old title to its new one.
+type PageRename struct {
+	UID      string `json:"uid"`
+	OldTitle string `json:"old_title"`
+	NewTitle string `json:"new_title"`
+}
+
+// PageRenameCascadeResult lists the pages a cascading rename renamed.
+type PageRenameCascadeResult struct {
+	Status  string       `json:"status"`
+	Count   int          `json:"count"`
+	Renamed []PageRename `json:"renamed"`
+}
+
+// runPageRenameCascade renames oldTitle and every page in its namespace,
+// swapping the oldTitle prefix for newTitle, in one batch.
+func runPageRenameCascade(oldTitle, newTitle string) error {
+	oldTitle = strings.TrimSuffix(oldTitle, "/")
+	newTitle = strings.TrimSuffix(newTitle, "/")
+	if oldTitle == "" || newTitle == "" {
+		return fmt.Errorf("titles must not be empty")
+	}
+	if oldTitle == newTitle {
+		return fmt.Errorf("old and new titles are the same")
+	}
+
+	client := GetClient()
+	renames, err := cascadeRenames(client, oldTitle, newTitle)
+	if err != nil {
+		return err
+	}
+	if len(renames) == 0 {
+		return fmt.Errorf("page not found: %s", oldTitle)
+	}
+
+	// A new title held by a page the cascade moves away is free once that
+	// page is renamed.
+	movingAway := make(map[string]bool, len(renames))
+	newTitles := make([]string, len(renames))
+	for i, r := range renames {
+		movingAway[r.OldTitle] = true
+		newTitles[i] = r.NewTitle
+	}
+	existing, err := existingPageUIDs(client, newTitles)
+	if err != nil {
+		return err
+	}
+	var collisions []string
+	for _, title := range newTitles {
+		if _, ok := existing[title]; ok && !movingAway[title] {
+			collisions = append(collisions, title)
+		}
+	}
+	if len(collisions) > 0 {
+		return fmt.Errorf("pages already exist with the new titles: %s", strings.Join(collisions, ", "))
+	}
+
+	batch := api.NewBatchBuilder()
+	for _, r := range renameOrder(renames) {
+		batch.UpdatePage(r.UID, api.PageOptions{Title: r.NewTitle})
+	}
+	if err := client.ExecuteBatch(batch); err != nil {
+		return fmt.Errorf("failed to rename pages: %w", err)
+	}
+
+	if structuredOutputRequested() {
+		return printStructured(PageRenameCascadeResult{Status: "renamed", Count: len(renames), Renamed: renames})
+	}
+	fmt.Printf("Renamed %d pages:\n", len(renames))
+	for _, r := range renames {
+		fmt.Printf("  %s -> %s\n", r.OldTitle, r.NewTitle)
+	}
+	return nil
+}
+
+// cascadeRenames returns the renames for oldTitle, if the page exists, and
+// for every page in its namespace, sorted by old title.
+func cascadeRenames(client api.RoamAPI, oldTitle, newTitle string) ([]PageRename, error) {
+	var renames []PageRename
+	raw, err := client.GetPageByTitle(oldTitle)
+	switch {
+	case err == nil:
+		page, err := roamdb.ParsePage(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse page: %w", err)
+		}
+		renames = append(renames, PageRename{UID: page.UID, OldTitle: oldTitle, NewTitle: newTitle})
+	case !isNotFound(err):
+		return nil, fmt.Errorf("failed to find page: %w", err)
+	}
+
+	rows, err := client.Query(roamdb.QueryNamespacePages(oldTitle))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespace pages: %w", err)
+	}
+	var children []PageRename
+	for _, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+		title := fmt.Sprint(row[0])
+		children = append(children, PageRename{
+			UID:      fmt.Sprint(row[1]),
+			OldTitle: title,
+			NewTitle: newTitle + strings.TrimPrefix(title, oldTitle),
+		})
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].OldTitle < children[j].OldTitle })
+	return append(renames, children...), nil
+}
+
+// renameOrder returns renames in the order to apply them: a page whose new
+// title is another renamed page's old title goes after that page, so the
+// title is free when it is taken. Prefix swaps cannot form cycles.
+func renameOrder(renames []PageRename) []PageRename {
+	byOld := make(map[string]int, len(renames))
+	for i, r := range renames {
+		byOld[r.OldTitle] = i
+	}
+	depth := make([]int, len(renames))
+	for i, r := range renames {
+		for next, ok := byOld[r.NewTitle]; ok; next, ok = byOld[renames[next].NewTitle] {
+			depth[i]++
+		}
+	}
+	order := make([]int, len(renames))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return depth[order[a]] < depth[order[b]] })
+	ordered := make([]PageRename, len(renames))
+	for i, idx := range order {
+		ordered[i] = renames[idx]
+	}
+	return ordered
+}
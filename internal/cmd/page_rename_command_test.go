@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/output"
+)
+
+func cascadeTestClient(existing []string, actions *[]map[string]interface{}) *fakeClient {
+	return &fakeClient{
+		GetPageByTitleFunc: func(title string) (json.RawMessage, error) {
+			if title == "Project/Alpha" {
+				return json.RawMessage(`{"node/title":"Project/Alpha","block/uid":"pa"}`), nil
+			}
+			return nil, api.NotFoundError{Message: "page not found"}
+		},
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			if strings.Contains(query, "starts-with") {
+				return [][]interface{}{{"Project/Alpha/Tasks", "pt"}, {"Project/Alpha/Notes", "pn"}}, nil
+			}
+			var rows [][]interface{}
+			for _, title := range args[0].([]string) {
+				for _, e := range existing {
+					if title == e {
+						rows = append(rows, []interface{}{title, "x"})
+					}
+				}
+			}
+			return rows, nil
+		},
+		ExecuteBatchFunc: func(b *api.BatchBuilder) error {
+			*actions = b.Build()
+			return nil
+		},
+	}
+}
+
+func TestPageRenameCascade(t *testing.T) {
+	var actions []map[string]interface{}
+	restoreClient := withTestClient(t, cascadeTestClient(nil, &actions))
+	defer restoreClient()
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+
+	if err := runPageRenameCascade("Project/Alpha/", "Project/Beta"); err != nil {
+		t.Fatalf("cascade rename failed: %v", err)
+	}
+	if len(actions) != 3 {
+		t.Fatalf("expected 3 renames, got %v", actions)
+	}
+	for _, a := range actions {
+		if a["action"] != "update-page" {
+			t.Fatalf("unexpected action: %v", a)
+		}
+	}
+
+	var result PageRenameCascadeResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	want := []PageRename{
+		{UID: "pa", OldTitle: "Project/Alpha", NewTitle: "Project/Beta"},
+		{UID: "pn", OldTitle: "Project/Alpha/Notes", NewTitle: "Project/Beta/Notes"},
+		{UID: "pt", OldTitle: "Project/Alpha/Tasks", NewTitle: "Project/Beta/Tasks"},
+	}
+	if result.Count != 3 || len(result.Renamed) != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	for i, r := range result.Renamed {
+		if r != want[i] {
+			t.Fatalf("rename %d: got %+v, want %+v", i, r, want[i])
+		}
+	}
+}
+
+func TestPageRenameCascadeCollision(t *testing.T) {
+	var actions []map[string]interface{}
+	restoreClient := withTestClient(t, cascadeTestClient([]string{"Project/Beta/Notes"}, &actions))
+	defer restoreClient()
+	_, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+
+	err := runPageRenameCascade("Project/Alpha", "Project/Beta")
+	if err == nil || !strings.Contains(err.Error(), "Project/Beta/Notes") {
+		t.Fatalf("expected a collision error, got %v", err)
+	}
+	if actions != nil {
+		t.Fatalf("renamed despite a collision: %v", actions)
+	}
+}
+
+func TestPageRenameCascadeIntoOwnNamespace(t *testing.T) {
+	var actions []map[string]interface{}
+	// Project/Alpha/Tasks exists, but the cascade renames it away first.
+	restoreClient := withTestClient(t, cascadeTestClient([]string{"Project/Alpha/Tasks"}, &actions))
+	defer restoreClient()
+	_, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+
+	if err := runPageRenameCascade("Project/Alpha", "Project/Alpha/Tasks"); err != nil {
+		t.Fatalf("cascade rename failed: %v", err)
+	}
+	order := map[string]int{}
+	for i, a := range actions {
+		page := a["page"].(map[string]interface{})
+		order[page["uid"].(string)] = i
+		if page["uid"] == "pt" && page["title"] != "Project/Alpha/Tasks/Tasks" {
+			t.Fatalf("unexpected rename of Tasks: %v", page)
+		}
+	}
+	if len(actions) != 3 || order["pt"] > order["pa"] {
+		t.Fatalf("expected Tasks renamed before Alpha takes its title, got %v", actions)
+	}
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// NamespaceNode is one segment of a namespace path such as "A/B/C".
+type NamespaceNode struct {
+	Name  string `json:"name"`
+	Title string `json:"title"`
+	// UID is empty when no page has this title, only pages below it.
+	UID string `json:"uid,omitempty"`
+	// Pages counts the pages at and below this node.
+	Pages    int              `json:"pages"`
+	Children []*NamespaceNode `json:"children,omitempty"`
+}
+
+var pageTreeCmd = &cobra.Command{
+	Use:   "tree [prefix]",
+	Short: "Show the namespace hierarchy of page titles",
+	Long: `Show page titles of the form A/B/C as a tree, with the number of pages
+at and below each namespace.
+
+With a prefix only that namespace is shown. Without one every top-level
+namespace is shown; pages outside any namespace are left out. Namespaces
+that have no page of their own are still listed so their children are
+reachable; in structured output they have no uid.`,
+	Example: `  roam page tree
+  roam page tree Project
+  roam page tree "Project/Alpha" -o json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPageTree,
+}
+
+func init() {
+	pageCmd.AddCommand(pageTreeCmd)
+}
+
+func runPageTree(cmd *cobra.Command, args []string) error {
+	prefix := ""
+	if len(args) > 0 {
+		prefix = strings.TrimSuffix(strings.TrimSpace(args[0]), "/")
+	}
+
+	client := GetClient()
+	titles, err := namespaceTitles(client, prefix)
+	if err != nil {
+		return err
+	}
+
+	root := &NamespaceNode{Name: prefix, Title: prefix}
+	for title, uid := range titles {
+		if title == prefix {
+			root.UID = uid
+			continue
+		}
+		addNamespacePage(root, strings.TrimPrefix(title, prefix+"/"), uid)
+	}
+	countNamespacePages(root)
+
+	var nodes []*NamespaceNode
+	if prefix != "" {
+		if root.Pages == 0 {
+			return fmt.Errorf("no pages in namespace: %s", prefix)
+		}
+		nodes = append(nodes, root)
+	} else {
+		for _, child := range root.Children {
+			if len(child.Children) > 0 {
+				nodes = append(nodes, child)
+			}
+		}
+	}
+
+	if structuredOutputRequested() {
+		if nodes == nil {
+			nodes = []*NamespaceNode{}
+		}
+		return printStructured(nodes)
+	}
+	out := stdoutFromContext(cmd.Context())
+	if len(nodes) == 0 {
+		fmt.Fprintln(out, "No namespaces found.")
+		return nil
+	}
+	for _, node := range nodes {
+		printNamespaceNode(out, node, 0)
+	}
+	return nil
+}
+
+// namespaceTitles returns the uids of the pages in the prefix namespace,
+// including the prefix page itself, or of every page without a prefix.
+func namespaceTitles(client api.RoamAPI, prefix string) (map[string]string, error) {
+	rows, err := client.Query(roamdb.QueryListPages(roamdb.PageFilter{Namespace: prefix}, time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pages: %w", err)
+	}
+	titles := make(map[string]string, len(rows))
+	for _, row := range rows {
+		page := pageListItemFromRow(row)
+		if page.Title != "" {
+			titles[page.Title] = page.UID
+		}
+	}
+	if prefix != "" {
+		existing, err := existingPageUIDs(client, []string{prefix})
+		if err != nil {
+			return nil, err
+		}
+		for title, uid := range existing {
+			titles[title] = uid
+		}
+	}
+	return titles, nil
+}
+
+// addNamespacePage adds the nodes on the path rel, relative to node, and
+// sets the uid of the last one.
+func addNamespacePage(node *NamespaceNode, rel, uid string) {
+	for _, name := range strings.Split(rel, "/") {
+		var next *NamespaceNode
+		for _, child := range node.Children {
+			if child.Name == name {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			path := name
+			if node.Title != "" {
+				path = node.Title + "/" + name
+			}
+			next = &NamespaceNode{Name: name, Title: path}
+			node.Children = append(node.Children, next)
+		}
+		node = next
+	}
+	node.UID = uid
+}
+
+// countNamespacePages sets Pages throughout the tree and sorts children by
+// name. It returns the count for node.
+func countNamespacePages(node *NamespaceNode) int {
+	node.Pages = 0
+	if node.UID != "" {
+		node.Pages = 1
+	}
+	sort.Slice(node.Children, func(i, j int) bool {
+		return strings.ToLower(node.Children[i].Name) < strings.ToLower(node.Children[j].Name)
+	})
+	for _, child := range node.Children {
+		node.Pages += countNamespacePages(child)
+	}
+	return node.Pages
+}
+
+func printNamespaceNode(w io.Writer, node *NamespaceNode, depth int) {
+	line := strings.Repeat("  ", depth) + node.Name
+	if len(node.Children) > 0 {
+		line += fmt.Sprintf(" (%d)", node.Pages)
+	}
+	fmt.Fprintln(w, line)
+	for _, child := range node.Children {
+		printNamespaceNode(w, child, depth+1)
+	}
+}
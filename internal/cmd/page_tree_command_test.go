@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/roam-cli/internal/output"
+)
+
+func TestPageTree(t *testing.T) {
+	client := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			if len(args) > 0 {
+				return nil, nil
+			}
+			return [][]interface{}{
+				{"Project", "p"},
+				{"Project/Beta", "pb"},
+				{"Project/Alpha/Tasks", "pat"},
+				{"Project/Alpha/Notes", "pan"},
+				{"Area/Home", "ah"},
+				{"Inbox", "in"},
+			}, nil
+		},
+	}
+	restoreClient := withTestClient(t, client)
+	defer restoreClient()
+	out, _, restoreCtx := withTestContext(t, output.FormatText, true)
+	defer restoreCtx()
+	setCmdContext(pageTreeCmd)
+
+	if err := runPageTree(pageTreeCmd, nil); err != nil {
+		t.Fatalf("page tree failed: %v", err)
+	}
+	want := "Area (1)\n  Home\nProject (4)\n  Alpha (2)\n    Notes\n    Tasks\n  Beta\n"
+	if out.String() != want {
+		t.Fatalf("unexpected tree:\n%s", out.String())
+	}
+}
+
+func TestPageTreePrefix(t *testing.T) {
+	var query string
+	client := &fakeClient{
+		QueryFunc: func(q string, args ...interface{}) ([][]interface{}, error) {
+			if len(args) > 0 {
+				return nil, nil // Project/Alpha has no page of its own
+			}
+			query = q
+			return [][]interface{}{{"Project/Alpha/Tasks", "pat"}, {"Project/Alpha/Notes", "pan"}}, nil
+		},
+	}
+	restoreClient := withTestClient(t, client)
+	defer restoreClient()
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(pageTreeCmd)
+
+	if err := runPageTree(pageTreeCmd, []string{"Project/Alpha/"}); err != nil {
+		t.Fatalf("page tree failed: %v", err)
+	}
+	if !strings.Contains(query, `"Project/Alpha/"`) {
+		t.Fatalf("expected a namespace filter: %s", query)
+	}
+	var nodes []NamespaceNode
+	if err := json.Unmarshal(out.Bytes(), &nodes); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if len(nodes) != 1 || nodes[0].Title != "Project/Alpha" || nodes[0].UID != "" || nodes[0].Pages != 2 {
+		t.Fatalf("unexpected root: %+v", nodes)
+	}
+	if len(nodes[0].Children) != 2 || nodes[0].Children[0].Title != "Project/Alpha/Notes" || nodes[0].Children[0].UID != "pan" {
+		t.Fatalf("unexpected children: %+v", nodes[0].Children)
+	}
+}
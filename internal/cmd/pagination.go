@@ -4,22 +4,30 @@ package cmd
 // total count, and the effective page number.
 func paginateResults(results [][]interface{}, page, limit int) ([][]interface{}, int, int) {
 	total := len(results)
+	start, end, page := pageBounds(total, page, limit)
+	if start >= total && limit > 0 {
+		return [][]interface{}{}, total, page
+	}
+	return results[start:end], total, page
+}
+
+// pageBounds returns the [start, end) slice bounds of page within total items,
+// and the effective page number. A limit <= 0 selects everything.
+func pageBounds(total, page, limit int) (int, int, int) {
 	if page < 1 {
 		page = 1
 	}
 	if limit <= 0 {
-		return results, total, page
+		return 0, total, page
 	}
 
 	start := (page - 1) * limit
 	end := start + limit
-
 	if start >= total {
-		return [][]interface{}{}, total, page
+		return total, total, page
 	}
 	if end > total {
 		end = total
 	}
-
-	return results[start:end], total, page
+	return start, end, page
 }
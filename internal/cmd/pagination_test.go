@@ -1,6 +1,12 @@
 package cmd
 
-import "testing"
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
 
 func TestPaginateResults(t *testing.T) {
 	results := [][]interface{}{{1}, {2}, {3}, {4}, {5}}
@@ -20,3 +26,97 @@ func TestPaginateResults(t *testing.T) {
 		t.Fatalf("unexpected page4 results: total=%d page=%d len=%d", total, pageUsed, len(page4))
 	}
 }
+
+func TestCursorRoundTrip(t *testing.T) {
+	token := encodeCursor(pageCursor{Kind: "search", After: 42, Hash: "abc"})
+
+	c, err := decodeCursor(token, "search", "abc")
+	if err != nil || c.After != 42 {
+		t.Fatalf("unexpected cursor %+v, err %v", c, err)
+	}
+	if _, err := decodeCursor(token, "search", "other"); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("expected mismatch error, got %v", err)
+	}
+	if _, err := decodeCursor(token, "pages", "abc"); err == nil {
+		t.Fatal("expected error for cursor of another kind")
+	}
+	if _, err := decodeCursor("%%%", "search", "abc"); err == nil {
+		t.Fatal("expected error for malformed cursor")
+	}
+	if c, err := decodeCursor(cursorStart, "search", "abc"); err != nil || c.After != 0 {
+		t.Fatalf("expected zero cursor for start, got %+v %v", c, err)
+	}
+}
+
+func TestEIDScan(t *testing.T) {
+	eids := []int64{3, 12, 13, 27, 41}
+	var windows []roamdb.EIDRange
+	scan := &eidScan{
+		eidCol: 0,
+		max:    45,
+		window: 10,
+		fetch: func(r roamdb.EIDRange) ([][]interface{}, error) {
+			windows = append(windows, r)
+			var rows [][]interface{}
+			for i := len(eids) - 1; i >= 0; i-- {
+				if eids[i] > r.After && eids[i] <= r.Upto {
+					rows = append(rows, []interface{}{float64(eids[i])})
+				}
+			}
+			return rows, nil
+		},
+	}
+
+	var got []int64
+	collect := func(rows [][]interface{}) error {
+		for _, row := range rows {
+			got = append(got, rowEID(row, 0))
+		}
+		return nil
+	}
+
+	after, more, err := scan.run(0, 2, collect)
+	if err != nil || !more || after != 12 {
+		t.Fatalf("first page: after=%d more=%v err=%v", after, more, err)
+	}
+	after, more, err = scan.run(after, 2, collect)
+	// The page filled up at a window boundary, so it resumes after the window.
+	if err != nil || !more || after < 27 || after >= 41 {
+		t.Fatalf("second page: after=%d more=%v err=%v", after, more, err)
+	}
+	if last := windows[len(windows)-1]; last.Upto != after {
+		t.Fatalf("kept scanning past the full page: %+v", last)
+	}
+	after, more, err = scan.run(after, 2, collect)
+	if err != nil || more || after != 45 {
+		t.Fatalf("last page: after=%d more=%v err=%v", after, more, err)
+	}
+
+	if fmt.Sprint(got) != "[3 12 13 27 41]" {
+		t.Fatalf("unexpected rows %v", got)
+	}
+	for _, w := range windows {
+		if w.After >= w.Upto || w.Upto > 45 {
+			t.Fatalf("bad window %+v", w)
+		}
+	}
+}
+
+func TestOffsetPage(t *testing.T) {
+	start, end, next, err := offsetPage("", false, 2, 5, "query", "h")
+	if err != nil || start != 0 || end != 2 || next == "" {
+		t.Fatalf("first page: %d %d %q %v", start, end, next, err)
+	}
+	start, end, next, err = offsetPage(next, false, 2, 5, "query", "h")
+	if err != nil || start != 2 || end != 4 || next == "" {
+		t.Fatalf("second page: %d %d %q %v", start, end, next, err)
+	}
+	start, end, next, err = offsetPage(next, false, 2, 5, "query", "h")
+	if err != nil || start != 4 || end != 5 || next != "" {
+		t.Fatalf("last page: %d %d %q %v", start, end, next, err)
+	}
+	start, end, next, err = offsetPage("", true, 2, 5, "query", "h")
+	if err != nil || start != 0 || end != 5 || next != "" {
+		t.Fatalf("all: %d %d %q %v", start, end, next, err)
+	}
+}
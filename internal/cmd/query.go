@@ -3,15 +3,30 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/edn"
 )
 
-var pullPattern string
+var (
+	pullPattern string
+	queryLimit  int
+	queryCursor string
+	queryAll    bool
+)
+
+// QueryOutput is the structured output of 'query' with --limit, --cursor or
+// --all.
+type QueryOutput struct {
+	Count      int             `json:"count"`
+	Results    [][]interface{} `json:"results"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
 
 var queryCmd = &cobra.Command{
 	Use:   "query <datalog>",
@@ -57,7 +72,23 @@ Examples:
 
 Output:
   Results are returned as a list of tuples matching the :find clause.
-  Use --output json for machine-readable output.`,
+  Use --output json for machine-readable output, or --output edn for EDN.
+
+Validation:
+  The query is checked before it is sent: EDN syntax, a leading :find,
+  known clauses (:find :in :with :where) and :find variables that :in or
+  :where bind. Errors show the line and column of the problem.
+
+Pagination:
+  --limit N returns N rows at a time, in a stable order, with a next_cursor
+  to pass to --cursor for the following rows; structured output is then
+  {"count", "results", "next_cursor"}. --all returns every row, one per line
+  as they are written with --output ndjson. The query itself runs in full
+  each time; add your own bounds (for example on :edit/time) to page huge
+  result sets server-side.
+
+  roam query '[:find ?title :where [?e :node/title ?title]]' --limit 100 -o json
+  roam query '[:find ?title :where [?e :node/title ?title]]' --limit 100 --cursor <next_cursor>`,
 	Args: cobra.ExactArgs(1),
 	RunE: runQuery,
 }
@@ -96,7 +127,12 @@ Examples:
   roam pull 12345 --pattern '[:block/string :block/uid]'
 
   # Pull with children recursively
-  roam pull '[:node/title "My Page"]' --pattern '[* {:block/children ...}]'`,
+  roam pull '[:node/title "My Page"]' --pattern '[* {:block/children ...}]'
+
+  # Pull as EDN
+  roam pull '[:block/uid "abc"]' --output edn
+
+The pattern is validated before it is sent, with the location of any error.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runPull,
 }
@@ -132,6 +168,9 @@ func init() {
 	rootCmd.AddCommand(pullCmd)
 	rootCmd.AddCommand(pullManyCmd)
 
+	queryCmd.Flags().IntVar(&queryLimit, "limit", 0, "Maximum number of rows per page (0 = all)")
+	addCursorFlags(queryCmd, &queryCursor, &queryAll)
+
 	pullCmd.Flags().StringVarP(&pullPattern, "pattern", "p", "[*]", "Pull pattern (Datomic pull syntax)")
 	pullManyCmd.Flags().StringVarP(&pullPattern, "pattern", "p", "[*]", "Pull pattern (Datomic pull syntax)")
 }
@@ -143,12 +182,19 @@ func runQuery(cmd *cobra.Command, args []string) error {
 	}
 
 	query := args[0]
+	if err := edn.ValidateQuery(query); err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
 
 	results, err := client.Query(query)
 	if err != nil {
 		return fmt.Errorf("query failed: %w", err)
 	}
 
+	if queryLimit > 0 || queryCursor != "" || queryAll {
+		return outputQueryPage(cmd, args, results)
+	}
+
 	if structuredOutputRequested() {
 		return printStructured(results)
 	}
@@ -174,6 +220,71 @@ func runQuery(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// outputQueryPage prints one --limit page of rows, paged by offset over the
+// rows in a stable order.
+func outputQueryPage(cmd *cobra.Command, args []string, results [][]interface{}) error {
+	sortRowsStable(results)
+	start, end, next, err := offsetPage(queryCursor, queryAll, queryLimit, len(results), "query", cursorFingerprint(cmd, args))
+	if err != nil {
+		return err
+	}
+	rows := results[start:end]
+
+	if structuredOutputRequested() {
+		if !streamingRequested() {
+			return printStructured(QueryOutput{Count: len(rows), Results: rows, NextCursor: next})
+		}
+		stream, err := newOutputStream()
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := stream.Write(row); err != nil {
+				return err
+			}
+		}
+		return writeNextCursor(stream, next)
+	}
+
+	if len(rows) == 0 {
+		fmt.Println("No results found")
+		return nil
+	}
+	fmt.Printf("Showing results %d-%d of %d:\n\n", start+1, end, len(results))
+	for i, row := range rows {
+		fmt.Printf("[%d] ", start+i+1)
+		for j, col := range row {
+			if j > 0 {
+				fmt.Print(" | ")
+			}
+			fmt.Printf("%v", col)
+		}
+		fmt.Println()
+	}
+	if next != "" {
+		fmt.Printf("\nMore results: --cursor %s\n", next)
+	}
+	return nil
+}
+
+// sortRowsStable orders query rows by their JSON encoding, so repeated runs
+// of a query page through the same sequence.
+func sortRowsStable(rows [][]interface{}) {
+	type keyed struct {
+		key string
+		row []interface{}
+	}
+	items := make([]keyed, len(rows))
+	for i, row := range rows {
+		raw, _ := json.Marshal(row)
+		items[i] = keyed{key: string(raw), row: row}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].key < items[j].key })
+	for i, item := range items {
+		rows[i] = item.row
+	}
+}
+
 func runPull(cmd *cobra.Command, args []string) error {
 	client := GetClient()
 	if client == nil {
@@ -184,6 +295,9 @@ func runPull(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("invalid entity ID: %w", err)
 	}
+	if err := edn.ValidatePull(pullPattern); err != nil {
+		return fmt.Errorf("invalid --pattern: %w", err)
+	}
 
 	result, err := client.Pull(eid, pullPattern)
 	if err != nil {
@@ -223,18 +337,11 @@ func runPullMany(cmd *cobra.Command, args []string) error {
 		}
 		eids[i] = eid
 	}
-
-	var result json.RawMessage
-	var err error
-
-	// Check if using LocalClient - it doesn't support pull-many natively,
-	// so we fall back to multiple individual pull calls
-	if localClient, ok := client.(*api.LocalClient); ok {
-		result, err = pullManyFallback(localClient, eids, pullPattern)
-	} else {
-		result, err = client.PullMany(eids, pullPattern)
+	if err := edn.ValidatePull(pullPattern); err != nil {
+		return fmt.Errorf("invalid --pattern: %w", err)
 	}
 
+	result, err := pullMany(client, eids, pullPattern)
 	if err != nil {
 		return fmt.Errorf("pull-many failed: %w", err)
 	}
@@ -258,6 +365,15 @@ func runPullMany(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// pullMany pulls eids with selector. LocalClient doesn't support pull-many
+// natively, so it falls back to individual pull calls.
+func pullMany(client api.RoamAPI, eids []interface{}, selector string) (json.RawMessage, error) {
+	if localClient, ok := client.(*api.LocalClient); ok {
+		return pullManyFallback(localClient, eids, selector)
+	}
+	return client.PullMany(eids, selector)
+}
+
 // pullManyFallback implements pull-many for LocalClient by calling Pull
 // individually for each entity and combining the results into an array.
 func pullManyFallback(client *api.LocalClient, eids []interface{}, selector string) (json.RawMessage, error) {
@@ -282,8 +398,11 @@ func pullManyFallback(client *api.LocalClient, eids []interface{}, selector stri
 
 // parseEntityID parses an entity ID from string input.
 // Supports:
-//   - Numeric IDs: "12345" -> int
+//   - Numeric IDs: "12345" -> int64
 //   - Lookup refs: "[:block/uid \"abc\"]" -> []interface{}{":block/uid", "abc"}
+//
+// Lookup ref values may be strings, numbers or (for compatibility with
+// unquoted input) bare words.
 func parseEntityID(input string) (interface{}, error) {
 	input = strings.TrimSpace(input)
 
@@ -292,32 +411,29 @@ func parseEntityID(input string) (interface{}, error) {
 		return id, nil
 	}
 
-	// Check if it looks like a lookup ref
-	if strings.HasPrefix(input, "[") && strings.HasSuffix(input, "]") {
-		// Parse as lookup ref: [:keyword "value"]
-		inner := strings.TrimPrefix(strings.TrimSuffix(input, "]"), "[")
-		inner = strings.TrimSpace(inner)
-
-		// Find the keyword (starts with :)
-		parts := strings.SplitN(inner, " ", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid lookup ref format: expected [:keyword \"value\"]")
-		}
-
-		keyword := strings.TrimSpace(parts[0])
-		if !strings.HasPrefix(keyword, ":") {
-			return nil, fmt.Errorf("lookup ref keyword must start with :")
-		}
-
-		// Extract the value (remove quotes if present)
-		value := strings.TrimSpace(parts[1])
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.TrimPrefix(strings.TrimSuffix(value, "\""), "\"")
-		}
+	if !strings.HasPrefix(input, "[") {
+		return nil, fmt.Errorf("entity ID must be a number or lookup ref like [:block/uid \"abc\"]")
+	}
 
-		// Return as a two-element array (Datomic lookup ref format)
-		return []interface{}{keyword, value}, nil
+	value, err := edn.Parse(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lookup ref: %w", err)
+	}
+	ref, ok := value.(edn.Vector)
+	if !ok || len(ref) != 2 {
+		return nil, fmt.Errorf("invalid lookup ref format: expected [:keyword \"value\"]")
+	}
+	keyword, ok := ref[0].(edn.Keyword)
+	if !ok {
+		return nil, fmt.Errorf("lookup ref keyword must start with :")
 	}
 
-	return nil, fmt.Errorf("entity ID must be a number or lookup ref like [:block/uid \"abc\"]")
+	// Return as a two-element array (Datomic lookup ref format)
+	switch v := ref[1].(type) {
+	case string, int64:
+		return []interface{}{":" + string(keyword), v}, nil
+	case edn.Symbol:
+		return []interface{}{":" + string(keyword), string(v)}, nil
+	}
+	return nil, fmt.Errorf("lookup ref value must be a string or number, got %s", edn.Marshal(ref[1]))
 }
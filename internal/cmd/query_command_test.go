@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/salmonumbrella/roam-cli/internal/output"
@@ -62,3 +63,91 @@ func TestRunQueryPullStructured(t *testing.T) {
 		t.Fatal("expected output")
 	}
 }
+
+func TestRunQueryLimitCursor(t *testing.T) {
+	fake := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			return [][]interface{}{{"c"}, {"a"}, {"b"}}, nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(queryCmd)
+
+	queryLimit = 2
+	defer func() {
+		queryLimit = 0
+		queryCursor = ""
+	}()
+
+	query := `[:find ?t :where [?e :node/title ?t]]`
+	if err := runQuery(queryCmd, []string{query}); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	var first QueryOutput
+	if err := json.Unmarshal(out.Bytes(), &first); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if first.Count != 2 || first.Results[0][0] != "a" || first.Results[1][0] != "b" || first.NextCursor == "" {
+		t.Fatalf("unexpected first page: %+v", first)
+	}
+
+	out.Reset()
+	queryCursor = first.NextCursor
+	if err := runQuery(queryCmd, []string{query}); err != nil {
+		t.Fatalf("cursor query failed: %v", err)
+	}
+	var second QueryOutput
+	if err := json.Unmarshal(out.Bytes(), &second); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if second.Count != 1 || second.Results[0][0] != "c" || second.NextCursor != "" {
+		t.Fatalf("unexpected second page: %+v", second)
+	}
+}
+
+func TestParseEntityIDLookupValues(t *testing.T) {
+	lookup, err := parseEntityID(`[:block/uid abc]`)
+	if err != nil || lookup.([]interface{})[1] != "abc" {
+		t.Fatalf("unexpected bare lookup: %#v %v", lookup, err)
+	}
+	lookup, err = parseEntityID(`[:node/title "Say \"hi\""]`)
+	if err != nil || lookup.([]interface{})[1] != `Say "hi"` {
+		t.Fatalf("unexpected escaped lookup: %#v %v", lookup, err)
+	}
+	if _, err := parseEntityID(`[:block/uid "abc"`); err == nil || !strings.Contains(err.Error(), "column 1") {
+		t.Fatalf("expected located error, got %v", err)
+	}
+}
+
+func TestQueryAndPullValidateBeforeSending(t *testing.T) {
+	fake := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			t.Fatal("query should not be sent")
+			return nil, nil
+		},
+		PullFunc: func(eid interface{}, selector string) (json.RawMessage, error) {
+			t.Fatal("pull should not be sent")
+			return nil, nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+	_, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+
+	err := runQuery(queryCmd, []string{`[:find ?t :where [?e :node/title ?title]]`})
+	if err == nil || !strings.Contains(err.Error(), "?t in :find is not bound") {
+		t.Fatalf("expected unbound variable error, got %v", err)
+	}
+
+	pullPattern = `[:block/string {:block/children}]`
+	defer func() { pullPattern = "[*]" }()
+	err = runPull(pullCmd, []string{"123"})
+	if err == nil || !strings.Contains(err.Error(), "invalid --pattern") || !strings.Contains(err.Error(), "column 16") {
+		t.Fatalf("expected pattern error, got %v", err)
+	}
+}
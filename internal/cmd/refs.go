@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/render"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// refPullBatch is how many referenced blocks one pull-many request fetches.
+const refPullBatch = 100
+
+const resolveRefsHelp = `Block references:
+  --resolve-refs replaces ((uid)) with the referenced block's text and expands
+  {{embed: ((uid))}} into the embedded block and its children. Referenced
+  blocks are pulled in batches. Refs inside referenced text are resolved too,
+  up to --ref-depth levels; refs that would loop back to a block already being
+  expanded, and refs to missing blocks, are left as written.
+  --resolve-refs=footnote writes [^n] markers instead, with the referenced
+  text collected as footnotes at the end.`
+
+// addResolveRefsFlags adds --resolve-refs and --ref-depth to cmd.
+func addResolveRefsFlags(cmd *cobra.Command) {
+	cmd.Flags().String("resolve-refs", "", "Resolve block refs and embeds: inline or footnote (bare flag = inline)")
+	cmd.Flags().Lookup("resolve-refs").NoOptDefVal = render.RefsInline
+	cmd.Flags().Int("ref-depth", render.DefaultRefDepth, "Levels of refs within refs and embeds within embeds to resolve")
+}
+
+// refResolverFromFlags returns the resolver --resolve-refs asks for, or nil
+// when references should be left as written.
+func refResolverFromFlags(cmd *cobra.Command, client api.RoamAPI) (*render.Resolver, error) {
+	style, _ := cmd.Flags().GetString("resolve-refs")
+	style = strings.ToLower(strings.TrimSpace(style))
+	if style == "" {
+		return nil, nil
+	}
+	if style != render.RefsInline && style != render.RefsFootnote {
+		return nil, fmt.Errorf("invalid --resolve-refs %q (expected inline|footnote)", style)
+	}
+	depth, _ := cmd.Flags().GetInt("ref-depth")
+	if depth < 1 {
+		return nil, fmt.Errorf("--ref-depth must be at least 1")
+	}
+	return &render.Resolver{Fetch: pullBlocksByUID(client, depth), Depth: depth, Style: style}, nil
+}
+
+// pullBlocksByUID returns a fetcher that pulls blocks, with depth levels of
+// children for embeds, refPullBatch at a time.
+func pullBlocksByUID(client api.RoamAPI, depth int) func([]string) (map[string]roamdb.Block, error) {
+	selector := buildBlockSelector(depth)
+	return func(uids []string) (map[string]roamdb.Block, error) {
+		blocks := make(map[string]roamdb.Block, len(uids))
+		for start := 0; start < len(uids); start += refPullBatch {
+			batch := uids[start:min(start+refPullBatch, len(uids))]
+			eids := make([]interface{}, len(batch))
+			for i, uid := range batch {
+				eids[i] = []interface{}{":block/uid", uid}
+			}
+
+			raw, err := pullMany(client, eids, selector)
+			if err != nil {
+				return nil, err
+			}
+
+			var items []json.RawMessage
+			if err := json.Unmarshal(raw, &items); err != nil {
+				return nil, fmt.Errorf("failed to parse pull-many response: %w", err)
+			}
+			for _, item := range items {
+				if len(item) == 0 || string(item) == "null" {
+					continue
+				}
+				block, err := roamdb.ParseBlock(item)
+				if err != nil || block.UID == "" {
+					continue
+				}
+				blocks[block.UID] = *block
+			}
+		}
+		return blocks, nil
+	}
+}
+
+// resolvedPage is a page with references resolved, as structured output.
+// Footnotes are only present in footnote style.
+type resolvedPage struct {
+	*roamdb.Page
+	Footnotes []render.Footnote `json:"footnotes,omitempty"`
+}
+
+// resolvedBlock is the block counterpart of resolvedPage.
+type resolvedBlock struct {
+	*roamdb.Block
+	Footnotes []render.Footnote `json:"footnotes,omitempty"`
+}
+
+// printFootnotes writes footnotes after text output.
+func printFootnotes(footnotes []render.Footnote) {
+	if len(footnotes) == 0 {
+		return
+	}
+	fmt.Println()
+	for _, note := range footnotes {
+		fmt.Printf("%s %s\n", note.Marker(), note.Text)
+	}
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/salmonumbrella/roam-cli/internal/render"
+)
+
+// renderFlagUsage is the --render help shared by page, block and daily get.
+var renderFlagUsage = "Render format: text, raw, " + strings.Join(render.Formats(), ", ")
+
+const renderFormatsHelp = `Render formats:
+  text      Summary (page get) or indented outline (default)
+  raw       The pull response as indented JSON
+  markdown  Nested lists with headings, task checkboxes, numbered lists and
+            fenced code blocks
+  logseq    Logseq-flavoured Markdown (tab indentation, TODO/DONE markers)
+  org       Org outline with TODO keywords, IDs and SRC blocks
+  html      Standalone HTML document
+  opml      OPML 2.0 outline
+  json      Normalized JSON tree (uid, string, plain text, refs, children)`
+
+// renderDocument writes doc in a render format other than text. raw is the
+// pull response for --render raw.
+func renderDocument(out io.Writer, format string, raw json.RawMessage, doc render.Document) error {
+	if strings.EqualFold(strings.TrimSpace(format), "raw") {
+		var pretty interface{}
+		if err := json.Unmarshal(raw, &pretty); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(pretty)
+	}
+	r, err := render.Lookup(format)
+	if err != nil {
+		return err
+	}
+	return r.Render(out, doc)
+}
+
+// textRenderRequested reports whether --render asks for the command's own
+// text output.
+func textRenderRequested(format string) bool {
+	format = strings.ToLower(strings.TrimSpace(format))
+	return format == "" || format == "text"
+}
@@ -133,6 +133,10 @@ Environment Variables:
 			cmd.Parent() != nil && cmd.Parent().Name() == "config" {
 			return nil
 		}
+		// Skip client initialization when only printing search syntax or plans.
+		if flagChanged(cmd, "help-syntax") || flagChanged(cmd, "explain") {
+			return nil
+		}
 		// Skip client initialization for local subcommands (they use Local API without token)
 		if cmd.Name() == "local" || (cmd.Parent() != nil && cmd.Parent().Name() == "local") {
 			return nil
@@ -214,7 +218,7 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVarP(&graphName, "graph", "g", "", "Graph name (env: ROAM_GRAPH_NAME)")
 	rootCmd.PersistentFlags().StringVar(&apiToken, "token", "", "API token (env: ROAM_API_TOKEN)")
-	rootCmd.PersistentFlags().StringVarP(&outputFmt, "output", "o", "text", "Output format (text|json|ndjson|table|yaml)")
+	rootCmd.PersistentFlags().StringVarP(&outputFmt, "output", "o", "text", "Output format (text|json|ndjson|table|yaml|edn)")
 	rootCmd.PersistentFlags().StringVar(&outputFmt, "format", "text", "Alias for --output")
 	rootCmd.PersistentFlags().StringVar(&queryExpr, "query", "", "jq expression to filter JSON output")
 	rootCmd.PersistentFlags().StringVar(&queryFile, "query-file", "", "Read jq expression from file (use - for stdin)")
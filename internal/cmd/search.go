@@ -3,13 +3,17 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/edn"
 	"github.com/salmonumbrella/roam-cli/internal/output"
 	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+	"github.com/salmonumbrella/roam-cli/internal/searchql"
 )
 
 // SearchResult represents a search result
@@ -18,6 +22,12 @@ type SearchResult struct {
 	Content   string `json:"content"`
 	PageTitle string `json:"page_title,omitempty"`
 	PageUID   string `json:"page_uid,omitempty"`
+	// Score and Snippet are set for ranked --index searches.
+	Score   float64 `json:"score,omitempty"`
+	Snippet string  `json:"snippet,omitempty"`
+	// Breadcrumbs and Children are set with --context.
+	Breadcrumbs []string      `json:"breadcrumbs,omitempty"`
+	Children    []SearchChild `json:"children,omitempty"`
 }
 
 // SearchOutput represents the JSON output format
@@ -25,12 +35,19 @@ type SearchOutput struct {
 	Query   string         `json:"query"`
 	Count   int            `json:"count"`
 	Results []SearchResult `json:"results"`
+	// NextCursor resumes after this page with --cursor.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 var (
 	searchPage          int
 	searchLimit         int
 	searchCaseSensitive bool
+	searchRegex         bool
+	searchWord          bool
+	searchUseIndex      bool
+	searchCursor        string
+	searchAll           bool
 	searchUIBlocks      bool
 	searchUIPages       bool
 	searchUIHideCode    bool
@@ -46,6 +63,40 @@ var searchCmd = &cobra.Command{
 Full-text search across all blocks and pages. Results include the block UID,
 content, and the page title where the block appears.
 
+Matching is case-insensitive by default on both the cloud and Local API.
+Use --regex to search with a regular expression and --word to match whole
+words only.
+
+Filters narrow the scan inside the Datalog query: --page-title, --exclude-page,
+--under (any descendant of a block or page UID), --namespace, --author, and
+--edited-since/--edited-before/--created-since/--created-before, which accept
+a date (2026-01-01) or an age (30m, 12h, 7d, 2w). The same filters are
+available on 'search tags' and 'search status'.
+
+With --index the search runs against the local full-text index built by
+'roam index build' instead of the graph. Results are ranked (BM25) with
+highlighted snippets; words are stemmed, "quoted phrases" must match exactly
+and word* matches prefixes. --page-title, --exclude-page, --namespace and the
+--edited-* filters still apply.
+
+With --boolean (-b) the text is a query such as
+  "exact phrase" tag:meeting -status:DONE page:"Weekly*" after:2026-01-01 (foo OR bar)
+compiled to a single Datalog query. See --help-syntax for the grammar and
+--explain to print the parsed tree (JSON) and the Datalog without running it.
+
+Use --context to add each hit's breadcrumb path (ancestor blocks up to the
+page) and its first --children N child blocks, in text and structured output.
+Text output trims long blocks around the match and highlights matches on a
+terminal.
+
+Results come in block creation order. Structured output includes a
+next_cursor while more results remain; pass it to --cursor for the next
+--limit results. With --cursor each request scans only a window of the graph
+instead of the whole of it, and --all returns every result. With
+--output ndjson, --cursor and --all write results one per line as they are
+found, ending with a {"next_cursor": ...} line when there are more. The same
+flags work on 'search tags', 'search status' and 'search refs'.
+
 Examples:
   # Search for text
   roam search "project ideas"
@@ -55,10 +106,36 @@ Examples:
   
   # Case-sensitive search
   roam search "API" --case-sensitive
+
+  # Whole-word and regular expression search
+  roam search "go" --word
+  roam search "v[0-9]+\.[0-9]+" --regex
+
+  # Scope the search to a page, subtree, namespace, author or time range
+  roam search "budget" --page-title "Q3 Planning"
+  roam search "risk" --under abc123def --exclude-page "Archive"
+  roam search "launch" --namespace "Project/" --edited-since 7d
+  roam search "draft" --author "Ada" --created-before 2026-01-01
+
+  # Boolean query
+  roam search -b 'tag:meeting -status:DONE (budget OR forecast)'
+  roam search -b 'page:"Weekly*" after:7d' --explain
+
+  # Page through a large result set, or stream all of it
+  roam search "meeting" --limit 100 -o json
+  roam search "meeting" --limit 100 --cursor <next_cursor> -o json
+  roam search "meeting" --all -o ndjson
+
+  # Show where each hit lives and what is nested under it
+  roam search "decision" --context --children 5
+
+  # Ranked search against the local index
+  roam search --index "quarterly budget"
+  roam search --index '"release plan" launch*'
   
   # Output as JSON
   roam search "todo" --output json`,
-	Args: cobra.ExactArgs(1),
+	Args: searchArgs,
 	RunE: runSearch,
 }
 
@@ -67,13 +144,22 @@ var searchTagsCmd = &cobra.Command{
 	Short: "Search by tag",
 	Long: `Search for blocks and pages with a specific tag.
 
-Tags in Roam are denoted by #tag or [[tag]]. This command finds all blocks
-that reference the given tag.
+Finds blocks whose references (:block/refs) include the tag's page, so #tag,
+#[[tag]], [[tag]] and tag:: attributes all count, while [[tag something]]
+does not. References to the page's aliases (listed in an Aliases:: block on
+the page) are included.
+
+The tag matches page titles exactly, ignoring case unless --case-sensitive is
+set. With --regex the argument is a pattern matched against page titles, and
+--word matches titles containing the word.
 
 Examples:
   # Search for a tag (with or without #)
   roam search tags project
   roam search tags "#project"
+
+  # Every tag under a namespace
+  roam search tags "^Project/" --regex
   
   # Search with limit
   roam search tags meeting --limit 50`,
@@ -97,7 +183,10 @@ Examples:
   roam search status DONE
   
   # With limit
-  roam search status TODO --limit 100`,
+  roam search status TODO --limit 100
+
+  # Open tasks on one page edited this week
+  roam search status TODO --page-title "Sprint 12" --edited-since 7d`,
 	Args: cobra.ExactArgs(1),
 	RunE: runSearchStatus,
 }
@@ -107,8 +196,10 @@ var searchRefsCmd = &cobra.Command{
 	Short: "Search block references",
 	Long: `Find all blocks that reference a specific block.
 
-Block references in Roam are created with ((uid)). This command finds
-all blocks that contain a reference to the specified block UID.
+Block references in Roam are created with ((uid)) and {{embed: ((uid))}}.
+This command finds all blocks whose references (:block/refs) include the
+specified block UID. With --regex the argument is a pattern matched against
+referenced UIDs.
 
 Examples:
   # Find references to a block
@@ -140,17 +231,32 @@ func init() {
 	// Flags for search command
 	searchCmd.Flags().IntVar(&searchPage, "page", 1, "Page number for pagination")
 	searchCmd.Flags().IntVar(&searchLimit, "limit", 50, "Maximum number of results per page")
-	searchCmd.Flags().BoolVar(&searchCaseSensitive, "case-sensitive", false, "Enable case-sensitive search")
+	addCursorFlags(searchCmd, &searchCursor, &searchAll)
+	addSearchMatchFlags(searchCmd, true)
+	addSearchFilterFlags(searchCmd)
+	addSearchContextFlags(searchCmd)
+	searchCmd.Flags().BoolVar(&searchUseIndex, "index", false, "Search the local full-text index (see 'roam index')")
+	addSearchBooleanFlags(searchCmd)
 
 	// Flags for subcommands
 	searchTagsCmd.Flags().IntVar(&searchPage, "page", 1, "Page number for pagination")
 	searchTagsCmd.Flags().IntVar(&searchLimit, "limit", 50, "Maximum number of results per page")
+	addCursorFlags(searchTagsCmd, &searchCursor, &searchAll)
+	addSearchMatchFlags(searchTagsCmd, true)
+	addSearchFilterFlags(searchTagsCmd)
+	addSearchContextFlags(searchTagsCmd)
 
 	searchStatusCmd.Flags().IntVar(&searchPage, "page", 1, "Page number for pagination")
 	searchStatusCmd.Flags().IntVar(&searchLimit, "limit", 50, "Maximum number of results per page")
+	addCursorFlags(searchStatusCmd, &searchCursor, &searchAll)
+	addSearchFilterFlags(searchStatusCmd)
+	addSearchContextFlags(searchStatusCmd)
 
 	searchRefsCmd.Flags().IntVar(&searchPage, "page", 1, "Page number for pagination")
 	searchRefsCmd.Flags().IntVar(&searchLimit, "limit", 50, "Maximum number of results per page")
+	addCursorFlags(searchRefsCmd, &searchCursor, &searchAll)
+	addSearchMatchFlags(searchRefsCmd, false)
+	addSearchContextFlags(searchRefsCmd)
 
 	searchUICmd.Flags().BoolVar(&searchUIBlocks, "search-blocks", true, "Include block results")
 	searchUICmd.Flags().BoolVar(&searchUIPages, "search-pages", true, "Include page results")
@@ -160,114 +266,85 @@ func init() {
 }
 
 func runSearch(cmd *cobra.Command, args []string) error {
-	client := GetClient()
+	if searchHelpSyntax {
+		fmt.Fprintln(stdoutFromContext(cmd.Context()), searchql.Syntax)
+		return nil
+	}
+
 	searchText := args[0]
 
-	// Build the query based on case sensitivity
-	// Roam API doesn't support clojure.string/lower-case, so always use case-sensitive search
-	var query string
-	escapedText := roamdb.EscapeString(searchText)
-
-	// Check if using local API (from stored credentials or --local flag)
-	_, isLocalClient := client.(*api.LocalClient)
-
-	// Determine if we need case-sensitive search
-	// Roam API (both cloud and local) doesn't support clojure.string/lower-case function
-	useCaseSensitive := searchCaseSensitive || isLocalClient
-	if isLocalClient && !searchCaseSensitive && !output.QuietFromContext(cmd.Context()) {
-		fmt.Fprintln(cmd.ErrOrStderr(), "Note: Local API uses case-sensitive search")
-	}
-
-	if useCaseSensitive {
-		query = fmt.Sprintf(`[:find ?uid ?string ?page-title ?page-uid
-			:where
-			[?b :block/uid ?uid]
-			[?b :block/string ?string]
-			[(clojure.string/includes? ?string "%s")]
-			[?b :block/page ?page]
-			[?page :node/title ?page-title]
-			[?page :block/uid ?page-uid]]`, escapedText)
-	} else {
-		// Case-insensitive: convert both to lowercase
-		query = fmt.Sprintf(`[:find ?uid ?string ?page-title ?page-uid
-			:where
-			[?b :block/uid ?uid]
-			[?b :block/string ?string]
-			[(clojure.string/lower-case ?string) ?lower-string]
-			[(clojure.string/includes? ?lower-string "%s")]
-			[?b :block/page ?page]
-			[?page :node/title ?page-title]
-			[?page :block/uid ?page-uid]]`, strings.ToLower(escapedText))
-	}
-
-	results, err := client.Query(query)
-	if err != nil {
-		return fmt.Errorf("search failed: %w", err)
+	if searchUseIndex {
+		if searchBoolean || searchExplain {
+			return fmt.Errorf("--boolean and --explain cannot be combined with --index")
+		}
+		return runIndexedSearch(cmd, searchText)
+	}
+	if searchBoolean || searchExplain {
+		return runBooleanSearch(cmd, args)
 	}
 
-	results, totalResults, pageUsed := paginateResults(results, searchPage, searchLimit)
+	match := searchTextMatch(searchText)
+	re, err := match.Compile()
+	if err != nil {
+		return err
+	}
 
-	// Convert to SearchResult
-	searchResults := make([]SearchResult, 0, len(results))
-	for _, row := range results {
-		if len(row) >= 4 {
-			searchResults = append(searchResults, SearchResult{
-				UID:       fmt.Sprintf("%v", row[0]),
-				Content:   fmt.Sprintf("%v", row[1]),
-				PageTitle: fmt.Sprintf("%v", row[2]),
-				PageUID:   fmt.Sprintf("%v", row[3]),
-			})
-		}
+	filters, err := searchFiltersFromFlags(time.Now())
+	if err != nil {
+		return err
+	}
+	search := roamdb.BlockSearch{
+		Clauses: []string{match.Clauses("?string", "?re")},
+		Filters: filters,
 	}
 
-	return outputSearchResults(searchText, searchResults, totalResults, pageUsed)
+	return runBlockSearch(cmd, args, searchText, search, re, re, "search")
 }
 
 func runSearchTags(cmd *cobra.Command, args []string) error {
-	client := GetClient()
 	tag := args[0]
 
 	// Remove # prefix if present
 	tag = strings.TrimPrefix(tag, "#")
-	escapedTag := roamdb.EscapeString(tag)
-
-	// Search for both #tag and [[tag]] references
-	query := fmt.Sprintf(`[:find ?uid ?string ?page-title ?page-uid
-		:where
-		[?b :block/uid ?uid]
-		[?b :block/string ?string]
-		(or
-			[(clojure.string/includes? ?string "#%s")]
-			[(clojure.string/includes? ?string "[[%s]]")])
-		[?b :block/page ?page]
-		[?page :node/title ?page-title]
-		[?page :block/uid ?page-uid]]`, escapedTag, escapedTag)
-
-	results, err := client.Query(query)
-	if err != nil {
-		return fmt.Errorf("tag search failed: %w", err)
+
+	// Match the tag page by title: exactly (ignoring case) by default, or by
+	// pattern/word with --regex/--word.
+	base := searchTextMatch(tag)
+	title := base
+	if !searchRegex && !searchWord {
+		title = roamdb.TextMatch{
+			Term:          "^" + base.Expr() + "$",
+			CaseSensitive: base.CaseSensitive,
+			Regex:         true,
+		}
+	}
+	if _, err := title.Compile(); err != nil {
+		return err
 	}
 
-	results, totalResults, pageUsed := paginateResults(results, searchPage, searchLimit)
+	filters, err := searchFiltersFromFlags(time.Now())
+	if err != nil {
+		return err
+	}
+	search := roamdb.BlockSearch{
+		Clauses: roamdb.PageRefClauses(title, true),
+		Filters: filters,
+	}
 
-	// Convert to SearchResult
-	searchResults := make([]SearchResult, 0, len(results))
-	for _, row := range results {
-		if len(row) >= 4 {
-			searchResults = append(searchResults, SearchResult{
-				UID:       fmt.Sprintf("%v", row[0]),
-				Content:   fmt.Sprintf("%v", row[1]),
-				PageTitle: fmt.Sprintf("%v", row[2]),
-				PageUID:   fmt.Sprintf("%v", row[3]),
-			})
-		}
+	// Highlight the common tag forms in text output.
+	highlight, err := roamdb.TextMatch{
+		Term:          `(?:#\[\[|\[\[|#)` + base.Expr(),
+		CaseSensitive: base.CaseSensitive,
+		Regex:         true,
+	}.Compile()
+	if err != nil {
+		highlight = nil
 	}
 
-	return outputSearchResults(fmt.Sprintf("tag:%s", tag), searchResults, totalResults, pageUsed)
+	return runBlockSearch(cmd, args, fmt.Sprintf("tag:%s", tag), search, nil, highlight, "tag search")
 }
 
 func runSearchStatus(cmd *cobra.Command, args []string) error {
-	client := GetClient()
 	status := strings.ToUpper(args[0])
 
 	if status != "TODO" && status != "DONE" {
@@ -276,65 +353,204 @@ func runSearchStatus(cmd *cobra.Command, args []string) error {
 
 	// Search for {{[[TODO]]}} or {{[[DONE]]}} markers
 	statusMarker := fmt.Sprintf("{{[[%s]]}}", status)
-	escapedMarker := roamdb.EscapeString(statusMarker)
-
-	query := fmt.Sprintf(`[:find ?uid ?string ?page-title ?page-uid
-		:where
-		[?b :block/uid ?uid]
-		[?b :block/string ?string]
-		[(clojure.string/includes? ?string "%s")]
-		[?b :block/page ?page]
-		[?page :node/title ?page-title]
-		[?page :block/uid ?page-uid]]`, escapedMarker)
-
-	results, err := client.Query(query)
+	match := roamdb.TextMatch{Term: statusMarker, CaseSensitive: true}
+
+	filters, err := searchFiltersFromFlags(time.Now())
 	if err != nil {
-		return fmt.Errorf("status search failed: %w", err)
+		return err
+	}
+	search := roamdb.BlockSearch{
+		Clauses: []string{match.Clauses("?string", "?re")},
+		Filters: filters,
 	}
 
-	results, totalResults, pageUsed := paginateResults(results, searchPage, searchLimit)
+	re := regexp.MustCompile(regexp.QuoteMeta(statusMarker))
+	return runBlockSearch(cmd, args, fmt.Sprintf("status:%s", status), search, nil, re, "status search")
+}
 
-	// Convert to SearchResult
-	searchResults := make([]SearchResult, 0, len(results))
-	for _, row := range results {
-		if len(row) >= 4 {
-			searchResults = append(searchResults, SearchResult{
-				UID:       fmt.Sprintf("%v", row[0]),
-				Content:   fmt.Sprintf("%v", row[1]),
-				PageTitle: fmt.Sprintf("%v", row[2]),
-				PageUID:   fmt.Sprintf("%v", row[3]),
-			})
-		}
+func runSearchRefs(cmd *cobra.Command, args []string) error {
+	uid := strings.TrimSuffix(strings.TrimPrefix(args[0], "(("), "))")
+
+	// UIDs are case-sensitive.
+	match := searchTextMatch(uid)
+	match.CaseSensitive = true
+	if _, err := match.Compile(); err != nil {
+		return err
+	}
+
+	highlight, err := roamdb.TextMatch{Term: `\(\(` + match.Expr() + `\)\)`, CaseSensitive: true, Regex: true}.Compile()
+	if err != nil {
+		highlight = nil
 	}
 
-	return outputSearchResults(fmt.Sprintf("status:%s", status), searchResults, totalResults, pageUsed)
+	search := roamdb.BlockSearch{Clauses: roamdb.BlockRefClauses(match)}
+	return runBlockSearch(cmd, args, fmt.Sprintf("refs:%s", uid), search, nil, highlight, "reference search")
 }
 
-func runSearchRefs(cmd *cobra.Command, args []string) error {
+// searchEIDColumn is the column of BlockSearch rows holding the block's
+// entity id.
+const searchEIDColumn = 4
+
+// runBlockSearch runs search and prints one --page of results, or with
+// --cursor/--all scans the graph in windows of block entity ids (see
+// streamBlockSearch). filter, when set, re-checks block text client-side;
+// highlight locates matches in text output. what names the search in errors.
+func runBlockSearch(cmd *cobra.Command, args []string, label string, search roamdb.BlockSearch, filter, highlight *regexp.Regexp, what string) error {
+	if searchCursor != "" || searchAll {
+		return streamBlockSearch(cmd, args, label, search, filter, highlight, what)
+	}
+
+	rows, err := GetClient().Query(search.Query(), search.Args()...)
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", what, err)
+	}
+	rows = filterSearchRows(rows, filter)
+
+	// Entity id order is the order cursor scans use, so next_cursor can pick
+	// up where this page ends.
+	sortRowsByEID(rows, searchEIDColumn)
+	total := len(rows)
+	start, end, pageUsed := pageBounds(total, searchPage, searchLimit)
+	next := ""
+	if end < total && end > 0 {
+		if eid := rowEID(rows[end-1], searchEIDColumn); eid > 0 {
+			next = encodeCursor(pageCursor{Kind: "search", After: eid, Hash: cursorFingerprint(cmd, args)})
+		}
+	}
+	return outputSearchResults(label, searchResultsFromRows(rows[start:end]), total, pageUsed, highlight, next)
+}
+
+// streamBlockSearch answers --cursor and --all. Each query covers a window of
+// block entity ids, so a page costs a partial scan of the graph, and with
+// --output ndjson results are written as each window returns.
+func streamBlockSearch(cmd *cobra.Command, args []string, label string, search roamdb.BlockSearch, filter, highlight *regexp.Regexp, what string) error {
 	client := GetClient()
-	uid := args[0]
-	escapedUID := roamdb.EscapeString(uid)
-
-	// Search for ((...)) block references
-	refPattern := fmt.Sprintf("((%s))", escapedUID)
-
-	query := fmt.Sprintf(`[:find ?uid ?string ?page-title ?page-uid
-		:where
-		[?b :block/uid ?uid]
-		[?b :block/string ?string]
-		[(clojure.string/includes? ?string "%s")]
-		[?b :block/page ?page]
-		[?page :node/title ?page-title]
-		[?page :block/uid ?page-uid]]`, refPattern)
-
-	results, err := client.Query(query)
+	hash := cursorFingerprint(cmd, args)
+	cursor, err := decodeCursor(searchCursor, "search", hash)
 	if err != nil {
-		return fmt.Errorf("reference search failed: %w", err)
+		return err
+	}
+	maxEID, err := queryMaxEID(client)
+	if err != nil {
+		return err
+	}
+	limit := searchLimit
+	if searchAll {
+		limit = 0
 	}
 
-	results, totalResults, pageUsed := paginateResults(results, searchPage, searchLimit)
+	scan := &eidScan{
+		eidCol: searchEIDColumn,
+		max:    maxEID,
+		fetch: func(r roamdb.EIDRange) ([][]interface{}, error) {
+			windowed := search
+			windowed.Range = &r
+			rows, err := client.Query(windowed.Query(), windowed.Args()...)
+			if err != nil {
+				return nil, fmt.Errorf("%s failed: %w", what, err)
+			}
+			return filterSearchRows(rows, filter), nil
+		},
+	}
 
-	// Convert to SearchResult
+	structured := structuredOutputRequested()
+	var stream *output.Stream
+	if structured && streamingRequested() {
+		if stream, err = newOutputStream(); err != nil {
+			return err
+		}
+	}
+
+	var collected []SearchResult
+	hlOpen, hlClose := searchHighlightMarkers()
+	printed := 0
+	after, more, err := scan.run(cursor.After, limit, func(rows [][]interface{}) error {
+		results := searchResultsFromRows(rows)
+		if searchContext {
+			if err := addSearchContext(client, results, searchChildren); err != nil {
+				return err
+			}
+		}
+		switch {
+		case stream != nil:
+			for _, r := range results {
+				if err := stream.Write(r); err != nil {
+					return err
+				}
+			}
+		case structured:
+			collected = append(collected, results...)
+		default:
+			for _, r := range results {
+				if printed == 0 {
+					fmt.Printf("Search: %s\n\n", label)
+				}
+				printed++
+				printSearchResult(printed, r, highlight, hlOpen, hlClose)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	next := ""
+	if more {
+		next = encodeCursor(pageCursor{Kind: "search", After: after, Hash: hash})
+	}
+	switch {
+	case stream != nil:
+		return writeNextCursor(stream, next)
+	case structured:
+		if collected == nil {
+			collected = []SearchResult{}
+		}
+		return printStructured(SearchOutput{Query: label, Count: len(collected), Results: collected, NextCursor: next})
+	}
+
+	if printed == 0 {
+		fmt.Printf("No results found for: %s\n", label)
+		return nil
+	}
+	if next != "" {
+		fmt.Printf("More results: --cursor %s\n", next)
+	}
+	return nil
+}
+
+// searchTextMatch builds a TextMatch for term from the shared match flags.
+func searchTextMatch(term string) roamdb.TextMatch {
+	return roamdb.TextMatch{
+		Term:          term,
+		CaseSensitive: searchCaseSensitive,
+		Regex:         searchRegex,
+		Word:          searchWord,
+	}
+}
+
+// filterSearchRows keeps rows whose content (column 1) matches re.
+// Query predicates already narrow the results server-side; this pass keeps
+// matching semantics identical on both backends.
+func filterSearchRows(results [][]interface{}, re *regexp.Regexp) [][]interface{} {
+	if re == nil {
+		return results
+	}
+	filtered := results[:0]
+	for _, row := range results {
+		if len(row) < 2 {
+			continue
+		}
+		content, _ := row[1].(string)
+		if re.MatchString(content) {
+			filtered = append(filtered, row)
+		}
+	}
+	return filtered
+}
+
+// searchResultsFromRows converts [uid string page-title page-uid] rows.
+func searchResultsFromRows(results [][]interface{}) []SearchResult {
 	searchResults := make([]SearchResult, 0, len(results))
 	for _, row := range results {
 		if len(row) >= 4 {
@@ -346,8 +562,16 @@ func runSearchRefs(cmd *cobra.Command, args []string) error {
 			})
 		}
 	}
+	return searchResults
+}
 
-	return outputSearchResults(fmt.Sprintf("refs:%s", uid), searchResults, totalResults, pageUsed)
+// addSearchMatchFlags registers the match mode flags shared by search commands.
+func addSearchMatchFlags(cmd *cobra.Command, caseFlag bool) {
+	if caseFlag {
+		cmd.Flags().BoolVar(&searchCaseSensitive, "case-sensitive", false, "Enable case-sensitive search")
+	}
+	cmd.Flags().BoolVar(&searchRegex, "regex", false, "Treat the search term as a regular expression")
+	cmd.Flags().BoolVar(&searchWord, "word", false, "Match whole words only")
 }
 
 func runSearchUI(cmd *cobra.Command, args []string) error {
@@ -370,6 +594,9 @@ func runSearchUI(cmd *cobra.Command, args []string) error {
 	}
 	if strings.TrimSpace(searchUIPull) != "" {
 		opts.Pull = strings.TrimSpace(searchUIPull)
+		if err := edn.ValidatePull(opts.Pull); err != nil {
+			return fmt.Errorf("invalid --pull: %w", err)
+		}
 	}
 
 	raw, err := localClient.Search(query, opts)
@@ -442,14 +669,35 @@ func firstString(item map[string]interface{}, keys ...string) string {
 	return ""
 }
 
-func outputSearchResults(query string, results []SearchResult, totalCount int, page int) error {
+// outputSearchResults prints a page of results. re, when set, locates the
+// match for excerpts and highlighting in text output.
+func outputSearchResults(query string, results []SearchResult, totalCount int, page int, re *regexp.Regexp, nextCursor string) error {
+	if searchContext {
+		if err := addSearchContext(GetClient(), results, searchChildren); err != nil {
+			return err
+		}
+	}
+
 	payload := SearchOutput{
-		Query:   query,
-		Count:   len(results),
-		Results: results,
+		Query:      query,
+		Count:      len(results),
+		Results:    results,
+		NextCursor: nextCursor,
 	}
 
 	if structuredOutputRequested() {
+		if streamingRequested() && (searchCursor != "" || searchAll) {
+			stream, err := newOutputStream()
+			if err != nil {
+				return err
+			}
+			for _, r := range results {
+				if err := stream.Write(r); err != nil {
+					return err
+				}
+			}
+			return writeNextCursor(stream, nextCursor)
+		}
 		ctx := currentContext()
 		printer := output.NewPrinter(stdoutFromContext(ctx), GetOutputFormat())
 		return printer.Print(ctx, payload)
@@ -464,20 +712,9 @@ func outputSearchResults(query string, results []SearchResult, totalCount int, p
 	fmt.Printf("Search: %s\n", query)
 	fmt.Printf("Showing %d of %d results (page %d)\n\n", len(results), totalCount, page)
 
+	hlOpen, hlClose := searchHighlightMarkers()
 	for i, r := range results {
-		// Truncate long content for display
-		content := r.Content
-		if len(content) > 100 {
-			content = content[:100] + "..."
-		}
-		// Replace newlines with spaces for cleaner display
-		content = strings.ReplaceAll(content, "\n", " ")
-
-		fmt.Printf("%d. [%s] %s\n", i+1, r.UID, content)
-		if r.PageTitle != "" {
-			fmt.Printf("   Page: %s\n", r.PageTitle)
-		}
-		fmt.Println()
+		printSearchResult(i+1, r, re, hlOpen, hlClose)
 	}
 
 	// Pagination info
@@ -488,3 +725,32 @@ func outputSearchResults(query string, results []SearchResult, totalCount int, p
 
 	return nil
 }
+
+// printSearchResult prints result number n in text output.
+func printSearchResult(n int, r SearchResult, re *regexp.Regexp, hlOpen, hlClose string) {
+	content := r.Snippet
+	if content == "" {
+		content = highlightMatches(searchExcerpt(r.Content, re, searchExcerptWidth), re, hlOpen, hlClose)
+	}
+
+	fmt.Printf("%d. [%s] %s\n", n, r.UID, content)
+	if len(r.Breadcrumbs) > 0 {
+		path := make([]string, 0, len(r.Breadcrumbs)+1)
+		if r.PageTitle != "" {
+			path = append(path, r.PageTitle)
+		}
+		for _, crumb := range r.Breadcrumbs {
+			path = append(path, searchExcerpt(crumb, nil, 40))
+		}
+		fmt.Printf("   Path: %s\n", strings.Join(path, " > "))
+	} else if r.PageTitle != "" {
+		fmt.Printf("   Page: %s\n", r.PageTitle)
+	}
+	for _, child := range r.Children {
+		fmt.Printf("     - %s\n", highlightMatches(searchExcerpt(child.Content, re, childExcerptWidth), re, hlOpen, hlClose))
+	}
+	if r.Score > 0 {
+		fmt.Printf("   Score: %.2f\n", r.Score)
+	}
+	fmt.Println()
+}
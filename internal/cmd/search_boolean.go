@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+	"github.com/salmonumbrella/roam-cli/internal/searchql"
+)
+
+// SearchExplain describes how a boolean query was parsed and compiled.
+type SearchExplain struct {
+	Query     string         `json:"query"`
+	Canonical string         `json:"canonical"`
+	AST       *searchql.Node `json:"ast"`
+	Datalog   string         `json:"datalog"`
+}
+
+var (
+	searchBoolean    bool
+	searchExplain    bool
+	searchHelpSyntax bool
+)
+
+// addSearchBooleanFlags registers the boolean query flags on the search command.
+func addSearchBooleanFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&searchBoolean, "boolean", "b", false, "Parse the search text as a boolean query (see --help-syntax)")
+	cmd.Flags().BoolVar(&searchExplain, "explain", false, "Print the parsed query and compiled Datalog instead of searching (implies --boolean)")
+	cmd.Flags().BoolVar(&searchHelpSyntax, "help-syntax", false, "Show the boolean query syntax")
+}
+
+// searchArgs accepts no argument for --help-syntax and exactly one otherwise.
+func searchArgs(cmd *cobra.Command, args []string) error {
+	if searchHelpSyntax {
+		return cobra.MaximumNArgs(1)(cmd, args)
+	}
+	return cobra.ExactArgs(1)(cmd, args)
+}
+
+func runBooleanSearch(cmd *cobra.Command, args []string) error {
+	text := args[0]
+	node, err := searchql.Parse(text)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	clauses, err := searchql.Compile(node, searchql.Options{
+		CaseSensitive: searchCaseSensitive,
+		Word:          searchWord,
+		Regex:         searchRegex,
+		ParseTime: func(value string) (int64, error) {
+			return parseTimeBound(value, now)
+		},
+	})
+	if err != nil {
+		var cerr *searchql.CompileError
+		if errors.As(err, &cerr) && cerr.Column > 0 && !strings.HasPrefix(strings.TrimSpace(text), "{") {
+			return &searchql.ParseError{Input: text, Column: cerr.Column, Msg: cerr.Msg}
+		}
+		return err
+	}
+
+	filters, err := searchFiltersFromFlags(now)
+	if err != nil {
+		return err
+	}
+	search := roamdb.BlockSearch{Clauses: clauses, Filters: filters}
+
+	if searchExplain {
+		return outputSearchExplain(SearchExplain{
+			Query:     text,
+			Canonical: node.String(),
+			AST:       node,
+			Datalog:   search.Query(),
+		})
+	}
+
+	return runBlockSearch(cmd, args, node.String(), search, nil, booleanHighlight(node), "search")
+}
+
+// booleanHighlight builds a regexp matching any positive term of the query.
+func booleanHighlight(node *searchql.Node) *regexp.Regexp {
+	terms := searchql.Highlights(node)
+	if len(terms) == 0 {
+		return nil
+	}
+	exprs := make([]string, len(terms))
+	for i, term := range terms {
+		exprs[i] = searchTextMatch(term).Expr()
+	}
+	match := roamdb.TextMatch{Term: strings.Join(exprs, "|"), CaseSensitive: searchCaseSensitive, Regex: true}
+	re, err := match.Compile()
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+func outputSearchExplain(explain SearchExplain) error {
+	if structuredOutputRequested() {
+		return printStructured(explain)
+	}
+
+	ast, err := json.MarshalIndent(explain.AST, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode query: %w", err)
+	}
+	fmt.Printf("Query:   %s\n", explain.Query)
+	fmt.Printf("Parsed:  %s\n\n", explain.Canonical)
+	fmt.Printf("AST:\n%s\n\n", ast)
+	fmt.Printf("Datalog:\n%s\n", explain.Datalog)
+	return nil
+}
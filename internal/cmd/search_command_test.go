@@ -2,9 +2,17 @@ package cmd
 
 import (
 	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
 
 	"github.com/salmonumbrella/roam-cli/internal/output"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
 )
 
 func TestSearchStructured(t *testing.T) {
@@ -138,3 +146,627 @@ func TestSearchUIRequiresFlags(t *testing.T) {
 		t.Fatalf("expected error when both search-blocks and search-pages are false")
 	}
 }
+
+func TestSearchCaseInsensitiveFiltersResults(t *testing.T) {
+	var gotQuery string
+	fake := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			gotQuery = query
+			return [][]interface{}{
+				{"uid1", "Hello World", "Page", "page-uid"},
+				{"uid2", "unrelated", "Page", "page-uid"},
+			}, nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(searchCmd)
+
+	searchPage = 1
+	searchLimit = 50
+	searchCaseSensitive = false
+
+	if err := runSearch(searchCmd, []string{"hello"}); err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+
+	if !strings.Contains(gotQuery, `(re-pattern "(?i)hello")`) {
+		t.Fatalf("expected case-insensitive re-pattern in query, got: %s", gotQuery)
+	}
+	var parsed SearchOutput
+	if err := json.Unmarshal(out.Bytes(), &parsed); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if parsed.Count != 1 || parsed.Results[0].UID != "uid1" {
+		t.Fatalf("expected only uid1, got %+v", parsed.Results)
+	}
+}
+
+func TestSearchWordModeLocal(t *testing.T) {
+	var received localRequest
+	localClient := newTestLocalClient(t, func(req localRequest) localResponse {
+		received = req
+		return localResponse{Success: true, Result: json.RawMessage(`[["u1","Go team","P","p1"],["u2","going","P","p1"]]`)}
+	})
+	restoreClient := withTestClient(t, localClient)
+	defer restoreClient()
+
+	out, errBuf, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(searchCmd)
+
+	searchPage = 1
+	searchLimit = 50
+	searchWord = true
+	defer func() { searchWord = false }()
+
+	if err := runSearch(searchCmd, []string{"go"}); err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+
+	query, _ := received.Args[0].(string)
+	if !strings.Contains(query, `(?i)\\b(?:go)\\b`) {
+		t.Fatalf("expected word pattern in query, got: %s", query)
+	}
+	if errBuf.Len() != 0 {
+		t.Fatalf("expected no stderr notes, got %q", errBuf.String())
+	}
+	var parsed SearchOutput
+	if err := json.Unmarshal(out.Bytes(), &parsed); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if parsed.Count != 1 || parsed.Results[0].UID != "u1" {
+		t.Fatalf("expected only u1, got %+v", parsed.Results)
+	}
+}
+
+func TestSearchTagsUsesRefs(t *testing.T) {
+	var gotQuery string
+	fake := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			gotQuery = query
+			return [][]interface{}{
+				{"u1", "see #[[Project]]", "P", "p"},
+				{"u2", "status:: [[project]]", "P", "p"},
+			}, nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(searchTagsCmd)
+
+	searchPage = 1
+	searchLimit = 50
+
+	if err := runSearchTags(searchTagsCmd, []string{"#project"}); err != nil {
+		t.Fatalf("tag search failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"[?b :block/refs ?ref-target]",
+		`(re-pattern "(?i)^project$")`,
+		`"Aliases::"`,
+	} {
+		if !strings.Contains(gotQuery, want) {
+			t.Fatalf("missing %q in query: %s", want, gotQuery)
+		}
+	}
+
+	var parsed SearchOutput
+	if err := json.Unmarshal(out.Bytes(), &parsed); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if parsed.Count != 2 {
+		t.Fatalf("expected 2 results, got %+v", parsed.Results)
+	}
+}
+
+func TestSearchTagsWordMatchesWithinTitles(t *testing.T) {
+	var gotQuery string
+	fake := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			gotQuery = query
+			return [][]interface{}{{"u1", "see [[Project Alpha]]", "P", "p"}}, nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(searchTagsCmd)
+
+	searchPage = 1
+	searchLimit = 50
+	searchWord = true
+	defer func() { searchWord = false }()
+
+	if err := runSearchTags(searchTagsCmd, []string{"project"}); err != nil {
+		t.Fatalf("tag search failed: %v", err)
+	}
+	if !strings.Contains(gotQuery, `(re-pattern "(?i)\\b(?:project)\\b")`) || strings.Contains(gotQuery, "^") {
+		t.Fatalf("expected an unanchored word pattern: %s", gotQuery)
+	}
+	var parsed SearchOutput
+	if err := json.Unmarshal(out.Bytes(), &parsed); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if parsed.Count != 1 {
+		t.Fatalf("expected 1 result, got %+v", parsed.Results)
+	}
+}
+
+func TestSearchRefsUsesRefs(t *testing.T) {
+	var gotQuery string
+	fake := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			gotQuery = query
+			return [][]interface{}{{"u1", "{{embed: ((abc123))}}", "P", "p"}}, nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(searchRefsCmd)
+
+	searchPage = 1
+	searchLimit = 50
+
+	if err := runSearchRefs(searchRefsCmd, []string{"((abc123))"}); err != nil {
+		t.Fatalf("refs search failed: %v", err)
+	}
+	if !strings.Contains(gotQuery, `[?ref-target :block/uid "abc123"]`) || !strings.Contains(gotQuery, "[?b :block/refs ?ref-target]") {
+		t.Fatalf("expected :block/refs query, got %s", gotQuery)
+	}
+
+	var parsed SearchOutput
+	if err := json.Unmarshal(out.Bytes(), &parsed); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if parsed.Count != 1 || parsed.Query != "refs:abc123" {
+		t.Fatalf("unexpected output %+v", parsed)
+	}
+}
+
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"30m", now.Add(-30 * time.Minute)},
+		{"12h", now.Add(-12 * time.Hour)},
+		{"7d", now.AddDate(0, 0, -7)},
+		{"2w", now.AddDate(0, 0, -14)},
+		{"2026-01-01", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseTimeBound(tt.value, now)
+		if err != nil {
+			t.Fatalf("parseTimeBound(%q) failed: %v", tt.value, err)
+		}
+		if got != tt.want.UnixMilli() {
+			t.Fatalf("parseTimeBound(%q) = %d, want %d", tt.value, got, tt.want.UnixMilli())
+		}
+	}
+
+	for _, bad := range []string{"", "soon", "-3d", "7y"} {
+		if _, err := parseTimeBound(bad, now); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestSearchFiltersPassedToQuery(t *testing.T) {
+	var gotQuery string
+	var gotArgs []interface{}
+	fake := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			gotQuery = query
+			gotArgs = args
+			return [][]interface{}{}, nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+
+	_, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(searchCmd)
+
+	searchFilterPage = "Q3 Planning"
+	searchFilterUnder = "abc123"
+	searchFilterExclude = []string{"Archive"}
+	searchFilterEditedSince = "7d"
+	defer func() {
+		searchFilterPage = ""
+		searchFilterUnder = ""
+		searchFilterExclude = nil
+		searchFilterEditedSince = ""
+	}()
+
+	if err := runSearch(searchCmd, []string{"budget"}); err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+
+	for _, want := range []string{
+		`[?page :node/title "Q3 Planning"]`,
+		`[?ancestor :block/uid "abc123"]`,
+		`[(not= ?page-title "Archive")]`,
+		"[(>= ?edit-time ",
+	} {
+		if !strings.Contains(gotQuery, want) {
+			t.Fatalf("missing %q in query: %s", want, gotQuery)
+		}
+	}
+	if len(gotArgs) != 1 || gotArgs[0] != roamdb.AncestorRules {
+		t.Fatalf("expected ancestor rules arg, got %v", gotArgs)
+	}
+}
+
+func TestSearchFiltersRejectInvalidTime(t *testing.T) {
+	restoreClient := withTestClient(t, &fakeClient{})
+	defer restoreClient()
+
+	_, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(searchStatusCmd)
+
+	searchFilterCreatedBefore = "someday"
+	defer func() { searchFilterCreatedBefore = "" }()
+
+	err := runSearchStatus(searchStatusCmd, []string{"TODO"})
+	if err == nil || !strings.Contains(err.Error(), "--created-before") {
+		t.Fatalf("expected --created-before error, got %v", err)
+	}
+}
+
+func TestSearchExcerptRuneSafeAndCentred(t *testing.T) {
+	text := strings.Repeat("é", 150) + " needle\n" + strings.Repeat("ü", 150)
+	re := regexp.MustCompile("needle")
+
+	excerpt := searchExcerpt(text, re, 40)
+	if !utf8.ValidString(excerpt) {
+		t.Fatalf("excerpt is not valid UTF-8: %q", excerpt)
+	}
+	if !strings.Contains(excerpt, "needle") {
+		t.Fatalf("expected excerpt centred on match, got %q", excerpt)
+	}
+	if !strings.HasPrefix(excerpt, "…") || !strings.HasSuffix(excerpt, "…") {
+		t.Fatalf("expected ellipses on both sides, got %q", excerpt)
+	}
+	if got := utf8.RuneCountInString(excerpt); got != 42 {
+		t.Fatalf("expected 40 runes plus ellipses, got %d", got)
+	}
+
+	if got := searchExcerpt("short\ntext", re, 40); got != "short text" {
+		t.Fatalf("unexpected short excerpt %q", got)
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	re := regexp.MustCompile("(?i)go")
+	if got := highlightMatches("Go to go", re, "[", "]"); got != "[Go] to [go]" {
+		t.Fatalf("unexpected highlight %q", got)
+	}
+	if got := highlightMatches("Go", re, "", ""); got != "Go" {
+		t.Fatalf("expected no markers, got %q", got)
+	}
+}
+
+func TestSearchContextStructured(t *testing.T) {
+	var contextArgs []interface{}
+	fake := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			switch {
+			case strings.Contains(query, ":block/parents"):
+				contextArgs = args
+				return [][]interface{}{
+					{"b3", "b3", "b2", "Child heading"},
+					{"b3", "b2", "b1", "Top block"},
+					{"b3", "b1", "page1", ""},
+				}, nil
+			case strings.Contains(query, "?child-string"):
+				return [][]interface{}{
+					{"b3", "c2", "second", float64(1)},
+					{"b3", "c1", "first", float64(0)},
+					{"b3", "c3", "third", float64(2)},
+				}, nil
+			default:
+				return [][]interface{}{{"b3", "the match", "Page", "page1"}}, nil
+			}
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(searchCmd)
+
+	searchContext = true
+	searchChildren = 2
+	defer func() {
+		searchContext = false
+		searchChildren = 3
+	}()
+
+	if err := runSearch(searchCmd, []string{"match"}); err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+
+	var parsed SearchOutput
+	if err := json.Unmarshal(out.Bytes(), &parsed); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if len(parsed.Results) != 1 {
+		t.Fatalf("expected 1 result, got %+v", parsed.Results)
+	}
+	r := parsed.Results[0]
+	if strings.Join(r.Breadcrumbs, " > ") != "Top block > Child heading" {
+		t.Fatalf("unexpected breadcrumbs %v", r.Breadcrumbs)
+	}
+	if len(r.Children) != 2 || r.Children[0].UID != "c1" || r.Children[1].UID != "c2" {
+		t.Fatalf("unexpected children %+v", r.Children)
+	}
+	if len(contextArgs) != 1 {
+		t.Fatalf("expected uid collection arg, got %v", contextArgs)
+	}
+	if uids, ok := contextArgs[0].([]string); !ok || len(uids) != 1 || uids[0] != "b3" {
+		t.Fatalf("unexpected uid arg %v", contextArgs[0])
+	}
+}
+
+func resetBooleanSearchFlags() {
+	searchBoolean = false
+	searchExplain = false
+	searchHelpSyntax = false
+	searchPage = 1
+	searchLimit = 50
+}
+
+func TestSearchBooleanCompilesSingleQuery(t *testing.T) {
+	var queries []string
+	fake := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			queries = append(queries, query)
+			return [][]interface{}{{"u1", "budget talk", "Weekly 3", "p"}}, nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(searchCmd)
+
+	resetBooleanSearchFlags()
+	searchBoolean = true
+	defer resetBooleanSearchFlags()
+
+	if err := runSearch(searchCmd, []string{`tag:meeting -status:DONE page:"Weekly*" (budget OR forecast)`}); err != nil {
+		t.Fatalf("boolean search failed: %v", err)
+	}
+	if len(queries) != 1 {
+		t.Fatalf("expected a single query, got %d", len(queries))
+	}
+	for _, want := range []string{"(or-join [?b]", "(not-join [?b]", "[?b :block/refs ?ref-target-"} {
+		if !strings.Contains(queries[0], want) {
+			t.Fatalf("missing %q in query: %s", want, queries[0])
+		}
+	}
+
+	var parsed SearchOutput
+	if err := json.Unmarshal(out.Bytes(), &parsed); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if parsed.Count != 1 {
+		t.Fatalf("expected 1 result, got %+v", parsed)
+	}
+}
+
+func TestSearchExplainStructured(t *testing.T) {
+	restoreClient := withTestClient(t, &fakeClient{})
+	defer restoreClient()
+
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(searchCmd)
+
+	resetBooleanSearchFlags()
+	searchExplain = true
+	defer resetBooleanSearchFlags()
+
+	if err := runSearch(searchCmd, []string{`foo OR "bar baz"`}); err != nil {
+		t.Fatalf("explain failed: %v", err)
+	}
+
+	var parsed struct {
+		Canonical string                 `json:"canonical"`
+		AST       map[string]interface{} `json:"ast"`
+		Datalog   string                 `json:"datalog"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &parsed); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if parsed.Canonical != `foo OR "bar baz"` || parsed.AST["op"] != "or" || !strings.Contains(parsed.Datalog, "or-join") {
+		t.Fatalf("unexpected explain output: %+v", parsed)
+	}
+}
+
+func TestSearchBooleanErrorShowsColumn(t *testing.T) {
+	restoreClient := withTestClient(t, &fakeClient{})
+	defer restoreClient()
+
+	_, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(searchCmd)
+
+	resetBooleanSearchFlags()
+	searchBoolean = true
+	defer resetBooleanSearchFlags()
+
+	err := runSearch(searchCmd, []string{"foo status:LATER"})
+	if err == nil || !strings.Contains(err.Error(), "column 5") || !strings.Contains(err.Error(), "    ^") {
+		t.Fatalf("expected column error with caret, got %v", err)
+	}
+}
+
+func TestSearchHelpSyntax(t *testing.T) {
+	out, _, restoreCtx := withTestContext(t, output.FormatText, true)
+	defer restoreCtx()
+	setCmdContext(searchCmd)
+
+	resetBooleanSearchFlags()
+	searchHelpSyntax = true
+	defer resetBooleanSearchFlags()
+
+	if err := searchArgs(searchCmd, nil); err != nil {
+		t.Fatalf("expected no args to be accepted: %v", err)
+	}
+	if err := runSearch(searchCmd, nil); err != nil {
+		t.Fatalf("help syntax failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "tag:name") {
+		t.Fatalf("expected syntax help, got %q", out.String())
+	}
+}
+
+// eidRangeClient answers BlockSearch queries from rows whose last column is
+// the block's entity id, honouring the query's entity id range.
+func eidRangeClient(rows [][]interface{}, maxEID int, queries *[]string) *fakeClient {
+	bounds := regexp.MustCompile(`\[\(> \?b (\d+)\)\]\s*\[\(<= \?b (\d+)\)\]`)
+	return &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			*queries = append(*queries, query)
+			if query == roamdb.QueryMaxEID() {
+				return [][]interface{}{{float64(maxEID)}}, nil
+			}
+			m := bounds.FindStringSubmatch(query)
+			if m == nil {
+				return rows, nil
+			}
+			var after, upto int
+			fmt.Sscan(m[1], &after)
+			fmt.Sscan(m[2], &upto)
+			var out [][]interface{}
+			for _, row := range rows {
+				eid, _ := intFromAny(row[len(row)-1])
+				if eid > after && eid <= upto {
+					out = append(out, row)
+				}
+			}
+			return out, nil
+		},
+	}
+}
+
+func resetSearchCursorFlags() {
+	searchPage = 1
+	searchLimit = 50
+	searchCursor = ""
+	searchAll = false
+}
+
+func TestSearchNextCursorContinuesScan(t *testing.T) {
+	rows := [][]interface{}{
+		{"c", "hello c", "P", "p", float64(30)},
+		{"a", "hello a", "P", "p", float64(10)},
+		{"b", "hello b", "P", "p", float64(20)},
+	}
+	var queries []string
+	restoreClient := withTestClient(t, eidRangeClient(rows, 40, &queries))
+	defer restoreClient()
+	defer resetSearchCursorFlags()
+
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(searchCmd)
+
+	searchLimit = 2
+	if err := runSearch(searchCmd, []string{"hello"}); err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	var first SearchOutput
+	if err := json.Unmarshal(out.Bytes(), &first); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if len(first.Results) != 2 || first.Results[0].UID != "a" || first.Results[1].UID != "b" || first.NextCursor == "" {
+		t.Fatalf("unexpected first page: %+v", first)
+	}
+
+	out.Reset()
+	queries = nil
+	searchCursor = first.NextCursor
+	if err := runSearch(searchCmd, []string{"hello"}); err != nil {
+		t.Fatalf("cursor search failed: %v", err)
+	}
+	var second SearchOutput
+	if err := json.Unmarshal(out.Bytes(), &second); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if len(second.Results) != 1 || second.Results[0].UID != "c" || second.NextCursor != "" {
+		t.Fatalf("unexpected second page: %+v", second)
+	}
+	if len(queries) < 2 || !strings.Contains(queries[1], "[(> ?b 20)]") {
+		t.Fatalf("expected a windowed query after eid 20, got %v", queries)
+	}
+
+	if err := runSearch(searchCmd, []string{"other"}); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("expected cursor mismatch error, got %v", err)
+	}
+}
+
+func TestSearchAllStreamsNDJSON(t *testing.T) {
+	rows := [][]interface{}{
+		{"a", "hello a", "P", "p", float64(10)},
+		{"b", "hello b", "P", "p", float64(20)},
+		{"c", "hello c", "P", "p", float64(30)},
+	}
+	var queries []string
+	restoreClient := withTestClient(t, eidRangeClient(rows, 40, &queries))
+	defer restoreClient()
+	defer resetSearchCursorFlags()
+
+	out, _, restoreCtx := withTestContext(t, output.FormatNDJSON, true)
+	defer restoreCtx()
+	setCmdContext(searchCmd)
+
+	searchLimit = 1
+	searchAll = true
+	if err := runSearch(searchCmd, []string{"hello"}); err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected one line per result, got %q", out.String())
+	}
+	for i, uid := range []string{"a", "b", "c"} {
+		var r SearchResult
+		if err := json.Unmarshal([]byte(lines[i]), &r); err != nil || r.UID != uid {
+			t.Fatalf("line %d: %q (%v)", i, lines[i], err)
+		}
+	}
+}
+
+func TestExcludePageKeepsCommas(t *testing.T) {
+	cmd := &cobra.Command{}
+	addSearchFilterFlags(cmd)
+	defer func() { searchFilterExclude = nil }()
+	if err := cmd.ParseFlags([]string{"--exclude-page", "Notes, 2024", "--exclude-page", "Archive"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(searchFilterExclude, "|") != "Notes, 2024|Archive" {
+		t.Fatalf("unexpected excluded pages: %q", searchFilterExclude)
+	}
+}
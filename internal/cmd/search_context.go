@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// SearchChild is a child block included with --context.
+type SearchChild struct {
+	UID     string `json:"uid"`
+	Content string `json:"content"`
+}
+
+const (
+	searchExcerptWidth = 100
+	childExcerptWidth  = 80
+	ansiBold           = "\x1b[1m"
+	ansiReset          = "\x1b[0m"
+)
+
+var (
+	searchContext  bool
+	searchChildren int
+)
+
+// addSearchContextFlags registers --context and --children on a search command.
+func addSearchContextFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&searchContext, "context", false, "Include ancestor breadcrumbs and the first child blocks of each result")
+	cmd.Flags().IntVar(&searchChildren, "children", 3, "Number of child blocks to include with --context (0 for none)")
+}
+
+// addSearchContext fills in breadcrumbs and children for results in place.
+// It issues two queries regardless of the number of results.
+func addSearchContext(client api.RoamAPI, results []SearchResult, children int) error {
+	if len(results) == 0 {
+		return nil
+	}
+	uids := make([]string, len(results))
+	for i, r := range results {
+		uids[i] = r.UID
+	}
+
+	edges, err := client.Query(roamdb.QueryBreadcrumbEdges(), uids)
+	if err != nil {
+		return fmt.Errorf("failed to fetch breadcrumbs: %w", err)
+	}
+	parents := make(map[string]map[string]string)
+	texts := make(map[string]string)
+	for _, row := range edges {
+		if len(row) < 4 {
+			continue
+		}
+		uid := fmt.Sprintf("%v", row[0])
+		child := fmt.Sprintf("%v", row[1])
+		parent := fmt.Sprintf("%v", row[2])
+		if parents[uid] == nil {
+			parents[uid] = make(map[string]string)
+		}
+		parents[uid][child] = parent
+		texts[parent] = fmt.Sprintf("%v", row[3])
+	}
+	for i := range results {
+		results[i].Breadcrumbs = breadcrumbPath(results[i].UID, results[i].PageUID, parents[results[i].UID], texts)
+	}
+
+	if children <= 0 {
+		return nil
+	}
+	rows, err := client.Query(roamdb.QueryChildBlocks(), uids)
+	if err != nil {
+		return fmt.Errorf("failed to fetch child blocks: %w", err)
+	}
+	type orderedChild struct {
+		SearchChild
+		order int
+	}
+	byParent := make(map[string][]orderedChild)
+	for _, row := range rows {
+		if len(row) < 4 {
+			continue
+		}
+		order, _ := intFromAny(row[3])
+		uid := fmt.Sprintf("%v", row[0])
+		byParent[uid] = append(byParent[uid], orderedChild{
+			SearchChild: SearchChild{UID: fmt.Sprintf("%v", row[1]), Content: fmt.Sprintf("%v", row[2])},
+			order:       order,
+		})
+	}
+	for i := range results {
+		kids := byParent[results[i].UID]
+		sort.SliceStable(kids, func(a, b int) bool { return kids[a].order < kids[b].order })
+		if len(kids) > children {
+			kids = kids[:children]
+		}
+		for _, kid := range kids {
+			results[i].Children = append(results[i].Children, kid.SearchChild)
+		}
+	}
+	return nil
+}
+
+// breadcrumbPath walks parent links from uid up to (but excluding) the page
+// and returns the ancestor block strings, outermost first.
+func breadcrumbPath(uid, pageUID string, parents map[string]string, texts map[string]string) []string {
+	var path []string
+	cur := uid
+	for steps := 0; steps <= len(parents); steps++ {
+		parent, ok := parents[cur]
+		if !ok || parent == pageUID {
+			break
+		}
+		path = append([]string{texts[parent]}, path...)
+		cur = parent
+	}
+	return path
+}
+
+// searchHighlightMarkers returns the strings placed around matches in text
+// output: bold on a terminal, nothing otherwise.
+func searchHighlightMarkers() (string, string) {
+	if isTerminal(stdoutFromContext(currentContext())) {
+		return ansiBold, ansiReset
+	}
+	return "", ""
+}
+
+// searchExcerpt flattens whitespace and trims text to at most width runes,
+// centred on the first match of re, marking cut edges with an ellipsis.
+func searchExcerpt(text string, re *regexp.Regexp, width int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	total := utf8.RuneCountInString(text)
+	if width <= 0 || total <= width {
+		return text
+	}
+
+	center := 0
+	if re != nil {
+		if loc := re.FindStringIndex(text); loc != nil {
+			center = utf8.RuneCountInString(text[:loc[0]])
+		}
+	}
+	start := center - width/3
+	if start < 0 {
+		start = 0
+	}
+	if start+width > total {
+		start = total - width
+	}
+
+	runes := []rune(text)
+	excerpt := string(runes[start : start+width])
+	if start > 0 {
+		excerpt = "…" + excerpt
+	}
+	if start+width < total {
+		excerpt += "…"
+	}
+	return excerpt
+}
+
+// highlightMatches wraps every match of re in text with hlOpen and hlClose.
+func highlightMatches(text string, re *regexp.Regexp, hlOpen, hlClose string) string {
+	if re == nil || (hlOpen == "" && hlClose == "") {
+		return text
+	}
+	return re.ReplaceAllStringFunc(text, func(m string) string {
+		if m == "" {
+			return m
+		}
+		return hlOpen + m + hlClose
+	})
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+var (
+	searchFilterPage          string
+	searchFilterExclude       []string
+	searchFilterUnder         string
+	searchFilterNamespace     string
+	searchFilterEditedSince   string
+	searchFilterEditedBefore  string
+	searchFilterCreatedSince  string
+	searchFilterCreatedBefore string
+	searchFilterAuthor        string
+)
+
+// addSearchFilterFlags registers the scope filter flags shared by search commands.
+func addSearchFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&searchFilterPage, "page-title", "", "Only search blocks on this page")
+	cmd.Flags().StringArrayVar(&searchFilterExclude, "exclude-page", nil, "Skip blocks on this page (repeatable)")
+	cmd.Flags().StringVar(&searchFilterUnder, "under", "", "Only search descendants of this block or page UID")
+	cmd.Flags().StringVar(&searchFilterNamespace, "namespace", "", "Only search pages in this namespace (e.g. Project/)")
+	cmd.Flags().StringVar(&searchFilterEditedSince, "edited-since", "", "Only blocks edited since a date or age (2026-01-01, 7d, 12h)")
+	cmd.Flags().StringVar(&searchFilterEditedBefore, "edited-before", "", "Only blocks edited before a date or age")
+	cmd.Flags().StringVar(&searchFilterCreatedSince, "created-since", "", "Only blocks created since a date or age")
+	cmd.Flags().StringVar(&searchFilterCreatedBefore, "created-before", "", "Only blocks created before a date or age")
+	cmd.Flags().StringVar(&searchFilterAuthor, "author", "", "Only blocks created by this user (display name)")
+}
+
+// searchFiltersFromFlags converts the filter flags into roamdb.SearchFilters.
+func searchFiltersFromFlags(now time.Time) (roamdb.SearchFilters, error) {
+	filters := roamdb.SearchFilters{
+		PageTitle:    strings.TrimSpace(searchFilterPage),
+		ExcludePages: searchFilterExclude,
+		Under:        strings.TrimSpace(searchFilterUnder),
+		Namespace:    strings.TrimSpace(searchFilterNamespace),
+		Author:       strings.TrimSpace(searchFilterAuthor),
+	}
+
+	bounds := []struct {
+		flag  string
+		value string
+		dest  *int64
+	}{
+		{"--edited-since", searchFilterEditedSince, &filters.EditedSince},
+		{"--edited-before", searchFilterEditedBefore, &filters.EditedBefore},
+		{"--created-since", searchFilterCreatedSince, &filters.CreatedSince},
+		{"--created-before", searchFilterCreatedBefore, &filters.CreatedBefore},
+	}
+	for _, bound := range bounds {
+		if strings.TrimSpace(bound.value) == "" {
+			continue
+		}
+		ms, err := parseTimeBound(bound.value, now)
+		if err != nil {
+			return roamdb.SearchFilters{}, fmt.Errorf("invalid %s: %w", bound.flag, err)
+		}
+		*bound.dest = ms
+	}
+
+	return filters, nil
+}
+
+// parseTimeBound parses an age such as 30m, 12h, 7d or 2w (relative to now)
+// or a calendar date (start of day, local time) into epoch milliseconds.
+func parseTimeBound(value string, now time.Time) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, fmt.Errorf("empty time value")
+	}
+
+	if unit := value[len(value)-1]; strings.ContainsRune("mhdw", rune(unit)) {
+		if n, err := strconv.Atoi(value[:len(value)-1]); err == nil {
+			if n < 0 {
+				return 0, fmt.Errorf("age must not be negative: %s", value)
+			}
+			var d time.Duration
+			switch unit {
+			case 'm':
+				d = time.Duration(n) * time.Minute
+			case 'h':
+				d = time.Duration(n) * time.Hour
+			case 'd':
+				d = time.Duration(n) * 24 * time.Hour
+			case 'w':
+				d = time.Duration(n) * 7 * 24 * time.Hour
+			}
+			return now.Add(-d).UnixMilli(), nil
+		}
+	}
+
+	t, err := parseDate(value)
+	if err != nil {
+		return 0, fmt.Errorf("expected a date (YYYY-MM-DD) or age (30m, 12h, 7d, 2w): %s", value)
+	}
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, now.Location())
+	return start.UnixMilli(), nil
+}
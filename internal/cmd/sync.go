@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/mdblocks"
+	"github.com/salmonumbrella/roam-cli/internal/mdsync"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+	"github.com/salmonumbrella/roam-cli/internal/treediff"
+)
+
+var (
+	syncPage   string
+	syncState  string
+	syncFrom   string
+	syncDryRun bool
+	syncIndent int
+)
+
+// SyncResult summarizes a sync run.
+type SyncResult struct {
+	File        string `json:"file"`
+	Page        string `json:"page"`
+	PageUID     string `json:"page_uid"`
+	State       string `json:"state"`
+	DryRun      bool   `json:"dry_run,omitempty"`
+	PageCreated bool   `json:"page_created,omitempty"`
+	// Pushed counts the writes to Roam by kind; Pulled counts the Roam
+	// changes written to the file.
+	Pushed      map[string]int    `json:"pushed"`
+	Pulled      map[string]int    `json:"pulled"`
+	FileWritten bool              `json:"file_written"`
+	Conflicts   []mdsync.Conflict `json:"conflicts,omitempty"`
+	Operations  []treediff.Op     `json:"operations,omitempty"`
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync <file.md>",
+	Short: "Sync a Markdown file with a Roam page, both ways",
+	Long: `Keep a Markdown file and a Roam page in step.
+
+Each run compares both sides with the state of the last sync, kept in a
+sidecar file next to the Markdown file (<file>.roam-sync.json): the uid of
+every block, its place in the tree and a hash of its content on each side.
+Changes made on one side since then are applied to the other: edits, new
+blocks, deletes and moves in the file are written to Roam in one batch,
+and Roam's changes are written to the file.
+
+When both sides changed a block differently, Roam keeps its text and the
+file shows both versions between conflict markers:
+
+  - <<<<<<< file
+    the file's text
+    =======
+    Roam's text
+    >>>>>>> roam
+
+Edit the block to the text you want, remove the markers and sync again;
+sync refuses to run while markers are left. When one side deleted a block
+the other changed, the change wins and the block is kept.
+
+The first sync copies whichever side has blocks to the other. If both
+have blocks, choose the side to start from with --from; --from also
+overwrites the other side on later runs. A file that does not exist is
+created from the page, and a page that does not exist is created from the
+file.
+
+The file is read with the Markdown importer and written with the Markdown
+renderer, so Roam markup that Markdown writes differently (italics,
+highlights, attributes) is written back in Markdown form only for blocks
+edited in the file.`,
+	Example: `  # First run: create notes.md from the page
+  roam sync notes.md --page "Meeting Notes"
+
+  # Later runs remember the page
+  roam sync notes.md
+
+  # Both sides already have content: start from the file
+  roam sync notes.md --page "Meeting Notes" --from file
+
+  # Show what would change
+  roam sync notes.md --dry-run -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSync,
+}
+
+func init() {
+	syncCmd.Flags().StringVar(&syncPage, "page", "", "Title of the page to sync with (remembered after the first sync)")
+	syncCmd.Flags().StringVar(&syncState, "state", "", "Sync state file (default <file>.roam-sync.json)")
+	syncCmd.Flags().StringVar(&syncFrom, "from", "", "Take one side as it is: file or roam")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Show the changes without writing either side")
+	syncCmd.Flags().IntVar(&syncIndent, "indent", mdblocks.DefaultIndent, "Columns a tab counts as when nesting list items")
+	rootCmd.AddCommand(syncCmd)
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	file := args[0]
+	from := strings.ToLower(strings.TrimSpace(syncFrom))
+	if from != "" && from != mdsync.FromFile && from != mdsync.FromRoam {
+		return fmt.Errorf("invalid --from %q (expected file or roam)", syncFrom)
+	}
+	statePath := syncState
+	if statePath == "" {
+		statePath = file + ".roam-sync.json"
+	}
+
+	state, err := mdsync.LoadState(statePath)
+	if err != nil {
+		return err
+	}
+	title := strings.TrimSpace(syncPage)
+	if title == "" && state != nil {
+		title = state.Page
+	}
+	if title == "" {
+		return fmt.Errorf("--page is required for the first sync of %s", file)
+	}
+
+	data, err := os.ReadFile(file)
+	fileExists := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	switch {
+	case !fileExists && from == mdsync.FromFile:
+		return fmt.Errorf("%s does not exist", file)
+	case !fileExists:
+		from = mdsync.FromRoam
+	}
+	if from == "" && mdsync.HasConflictMarkers(string(data)) {
+		return fmt.Errorf("%s has conflict markers from the last sync; resolve them and sync again", file)
+	}
+	blocks, viewType := parseMarkdown(string(data), syncIndent)
+	fileNodes := syncNodes(blocks)
+
+	client := GetClient()
+	result := SyncResult{File: file, Page: title, State: statePath, DryRun: syncDryRun}
+	var roamNodes []*treediff.Node
+	// pageViewType is the view type the file's top-level list sets on the
+	// page: always for a new page, and for an existing one when the file is
+	// taken as it is.
+	pageViewType := ""
+	raw, err := client.GetPageByTitle(title)
+	switch {
+	case err == nil:
+		page, err := roamdb.ParsePage(raw)
+		if err != nil {
+			return fmt.Errorf("failed to parse page: %w", err)
+		}
+		result.PageUID = page.UID
+		roamNodes = treediff.FromBlocks(page.Children)
+		if from == mdsync.FromFile && viewType != "" && viewType != page.ViewType {
+			pageViewType = viewType
+		}
+	case isNotFound(err):
+		if state != nil && from != mdsync.FromFile {
+			return fmt.Errorf("page %q no longer exists; sync with --from file to create it again", title)
+		}
+		if !fileExists {
+			return fmt.Errorf("neither %s nor page %q exists", file, title)
+		}
+		result.PageUID, result.PageCreated = roamdb.NewUID(), true
+		pageViewType = viewType
+		if from == mdsync.FromRoam {
+			from = mdsync.FromFile
+		}
+	default:
+		return fmt.Errorf("failed to get page: %w", err)
+	}
+
+	plan, err := mdsync.Merge(state, result.PageUID, fileNodes, roamNodes, from)
+	if err != nil {
+		return fmt.Errorf("%w (use --from file or --from roam)", err)
+	}
+	result.Operations = treediff.Diff(result.PageUID, roamNodes, plan.Tree)
+	result.Pushed = map[string]int{}
+	for _, op := range result.Operations {
+		result.Pushed[op.Kind]++
+	}
+	result.Pulled = plan.Pulled
+	result.Conflicts = plan.Conflicts
+	result.FileWritten = plan.FileChanged() || !fileExists
+
+	if !syncDryRun {
+		if result.PageCreated || pageViewType != "" || len(result.Operations) > 0 {
+			batch := api.NewBatchBuilder()
+			if result.PageCreated {
+				batch.CreatePage(api.PageOptions{Title: title, UID: result.PageUID, ChildrenViewType: pageViewType})
+			} else {
+				addParentViewType(batch, result.PageUID, true, pageViewType)
+			}
+			for _, op := range result.Operations {
+				addTreeEditOp(batch, op)
+			}
+			if err := client.ExecuteBatch(batch); err != nil {
+				return fmt.Errorf("failed to update page: %w", err)
+			}
+		}
+		if result.FileWritten {
+			if err := os.WriteFile(file, []byte(plan.Markdown()), 0o644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", file, err)
+			}
+		}
+		if err := plan.State(title, result.PageUID).Save(statePath); err != nil {
+			return fmt.Errorf("failed to save sync state: %w", err)
+		}
+	}
+
+	if structuredOutputRequested() {
+		return printStructured(result)
+	}
+	verb := "Synced"
+	if syncDryRun {
+		verb = "Dry run - would sync"
+	}
+	fmt.Printf("%s %s with %q\n", verb, file, title)
+	if result.PageCreated {
+		fmt.Println("  Page created")
+	}
+	fmt.Printf("  Roam: %d updates, %d moves, %d creates, %d deletes\n",
+		result.Pushed[treediff.OpUpdate], result.Pushed[treediff.OpMove], result.Pushed[treediff.OpCreate], result.Pushed[treediff.OpDelete])
+	if result.FileWritten {
+		fmt.Printf("  File: %d updates, %d creates, %d deletes\n", result.Pulled["update"], result.Pulled["create"], result.Pulled["delete"])
+	} else {
+		fmt.Println("  File: unchanged")
+	}
+	for _, c := range result.Conflicts {
+		switch c.Kept {
+		case mdsync.FromFile:
+			fmt.Printf("  Kept %s: changed in the file, deleted in Roam\n", previewLine(c.File, 50))
+		case mdsync.FromRoam:
+			fmt.Printf("  Kept %s: changed in Roam, deleted in the file\n", previewLine(c.Roam, 50))
+		default:
+			fmt.Printf("  Conflict in %s: %s\n", c.UID, previewLine(c.File, 50))
+		}
+	}
+	return nil
+}
+
+// syncNodes converts parsed Markdown blocks to tree nodes.
+func syncNodes(blocks []*MarkdownBlock) []*treediff.Node {
+	nodes := make([]*treediff.Node, len(blocks))
+	for i, b := range blocks {
+		nodes[i] = &treediff.Node{String: b.Content, Heading: b.Heading, Children: syncNodes(b.Children)}
+	}
+	return nodes
+}
+
+func isNotFound(err error) bool {
+	var notFound api.NotFoundError
+	return errors.As(err, &notFound)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/output"
+)
+
+func TestSyncPullsThenPushes(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "notes.md")
+	var actions []map[string]interface{}
+	client := &fakeClient{
+		GetPageByTitleFunc: func(title string) (json.RawMessage, error) {
+			if title != "Meeting" {
+				t.Fatalf("unexpected title %q", title)
+			}
+			return json.RawMessage(`{"node/title":"Meeting","block/uid":"page1","block/children":[
+				{"block/uid":"a","block/string":"Agenda","block/heading":2,"block/order":0,"block/children":[
+					{"block/uid":"a1","block/string":"budget","block/order":0}]},
+				{"block/uid":"b","block/string":"{{[[TODO]]}} send notes","block/order":1}]}`), nil
+		},
+		ExecuteBatchFunc: func(b *api.BatchBuilder) error {
+			actions = b.Build()
+			return nil
+		},
+	}
+	restoreClient := withTestClient(t, client)
+	defer restoreClient()
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(syncCmd)
+	syncPage = "Meeting"
+	defer func() { syncPage = "" }()
+
+	if err := runSync(syncCmd, []string{file}); err != nil {
+		t.Fatalf("first sync failed: %v", err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "- ## Agenda\n  - budget\n- [ ] send notes\n" {
+		t.Fatalf("unexpected file:\n%s", data)
+	}
+	if actions != nil {
+		t.Fatalf("first sync wrote to Roam: %v", actions)
+	}
+	if _, err := os.Stat(file + ".roam-sync.json"); err != nil {
+		t.Fatalf("state not saved: %v", err)
+	}
+
+	// The second run finds the page through the state.
+	syncPage = ""
+	out.Reset()
+	edited := "- ## Agenda\n  - budget for Q3\n  - hiring\n- [x] send notes\n"
+	if err := os.WriteFile(file, []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runSync(syncCmd, []string{file}); err != nil {
+		t.Fatalf("second sync failed: %v", err)
+	}
+	var result SyncResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if result.FileWritten || result.Pushed["update"] != 2 || result.Pushed["create"] != 1 || len(result.Conflicts) != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	var writes []string
+	for _, a := range actions {
+		block := a["block"].(map[string]interface{})
+		writes = append(writes, a["action"].(string)+":"+block["string"].(string))
+	}
+	got := strings.Join(writes, "|")
+	if got != "update-block:{{[[DONE]]}} send notes|update-block:budget for Q3|create-block:hiring" {
+		t.Fatalf("unexpected actions: %s", got)
+	}
+	if data, _ := os.ReadFile(file); string(data) != edited {
+		t.Fatalf("file rewritten:\n%s", data)
+	}
+}
+
+func TestSyncRefusesConflictMarkers(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(file, []byte("- <<<<<<< file\n  mine\n  =======\n  theirs\n  >>>>>>> roam\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	restoreClient := withTestClient(t, &fakeClient{})
+	defer restoreClient()
+	_, _, restoreCtx := withTestContext(t, output.FormatText, true)
+	defer restoreCtx()
+	setCmdContext(syncCmd)
+	syncPage = "Meeting"
+	defer func() { syncPage = "" }()
+
+	err := runSync(syncCmd, []string{file})
+	if err == nil || !strings.Contains(err.Error(), "conflict markers") {
+		t.Fatalf("expected a conflict marker error, got %v", err)
+	}
+}
@@ -0,0 +1,338 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+	"github.com/salmonumbrella/roam-cli/internal/templates"
+)
+
+// Template sources reported by template list.
+const (
+	templateSourceRoam = "roam/templates"
+	templateSourcePage = "page"
+)
+
+// templateHelp explains --template and --var for page and block create.
+const templateHelp = `Templates:
+  --template names a page (its children are the template), a template
+  filed under a [[roam/templates]] block (see 'roam template list'), or a
+  block uid (its children are the template). The template's blocks are
+  copied with fresh uids, keeping headings, props and view types, and
+  ((refs)) between them point at the copies. Placeholders are filled in:
+    {{date}}        today's daily note title (the daily note's, with --daily-note)
+    {{title}}       the title of the page the copy goes to
+    {{var:name}}    the value of --var name=value`
+
+var templateListNamespace string
+
+// TemplateInfo is a template found by template list.
+type TemplateInfo struct {
+	Name   string `json:"name"`
+	UID    string `json:"uid"`
+	Source string `json:"source"`
+	// Page is the page a roam/templates template is filed on.
+	Page string `json:"page,omitempty"`
+}
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Find templates for page create and block create",
+	Long: `Find the templates that page create and block create can copy with
+--template.`,
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List templates",
+	Long: `List the templates in the graph.
+
+Two kinds are listed: Roam's own templates, which are the children of any
+block that references [[roam/templates]] and are named by their text, and
+pages inside the templates namespace (Templates/ by default), named by
+their title.`,
+	Example: `  roam template list
+  roam template list --namespace "Meeting Templates"
+  roam template list -o json`,
+	Args: cobra.NoArgs,
+	RunE: runTemplateList,
+}
+
+func init() {
+	templateListCmd.Flags().StringVar(&templateListNamespace, "namespace", "Templates", "Namespace whose pages are templates (empty to skip)")
+	templateCmd.AddCommand(templateListCmd)
+	rootCmd.AddCommand(templateCmd)
+}
+
+func runTemplateList(cmd *cobra.Command, args []string) error {
+	client := GetClient()
+	var list []TemplateInfo
+	rows, err := client.Query(roamdb.QueryRoamTemplates())
+	if err != nil {
+		return fmt.Errorf("failed to find roam/templates: %w", err)
+	}
+	for _, row := range rows {
+		if len(row) < 3 {
+			continue
+		}
+		list = append(list, TemplateInfo{
+			Name:   strings.TrimSpace(fmt.Sprint(row[1])),
+			UID:    fmt.Sprint(row[0]),
+			Source: templateSourceRoam,
+			Page:   fmt.Sprint(row[2]),
+		})
+	}
+	if ns := strings.TrimSpace(templateListNamespace); ns != "" {
+		rows, err := client.Query(roamdb.QueryNamespacePages(ns))
+		if err != nil {
+			return fmt.Errorf("failed to list template pages: %w", err)
+		}
+		for _, row := range rows {
+			if len(row) < 2 {
+				continue
+			}
+			list = append(list, TemplateInfo{Name: fmt.Sprint(row[0]), UID: fmt.Sprint(row[1]), Source: templateSourcePage})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Source != list[j].Source {
+			return list[i].Source == templateSourceRoam
+		}
+		return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name)
+	})
+
+	if structuredOutputRequested() {
+		if list == nil {
+			list = []TemplateInfo{}
+		}
+		return printStructured(list)
+	}
+	printTemplateList(stdoutFromContext(cmd.Context()), list)
+	return nil
+}
+
+func printTemplateList(w io.Writer, list []TemplateInfo) {
+	if len(list) == 0 {
+		fmt.Fprintln(w, "No templates found.")
+		return
+	}
+	for _, t := range list {
+		if t.Source == templateSourceRoam {
+			fmt.Fprintf(w, "%s  (%s, on %s)\n", t.Name, t.UID, t.Page)
+		} else {
+			fmt.Fprintf(w, "%s  (page)\n", t.Name)
+		}
+	}
+}
+
+// templateSource is the template a --template value names.
+type templateSource struct {
+	Name string
+	UID  string
+	// ViewType is the children view type of a page template.
+	ViewType string
+	Blocks   []roamdb.Block
+}
+
+// resolveTemplate finds a template by page title, roam/templates name or
+// block uid, in that order.
+func resolveTemplate(client api.RoamAPI, ref string) (*templateSource, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, fmt.Errorf("--template is empty")
+	}
+	raw, err := client.GetPageByTitle(ref)
+	if err == nil {
+		page, err := roamdb.ParsePage(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template page: %w", err)
+		}
+		return &templateSource{Name: page.Title, UID: page.UID, ViewType: page.ViewType, Blocks: page.Children}, nil
+	}
+	if !isNotFound(err) {
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+
+	uid := strings.TrimSuffix(strings.TrimPrefix(ref, "(("), "))")
+	rows, err := client.Query(roamdb.QueryRoamTemplates())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find roam/templates: %w", err)
+	}
+	for _, row := range rows {
+		if len(row) >= 2 && strings.TrimSpace(fmt.Sprint(row[1])) == ref {
+			uid = fmt.Sprint(row[0])
+			break
+		}
+	}
+	raw, err = client.GetBlockByUID(uid)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("template not found: %s (not a page, a roam/templates name or a block uid)", ref)
+		}
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+	block, err := roamdb.ParseBlock(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template block: %w", err)
+	}
+	return &templateSource{Name: block.String, UID: block.UID, Blocks: block.Children}, nil
+}
+
+// instantiateTemplate copies a template's blocks for a page titled title.
+// date is the day {{date}} stands for.
+func instantiateTemplate(tmpl *templateSource, vars []string, title string, date time.Time) ([]roamdb.Block, error) {
+	values, err := templates.ParseVars(vars)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := templates.Instantiate(tmpl.Blocks, templates.Values{
+		Date:  formatDailyNoteTitle(date),
+		Title: title,
+		Vars:  values,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("template %q: %w", tmpl.Name, err)
+	}
+	return blocks, nil
+}
+
+// addBlocksWithUIDs adds create actions for blocks under their own uids,
+// placing the top-level blocks together from loc.Order.
+func addBlocksWithUIDs(batch *api.BatchBuilder, loc api.Location, blocks []roamdb.Block) {
+	for i := range blocks {
+		b := &blocks[i]
+		at := loc
+		at.Order = siblingOrder(loc.Order, i)
+		opts := exportBlockOptions(b)
+		opts.UID = b.UID
+		batch.CreateBlock(at, opts)
+		addBlocksWithUIDs(batch, api.Location{ParentUID: b.UID, Order: "last"}, b.Children)
+	}
+}
+
+// locationPageTitle returns the title of the page a location is on, or ""
+// when it cannot be found.
+func locationPageTitle(client api.RoamAPI, loc api.Location) string {
+	switch {
+	case loc.PageTitle != "":
+		return loc.PageTitle
+	case loc.DailyNoteDate != "":
+		if t, err := time.Parse("01-02-2006", loc.DailyNoteDate); err == nil {
+			return formatDailyNoteTitle(t)
+		}
+		return ""
+	}
+	raw, err := client.Pull([]interface{}{":block/uid", loc.ParentUID}, "[:node/title {:block/page [:node/title]}]")
+	if err != nil || len(raw) == 0 {
+		return ""
+	}
+	if page, err := roamdb.ParsePage(raw); err == nil && page.Title != "" {
+		return page.Title
+	}
+	if block, err := roamdb.ParseBlock(raw); err == nil && block.Page != nil {
+		return block.Page.Title
+	}
+	return ""
+}
+
+// createPageFromTemplate creates a page holding a copy of a template in one
+// batch. The page takes the template's children view unless one is given.
+func createPageFromTemplate(client api.RoamAPI, opts api.PageOptions, ref string, vars []string) error {
+	tmpl, err := resolveTemplate(client, ref)
+	if err != nil {
+		return err
+	}
+	date, ok := roamdb.ParseDailyNoteTitle(opts.Title)
+	if !ok {
+		date = time.Now()
+	}
+	blocks, err := instantiateTemplate(tmpl, vars, opts.Title, date)
+	if err != nil {
+		return err
+	}
+	if opts.UID == "" {
+		opts.UID = roamdb.NewUID()
+	}
+	if opts.ChildrenViewType == "" {
+		opts.ChildrenViewType = tmpl.ViewType
+	}
+
+	batch := api.NewBatchBuilder()
+	batch.CreatePage(opts)
+	addBlocksWithUIDs(batch, api.Location{ParentUID: opts.UID, Order: "last"}, blocks)
+	if err := client.ExecuteBatch(batch); err != nil {
+		return fmt.Errorf("failed to create page: %w", err)
+	}
+
+	if structuredOutputRequested() {
+		return printStructured(map[string]interface{}{
+			"status":         "created",
+			"title":          opts.Title,
+			"uid":            opts.UID,
+			"template":       tmpl.Name,
+			"blocks_created": countBlocks(blocks),
+		})
+	}
+	fmt.Printf("Created page: %s (%d blocks from template %q)\n", opts.Title, countBlocks(blocks), tmpl.Name)
+	return nil
+}
+
+// createBlocksFromTemplate creates a copy of a template at loc in one batch.
+// When opts has content, a block is created there and the copy goes under
+// it.
+func createBlocksFromTemplate(client api.RoamAPI, loc api.Location, opts api.BlockOptions, ref string, vars []string) error {
+	tmpl, err := resolveTemplate(client, ref)
+	if err != nil {
+		return err
+	}
+	date := time.Now()
+	if loc.DailyNoteDate != "" {
+		if date, err = time.Parse("01-02-2006", loc.DailyNoteDate); err != nil {
+			return fmt.Errorf("invalid daily note date %q (expected MM-DD-YYYY)", loc.DailyNoteDate)
+		}
+	}
+	blocks, err := instantiateTemplate(tmpl, vars, locationPageTitle(client, loc), date)
+	if err != nil {
+		return err
+	}
+
+	batch := api.NewBatchBuilder()
+	created := countBlocks(blocks)
+	var uids []string
+	if opts.Content != "" {
+		if opts.UID == "" {
+			opts.UID = roamdb.NewUID()
+		}
+		batch.CreateBlock(loc, opts)
+		addBlocksWithUIDs(batch, api.Location{ParentUID: opts.UID, Order: "last"}, blocks)
+		uids = []string{opts.UID}
+		created++
+	} else {
+		addBlocksWithUIDs(batch, loc, blocks)
+		for _, b := range blocks {
+			uids = append(uids, b.UID)
+		}
+	}
+	if err := client.ExecuteBatch(batch); err != nil {
+		return fmt.Errorf("failed to create blocks: %w", err)
+	}
+
+	if structuredOutputRequested() {
+		return printStructured(map[string]interface{}{
+			"success":        true,
+			"template":       tmpl.Name,
+			"uids":           uids,
+			"blocks_created": created,
+			"target":         loc.ToMap(),
+		})
+	}
+	fmt.Printf("Created %d blocks from template %q\n", created, tmpl.Name)
+	return nil
+}
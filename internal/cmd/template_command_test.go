@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/output"
+)
+
+const oneOnOneTemplate = `{"node/title":"Templates/1on1","block/uid":"tpl","children/view-type":"numbered","block/children":[
+	{"block/uid":"t1","block/string":"{{title}} with {{var:person}}","block/order":0,"block/heading":2,"block/children":[
+		{"block/uid":"t2","block/string":"Follow up on ((t3))","block/order":0,"block/props":{"k":"v"}}]},
+	{"block/uid":"t3","block/string":"Actions [[{{date}}]]","block/order":1,"children/view-type":"document"}]}`
+
+func TestPageCreateFromTemplate(t *testing.T) {
+	var actions []map[string]interface{}
+	client := &fakeClient{
+		GetPageByTitleFunc: func(title string) (json.RawMessage, error) {
+			if title == "Templates/1on1" {
+				return json.RawMessage(oneOnOneTemplate), nil
+			}
+			return nil, api.NotFoundError{Message: "page not found"}
+		},
+		ExecuteBatchFunc: func(b *api.BatchBuilder) error {
+			actions = b.Build()
+			return nil
+		},
+	}
+	restoreClient := withTestClient(t, client)
+	defer restoreClient()
+	_, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(pageCreateCmd)
+
+	flags := pageCreateCmd.Flags()
+	_ = flags.Set("template", "Templates/1on1")
+	_ = flags.Set("var", "person=Alice")
+	defer func() {
+		_ = flags.Set("template", "")
+		_ = flags.Lookup("var").Value.(pflag.SliceValue).Replace(nil)
+	}()
+
+	if err := pageCreateCmd.RunE(pageCreateCmd, []string{"October 16th, 2026"}); err != nil {
+		t.Fatalf("page create failed: %v", err)
+	}
+	if len(actions) != 4 || actions[0]["action"] != "create-page" {
+		t.Fatalf("unexpected actions: %v", actions)
+	}
+	page := actions[0]["page"].(map[string]interface{})
+	if page["title"] != "October 16th, 2026" || page["children-view-type"] != "numbered" {
+		t.Fatalf("unexpected page: %v", page)
+	}
+
+	blocks := map[string]map[string]interface{}{}
+	parents := map[string]interface{}{}
+	for _, a := range actions[1:] {
+		b := a["block"].(map[string]interface{})
+		blocks[b["string"].(string)] = b
+		parents[b["string"].(string)] = a["location"].(map[string]interface{})["parent-uid"]
+	}
+	head := blocks["October 16th, 2026 with Alice"]
+	actionsBlock := blocks["Actions [[October 16th, 2026]]"]
+	if head == nil || actionsBlock == nil || head["heading"] != 2 || actionsBlock["children-view-type"] != "document" {
+		t.Fatalf("unexpected blocks: %v", blocks)
+	}
+	if parents["October 16th, 2026 with Alice"] != page["uid"] || head["uid"] == "t1" {
+		t.Fatalf("unexpected heading placement: %v", actions[1])
+	}
+	follow := blocks["Follow up on (("+actionsBlock["uid"].(string)+"))"]
+	if follow == nil || parents["Follow up on (("+actionsBlock["uid"].(string)+"))"] != head["uid"] {
+		t.Fatalf("block ref not remapped: %v", blocks)
+	}
+}
+
+func TestBlockCreateFromRoamTemplate(t *testing.T) {
+	var actions []map[string]interface{}
+	client := &fakeClient{
+		GetPageByTitleFunc: func(title string) (json.RawMessage, error) {
+			return nil, api.NotFoundError{Message: "page not found"}
+		},
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			if strings.Contains(query, "roam/templates") {
+				return [][]interface{}{{"rt1", "Daily Review", "roam/templates"}}, nil
+			}
+			return nil, nil
+		},
+		GetBlockByUIDFunc: func(uid string) (json.RawMessage, error) {
+			if uid != "rt1" {
+				return nil, api.NotFoundError{Message: "block not found"}
+			}
+			return json.RawMessage(`{"block/uid":"rt1","block/string":"Daily Review","block/children":[
+				{"block/uid":"r1","block/string":"Wins on {{date}}","block/order":0},
+				{"block/uid":"r2","block/string":"Lessons","block/order":1}]}`), nil
+		},
+		ExecuteBatchFunc: func(b *api.BatchBuilder) error {
+			actions = b.Build()
+			return nil
+		},
+	}
+	restoreClient := withTestClient(t, client)
+	defer restoreClient()
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(blockCreateCmd)
+
+	blockCreateDailyNote = "10-16-2026"
+	blockCreateTemplate = "Daily Review"
+	blockCreateOrder = "first"
+	defer func() {
+		blockCreateDailyNote = ""
+		blockCreateTemplate = ""
+		blockCreateOrder = "last"
+	}()
+
+	if err := runBlockCreate(blockCreateCmd, nil); err != nil {
+		t.Fatalf("block create failed: %v", err)
+	}
+	if len(actions) != 2 {
+		t.Fatalf("unexpected actions: %v", actions)
+	}
+	first := actions[0]["location"].(map[string]interface{})
+	second := actions[1]["location"].(map[string]interface{})
+	if first["order"] != 0 || second["order"] != 1 {
+		t.Fatalf("unexpected orders: %v %v", first, second)
+	}
+	if actions[0]["block"].(map[string]interface{})["string"] != "Wins on October 16th, 2026" {
+		t.Fatalf("unexpected block: %v", actions[0])
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if result["template"] != "Daily Review" || result["blocks_created"] != float64(2) {
+		t.Fatalf("unexpected result: %v", result)
+	}
+}
+
+func TestTemplateList(t *testing.T) {
+	client := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			if strings.Contains(query, "starts-with") {
+				return [][]interface{}{{"Templates/1on1", "tpl"}}, nil
+			}
+			return [][]interface{}{{"rt1", "Daily Review ", "roam/templates"}}, nil
+		},
+	}
+	restoreClient := withTestClient(t, client)
+	defer restoreClient()
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(templateListCmd)
+
+	if err := runTemplateList(templateListCmd, nil); err != nil {
+		t.Fatalf("template list failed: %v", err)
+	}
+	var list []TemplateInfo
+	if err := json.Unmarshal(out.Bytes(), &list); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if len(list) != 2 || list[0].Name != "Daily Review" || list[0].Source != templateSourceRoam || list[1].Source != templateSourcePage {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+}
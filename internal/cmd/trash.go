@@ -0,0 +1,349 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/config"
+	"github.com/salmonumbrella/roam-cli/internal/output"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+	"github.com/salmonumbrella/roam-cli/internal/trash"
+)
+
+// trashDirFunc resolves the trash directory for a graph (overridable in tests).
+var trashDirFunc = func(graph string) (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return trash.Dir(dir, graph), nil
+}
+
+// TrashItem is one entry in 'trash list' output.
+type TrashItem struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	UID       string    `json:"uid"`
+	Title     string    `json:"title"`
+	Blocks    int       `json:"blocks"`
+	Refs      int       `json:"refs"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// TrashRestoreResult summarizes a trash restore.
+type TrashRestoreResult struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	UID       string `json:"uid"`
+	Title     string `json:"title"`
+	ParentUID string `json:"parent_uid,omitempty"`
+	Blocks    int    `json:"blocks"`
+}
+
+var trashRestoreParent string
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List and restore deleted pages and blocks",
+	Long: `'roam page delete' and 'roam block delete' save the deleted page or block,
+with its whole subtree, uids and attributes, to a local trash directory
+(~/.config/roam/trash/<graph>) before deleting it, unless --no-trash is
+given. A page keeps its own view type and props.
+
+'roam trash restore' recreates an entry with its original uids, so
+((block refs)) and embeds that pointed into it work again.`,
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List deleted pages and blocks",
+	Example: `  roam trash list
+  roam trash list -o json`,
+	Args: cobra.NoArgs,
+	RunE: runTrashList,
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <id>",
+	Short: "Recreate a deleted page or block with its original uids",
+	Long: `Recreate a deleted page or block from the trash, in one batch, with the
+uids, text and attributes it had. A block goes back under its old parent at
+its old position unless --parent is given. The entry is removed from the
+trash once it is restored.
+
+Restoring fails if the uid is in use again, or a page with the same title
+has been created since.`,
+	Example: `  roam trash restore 20261018-153012-abc123
+  roam trash restore 20261018-153012-abc123 --parent def456`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTrashRestore,
+}
+
+func init() {
+	trashRestoreCmd.Flags().StringVar(&trashRestoreParent, "parent", "", "Restore a block under this parent instead of its old one")
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	rootCmd.AddCommand(trashCmd)
+}
+
+func runTrashList(cmd *cobra.Command, args []string) error {
+	dir, err := trashDirFunc(graphName)
+	if err != nil {
+		return err
+	}
+	entries, err := trash.List(dir)
+	if err != nil {
+		return err
+	}
+	items := make([]TrashItem, 0, len(entries))
+	for _, e := range entries {
+		items = append(items, TrashItem{ID: e.ID, Kind: e.Kind, UID: e.UID, Title: e.Title, Blocks: e.Blocks, Refs: e.Refs, DeletedAt: e.DeletedAt})
+	}
+
+	if structuredOutputRequested() {
+		return printStructured(items)
+	}
+	if len(items) == 0 {
+		fmt.Fprintln(stdoutFromContext(cmd.Context()), "Trash is empty.")
+		return nil
+	}
+	ctx := cmd.Context()
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		rows = append(rows, []string{
+			item.ID,
+			item.Kind,
+			previewLine(item.Title, 50),
+			strconv.Itoa(item.Blocks),
+			refsCell(item.Refs),
+			item.DeletedAt.Local().Format("2006-01-02 15:04"),
+		})
+	}
+	printer := output.NewPrinter(stdoutFromContext(ctx), output.FormatTable)
+	return printer.Print(ctx, output.Table{Headers: []string{"ID", "KIND", "TITLE", "BLOCKS", "REFS", "DELETED"}, Rows: rows})
+}
+
+// refsCell formats a trash entry's reference count for the list table.
+func refsCell(refs int) string {
+	if refs == trash.RefsUnknown {
+		return "?"
+	}
+	return strconv.Itoa(refs)
+}
+
+func runTrashRestore(cmd *cobra.Command, args []string) error {
+	dir, err := trashDirFunc(graphName)
+	if err != nil {
+		return err
+	}
+	entry, err := trash.Load(dir, args[0])
+	if err != nil {
+		return err
+	}
+
+	client := GetClient()
+	existing, err := existingUIDs(client, []string{entry.UID})
+	if err != nil {
+		return err
+	}
+	if existing[entry.UID] {
+		return fmt.Errorf("%s %s already exists; it may have been restored already", entry.Kind, entry.UID)
+	}
+
+	result := TrashRestoreResult{ID: entry.ID, Kind: entry.Kind, UID: entry.UID, Title: entry.Title}
+	batch := api.NewBatchBuilder()
+	switch entry.Kind {
+	case trash.KindPage:
+		page, err := roamdb.ParsePage(entry.Data)
+		if err != nil {
+			return fmt.Errorf("failed to parse trashed page: %w", err)
+		}
+		taken, err := existingPageUIDs(client, []string{page.Title})
+		if err != nil {
+			return err
+		}
+		if uid, ok := taken[page.Title]; ok {
+			return fmt.Errorf("a page titled %q already exists (%s)", page.Title, uid)
+		}
+		batch.CreatePage(api.PageOptions{Title: page.Title, UID: page.UID, ChildrenViewType: entry.ViewType})
+		if len(entry.Props) > 0 {
+			batch.UpdateBlock(page.UID, api.BlockOptions{Props: entry.Props})
+		}
+		addBlocksWithUIDs(batch, api.Location{ParentUID: page.UID, Order: "last"}, page.Children)
+		result.Blocks = countBlocks(page.Children)
+	case trash.KindBlock:
+		block, err := roamdb.ParseBlock(entry.Data)
+		if err != nil {
+			return fmt.Errorf("failed to parse trashed block: %w", err)
+		}
+		loc := api.Location{ParentUID: entry.ParentUID, Order: entry.Order}
+		if trashRestoreParent != "" {
+			loc = api.Location{ParentUID: trashRestoreParent, Order: "last"}
+		}
+		if loc.ParentUID == "" {
+			return fmt.Errorf("the block's parent is unknown; pass --parent")
+		}
+		addBlocksWithUIDs(batch, loc, []roamdb.Block{*block})
+		result.ParentUID = loc.ParentUID
+		result.Blocks = 1 + countBlocks(block.Children)
+	default:
+		return fmt.Errorf("unknown trash entry kind: %s", entry.Kind)
+	}
+
+	if err := client.ExecuteBatch(batch); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", entry.Kind, err)
+	}
+	if err := trash.Remove(dir, entry.ID); err != nil {
+		return err
+	}
+
+	if structuredOutputRequested() {
+		return printStructured(result)
+	}
+	fmt.Fprintf(stdoutFromContext(cmd.Context()), "Restored %s %s (%d blocks)\n", entry.Kind, previewLine(entry.Title, 60), result.Blocks)
+	return nil
+}
+
+// newTrashEntry pulls the page or block uid with its subtree, ready to be
+// saved to the trash, and finds the blocks outside the subtree that
+// reference it or its descendants. When the reference query fails it warns
+// and marks the entry's Refs as unknown rather than failing.
+func newTrashEntry(client api.RoamAPI, kind, uid string, warn io.Writer) (*trash.Entry, []string, error) {
+	raw, err := client.GetBlockByUID(uid)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil, api.NotFoundError{Message: fmt.Sprintf("%s not found: %s", kind, uid)}
+		}
+		return nil, nil, fmt.Errorf("failed to get %s: %w", kind, err)
+	}
+
+	entry := &trash.Entry{Graph: graphName, Kind: kind, UID: uid, Data: raw}
+	var children []roamdb.Block
+	if kind == trash.KindPage {
+		page, err := roamdb.ParsePage(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse page: %w", err)
+		}
+		entry.Title, children = page.Title, page.Children
+		entry.ViewType, entry.Props = page.ViewType, page.Props
+		entry.Blocks = countBlocks(children)
+	} else {
+		block, err := roamdb.ParseBlock(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse block: %w", err)
+		}
+		entry.Title, children = block.String, block.Children
+		entry.Blocks = 1 + countBlocks(children)
+
+		rows, err := client.Query(roamdb.QueryBlockParent(uid))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to find the block's parent: %w", err)
+		}
+		if len(rows) > 0 && len(rows[0]) >= 2 {
+			entry.ParentUID = fmt.Sprint(rows[0][0])
+			entry.Order, _ = intFromAny(rows[0][1])
+		}
+	}
+
+	subtree := map[string]bool{uid: true}
+	uids := []string{uid}
+	var collect func(blocks []roamdb.Block)
+	collect = func(blocks []roamdb.Block) {
+		for _, b := range blocks {
+			subtree[b.UID] = true
+			uids = append(uids, b.UID)
+			collect(b.Children)
+		}
+	}
+	collect(children)
+
+	refs := map[string]bool{}
+	for start := 0; start < len(uids); start += lookupChunk {
+		rows, err := client.Query(roamdb.QueryReferencingBlocks(), uids[start:min(start+lookupChunk, len(uids))])
+		if err != nil {
+			fmt.Fprintf(warn, "warning: failed to find references: %v\n", err)
+			entry.Refs = trash.RefsUnknown
+			return entry, nil, nil
+		}
+		for _, row := range rows {
+			if len(row) == 0 {
+				continue
+			}
+			if ref := fmt.Sprint(row[0]); !subtree[ref] {
+				refs[ref] = true
+			}
+		}
+	}
+	referencing := make([]string, 0, len(refs))
+	for ref := range refs {
+		referencing = append(referencing, ref)
+	}
+	sort.Strings(referencing)
+	entry.Refs = len(referencing)
+	return entry, referencing, nil
+}
+
+// deleteTrashEntry returns the trash entry to save before deleting uid, or
+// nil with noTrash. When the page or block can't be pulled the delete must
+// not go ahead, so the error points at --no-trash.
+func deleteTrashEntry(client api.RoamAPI, kind, uid string, noTrash bool, warn io.Writer) (*trash.Entry, []string, error) {
+	if noTrash {
+		return nil, nil, nil
+	}
+	entry, referencing, err := newTrashEntry(client, kind, uid, warn)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("%w; use --no-trash to delete without saving to the trash", err)
+	}
+	return entry, referencing, nil
+}
+
+// saveToTrash stamps entry with an id and writes it to the graph's trash.
+// It returns the trash directory so a failed delete can take it back out.
+func saveToTrash(entry *trash.Entry) (string, error) {
+	dir, err := trashDirFunc(graphName)
+	if err != nil {
+		return "", err
+	}
+	entry.DeletedAt = time.Now().UTC()
+	entry.ID = trash.NewID(entry.DeletedAt, entry.UID)
+	if err := trash.Save(dir, entry); err != nil {
+		return "", fmt.Errorf("failed to save to trash (nothing was deleted): %w", err)
+	}
+	return dir, nil
+}
+
+// discardTrashEntry removes an entry saved for a delete that failed.
+func discardTrashEntry(dir string, entry *trash.Entry) {
+	if err := trash.Remove(dir, entry.ID); err != nil && !errors.Is(err, trash.ErrNotFound) {
+		fmt.Fprintf(stderrFromContext(currentContext()), "warning: %v\n", err)
+	}
+}
+
+// printDeleteImpact describes what deleting entry removes and how many
+// blocks elsewhere lose their references.
+func printDeleteImpact(w io.Writer, entry *trash.Entry) {
+	if entry.Kind == trash.KindPage {
+		fmt.Fprintf(w, "  Page: %s\n", entry.Title)
+		fmt.Fprintf(w, "  Blocks on the page: %d\n", entry.Blocks)
+	} else {
+		if entry.Title != "" {
+			fmt.Fprintf(w, "  Content: %s\n", previewLine(entry.Title, 50))
+		}
+		fmt.Fprintf(w, "  Blocks (with children): %d\n", entry.Blocks)
+	}
+	if entry.Refs == trash.RefsUnknown {
+		fmt.Fprintf(w, "  Referenced by an unknown number of other blocks\n")
+		return
+	}
+	fmt.Fprintf(w, "  Referenced by %d other blocks\n", entry.Refs)
+}
@@ -0,0 +1,316 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/output"
+	"github.com/salmonumbrella/roam-cli/internal/trash"
+)
+
+// withTestTrash points the trash at a temporary directory and returns it.
+func withTestTrash(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	prev := trashDirFunc
+	trashDirFunc = func(graph string) (string, error) { return trash.Dir(dir, graph), nil }
+	t.Cleanup(func() { trashDirFunc = prev })
+	return trash.Dir(dir, graphName)
+}
+
+const trashedBlock = `{"block/uid":"b1","block/string":"Plan ((b2))","block/heading":2,"block/children":[
+	{"block/uid":"b2","block/string":"step","block/order":0,"block/props":{"k":"v"},"block/children":[
+		{"block/uid":"b3","block/string":"detail","block/order":0}]}]}`
+
+func TestBlockDeleteSavesToTrashAndRestores(t *testing.T) {
+	dir := withTestTrash(t)
+	var deleted string
+	var actions []map[string]interface{}
+	fake := &fakeClient{
+		GetBlockByUIDFunc: func(uid string) (json.RawMessage, error) {
+			return json.RawMessage(trashedBlock), nil
+		},
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			switch {
+			case strings.Contains(query, "?parent-uid ?order"):
+				return [][]interface{}{{"parent", float64(3)}}, nil
+			case strings.Contains(query, ":block/refs"):
+				// b1 refers to b2 inside the subtree; r1 and r2 are outside.
+				return [][]interface{}{{"b1"}, {"r1"}, {"r2"}, {"r1"}}, nil
+			}
+			return nil, nil
+		},
+		DeleteBlockFunc: func(uid string) error {
+			deleted = uid
+			return nil
+		},
+		ExecuteBatchFunc: func(b *api.BatchBuilder) error {
+			actions = b.Build()
+			return nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(blockDeleteCmd)
+
+	if err := runBlockDelete(blockDeleteCmd, []string{"b1"}); err != nil {
+		t.Fatalf("block delete failed: %v", err)
+	}
+	if deleted != "b1" {
+		t.Fatalf("expected b1 to be deleted, got %q", deleted)
+	}
+	var result struct {
+		Blocks       int      `json:"blocks"`
+		ReferencedBy []string `json:"referenced_by"`
+		TrashID      string   `json:"trash_id"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if result.Blocks != 3 || strings.Join(result.ReferencedBy, ",") != "r1,r2" || result.TrashID == "" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	entries, err := trash.List(dir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected one trash entry, got %v, %v", entries, err)
+	}
+	if e := entries[0]; e.ID != result.TrashID || e.ParentUID != "parent" || e.Order != 3 || e.Refs != 2 || e.Title != "Plan ((b2))" {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+
+	out.Reset()
+	setCmdContext(trashRestoreCmd)
+	if err := runTrashRestore(trashRestoreCmd, []string{result.TrashID}); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if len(actions) != 3 {
+		t.Fatalf("expected three creates, got %v", actions)
+	}
+	wantUIDs := []string{"b1", "b2", "b3"}
+	wantParents := []string{"parent", "b1", "b2"}
+	for i, a := range actions {
+		block := a["block"].(map[string]interface{})
+		loc := a["location"].(map[string]interface{})
+		if block["uid"] != wantUIDs[i] || loc["parent-uid"] != wantParents[i] {
+			t.Fatalf("create %d: %v", i, a)
+		}
+	}
+	if actions[0]["location"].(map[string]interface{})["order"] != 3 || actions[0]["block"].(map[string]interface{})["heading"] != 2 {
+		t.Fatalf("unexpected root restore: %v", actions[0])
+	}
+	if actions[1]["block"].(map[string]interface{})["props"].(map[string]interface{})["k"] != "v" {
+		t.Fatalf("props not restored: %v", actions[1])
+	}
+	if entries, _ := trash.List(dir); len(entries) != 0 {
+		t.Fatalf("expected the entry to be removed, got %v", entries)
+	}
+}
+
+func TestPageDeletePromptShowsImpact(t *testing.T) {
+	dir := withTestTrash(t)
+	deleted := false
+	fake := &fakeClient{
+		GetBlockByUIDFunc: func(uid string) (json.RawMessage, error) {
+			return json.RawMessage(`{"node/title":"Old","block/uid":"pg","block/children":[{"block/uid":"c1","block/string":"x"}]}`), nil
+		},
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			return [][]interface{}{{"r1"}}, nil
+		},
+		DeletePageFunc: func(uid string) error {
+			deleted = true
+			return nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+	_, _, restoreCtx := withTestContext(t, output.FormatText, false)
+	defer restoreCtx()
+	setCmdContext(pageDeleteCmd)
+	errBuf := &bytes.Buffer{}
+	pageDeleteCmd.SetErr(errBuf)
+	defer pageDeleteCmd.SetErr(nil)
+
+	if err := pageDeleteCmd.RunE(pageDeleteCmd, []string{"pg"}); err != nil {
+		t.Fatalf("page delete failed: %v", err)
+	}
+	if deleted {
+		t.Fatal("expected delete to be skipped without confirmation")
+	}
+	for _, want := range []string{"Page: Old", "Blocks on the page: 1", "Referenced by 1 other blocks", "Aborted."} {
+		if !strings.Contains(errBuf.String(), want) {
+			t.Fatalf("missing %q in:\n%s", want, errBuf.String())
+		}
+	}
+	if entries, _ := trash.List(dir); len(entries) != 0 {
+		t.Fatalf("nothing should be trashed when aborted: %v", entries)
+	}
+}
+
+func TestTrashRestoreRefusesExistingUID(t *testing.T) {
+	dir := withTestTrash(t)
+	entry := &trash.Entry{ID: "x-pg", Kind: trash.KindPage, UID: "pg", Title: "Old", Data: json.RawMessage(`{"node/title":"Old","block/uid":"pg"}`)}
+	if err := trash.Save(dir, entry); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	fake := &fakeClient{
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			return [][]interface{}{{"pg"}}, nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+	_, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(trashRestoreCmd)
+
+	if err := runTrashRestore(trashRestoreCmd, []string{"x-pg"}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected an existing uid error, got %v", err)
+	}
+}
+
+func TestBlockDeleteFailsWhenPullFails(t *testing.T) {
+	dir := withTestTrash(t)
+	var deleted []string
+	pulls := 0
+	fake := &fakeClient{
+		GetBlockByUIDFunc: func(uid string) (json.RawMessage, error) {
+			pulls++
+			return nil, errors.New("pull timed out")
+		},
+		DeleteBlockFunc: func(uid string) error {
+			deleted = append(deleted, uid)
+			return nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(blockDeleteCmd)
+
+	err := runBlockDelete(blockDeleteCmd, []string{"b1"})
+	if err == nil || !strings.Contains(err.Error(), "pull timed out") || !strings.Contains(err.Error(), "--no-trash") {
+		t.Fatalf("expected the pull error, got %v", err)
+	}
+	if len(deleted) != 0 {
+		t.Fatalf("deleted without a trash entry: %v", deleted)
+	}
+
+	// --no-trash deletes without pulling the block at all.
+	blockDeleteNoTrash = true
+	defer func() { blockDeleteNoTrash = false }()
+	if err := runBlockDelete(blockDeleteCmd, []string{"b2"}); err != nil {
+		t.Fatalf("block delete failed: %v", err)
+	}
+	if pulls != 1 || strings.Join(deleted, ",") != "b2" {
+		t.Fatalf("unexpected pulls %d and deletes %v", pulls, deleted)
+	}
+	if strings.Contains(out.String(), "trash_id") {
+		t.Fatalf("reported a trash entry: %s", out.String())
+	}
+	if entries, _ := trash.List(dir); len(entries) != 0 {
+		t.Fatalf("expected no trash entries, got %v", entries)
+	}
+}
+
+func TestBlockDeleteSavesTrashWhenRefsFail(t *testing.T) {
+	dir := withTestTrash(t)
+	var deleted []string
+	fake := &fakeClient{
+		GetBlockByUIDFunc: func(uid string) (json.RawMessage, error) {
+			return json.RawMessage(`{":block/uid":"b1",":block/string":"hello"}`), nil
+		},
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			if len(args) > 0 {
+				return nil, errors.New("query timed out")
+			}
+			return [][]interface{}{{"p1", 0}}, nil
+		},
+		DeleteBlockFunc: func(uid string) error {
+			deleted = append(deleted, uid)
+			return nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+	_, _, restoreCtx := withTestContext(t, output.FormatText, false)
+	defer restoreCtx()
+	setCmdContext(blockDeleteCmd)
+	errBuf := &bytes.Buffer{}
+	blockDeleteCmd.SetErr(errBuf)
+	defer blockDeleteCmd.SetErr(nil)
+
+	if err := runBlockDelete(blockDeleteCmd, []string{"b1"}); err != nil {
+		t.Fatalf("block delete failed: %v", err)
+	}
+	if !strings.Contains(errBuf.String(), "query timed out") || !strings.Contains(errBuf.String(), "unknown number") {
+		t.Fatalf("expected an unknown impact, got %q", errBuf.String())
+	}
+
+	_, _, restoreYes := withTestContext(t, output.FormatJSON, true)
+	defer restoreYes()
+	setCmdContext(blockDeleteCmd)
+	if err := runBlockDelete(blockDeleteCmd, []string{"b1"}); err != nil {
+		t.Fatalf("block delete failed: %v", err)
+	}
+	entries, _ := trash.List(dir)
+	if strings.Join(deleted, ",") != "b1" || len(entries) != 1 || entries[0].Refs != trash.RefsUnknown {
+		t.Fatalf("expected a delete with a saved entry, got %v and %v", deleted, entries)
+	}
+}
+
+func TestPageDeleteRestoresViewTypeAndProps(t *testing.T) {
+	withTestTrash(t)
+	var actions []map[string]interface{}
+	fake := &fakeClient{
+		GetBlockByUIDFunc: func(uid string) (json.RawMessage, error) {
+			return json.RawMessage(`{"node/title":"Steps","block/uid":"pg","children/view-type":":numbered","block/props":{"k":"v"},
+				"block/children":[{"block/uid":"c1","block/string":"one"}]}`), nil
+		},
+		QueryFunc: func(query string, args ...interface{}) ([][]interface{}, error) {
+			return nil, nil
+		},
+		DeletePageFunc: func(uid string) error { return nil },
+		ExecuteBatchFunc: func(b *api.BatchBuilder) error {
+			actions = b.Build()
+			return nil
+		},
+	}
+	restoreClient := withTestClient(t, fake)
+	defer restoreClient()
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(pageDeleteCmd)
+
+	if err := pageDeleteCmd.RunE(pageDeleteCmd, []string{"pg"}); err != nil {
+		t.Fatalf("page delete failed: %v", err)
+	}
+	var result struct {
+		TrashID string `json:"trash_id"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+
+	setCmdContext(trashRestoreCmd)
+	if err := runTrashRestore(trashRestoreCmd, []string{result.TrashID}); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if len(actions) != 3 {
+		t.Fatalf("expected page, props and block actions, got %v", actions)
+	}
+	if page := actions[0]["page"].(map[string]interface{}); page["uid"] != "pg" || page["children-view-type"] != "numbered" {
+		t.Fatalf("unexpected page restore: %v", actions[0])
+	}
+	block := actions[1]["block"].(map[string]interface{})
+	if actions[1]["action"] != "update-block" || block["uid"] != "pg" || block["props"].(map[string]interface{})["k"] != "v" {
+		t.Fatalf("page props not restored: %v", actions[1])
+	}
+}
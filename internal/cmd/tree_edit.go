@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/output"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+	"github.com/salmonumbrella/roam-cli/internal/treediff"
+)
+
+// TreeEditResult is the result of 'page edit' and 'block edit'.
+type TreeEditResult struct {
+	UID     string         `json:"uid"`
+	Title   string         `json:"title,omitempty"`
+	DryRun  bool           `json:"dry_run,omitempty"`
+	Applied bool           `json:"applied"`
+	Ops     []treediff.Op  `json:"operations"`
+	Counts  map[string]int `json:"counts"`
+}
+
+var treeEditDryRun bool
+
+const treeEditHelp = `The tree is written to a temporary file as an outline, one "- " item per
+block, indented two spaces per level, and opened in $VISUAL or $EDITOR (vi,
+or notepad on Windows, if neither is set). Each item ends with a hidden
+<!-- uid:... --> marker.
+Headings are written as "# " prefixes.
+
+Edit, reorder, indent and outdent items freely, add new items without a
+marker and delete lines to delete blocks. On save the outline is compared
+with the tree: items are matched to blocks by their marker, or by similar
+content when a marker was lost, and the fewest updates, moves, creates and
+deletes that produce the new outline are shown for confirmation and sent
+in one batch. Blocks that keep their marker keep their uid, so refs to them
+keep working.
+
+Saving an empty file, or quitting without changes, cancels the edit.`
+
+var pageEditCmd = &cobra.Command{
+	Use:   "edit <title>",
+	Short: "Edit a page's blocks in $EDITOR",
+	Long: `Edit the blocks of a page as text in your editor.
+
+` + treeEditHelp,
+	Example: `  roam page edit "Project Alpha"
+  EDITOR="code --wait" roam page edit "Reading List"
+  roam page edit "Project Alpha" --dry-run -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPageEdit,
+}
+
+var blockEditCmd = &cobra.Command{
+	Use:   "edit <uid>",
+	Short: "Edit a block and its children in $EDITOR",
+	Long: `Edit a block and its children as text in your editor.
+
+The block is the single top-level item of the outline; its children are
+nested under it.
+
+` + treeEditHelp,
+	Example: `  roam block edit abc123def
+  roam block edit abc123def --yes`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBlockEdit,
+}
+
+func init() {
+	pageCmd.AddCommand(pageEditCmd)
+	blockCmd.AddCommand(blockEditCmd)
+	for _, c := range []*cobra.Command{pageEditCmd, blockEditCmd} {
+		c.Flags().BoolVar(&treeEditDryRun, "dry-run", false, "Show the changes without applying them")
+	}
+}
+
+func runPageEdit(cmd *cobra.Command, args []string) error {
+	client := GetClient()
+	raw, err := client.GetPageByTitle(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get page: %w", err)
+	}
+	page, err := roamdb.ParsePage(raw)
+	if err != nil {
+		return fmt.Errorf("failed to parse page: %w", err)
+	}
+
+	old := treediff.FromBlocks(page.Children)
+	header := fmt.Sprintf("Editing page %q.", page.Title)
+	edited, ok, err := editOutline(header, old)
+	if err != nil {
+		return err
+	}
+	result := TreeEditResult{UID: page.UID, Title: page.Title}
+	if ok {
+		result.Ops = treediff.Diff(page.UID, old, edited)
+	}
+	return applyTreeEdit(cmd, client, result)
+}
+
+func runBlockEdit(cmd *cobra.Command, args []string) error {
+	client := GetClient()
+	raw, err := client.GetBlockByUID(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get block: %w", err)
+	}
+	block, err := roamdb.ParseBlock(raw)
+	if err != nil {
+		return fmt.Errorf("failed to parse block data: %w", err)
+	}
+
+	root := treediff.FromBlocks([]roamdb.Block{*block})[0]
+	header := fmt.Sprintf("Editing block %s.", block.UID)
+	edited, ok, err := editOutline(header, []*treediff.Node{root})
+	if err != nil {
+		return err
+	}
+	result := TreeEditResult{UID: block.UID}
+	if !ok {
+		return applyTreeEdit(cmd, client, result)
+	}
+	if len(edited) != 1 || edited[0].UID != block.UID {
+		return fmt.Errorf("the outline must keep block %s as its single top-level item", block.UID)
+	}
+
+	if edited[0].String != strings.TrimRight(block.String, "\n") || edited[0].Heading != block.Heading {
+		result.Ops = append(result.Ops, treediff.Op{
+			Kind: treediff.OpUpdate, UID: block.UID, String: edited[0].String, Heading: edited[0].Heading, Old: block.String,
+		})
+	}
+	result.Ops = append(result.Ops, treediff.Diff(block.UID, root.Children, edited[0].Children)...)
+	return applyTreeEdit(cmd, client, result)
+}
+
+// editOutline opens the outline of nodes in the editor and parses the
+// result. ok is false when the edit was cancelled or left the file as it
+// was.
+func editOutline(header string, nodes []*treediff.Node) ([]*treediff.Node, bool, error) {
+	text := "<!-- " + strings.ReplaceAll(header, "-->", "-- >") + "\n" +
+		"     Keep the uid markers to keep blocks; items without one are created\n" +
+		"     and deleted lines are deleted. Save an empty file to cancel. -->\n" +
+		treediff.Format(nodes)
+
+	f, err := os.CreateTemp("", "roam-edit-*.md")
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	path := f.Name()
+	defer os.Remove(path)
+	_, err = f.WriteString(text)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	if err := runEditor(path); err != nil {
+		return nil, false, fmt.Errorf("editor failed: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read edited file: %w", err)
+	}
+	if string(data) == text || strings.TrimSpace(string(data)) == "" {
+		return nil, false, nil
+	}
+	edited, err := treediff.Parse(string(data))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to parse edited outline: %w", err)
+	}
+	if len(edited) == 0 {
+		return nil, false, nil
+	}
+	return edited, true, nil
+}
+
+// applyTreeEdit shows the ops of an edit, asks for confirmation and runs
+// them as one batch.
+func applyTreeEdit(cmd *cobra.Command, client api.RoamAPI, result TreeEditResult) error {
+	result.DryRun = treeEditDryRun
+	result.Counts = map[string]int{}
+	for _, op := range result.Ops {
+		result.Counts[op.Kind]++
+	}
+	errOut := stderrFromContext(cmd.Context())
+
+	if len(result.Ops) > 0 && !treeEditDryRun {
+		if !structuredOutputRequested() || !output.YesFromContext(cmd.Context()) {
+			printTreeEditOps(errOut, result.Ops)
+		}
+		if !output.YesFromContext(cmd.Context()) {
+			fmt.Fprintf(errOut, "Apply %d changes? [y/N]: ", len(result.Ops))
+			answer, _ := bufio.NewReader(stdinFromContext(cmd.Context())).ReadString('\n')
+			if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+				fmt.Fprintln(errOut, "Aborted.")
+				return nil
+			}
+		}
+
+		batch := api.NewBatchBuilder()
+		for _, op := range result.Ops {
+			addTreeEditOp(batch, op)
+		}
+		if err := client.ExecuteBatch(batch); err != nil {
+			return fmt.Errorf("failed to apply edit: %w", err)
+		}
+		result.Applied = true
+	}
+
+	if structuredOutputRequested() {
+		return printStructured(result)
+	}
+	w := stdoutFromContext(cmd.Context())
+	switch {
+	case len(result.Ops) == 0:
+		fmt.Fprintln(w, "No changes.")
+	case treeEditDryRun:
+		printTreeEditOps(w, result.Ops)
+	default:
+		fmt.Fprintf(w, "Applied %d updates, %d moves, %d creates and %d deletes\n",
+			result.Counts[treediff.OpUpdate], result.Counts[treediff.OpMove], result.Counts[treediff.OpCreate], result.Counts[treediff.OpDelete])
+	}
+	return nil
+}
+
+func addTreeEditOp(batch *api.BatchBuilder, op treediff.Op) {
+	heading := op.Heading
+	switch op.Kind {
+	case treediff.OpCreate:
+		opts := api.BlockOptions{Content: op.String, UID: op.UID}
+		if heading > 0 {
+			opts.Heading = &heading
+		}
+		batch.CreateBlock(api.Location{ParentUID: op.Parent, Order: op.Order}, opts)
+	case treediff.OpUpdate:
+		batch.UpdateBlock(op.UID, api.BlockOptions{Content: op.String, Heading: &heading})
+	case treediff.OpMove:
+		batch.MoveBlock(op.UID, api.Location{ParentUID: op.Parent, Order: op.Order})
+	case treediff.OpDelete:
+		batch.DeleteBlock(op.UID)
+	}
+}
+
+func printTreeEditOps(w io.Writer, ops []treediff.Op) {
+	for _, op := range ops {
+		switch op.Kind {
+		case treediff.OpCreate:
+			fmt.Fprintf(w, "  + create %s under %s at %d: %s\n", op.UID, op.Parent, op.Order, previewLine(op.String, 60))
+		case treediff.OpUpdate:
+			fmt.Fprintf(w, "  ~ update %s: %s -> %s\n", op.UID, previewLine(op.Old, 40), previewLine(op.String, 40))
+		case treediff.OpMove:
+			fmt.Fprintf(w, "  > move   %s under %s at %d\n", op.UID, op.Parent, op.Order)
+		case treediff.OpDelete:
+			fmt.Fprintf(w, "  - delete %s: %s\n", op.UID, previewLine(op.Old, 60))
+		}
+	}
+}
+
+// previewLine shortens a block string to one line of at most n characters.
+func previewLine(s string, n int) string {
+	return truncateString(strings.Join(strings.Fields(s), " "), n)
+}
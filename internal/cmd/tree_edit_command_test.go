@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/salmonumbrella/roam-cli/internal/api"
+	"github.com/salmonumbrella/roam-cli/internal/output"
+)
+
+// withTestEditor replaces the editor with one that rewrites the file.
+func withTestEditor(t *testing.T, edit func(text string) string) func() {
+	t.Helper()
+	prev := runEditor
+	runEditor = func(path string) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, []byte(edit(string(data))), 0o600)
+	}
+	return func() { runEditor = prev }
+}
+
+func TestPageEditAppliesTreeDiff(t *testing.T) {
+	var actions []map[string]interface{}
+	client := &fakeClient{
+		GetPageByTitleFunc: func(title string) (json.RawMessage, error) {
+			return json.RawMessage(`{"node/title":"Plan","block/uid":"page1","block/children":[
+				{"block/uid":"a","block/string":"first","block/order":0},
+				{"block/uid":"b","block/string":"second","block/order":1,"block/children":[
+					{"block/uid":"c","block/string":"nested","block/order":0}]}]}`), nil
+		},
+		ExecuteBatchFunc: func(b *api.BatchBuilder) error {
+			actions = b.Build()
+			return nil
+		},
+	}
+	restoreClient := withTestClient(t, client)
+	defer restoreClient()
+	out, _, restoreCtx := withTestContext(t, output.FormatJSON, true)
+	defer restoreCtx()
+	setCmdContext(pageEditCmd)
+
+	var shown string
+	restoreEditor := withTestEditor(t, func(text string) string {
+		shown = text
+		return "- ## second <!-- uid:b -->\n- first <!-- uid:a -->\n  - added\n"
+	})
+	defer restoreEditor()
+
+	if err := runPageEdit(pageEditCmd, []string{"Plan"}); err != nil {
+		t.Fatalf("page edit failed: %v", err)
+	}
+	if !strings.Contains(shown, "  - nested <!-- uid:c -->\n") {
+		t.Fatalf("unexpected outline:\n%s", shown)
+	}
+
+	var result TreeEditResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	if !result.Applied || result.Counts["move"] != 1 || result.Counts["update"] != 1 || result.Counts["create"] != 1 || result.Counts["delete"] != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(actions) != 4 {
+		t.Fatalf("expected 4 actions, got %v", actions)
+	}
+	update := actions[1]["block"].(map[string]interface{})
+	if actions[1]["action"] != "update-block" || update["uid"] != "b" || update["heading"] != 2 {
+		t.Fatalf("unexpected update: %v", actions[1])
+	}
+	if actions[3]["action"] != "delete-block" || actions[3]["block"].(map[string]interface{})["uid"] != "c" {
+		t.Fatalf("unexpected delete: %v", actions[3])
+	}
+}
+
+func TestBlockEditKeepsRoot(t *testing.T) {
+	client := &fakeClient{
+		GetBlockByUIDFunc: func(uid string) (json.RawMessage, error) {
+			return json.RawMessage(`{"block/uid":"r","block/string":"root","block/children":[{"block/uid":"k","block/string":"kid"}]}`), nil
+		},
+		ExecuteBatchFunc: func(b *api.BatchBuilder) error {
+			t.Fatal("no batch expected")
+			return nil
+		},
+	}
+	restoreClient := withTestClient(t, client)
+	defer restoreClient()
+	out, _, restoreCtx := withTestContext(t, output.FormatText, true)
+	defer restoreCtx()
+	setCmdContext(blockEditCmd)
+
+	restoreEditor := withTestEditor(t, func(text string) string { return "- root <!-- uid:r -->\n- sibling\n" })
+	if err := runBlockEdit(blockEditCmd, []string{"r"}); err == nil {
+		t.Fatal("expected an error for a second top-level item")
+	}
+	restoreEditor()
+
+	restoreEditor = withTestEditor(t, func(text string) string { return text })
+	defer restoreEditor()
+	if err := runBlockEdit(blockEditCmd, []string{"r"}); err != nil {
+		t.Fatalf("block edit failed: %v", err)
+	}
+	if strings.TrimSpace(out.String()) != "No changes." {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+func TestPreviewLineKeepsRunesWhole(t *testing.T) {
+	got := previewLine("Café  notes\nüber Zürich 東京", 16)
+	if got != "Café notes üb..." {
+		t.Fatalf("unexpected preview %q", got)
+	}
+	if !utf8.ValidString(got) {
+		t.Fatalf("preview split a rune: %q", got)
+	}
+	if got := previewLine("東京タワー", 5); got != "東京タワー" {
+		t.Fatalf("expected short text kept, got %q", got)
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	cmd := editorCommand("windows", "code --wait", `C:\Temp\page.md`)
+	if got := strings.Join(cmd.Args, "|"); got != `cmd|/C|code|--wait|C:\Temp\page.md` {
+		t.Fatalf("unexpected windows command: %s", got)
+	}
+	if cmd := editorCommand("windows", "", "page.md"); strings.Join(cmd.Args, "|") != "cmd|/C|notepad|page.md" {
+		t.Fatalf("unexpected windows default: %v", cmd.Args)
+	}
+	if cmd := editorCommand("linux", "", "page.md"); strings.Join(cmd.Args, "|") != `sh|-c|vi "$1"|sh|page.md` {
+		t.Fatalf("unexpected default: %v", cmd.Args)
+	}
+}
@@ -18,6 +18,7 @@ type Config struct {
 	Token          string `yaml:"token,omitempty"`
 	KeyringBackend string `yaml:"keyring_backend,omitempty"` // auto, keychain, file
 	OutputFormat   string `yaml:"output_format,omitempty"`   // text, json, yaml, table
+	UploadURL      string `yaml:"upload_url,omitempty"`      // file upload endpoint for cloud graphs
 }
 
 // ConfigDir returns the config directory path
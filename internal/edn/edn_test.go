@@ -0,0 +1,255 @@
+package edn
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseValues(t *testing.T) {
+	v, err := Parse(`{:block/uid "a\"b\n" :n 42 :f -1.5e3 :ok true :none nil
+	  :v [1 2 #{:x}] :l (pull ?e [*]) :c \newline
+	  :t #inst "2024-01-02T03:04:05Z" :id #uuid "6a1f8bd2-6c7e-4a5c-9a53-1d3e0c2b4f11"
+	  :tag #my/tag [1] ; comment
+	  #_ :discarded}`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	m := v.(*Map)
+	want := map[Keyword]interface{}{
+		"block/uid": "a\"b\n",
+		"n":         int64(42),
+		"f":         -1500.0,
+		"ok":        true,
+		"none":      nil,
+		"c":         Char('\n'),
+		"t":         time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		"id":        UUID("6a1f8bd2-6c7e-4a5c-9a53-1d3e0c2b4f11"),
+	}
+	for k, w := range want {
+		got, ok := m.Get(k)
+		if !ok {
+			t.Fatalf("missing key %s", k)
+		}
+		if gt, isTime := got.(time.Time); isTime {
+			if !gt.Equal(w.(time.Time)) {
+				t.Fatalf("%s = %v, want %v", k, got, w)
+			}
+			continue
+		}
+		if got != w {
+			t.Fatalf("%s = %#v, want %#v", k, got, w)
+		}
+	}
+	if m.Len() != 11 {
+		t.Fatalf("expected 11 entries, got %d", m.Len())
+	}
+	vec, _ := m.Get(Keyword("v"))
+	if got := Marshal(vec); got != `[1 2 #{:x}]` {
+		t.Fatalf("unexpected vector %s", got)
+	}
+	list, _ := m.Get(Keyword("l"))
+	if got := Marshal(list); got != `(pull ?e [*])` {
+		t.Fatalf("unexpected list %s", got)
+	}
+	tagged, _ := m.Get(Keyword("tag"))
+	if tv, ok := tagged.(Tagged); !ok || tv.Tag != "my/tag" {
+		t.Fatalf("unexpected tagged value %#v", tagged)
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	values, err := ParseAll(":a 1 [2] ")
+	if err != nil || len(values) != 3 {
+		t.Fatalf("unexpected values %v %v", values, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		in        string
+		line, col int
+		msg       string
+	}{
+		{"[:a\n :b", 1, 1, "unclosed '['"},
+		{`{:a 1 :b}`, 1, 1, "even number"},
+		{"[:a]]", 1, 5, "unexpected input"},
+		{`"abc`, 1, 1, "unterminated string"},
+		{`["\q"]`, 1, 3, `invalid escape \q`},
+		{"[1 2 }", 1, 6, "unexpected '}'"},
+		{`{:a 1 :a 2}`, 1, 7, "duplicate map key :a"},
+		{"[#inst 5]", 1, 8, "#inst expects a string"},
+		{"[:]", 1, 2, "invalid keyword"},
+		{"", 1, 1, "empty input"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.in)
+		var ee *Error
+		if !errors.As(err, &ee) {
+			t.Fatalf("Parse(%q): expected *Error, got %v", tt.in, err)
+		}
+		if ee.Line != tt.line || ee.Column != tt.col || !strings.Contains(ee.Msg, tt.msg) {
+			t.Errorf("Parse(%q): got %d:%d %q, want %d:%d %q", tt.in, ee.Line, ee.Column, ee.Msg, tt.line, tt.col, tt.msg)
+		}
+	}
+}
+
+func TestErrorShowsLocation(t *testing.T) {
+	_, err := Parse("[:find ?e\n :where [?e :a}]")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	want := "line 2, column 15: unexpected '}'\n   :where [?e :a}]\n                ^"
+	if err.Error() != want {
+		t.Fatalf("unexpected error:\n%s\nwant:\n%s", err, want)
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	inputs := []string{
+		`[* {:block/children ...}]`,
+		`{:a "x\\y\"z\n" :b [1 2.5 -3] :c #{:d} :e nil :f true}`,
+		`(pull ?e [:block/string {[:block/children :limit 5] 2}])`,
+		`#inst "2024-01-02T03:04:05.5Z"`,
+		`#uuid "6a1f8bd2-6c7e-4a5c-9a53-1d3e0c2b4f11"`,
+		`[\a \space 1.0 ##NaN]`,
+		`#roam/page "x"`,
+	}
+	for _, in := range inputs {
+		v, err := Parse(in)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", in, err)
+		}
+		if got := Marshal(v); got != in {
+			t.Errorf("Marshal(Parse(%q)) = %q", in, got)
+		}
+		again, err := Parse(Pretty(v))
+		if err != nil || Marshal(again) != in {
+			t.Errorf("Pretty(%q) did not round-trip: %v", in, err)
+		}
+	}
+}
+
+func TestPretty(t *testing.T) {
+	v, _ := FromJSON([]byte(`{"uid":"abc","string":"a fairly long block string that will not fit","children":[{"uid":"c1","string":"short"}]}`))
+	got := Pretty(v)
+	want := `{:children [{:string "short" :uid "c1"}]
+ :string "a fairly long block string that will not fit"
+ :uid "abc"}`
+	if got != want {
+		t.Fatalf("unexpected pretty output:\n%s", got)
+	}
+}
+
+func TestFromJSON(t *testing.T) {
+	v, err := FromJSON([]byte(`{":block/uid":"x",":block/order":2,"edit time":1.5,"refs":[{":db/id":7}]}`))
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	if got := Marshal(v); got != `{:block/order 2 :block/uid "x" "edit time" 1.5 :refs [{:db/id 7}]}` {
+		t.Fatalf("unexpected conversion %s", got)
+	}
+	if got := Marshal(map[string]interface{}{"n": math.Inf(1)}); got != `{:n ##Inf}` {
+		t.Fatalf("unexpected map conversion %s", got)
+	}
+	type row struct {
+		UID string `json:"uid"`
+	}
+	if got := Marshal([]row{{UID: "a"}}); got != `[{:uid "a"}]` {
+		t.Fatalf("unexpected struct conversion %s", got)
+	}
+}
+
+func TestValidatePull(t *testing.T) {
+	valid := []string{
+		"[*]",
+		`[:block/string :block/uid {:block/children ...}]`,
+		`[* {:block/children 3} {:block/_refs [:block/uid]}]`,
+		`[[:block/string :as "s"] {[:block/children :limit 2] [:block/uid]}]`,
+		`[(limit :block/children 5) (default :block/heading 0)]`,
+		`[(:block/string :as "s")]`,
+		`[{(:block/children :limit 5) [*]}]`,
+	}
+	for _, p := range valid {
+		if err := ValidatePull(p); err != nil {
+			t.Errorf("ValidatePull(%q): %v", p, err)
+		}
+	}
+
+	invalid := []struct {
+		in, msg string
+		col     int
+	}{
+		{":block/uid", "must be a vector", 1},
+		{"[]", "pattern is empty", 1},
+		{"[block/uid]", "unexpected block/uid", 2},
+		{"[{:block/children 0}]", "recursion limit", 19},
+		{"[{:block/children}]", "even number", 2},
+		{"[[:block/children :lim 2]]", "unknown attribute option :lim", 19},
+		{"[* {:block/children [x]}]", "unexpected x", 22},
+		{"[(:block/children :lim 2)]", "unknown attribute option :lim", 19},
+		{"[(block/children :x 2)]", "unknown attribute function block/children", 3},
+	}
+	for _, tt := range invalid {
+		err := ValidatePull(tt.in)
+		var ee *Error
+		if !errors.As(err, &ee) || !strings.Contains(ee.Msg, tt.msg) || ee.Column != tt.col {
+			t.Errorf("ValidatePull(%q) = %v, want %q at column %d", tt.in, err, tt.msg, tt.col)
+		}
+	}
+}
+
+func TestValidateQuery(t *testing.T) {
+	valid := []string{
+		`[:find ?title :where [?e :node/title ?title]]`,
+		`[:find ?uid ?s :in $ [?uid ...] :where [?b :block/uid ?uid] [?b :block/string ?s]]`,
+		`[:find (pull ?e [*]) . :in $ ?t :where [?e :node/title ?t]]`,
+		`[:find (count ?b) :where [?b :block/string _] (not [?b :block/heading 1])]`,
+		`{:find [?e] :where [[?e :node/title]]}`,
+		`[:find ?e]`,
+	}
+	for _, q := range valid {
+		if err := ValidateQuery(q); err != nil {
+			t.Errorf("ValidateQuery(%q): %v", q, err)
+		}
+	}
+
+	invalid := []struct {
+		in, msg string
+		col     int
+	}{
+		{`[:where [?e :node/title]]`, "must start with :find", 2},
+		{`[:find ?e :whre [?e :node/title]]`, "unknown query clause :whre", 11},
+		{`[:find :where [?e :node/title]]`, ":find needs at least one variable", 2},
+		{`[:find ?t :where [?e :node/title ?title]]`, "?t in :find is not bound", 8},
+		{`[:find ?e :where ?e]`, ":where clauses must be vectors", 18},
+		{`[:find e :where [?e :node/title]]`, "variables start with ?", 8},
+		{`[:find (pull ?e [foo]) :where [?e :node/title]]`, "unexpected foo", 18},
+		{`[:find ?e :where [?e :node/title]`, "unclosed '['", 1},
+	}
+	for _, tt := range invalid {
+		err := ValidateQuery(tt.in)
+		var ee *Error
+		if !errors.As(err, &ee) || !strings.Contains(ee.Msg, tt.msg) || ee.Column != tt.col {
+			t.Errorf("ValidateQuery(%q) = %v, want %q at column %d", tt.in, err, tt.msg, tt.col)
+		}
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	v, err := Parse(`{:block/uid "x" :children/view-type :numbered :block/props {:tags #{"a"}} "k" 1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(ToJSON(v))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{":block/props":{":tags":["a"]},":block/uid":"x",":children/view-type":":numbered","k":1}`
+	if string(data) != want {
+		t.Fatalf("unexpected conversion %s", data)
+	}
+}
@@ -0,0 +1,465 @@
+package edn
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Error reports a syntax or validation error and where it occurred.
+type Error struct {
+	Input string
+	// Offset is the byte offset of the error; Line and Column are 1-based,
+	// with Column counting runes.
+	Offset int
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	lines := strings.Split(e.Input, "\n")
+	if e.Line < 1 || e.Line > len(lines) {
+		return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+	}
+	caret := strings.Repeat(" ", max(e.Column-1, 0)) + "^"
+	return fmt.Sprintf("line %d, column %d: %s\n  %s\n  %s", e.Line, e.Column, e.Msg, lines[e.Line-1], caret)
+}
+
+func newError(input string, offset int, format string, args ...interface{}) *Error {
+	offset = min(max(offset, 0), len(input))
+	before := input[:offset]
+	line := strings.Count(before, "\n") + 1
+	col := utf8.RuneCountInString(before[strings.LastIndexByte(before, '\n')+1:]) + 1
+	return &Error{Input: input, Offset: offset, Line: line, Column: col, Msg: fmt.Sprintf(format, args...)}
+}
+
+// node is a value with the span it was read from, kept for validation
+// errors that point into the source.
+type node struct {
+	value interface{}
+	start int
+	end   int
+	// items are the elements of collections (map keys and values
+	// alternate) and the value of tagged literals.
+	items []*node
+}
+
+// Parse reads exactly one EDN value from s.
+func Parse(s string) (interface{}, error) {
+	n, err := parseOne(s)
+	if err != nil {
+		return nil, err
+	}
+	return n.value, nil
+}
+
+// ParseAll reads every top-level value in s.
+func ParseAll(s string) ([]interface{}, error) {
+	r := &reader{src: s}
+	var values []interface{}
+	for {
+		if err := r.skipSpace(); err != nil {
+			return nil, err
+		}
+		if r.off >= len(r.src) {
+			return values, nil
+		}
+		n, err := r.read()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, n.value)
+	}
+}
+
+func parseOne(s string) (*node, error) {
+	r := &reader{src: s}
+	if err := r.skipSpace(); err != nil {
+		return nil, err
+	}
+	if r.off >= len(s) {
+		return nil, r.errorf(r.off, "empty input")
+	}
+	n, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.skipSpace(); err != nil {
+		return nil, err
+	}
+	if r.off < len(s) {
+		return nil, r.errorf(r.off, "unexpected input after the value")
+	}
+	return n, nil
+}
+
+type reader struct {
+	src string
+	off int
+}
+
+func (r *reader) errorf(offset int, format string, args ...interface{}) *Error {
+	return newError(r.src, offset, format, args...)
+}
+
+// skipSpace skips whitespace, commas, ; comments and #_ discarded forms.
+func (r *reader) skipSpace() error {
+	for r.off < len(r.src) {
+		c := r.src[r.off]
+		switch {
+		case c == ',' || c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			r.off++
+		case c == ';':
+			for r.off < len(r.src) && r.src[r.off] != '\n' {
+				r.off++
+			}
+		case c == '#' && strings.HasPrefix(r.src[r.off:], "#_"):
+			r.off += 2
+			if err := r.skipSpace(); err != nil {
+				return err
+			}
+			if _, err := r.read(); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *reader) read() (*node, error) {
+	start := r.off
+	if start >= len(r.src) {
+		return nil, r.errorf(start, "unexpected end of input")
+	}
+	switch c := r.src[start]; c {
+	case '[':
+		return r.collection(start, 1, ']', func(items []interface{}) interface{} { return Vector(items) })
+	case '(':
+		return r.collection(start, 1, ')', func(items []interface{}) interface{} { return List(items) })
+	case '{':
+		return r.mapLiteral(start)
+	case ']', ')', '}':
+		return nil, r.errorf(start, "unexpected '%c'", c)
+	case '"':
+		return r.stringLiteral(start)
+	case '\\':
+		return r.char(start)
+	case '#':
+		return r.dispatch(start)
+	}
+	return r.atom(start)
+}
+
+func (r *reader) collection(start, skip int, close byte, build func([]interface{}) interface{}) (*node, error) {
+	r.off = start + skip
+	n := &node{start: start}
+	for {
+		if err := r.skipSpace(); err != nil {
+			return nil, err
+		}
+		if r.off >= len(r.src) {
+			return nil, r.errorf(start, "unclosed '%s' (expected '%c')", r.src[start:start+skip], close)
+		}
+		if r.src[r.off] == close {
+			r.off++
+			break
+		}
+		item, err := r.read()
+		if err != nil {
+			return nil, err
+		}
+		n.items = append(n.items, item)
+	}
+	values := make([]interface{}, len(n.items))
+	for i, item := range n.items {
+		values[i] = item.value
+	}
+	n.value = build(values)
+	n.end = r.off
+	return n, nil
+}
+
+func (r *reader) mapLiteral(start int) (*node, error) {
+	n, err := r.collection(start, 1, '}', func(items []interface{}) interface{} { return items })
+	if err != nil {
+		return nil, err
+	}
+	if len(n.items)%2 != 0 {
+		return nil, r.errorf(start, "map literal must contain an even number of forms")
+	}
+	m := &Map{}
+	for i := 0; i < len(n.items); i += 2 {
+		key := n.items[i].value
+		if _, dup := m.Get(key); dup {
+			return nil, r.errorf(n.items[i].start, "duplicate map key %s", Marshal(key))
+		}
+		m.Keys = append(m.Keys, key)
+		m.Vals = append(m.Vals, n.items[i+1].value)
+	}
+	n.value = m
+	return n, nil
+}
+
+func (r *reader) stringLiteral(start int) (*node, error) {
+	var sb strings.Builder
+	for i := start + 1; i < len(r.src); {
+		c := r.src[i]
+		switch c {
+		case '"':
+			r.off = i + 1
+			return &node{value: sb.String(), start: start, end: r.off}, nil
+		case '\\':
+			if i+1 >= len(r.src) {
+				return nil, r.errorf(start, "unterminated string")
+			}
+			switch e := r.src[i+1]; e {
+			case '"', '\\':
+				sb.WriteByte(e)
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'u':
+				if i+6 > len(r.src) {
+					return nil, r.errorf(i, "invalid \\u escape")
+				}
+				code, err := strconv.ParseUint(r.src[i+2:i+6], 16, 32)
+				if err != nil {
+					return nil, r.errorf(i, "invalid \\u escape")
+				}
+				sb.WriteRune(rune(code))
+				i += 4
+			default:
+				return nil, r.errorf(i, "invalid escape \\%c in string", e)
+			}
+			i += 2
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+	return nil, r.errorf(start, "unterminated string")
+}
+
+var namedChars = map[string]rune{
+	"newline":   '\n',
+	"space":     ' ',
+	"tab":       '\t',
+	"return":    '\r',
+	"formfeed":  '\f',
+	"backspace": '\b',
+}
+
+func (r *reader) char(start int) (*node, error) {
+	end := start + 1
+	if end >= len(r.src) {
+		return nil, r.errorf(start, "incomplete character literal")
+	}
+	_, size := utf8.DecodeRuneInString(r.src[end:])
+	end += size
+	for end < len(r.src) && !isDelimiter(r.src[end]) {
+		end++
+	}
+	name := r.src[start+1 : end]
+	r.off = end
+	if ch, ok := namedChars[name]; ok {
+		return &node{value: Char(ch), start: start, end: end}, nil
+	}
+	if len(name) == 5 && name[0] == 'u' {
+		if code, err := strconv.ParseUint(name[1:], 16, 32); err == nil {
+			return &node{value: Char(rune(code)), start: start, end: end}, nil
+		}
+	}
+	if ch, size := utf8.DecodeRuneInString(name); size == len(name) {
+		return &node{value: Char(ch), start: start, end: end}, nil
+	}
+	return nil, r.errorf(start, "invalid character literal \\%s", name)
+}
+
+func (r *reader) dispatch(start int) (*node, error) {
+	if strings.HasPrefix(r.src[start:], "#{") {
+		n, err := r.collection(start, 2, '}', func(items []interface{}) interface{} { return Set(items) })
+		if err != nil {
+			return nil, err
+		}
+		seen := Set{}
+		for _, item := range n.items {
+			for _, prev := range seen {
+				if isComparable(prev) && prev == item.value {
+					return nil, r.errorf(item.start, "duplicate set element %s", Marshal(item.value))
+				}
+			}
+			seen = append(seen, item.value)
+		}
+		return n, nil
+	}
+
+	for _, special := range []struct {
+		text  string
+		value float64
+	}{{"##NaN", math.NaN()}, {"##Inf", math.Inf(1)}, {"##-Inf", math.Inf(-1)}} {
+		if strings.HasPrefix(r.src[start:], special.text) {
+			r.off = start + len(special.text)
+			return &node{value: special.value, start: start, end: r.off}, nil
+		}
+	}
+
+	// #tag value
+	end := start + 1
+	for end < len(r.src) && !isDelimiter(r.src[end]) {
+		end++
+	}
+	tag := r.src[start+1 : end]
+	if tag == "" || !unicode.IsLetter(rune(tag[0])) {
+		return nil, r.errorf(start, "invalid dispatch '#%s'", tag)
+	}
+	r.off = end
+	if err := r.skipSpace(); err != nil {
+		return nil, err
+	}
+	inner, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	n := &node{start: start, end: r.off, items: []*node{inner}}
+
+	switch tag {
+	case "inst":
+		s, ok := inner.value.(string)
+		if !ok {
+			return nil, r.errorf(inner.start, "#inst expects a string")
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, r.errorf(inner.start, "invalid #inst timestamp %q", s)
+		}
+		n.value = t
+	case "uuid":
+		s, ok := inner.value.(string)
+		if !ok || !isUUID(s) {
+			return nil, r.errorf(inner.start, "invalid #uuid value")
+		}
+		n.value = UUID(s)
+	default:
+		n.value = Tagged{Tag: tag, Value: inner.value}
+	}
+	return n, nil
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, c := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// atom reads numbers, keywords, symbols, nil, true and false.
+func (r *reader) atom(start int) (*node, error) {
+	end := start
+	for end < len(r.src) && !isDelimiter(r.src[end]) {
+		end++
+	}
+	if end == start {
+		return nil, r.errorf(start, "unexpected '%c'", r.src[start])
+	}
+	tok := r.src[start:end]
+	r.off = end
+	n := &node{start: start, end: end}
+
+	switch {
+	case tok == "nil":
+		n.value = nil
+	case tok == "true":
+		n.value = true
+	case tok == "false":
+		n.value = false
+	case tok[0] == ':':
+		name := tok[1:]
+		if name == "" || name[0] == ':' || !isSymbolName(name) {
+			return nil, r.errorf(start, "invalid keyword %q", tok)
+		}
+		n.value = Keyword(name)
+	case isNumberStart(tok):
+		v, err := parseNumber(tok)
+		if err != nil {
+			return nil, r.errorf(start, "invalid number %q", tok)
+		}
+		n.value = v
+	default:
+		if !isSymbolName(tok) {
+			return nil, r.errorf(start, "invalid symbol %q", tok)
+		}
+		n.value = Symbol(tok)
+	}
+	return n, nil
+}
+
+func isDelimiter(c byte) bool {
+	return strings.IndexByte(" \t\n\r\f,()[]{}\";", c) >= 0
+}
+
+func isNumberStart(tok string) bool {
+	if tok[0] >= '0' && tok[0] <= '9' {
+		return true
+	}
+	return len(tok) > 1 && (tok[0] == '-' || tok[0] == '+') && tok[1] >= '0' && tok[1] <= '9'
+}
+
+func parseNumber(tok string) (interface{}, error) {
+	if strings.HasSuffix(tok, "N") {
+		tok = strings.TrimSuffix(tok, "N")
+		return strconv.ParseInt(tok, 10, 64)
+	}
+	if strings.HasSuffix(tok, "M") {
+		return strconv.ParseFloat(strings.TrimSuffix(tok, "M"), 64)
+	}
+	if strings.ContainsAny(tok, ".eE") {
+		return strconv.ParseFloat(tok, 64)
+	}
+	return strconv.ParseInt(tok, 10, 64)
+}
+
+// isSymbolName reports whether s is a valid symbol or keyword name,
+// optionally with a namespace (ns/name).
+func isSymbolName(s string) bool {
+	if s == "/" {
+		return true
+	}
+	for i, c := range s {
+		switch {
+		case unicode.IsLetter(c) || unicode.IsDigit(c):
+		case strings.ContainsRune(".*+!-_?$%&=<>/'#:", c):
+			if c == '#' && i == 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return !strings.HasSuffix(s, "/") && !strings.HasPrefix(s, "/")
+}
@@ -0,0 +1,321 @@
+package edn
+
+import (
+	"sort"
+	"strings"
+)
+
+// ValidatePull checks that s is a pull pattern: a vector of attribute
+// names, wildcards, attribute expressions and {attr pattern} maps. Errors
+// are *Error values pointing at the offending form.
+func ValidatePull(s string) error {
+	n, err := parseOne(s)
+	if err != nil {
+		return err
+	}
+	return (&validator{src: s}).pattern(n)
+}
+
+// ValidateQuery checks that s is a Datalog query in vector or map form with
+// a :find clause, known clause keywords, well-formed :where clauses and
+// :find variables that :in or :where bind.
+func ValidateQuery(s string) error {
+	n, err := parseOne(s)
+	if err != nil {
+		return err
+	}
+	return (&validator{src: s}).query(n)
+}
+
+type validator struct {
+	src string
+}
+
+func (v *validator) errorf(n *node, format string, args ...interface{}) error {
+	return newError(v.src, n.start, format, args...)
+}
+
+func (v *validator) pattern(n *node) error {
+	if _, ok := n.value.(Vector); !ok {
+		return v.errorf(n, "pull pattern must be a vector, like [*] or [:block/string :block/uid]")
+	}
+	if len(n.items) == 0 {
+		return v.errorf(n, "pull pattern is empty")
+	}
+	for _, item := range n.items {
+		if err := v.attrSpec(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *validator) attrSpec(n *node) error {
+	switch t := n.value.(type) {
+	case Keyword:
+		return nil
+	case Symbol:
+		if t == "*" {
+			return nil
+		}
+	case string:
+		if t == "*" {
+			return nil
+		}
+	case *Map:
+		for i := 0; i < len(n.items); i += 2 {
+			if err := v.attrName(n.items[i]); err != nil {
+				return err
+			}
+			if err := v.subPattern(n.items[i+1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case Vector:
+		return v.attrExpr(n)
+	case List:
+		return v.legacyAttrExpr(n)
+	}
+	return v.errorf(n, "unexpected %s in pull pattern (expected an attribute like :block/string, *, or a map like {:block/children ...})", Marshal(n.value))
+}
+
+// attrName accepts a map-spec key: an attribute or an attribute expression.
+func (v *validator) attrName(n *node) error {
+	switch n.value.(type) {
+	case Keyword:
+		return nil
+	case Vector:
+		return v.attrExpr(n)
+	case List:
+		return v.legacyAttrExpr(n)
+	}
+	return v.errorf(n, "map keys in a pull pattern must be attributes, got %s", Marshal(n.value))
+}
+
+// subPattern accepts the value of a map-spec: a pattern, a recursion limit
+// or ... for unbounded recursion.
+func (v *validator) subPattern(n *node) error {
+	switch t := n.value.(type) {
+	case Vector:
+		return v.pattern(n)
+	case Symbol:
+		if t == "..." {
+			return nil
+		}
+	case int64:
+		if t > 0 {
+			return nil
+		}
+	}
+	return v.errorf(n, "expected a nested pattern, a recursion limit or ..., got %s", Marshal(n.value))
+}
+
+// attrExpr checks [:attr :as x :limit n :default v], or the same as a list.
+func (v *validator) attrExpr(n *node) error {
+	if len(n.items) == 0 {
+		return v.errorf(n, "empty attribute expression")
+	}
+	if _, ok := n.items[0].value.(Keyword); !ok {
+		return v.errorf(n.items[0], "attribute expression must start with an attribute, got %s", Marshal(n.items[0].value))
+	}
+	opts := n.items[1:]
+	if len(opts)%2 != 0 {
+		return v.errorf(n, "attribute options must come in pairs, like [:block/children :limit 10]")
+	}
+	for i := 0; i < len(opts); i += 2 {
+		switch opts[i].value {
+		case Keyword("as"), Keyword("default"):
+		case Keyword("limit"):
+			if _, ok := opts[i+1].value.(int64); !ok && opts[i+1].value != nil {
+				return v.errorf(opts[i+1], ":limit expects a number or nil")
+			}
+		default:
+			return v.errorf(opts[i], "unknown attribute option %s (expected :as, :limit or :default)", Marshal(opts[i].value))
+		}
+	}
+	return nil
+}
+
+// legacyAttrExpr checks (limit :attr n) and (default :attr v), and the list
+// form of attribute expressions, (:attr :as x :limit n).
+func (v *validator) legacyAttrExpr(n *node) error {
+	if len(n.items) > 0 {
+		if _, ok := n.items[0].value.(Keyword); ok {
+			return v.attrExpr(n)
+		}
+	}
+	if len(n.items) != 3 {
+		return v.errorf(n, "expected (limit :attr n), (default :attr value) or (:attr :limit n)")
+	}
+	switch n.items[0].value {
+	case Symbol("limit"), Symbol("default"):
+	default:
+		return v.errorf(n.items[0], "unknown attribute function %s (expected limit or default)", Marshal(n.items[0].value))
+	}
+	if _, ok := n.items[1].value.(Keyword); !ok {
+		return v.errorf(n.items[1], "expected an attribute, got %s", Marshal(n.items[1].value))
+	}
+	return nil
+}
+
+var querySections = map[Keyword]bool{
+	"find": true, "keys": true, "syms": true, "strs": true,
+	"with": true, "in": true, "where": true,
+}
+
+func (v *validator) query(n *node) error {
+	sections := map[Keyword]*node{}
+	var order []Keyword
+
+	switch n.value.(type) {
+	case Vector:
+		var current *node
+		for _, item := range n.items {
+			if kw, ok := item.value.(Keyword); ok {
+				if !querySections[kw] {
+					return v.errorf(item, "unknown query clause %s (expected :find, :in, :with or :where)", Marshal(kw))
+				}
+				if len(order) == 0 && kw != "find" {
+					return v.errorf(item, "query must start with :find")
+				}
+				if _, dup := sections[kw]; dup {
+					return v.errorf(item, "duplicate %s clause", Marshal(kw))
+				}
+				current = &node{value: kw, start: item.start, end: item.end}
+				sections[kw] = current
+				order = append(order, kw)
+				continue
+			}
+			if current == nil {
+				return v.errorf(item, "query must start with :find")
+			}
+			current.items = append(current.items, item)
+		}
+	case *Map:
+		for i := 0; i < len(n.items); i += 2 {
+			key, val := n.items[i], n.items[i+1]
+			kw, ok := key.value.(Keyword)
+			if !ok || !querySections[kw] {
+				return v.errorf(key, "unknown query clause %s (expected :find, :in, :with or :where)", Marshal(key.value))
+			}
+			if _, ok := val.value.(Vector); !ok {
+				return v.errorf(val, "%s must be a vector in map-form queries", Marshal(kw))
+			}
+			sections[kw] = &node{value: kw, start: key.start, end: key.end, items: val.items}
+			order = append(order, kw)
+		}
+	default:
+		return v.errorf(n, "query must be a vector like [:find ?e :where ...]")
+	}
+
+	find, ok := sections["find"]
+	if !ok {
+		return v.errorf(n, "query has no :find clause")
+	}
+	if len(find.items) == 0 {
+		return v.errorf(find, ":find needs at least one variable")
+	}
+	for _, kw := range order {
+		if kw != "find" && len(sections[kw].items) == 0 {
+			return v.errorf(sections[kw], "%s clause is empty", Marshal(kw))
+		}
+	}
+
+	if where, ok := sections["where"]; ok {
+		for _, clause := range where.items {
+			switch clause.value.(type) {
+			case Vector, List:
+				if len(clause.items) == 0 {
+					return v.errorf(clause, "empty :where clause")
+				}
+			default:
+				return v.errorf(clause, ":where clauses must be vectors or lists, got %s", Marshal(clause.value))
+			}
+		}
+	}
+
+	findVars, err := v.findElements(find)
+	if err != nil {
+		return err
+	}
+
+	// Without :in or :where nothing binds variables; leave that to the
+	// server, which reports it in its own terms.
+	if sections["in"] == nil && sections["where"] == nil {
+		return nil
+	}
+	bound := map[Symbol]bool{}
+	for _, kw := range []Keyword{"in", "where"} {
+		if sec := sections[kw]; sec != nil {
+			for _, item := range sec.items {
+				collectVars(item, func(s Symbol, _ *node) { bound[s] = true })
+			}
+		}
+	}
+	names := make([]string, 0, len(findVars))
+	for s := range findVars {
+		if !bound[s] {
+			names = append(names, string(s))
+		}
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		return v.errorf(findVars[Symbol(names[0])], "%s in :find is not bound by :in or :where", strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// findElements checks the :find specs and returns their variables with the
+// first node each appears at.
+func (v *validator) findElements(find *node) (map[Symbol]*node, error) {
+	vars := map[Symbol]*node{}
+	add := func(s Symbol, n *node) {
+		if _, seen := vars[s]; !seen {
+			vars[s] = n
+		}
+	}
+	for _, item := range find.items {
+		switch t := item.value.(type) {
+		case Symbol:
+			switch {
+			case t == "." || t == "...":
+			case strings.HasPrefix(string(t), "?"):
+				add(t, item)
+			default:
+				return nil, v.errorf(item, "unexpected %s in :find (variables start with ?)", t)
+			}
+		case List:
+			if len(item.items) == 0 {
+				return nil, v.errorf(item, "empty expression in :find")
+			}
+			if item.items[0].value == Symbol("pull") {
+				if len(item.items) != 3 {
+					return nil, v.errorf(item, "expected (pull ?e pattern)")
+				}
+				if _, ok := item.items[2].value.(Vector); ok {
+					if err := v.pattern(item.items[2]); err != nil {
+						return nil, err
+					}
+				}
+			}
+			collectVars(item, add)
+		case Vector:
+			// [?e ...] collection and [?a ?b] tuple find specs.
+			collectVars(item, add)
+		default:
+			return nil, v.errorf(item, "unexpected %s in :find (variables start with ?)", Marshal(item.value))
+		}
+	}
+	return vars, nil
+}
+
+// collectVars calls fn for every ?variable in n.
+func collectVars(n *node, fn func(Symbol, *node)) {
+	if s, ok := n.value.(Symbol); ok && strings.HasPrefix(string(s), "?") && len(s) > 1 {
+		fn(s, n)
+	}
+	for _, item := range n.items {
+		collectVars(item, fn)
+	}
+}
@@ -0,0 +1,79 @@
+// Package edn reads and writes EDN, the data notation Roam uses for pull
+// patterns, Datalog queries and Local API payloads.
+//
+// Values read from EDN map to Go as follows: nil to nil, booleans to bool,
+// integers to int64, floats to float64, strings to string, and the types
+// below for everything else. #inst values become time.Time and #uuid values
+// UUID; other tagged values become Tagged.
+package edn
+
+import "time"
+
+// Keyword is an EDN keyword without its leading colon (:block/uid is
+// Keyword("block/uid")).
+type Keyword string
+
+// Symbol is an EDN symbol, such as ?e, * or ...
+type Symbol string
+
+// Char is an EDN character literal (\a, \newline).
+type Char rune
+
+// UUID is a #uuid tagged string.
+type UUID string
+
+// Vector is [a b c].
+type Vector []interface{}
+
+// List is (a b c).
+type List []interface{}
+
+// Set is #{a b c}, in the order written.
+type Set []interface{}
+
+// Tagged is a #tag value with a tag this package does not interpret.
+type Tagged struct {
+	Tag   string
+	Value interface{}
+}
+
+// Map is an EDN map. Entries keep the order they were read or added in.
+type Map struct {
+	Keys []interface{}
+	Vals []interface{}
+}
+
+// Get returns the value for key, comparing keys with ==.
+func (m *Map) Get(key interface{}) (interface{}, bool) {
+	for i, k := range m.Keys {
+		if isComparable(k) && k == key {
+			return m.Vals[i], true
+		}
+	}
+	return nil, false
+}
+
+// Set adds or replaces the value for key.
+func (m *Map) Set(key, value interface{}) {
+	for i, k := range m.Keys {
+		if isComparable(k) && k == key {
+			m.Vals[i] = value
+			return
+		}
+	}
+	m.Keys = append(m.Keys, key)
+	m.Vals = append(m.Vals, value)
+}
+
+// Len returns the number of entries.
+func (m *Map) Len() int {
+	return len(m.Keys)
+}
+
+func isComparable(v interface{}) bool {
+	switch v.(type) {
+	case nil, bool, int64, float64, string, Keyword, Symbol, Char, UUID, time.Time:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,331 @@
+package edn
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// prettyWidth is the line width Pretty tries to stay within.
+const prettyWidth = 80
+
+// Marshal renders v as compact EDN. Besides the types of this package it
+// accepts the values encoding/json produces (map[string]interface{},
+// []interface{}, json.Number) and falls back to the JSON encoding of any
+// other Go value, with map keys written as keywords.
+func Marshal(v interface{}) string {
+	var sb strings.Builder
+	writeCompact(&sb, normalize(v))
+	return sb.String()
+}
+
+// Pretty renders v as indented EDN. Collections that fit on one line stay
+// on one line; longer ones put each element (or map entry) on its own line.
+func Pretty(v interface{}) string {
+	var sb strings.Builder
+	writePretty(&sb, normalize(v), 0)
+	return sb.String()
+}
+
+// FromJSON converts a JSON document into EDN values. Object keys become
+// keywords, so both {"uid": ...} and the Local API's {":block/uid": ...}
+// read as {:block/uid ...}; keys that are not valid keywords stay strings.
+func FromJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return fromJSONValue(v), nil
+}
+
+func fromJSONValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		m := &Map{}
+		for _, k := range keys {
+			m.Keys = append(m.Keys, jsonKey(k))
+			m.Vals = append(m.Vals, fromJSONValue(t[k]))
+		}
+		return m
+	case []interface{}:
+		vec := make(Vector, len(t))
+		for i, item := range t {
+			vec[i] = fromJSONValue(item)
+		}
+		return vec
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return f
+	case float64:
+		if t == math.Trunc(t) && math.Abs(t) < 1<<53 {
+			return int64(t)
+		}
+		return t
+	}
+	return v
+}
+
+// ToJSON converts EDN values into the values encoding/json produces, in
+// the Local API's shape: keywords become strings with their leading colon
+// (map keys included), vectors, lists and sets become slices, and other keys
+// are written with Marshal. #inst values become RFC 3339 strings.
+func ToJSON(v interface{}) interface{} {
+	switch t := v.(type) {
+	case *Map:
+		m := make(map[string]interface{}, t.Len())
+		for i, k := range t.Keys {
+			key, ok := k.(string)
+			if !ok {
+				key = Marshal(k)
+			}
+			m[key] = ToJSON(t.Vals[i])
+		}
+		return m
+	case Map:
+		return ToJSON(&t)
+	case Vector:
+		return toJSONSeq(t)
+	case List:
+		return toJSONSeq(t)
+	case Set:
+		return toJSONSeq(t)
+	case Keyword:
+		return ":" + string(t)
+	case Symbol:
+		return string(t)
+	case Char:
+		return string(rune(t))
+	case UUID:
+		return string(t)
+	case time.Time:
+		return t.Format(time.RFC3339Nano)
+	case Tagged:
+		return ToJSON(t.Value)
+	}
+	return v
+}
+
+func toJSONSeq(items []interface{}) []interface{} {
+	out := make([]interface{}, len(items))
+	for i, item := range items {
+		out[i] = ToJSON(item)
+	}
+	return out
+}
+
+func jsonKey(k string) interface{} {
+	name := strings.TrimPrefix(k, ":")
+	if name != "" && isSymbolName(name) && !isNumberStart(name) {
+		return Keyword(name)
+	}
+	return k
+}
+
+// normalize converts Go values without an EDN mapping of their own into
+// the types of this package.
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case nil, bool, int64, float64, string, Keyword, Symbol, Char, UUID, time.Time, *Map, Tagged:
+		return v
+	case Vector, List, Set:
+		return v
+	case Map:
+		return &t
+	case int:
+		return int64(t)
+	case int32:
+		return int64(t)
+	case float32:
+		return float64(t)
+	case json.Number, map[string]interface{}, []interface{}:
+		return fromJSONValue(t)
+	case json.RawMessage:
+		if converted, err := FromJSON(t); err == nil {
+			return converted
+		}
+		return string(t)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	converted, err := FromJSON(data)
+	if err != nil {
+		return string(data)
+	}
+	return converted
+}
+
+func writeCompact(sb *strings.Builder, v interface{}) {
+	switch t := v.(type) {
+	case Vector:
+		writeSeq(sb, "[", "]", t)
+	case List:
+		writeSeq(sb, "(", ")", t)
+	case Set:
+		writeSeq(sb, "#{", "}", t)
+	case *Map:
+		sb.WriteByte('{')
+		for i := range t.Keys {
+			if i > 0 {
+				sb.WriteByte(' ')
+			}
+			writeCompact(sb, normalize(t.Keys[i]))
+			sb.WriteByte(' ')
+			writeCompact(sb, normalize(t.Vals[i]))
+		}
+		sb.WriteByte('}')
+	case Tagged:
+		sb.WriteString("#" + t.Tag + " ")
+		writeCompact(sb, normalize(t.Value))
+	default:
+		sb.WriteString(scalar(t))
+	}
+}
+
+func writeSeq(sb *strings.Builder, open, close string, items []interface{}) {
+	sb.WriteString(open)
+	for i, item := range items {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		writeCompact(sb, normalize(item))
+	}
+	sb.WriteString(close)
+}
+
+func writePretty(sb *strings.Builder, v interface{}, indent int) {
+	compact := Marshal(v)
+	if indent+len(compact) <= prettyWidth {
+		sb.WriteString(compact)
+		return
+	}
+
+	var openDelim, closeDelim string
+	var items []interface{}
+	switch t := v.(type) {
+	case Vector:
+		openDelim, closeDelim, items = "[", "]", t
+	case List:
+		openDelim, closeDelim, items = "(", ")", t
+	case Set:
+		openDelim, closeDelim, items = "#{", "}", t
+	case *Map:
+		sb.WriteByte('{')
+		for i := range t.Keys {
+			if i > 0 {
+				sb.WriteString("\n" + strings.Repeat(" ", indent+1))
+			}
+			key := Marshal(t.Keys[i])
+			sb.WriteString(key + " ")
+			writePretty(sb, normalize(t.Vals[i]), indent+1+len(key)+1)
+		}
+		sb.WriteByte('}')
+		return
+	case Tagged:
+		sb.WriteString("#" + t.Tag + " ")
+		writePretty(sb, normalize(t.Value), indent+len(t.Tag)+2)
+		return
+	default:
+		sb.WriteString(compact)
+		return
+	}
+
+	sb.WriteString(openDelim)
+	for i, item := range items {
+		if i > 0 {
+			sb.WriteString("\n" + strings.Repeat(" ", indent+len(openDelim)))
+		}
+		writePretty(sb, normalize(item), indent+len(openDelim))
+	}
+	sb.WriteString(closeDelim)
+}
+
+func scalar(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return strconv.FormatBool(t)
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case float64:
+		return formatFloat(t)
+	case string:
+		return quote(t)
+	case Keyword:
+		return ":" + string(t)
+	case Symbol:
+		return string(t)
+	case Char:
+		for name, ch := range namedChars {
+			if ch == rune(t) {
+				return `\` + name
+			}
+		}
+		return `\` + string(rune(t))
+	case UUID:
+		return "#uuid " + quote(string(t))
+	case time.Time:
+		return "#inst " + quote(t.UTC().Format(time.RFC3339Nano))
+	}
+	return quote(fmt.Sprint(v))
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "##NaN"
+	case math.IsInf(f, 1):
+		return "##Inf"
+	case math.IsInf(f, -1):
+		return "##-Inf"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return s
+}
+
+func quote(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\r':
+			sb.WriteString(`\r`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&sb, `\u%04x`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,84 @@
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// assetURL matches files Roam hosts in Firebase storage, as they appear in
+// image links, {{pdf: ...}} macros and bare URLs.
+var assetURL = regexp.MustCompile(`https://firebasestorage\.googleapis\.com/[^\s)\]}>"']+`)
+
+// AssetURLs returns the distinct Firebase-hosted file URLs in blocks and
+// their descendants, in the order they first appear.
+func AssetURLs(blocks []roamdb.Block) []string {
+	var urls []string
+	seen := map[string]bool{}
+	var walk func([]roamdb.Block)
+	walk = func(blocks []roamdb.Block) {
+		for _, b := range blocks {
+			for _, u := range assetURL.FindAllString(b.String, -1) {
+				if !seen[u] {
+					seen[u] = true
+					urls = append(urls, u)
+				}
+			}
+			walk(b.Children)
+		}
+	}
+	walk(blocks)
+	return urls
+}
+
+// AssetName returns the file name an asset is saved under: a hash of its
+// URL, so every run picks the same name, plus the extension of the stored
+// file.
+func AssetName(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	return hex.EncodeToString(sum[:8]) + assetExt(rawURL)
+}
+
+// assetExt returns the extension of the object a storage URL points at.
+// Firebase escapes the object path into one segment (imgs%2Fapp%2F...).
+func assetExt(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	ext := strings.ToLower(path.Ext(u.Path))
+	if len(ext) < 2 || len(ext) > 6 {
+		return ""
+	}
+	for _, r := range ext[1:] {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return ""
+		}
+	}
+	return ext
+}
+
+// RewriteLinks returns copies of blocks with each URL in links replaced by
+// its local path.
+func RewriteLinks(blocks []roamdb.Block, links map[string]string) []roamdb.Block {
+	if len(links) == 0 || len(blocks) == 0 {
+		return blocks
+	}
+	out := make([]roamdb.Block, len(blocks))
+	for i, b := range blocks {
+		b.String = assetURL.ReplaceAllStringFunc(b.String, func(u string) string {
+			if local, ok := links[u]; ok {
+				return local
+			}
+			return u
+		})
+		b.Children = RewriteLinks(b.Children, links)
+		out[i] = b
+	}
+	return out
+}
@@ -0,0 +1,186 @@
+// Package export lays a Roam graph out as a directory of Markdown files: one
+// file per page, namespaces as subdirectories, daily notes under journals/,
+// and attachments under assets/. A manifest in the directory records what
+// was written so later runs only rewrite pages edited since.
+package export
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/salmonumbrella/roam-cli/internal/render"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// Directory names inside an export.
+const (
+	JournalsDir = "journals"
+	AssetsDir   = "assets"
+)
+
+// maxSegment caps a file or directory name, leaving room for the extension
+// and a collision suffix within the usual 255-byte limit.
+const maxSegment = 200
+
+var dailyUID = regexp.MustCompile(`^\d{2}-\d{2}-\d{4}$`)
+
+// DailyNoteDate returns the date of a daily note page, recognized by its
+// MM-DD-YYYY uid.
+func DailyNoteDate(uid string) (time.Time, bool) {
+	if !dailyUID.MatchString(uid) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("01-02-2006", uid)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// PagePath returns the slash-separated path of a page's file within an
+// export: journals/2006-01-02.md for daily notes, and the title with each
+// namespace segment as a directory otherwise ("Projects/Roam CLI" becomes
+// Projects/Roam CLI.md).
+func PagePath(title, uid string) string {
+	if t, ok := DailyNoteDate(uid); ok {
+		return path.Join(JournalsDir, t.Format("2006-01-02")+".md")
+	}
+	var segments []string
+	for _, part := range strings.Split(title, "/") {
+		if seg := sanitizeSegment(part); seg != "" {
+			segments = append(segments, seg)
+		}
+	}
+	if len(segments) == 0 {
+		segments = []string{"untitled"}
+	}
+	// Keep pages out of the directories the export itself owns.
+	if len(segments) > 1 && (strings.EqualFold(segments[0], JournalsDir) || strings.EqualFold(segments[0], AssetsDir)) {
+		segments[0] = "_" + segments[0]
+	}
+	return path.Join(segments...) + ".md"
+}
+
+// sanitizeSegment makes one path segment safe on common filesystems:
+// reserved characters and control characters become underscores, and
+// leading dots and trailing dots and spaces are dropped.
+func sanitizeSegment(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case strings.ContainsRune(`\:*?"<>|`, r), unicode.IsControl(r):
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(s))
+	s = strings.TrimLeft(s, ".")
+	s = strings.TrimRight(s, ". ")
+	if len(s) > maxSegment {
+		cut := maxSegment
+		for cut > 0 && !isRuneStart(s[cut]) {
+			cut--
+		}
+		s = strings.TrimRight(s[:cut], ". ")
+	}
+	return s
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// PageRef identifies a page to place in an export.
+type PageRef struct {
+	Title string
+	UID   string
+}
+
+// AssignPaths returns the file path for each page uid. Titles that
+// sanitize to the same path, compared case-insensitively for the benefit of
+// macOS and Windows, are told apart by appending the page uid; the page
+// whose title sorts first keeps the plain name, so paths are stable between
+// runs.
+func AssignPaths(pages []PageRef) map[string]string {
+	sorted := append([]PageRef(nil), pages...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Title != sorted[j].Title {
+			return sorted[i].Title < sorted[j].Title
+		}
+		return sorted[i].UID < sorted[j].UID
+	})
+
+	paths := make(map[string]string, len(sorted))
+	taken := make(map[string]bool, len(sorted))
+	for _, p := range sorted {
+		file := PagePath(p.Title, p.UID)
+		if taken[strings.ToLower(file)] {
+			file = strings.TrimSuffix(file, ".md") + " (" + sanitizeSegment(p.UID) + ").md"
+		}
+		taken[strings.ToLower(file)] = true
+		paths[p.UID] = file
+	}
+	return paths
+}
+
+// RelativeTo returns target, a path within the export, relative to the
+// directory holding file.
+func RelativeTo(file, target string) string {
+	return strings.Repeat("../", strings.Count(file, "/")) + target
+}
+
+// PageMarkdown returns the file contents for a page: front matter from its
+// attributes, then its blocks as a Markdown list with uid comments. Asset
+// links should already have been rewritten.
+func PageMarkdown(page *roamdb.Page) (string, error) {
+	attrs, body := PageAttributes(page.Children)
+	front, err := FrontMatter(page, attrs)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	sb.WriteString(front)
+	if len(body) > 0 {
+		sb.WriteString("\n")
+		doc := render.FromPage(page)
+		doc.Title = ""
+		doc.Blocks = body
+		if err := render.MarkdownWithUIDs().Render(&sb, doc); err != nil {
+			return "", err
+		}
+	}
+	return sb.String(), nil
+}
+
+// WriteFile writes data to rel, a path within dir, creating directories.
+func WriteFile(dir, rel string, data []byte) error {
+	target := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(target), err)
+	}
+	if err := os.WriteFile(target, data, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", target, err)
+	}
+	return nil
+}
+
+// RemoveFile deletes rel, a path within dir, and any directories the removal
+// leaves empty. A file that is already gone is not an error.
+func RemoveFile(dir, rel string) error {
+	target := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing %s: %w", target, err)
+	}
+	root := filepath.Clean(dir)
+	for parent := filepath.Dir(target); parent != root && strings.HasPrefix(parent, root); parent = filepath.Dir(parent) {
+		if os.Remove(parent) != nil {
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package export
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+func TestPagePath(t *testing.T) {
+	cases := []struct {
+		title, uid, want string
+	}{
+		{"Plain", "abc", "Plain.md"},
+		{"Projects/Roam CLI", "abc", "Projects/Roam CLI.md"},
+		{"a:b*c?", "abc", "a_b_c_.md"},
+		{"..hidden. ", "abc", "hidden.md"},
+		{"//", "abc", "untitled.md"},
+		{"assets/logo", "abc", "_assets/logo.md"},
+		{"January 15th, 2025", "01-15-2025", "journals/2025-01-15.md"},
+	}
+	for _, c := range cases {
+		if got := PagePath(c.title, c.uid); got != c.want {
+			t.Errorf("PagePath(%q, %q) = %q, want %q", c.title, c.uid, got, c.want)
+		}
+	}
+}
+
+func TestAssignPathsCollisions(t *testing.T) {
+	paths := AssignPaths([]PageRef{
+		{Title: "a?b", UID: "u2"},
+		{Title: "a:b", UID: "u1"},
+		{Title: "Notes", UID: "u3"},
+		{Title: "notes", UID: "u4"},
+	})
+	want := map[string]string{
+		"u1": "a_b.md",
+		"u2": "a_b (u2).md",
+		"u3": "Notes.md",
+		"u4": "notes (u4).md",
+	}
+	for uid, p := range want {
+		if paths[uid] != p {
+			t.Errorf("path for %s = %q, want %q", uid, paths[uid], p)
+		}
+	}
+}
+
+func TestRelativeTo(t *testing.T) {
+	if got := RelativeTo("Projects/Roam.md", "assets/x.png"); got != "../assets/x.png" {
+		t.Fatalf("unexpected relative path %q", got)
+	}
+	if got := RelativeTo("Top.md", "assets/x.png"); got != "assets/x.png" {
+		t.Fatalf("unexpected relative path %q", got)
+	}
+}
+
+func TestPageMarkdown(t *testing.T) {
+	page, err := roamdb.ParsePage(json.RawMessage(`{
+		"node/title": "Roam CLI", "block/uid": "p1", "create/time": 1700000000000,
+		"block/children": [
+			{"block/uid": "a1", "block/string": "Status:: active", "block/order": 0},
+			{"block/uid": "a2", "block/string": "Year:: 2024", "block/order": 1},
+			{"block/uid": "b1", "block/string": "Ship **export**", "block/order": 2,
+			 "block/children": [{"block/uid": "b2", "block/string": "tests", "block/order": 0}]}
+		]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := PageMarkdown(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "---\n" +
+		"title: Roam CLI\n" +
+		"uid: p1\n" +
+		"created: \"2023-11-14T22:13:20Z\"\n" +
+		"Status: active\n" +
+		"Year: \"2024\"\n" +
+		"uids:\n" +
+		"  Status: a1\n" +
+		"  Year: a2\n" +
+		"---\n\n" +
+		"- Ship **export** <!-- uid: b1 -->\n" +
+		"  - tests <!-- uid: b2 -->\n"
+	if got != want {
+		t.Fatalf("unexpected page markdown:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestAssets(t *testing.T) {
+	img := "https://firebasestorage.googleapis.com/v0/b/app/o/imgs%2Fapp%2Fg%2Fpic.PNG?alt=media&token=t"
+	pdf := "https://firebasestorage.googleapis.com/v0/b/app/o/files%2Fdoc.pdf?alt=media"
+	blocks := []roamdb.Block{
+		{String: "![](" + img + ")", Children: []roamdb.Block{{String: "{{pdf: " + pdf + "}} and " + img}}},
+	}
+	urls := AssetURLs(blocks)
+	if len(urls) != 2 || urls[0] != img || urls[1] != pdf {
+		t.Fatalf("unexpected asset urls: %v", urls)
+	}
+	if name := AssetName(img); !strings.HasSuffix(name, ".png") || name != AssetName(img) || name == AssetName(pdf) {
+		t.Fatalf("unexpected asset name %q", name)
+	}
+
+	out := RewriteLinks(blocks, map[string]string{img: "assets/pic.png"})
+	if out[0].String != "![](assets/pic.png)" || out[0].Children[0].String != "{{pdf: "+pdf+"}} and assets/pic.png" {
+		t.Fatalf("unexpected rewrite: %+v", out)
+	}
+	if blocks[0].String != "![]("+img+")" {
+		t.Fatalf("RewriteLinks modified its input")
+	}
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	m, err := LoadManifest(dir, "g")
+	if err != nil || len(m.Pages) != 0 {
+		t.Fatalf("expected empty manifest, got %+v, %v", m, err)
+	}
+	m.Pages["p1"] = ManifestPage{Title: "T", Path: "T.md", EditTime: 5}
+	if err := m.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadManifest(dir, "g")
+	if err != nil || loaded.Pages["p1"].EditTime != 5 {
+		t.Fatalf("manifest not reloaded: %+v, %v", loaded, err)
+	}
+	other, err := LoadManifest(dir, "other")
+	if err != nil || len(other.Pages) != 0 {
+		t.Fatalf("manifest of another graph should be ignored: %+v, %v", other, err)
+	}
+}
+
+func TestRemoveFilePrunesEmptyDirs(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteFile(dir, "a/b/c.md", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveFile(dir, "a/b/c.md"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a")); !os.IsNotExist(err) {
+		t.Fatalf("expected empty directories to be removed, got %v", err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("export directory itself must stay: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salmonumbrella/roam-cli/internal/markup"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// Field is one front matter entry.
+type Field struct {
+	Key   string
+	Value string
+	// UID is the uid of the attribute block the field came from, if any.
+	UID string
+}
+
+// UIDsKey is the front matter key mapping attribute names to the uids of
+// their blocks, so a re-import can create them with the same uids.
+const UIDsKey = "uids"
+
+// PageAttributes splits a page's top-level "Name:: value" blocks, the Roam
+// equivalent of front matter, from the rest of its blocks. Attribute blocks
+// with children stay in the body, since front matter can't hold them.
+func PageAttributes(blocks []roamdb.Block) ([]Field, []roamdb.Block) {
+	var fields []Field
+	body := make([]roamdb.Block, 0, len(blocks))
+	for _, b := range blocks {
+		nodes := markup.Parse(b.String)
+		if len(b.Children) > 0 || len(nodes) != 1 || nodes[0].Kind != markup.KindAttribute {
+			body = append(body, b)
+			continue
+		}
+		fields = append(fields, Field{
+			Key:   nodes[0].Target,
+			Value: strings.TrimSpace(markup.Render(nodes[0].Children)),
+			UID:   b.UID,
+		})
+	}
+	return fields, body
+}
+
+// FrontMatter returns the YAML front matter for a page: its title, uid and
+// timestamps followed by attrs and the uids of their blocks, between ---
+// lines.
+func FrontMatter(page *roamdb.Page, attrs []Field) (string, error) {
+	fields := []Field{{Key: "title", Value: page.Title}, {Key: "uid", Value: page.UID}}
+	if page.CreateTime > 0 {
+		fields = append(fields, Field{Key: "created", Value: formatMillis(page.CreateTime)})
+	}
+	if page.EditTime > 0 {
+		fields = append(fields, Field{Key: "edited", Value: formatMillis(page.EditTime)})
+	}
+	seen := map[string]bool{UIDsKey: true}
+	for _, f := range fields {
+		seen[f.Key] = true
+	}
+	uids := &yaml.Node{Kind: yaml.MappingNode}
+	for _, f := range attrs {
+		// The page's own title and uid win over attributes of the same name.
+		if !seen[f.Key] {
+			seen[f.Key] = true
+			fields = append(fields, f)
+			if f.UID != "" {
+				uids.Content = append(uids.Content, scalarNode(f.Key), scalarNode(f.UID))
+			}
+		}
+	}
+
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	for _, f := range fields {
+		doc.Content = append(doc.Content, scalarNode(f.Key), scalarNode(f.Value))
+	}
+	if len(uids.Content) > 0 {
+		doc.Content = append(doc.Content, scalarNode(UIDsKey), uids)
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return "", fmt.Errorf("encoding front matter: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("encoding front matter: %w", err)
+	}
+	return "---\n" + buf.String() + "---\n", nil
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: value, Style: scalarStyle(value)}
+}
+
+// scalarStyle quotes values YAML would otherwise read as another type, such
+// as "true" or "2024", so attributes come back as the strings Roam stores.
+func scalarStyle(value string) yaml.Style {
+	var v interface{}
+	if err := yaml.Unmarshal([]byte(value), &v); err != nil {
+		return yaml.DoubleQuotedStyle
+	}
+	if s, ok := v.(string); ok && s == value {
+		return 0
+	}
+	return yaml.DoubleQuotedStyle
+}
+
+func formatMillis(ms int64) string {
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339)
+}
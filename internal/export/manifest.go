@@ -0,0 +1,105 @@
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ManifestFile is the name of the manifest within an export directory.
+const ManifestFile = ".roam-export.json"
+
+// ManifestVersion is bumped when the manifest or file layout changes
+// incompatibly; older manifests are then ignored and the graph re-exported.
+const ManifestVersion = 1
+
+// Manifest records what an export wrote.
+type Manifest struct {
+	Version    int                     `json:"version"`
+	Graph      string                  `json:"graph"`
+	ExportedAt time.Time               `json:"exported_at"`
+	Pages      map[string]ManifestPage `json:"pages"`
+	// Assets maps downloaded URLs to their path within the export.
+	Assets map[string]string `json:"assets,omitempty"`
+}
+
+// ManifestPage is one exported page.
+type ManifestPage struct {
+	Title string `json:"title"`
+	Path  string `json:"path"`
+	// EditTime is the latest edit time of the page or any of its blocks
+	// when it was written.
+	EditTime int64 `json:"edit_time"`
+	// Blocks hashes where each block on the page was when it was written;
+	// see BlocksHash.
+	Blocks string `json:"blocks,omitempty"`
+}
+
+// BlocksHash hashes a page's blocks, given as "uid parent order" strings
+// in any order. Deleting, adding or moving a block changes the hash even
+// when no edit time changes.
+func BlocksHash(blocks []string) string {
+	sorted := append([]string(nil), blocks...)
+	sort.Strings(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "\n")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// NewManifest returns an empty manifest for graph.
+func NewManifest(graph string) *Manifest {
+	return &Manifest{
+		Version: ManifestVersion,
+		Graph:   graph,
+		Pages:   map[string]ManifestPage{},
+		Assets:  map[string]string{},
+	}
+}
+
+// LoadManifest reads the manifest in dir. A missing manifest, or one from
+// another graph or format version, yields an empty manifest so everything
+// is exported again.
+func LoadManifest(dir, graph string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if os.IsNotExist(err) {
+		return NewManifest(graph), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading export manifest: %w", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("reading export manifest %s: %w", filepath.Join(dir, ManifestFile), err)
+	}
+	if m.Version != ManifestVersion || m.Graph != graph {
+		return NewManifest(graph), nil
+	}
+	if m.Pages == nil {
+		m.Pages = map[string]ManifestPage{}
+	}
+	if m.Assets == nil {
+		m.Assets = map[string]string{}
+	}
+	return &m, nil
+}
+
+// Save writes the manifest to dir.
+func (m *Manifest) Save(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding export manifest: %w", err)
+	}
+	tmp := filepath.Join(dir, ManifestFile+".tmp")
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing export manifest: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, ManifestFile)); err != nil {
+		return fmt.Errorf("writing export manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+// Package index implements an on-disk inverted index of a graph's blocks for
+// fast, ranked full-text search.
+package index
+
+import (
+	"sort"
+	"time"
+)
+
+// FormatVersion is bumped whenever the on-disk layout changes. Indexes written
+// with a different version must be rebuilt.
+const FormatVersion = 1
+
+// Doc is an indexed block.
+type Doc struct {
+	UID       string
+	Text      string
+	PageTitle string
+	PageUID   string
+	EditTime  int64
+	Length    int
+}
+
+// Index is an inverted index mapping stemmed terms to the blocks (and word
+// positions) they occur in.
+type Index struct {
+	Version   int
+	Graph     string
+	BuiltAt   time.Time
+	UpdatedAt time.Time
+	// LastEditTime is the newest :edit/time seen, the starting point for
+	// incremental updates.
+	LastEditTime int64
+
+	Docs map[string]*Doc
+	// Postings maps term -> block UID -> word positions.
+	Postings map[string]map[string][]int
+	// Forms maps lower-cased surface words to their term, for prefix queries.
+	Forms       map[string]string
+	TotalLength int64
+}
+
+// New returns an empty index for graph.
+func New(graph string) *Index {
+	return &Index{
+		Version:  FormatVersion,
+		Graph:    graph,
+		Docs:     make(map[string]*Doc),
+		Postings: make(map[string]map[string][]int),
+		Forms:    make(map[string]string),
+	}
+}
+
+// Add indexes doc, replacing any existing entry with the same UID.
+func (ix *Index) Add(doc Doc) {
+	ix.Remove(doc.UID)
+
+	tokens := Tokenize(doc.Text)
+	doc.Length = len(tokens)
+	for _, tok := range tokens {
+		postings := ix.Postings[tok.Term]
+		if postings == nil {
+			postings = make(map[string][]int)
+			ix.Postings[tok.Term] = postings
+		}
+		postings[doc.UID] = append(postings[doc.UID], tok.Pos)
+		ix.Forms[tok.Word] = tok.Term
+	}
+
+	ix.Docs[doc.UID] = &doc
+	ix.TotalLength += int64(doc.Length)
+	if doc.EditTime > ix.LastEditTime {
+		ix.LastEditTime = doc.EditTime
+	}
+}
+
+// Remove drops the block with uid from the index. It reports whether the
+// block was indexed.
+func (ix *Index) Remove(uid string) bool {
+	doc, ok := ix.Docs[uid]
+	if !ok {
+		return false
+	}
+	for _, term := range Terms(doc.Text) {
+		postings := ix.Postings[term]
+		delete(postings, uid)
+		if len(postings) == 0 {
+			delete(ix.Postings, term)
+		}
+	}
+	delete(ix.Docs, uid)
+	ix.TotalLength -= int64(doc.Length)
+	return true
+}
+
+// Prune removes every indexed block whose UID is not in keep and returns the
+// number removed.
+func (ix *Index) Prune(keep map[string]bool) int {
+	var stale []string
+	for uid := range ix.Docs {
+		if !keep[uid] {
+			stale = append(stale, uid)
+		}
+	}
+	for _, uid := range stale {
+		ix.Remove(uid)
+	}
+	if len(stale) > 0 {
+		for word, term := range ix.Forms {
+			if _, ok := ix.Postings[term]; !ok {
+				delete(ix.Forms, word)
+			}
+		}
+	}
+	return len(stale)
+}
+
+// PageUIDs returns the distinct page uids of the indexed blocks, sorted.
+func (ix *Index) PageUIDs() []string {
+	seen := make(map[string]bool)
+	var uids []string
+	for _, doc := range ix.Docs {
+		if doc.PageUID != "" && !seen[doc.PageUID] {
+			seen[doc.PageUID] = true
+			uids = append(uids, doc.PageUID)
+		}
+	}
+	sort.Strings(uids)
+	return uids
+}
+
+// SetPageTitles sets the page title of indexed blocks from titles, keyed by
+// page uid, and returns the number of blocks changed. Renaming a page does
+// not touch its blocks' edit times, so incremental updates miss it.
+func (ix *Index) SetPageTitles(titles map[string]string) int {
+	changed := 0
+	for _, doc := range ix.Docs {
+		if title, ok := titles[doc.PageUID]; ok && title != doc.PageTitle {
+			doc.PageTitle = title
+			changed++
+		}
+	}
+	return changed
+}
+
+// Len returns the number of indexed blocks.
+func (ix *Index) Len() int {
+	return len(ix.Docs)
+}
+
+// TermCount returns the number of distinct terms.
+func (ix *Index) TermCount() int {
+	return len(ix.Postings)
+}
+
+// expandPrefix returns the terms whose surface forms (or stems) start with
+// prefix, sorted for stable scoring.
+func (ix *Index) expandPrefix(prefix string) []string {
+	seen := make(map[string]bool)
+	for word, term := range ix.Forms {
+		if len(word) >= len(prefix) && word[:len(prefix)] == prefix {
+			if _, ok := ix.Postings[term]; ok {
+				seen[term] = true
+			}
+		}
+	}
+	for term := range ix.Postings {
+		if len(term) >= len(prefix) && term[:len(prefix)] == prefix {
+			seen[term] = true
+		}
+	}
+	terms := make([]string, 0, len(seen))
+	for term := range seen {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	return terms
+}
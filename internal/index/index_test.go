@@ -0,0 +1,165 @@
+package index
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testIndex() *Index {
+	ix := New("test")
+	ix.Add(Doc{UID: "a", Text: "Planning the quarterly budget review", PageTitle: "Finance", EditTime: 10})
+	ix.Add(Doc{UID: "b", Text: "Budget budget budget", PageTitle: "Finance", EditTime: 20})
+	ix.Add(Doc{UID: "c", Text: "Review the plan for the launch", PageTitle: "Launch", EditTime: 30})
+	ix.Add(Doc{UID: "d", Text: "The launch was reviewed by the planning group", PageTitle: "Launch", EditTime: 40})
+	return ix
+}
+
+func searchUIDs(t *testing.T, ix *Index, query string) []string {
+	t.Helper()
+	q, err := ParseQuery(query)
+	if err != nil {
+		t.Fatalf("ParseQuery(%q): %v", query, err)
+	}
+	var uids []string
+	for _, hit := range ix.Search(q) {
+		uids = append(uids, hit.Doc.UID)
+	}
+	return uids
+}
+
+func TestSearchRanksByBM25(t *testing.T) {
+	uids := searchUIDs(t, testIndex(), "budget")
+	if strings.Join(uids, ",") != "b,a" {
+		t.Fatalf("expected b before a, got %v", uids)
+	}
+}
+
+func TestSearchRequiresAllTermsAndStems(t *testing.T) {
+	uids := searchUIDs(t, testIndex(), "reviews planned")
+	if len(uids) != 3 || strings.Contains(strings.Join(uids, ","), "b") {
+		t.Fatalf("expected stemmed matches for a, c and d, got %v", uids)
+	}
+	if got := searchUIDs(t, testIndex(), "budget launch"); len(got) != 0 {
+		t.Fatalf("expected no results, got %v", got)
+	}
+}
+
+func TestSearchPhrase(t *testing.T) {
+	uids := searchUIDs(t, testIndex(), `"the launch"`)
+	if strings.Join(uids, ",") != "c,d" && strings.Join(uids, ",") != "d,c" {
+		t.Fatalf("expected c and d, got %v", uids)
+	}
+	if got := searchUIDs(t, testIndex(), `"launch the"`); len(got) != 0 {
+		t.Fatalf("expected phrase order to matter, got %v", got)
+	}
+}
+
+func TestSearchPrefix(t *testing.T) {
+	uids := searchUIDs(t, testIndex(), "quart*")
+	if strings.Join(uids, ",") != "a" {
+		t.Fatalf("expected a, got %v", uids)
+	}
+	if got := searchUIDs(t, testIndex(), "launc*"); len(got) != 2 {
+		t.Fatalf("expected two launch matches, got %v", got)
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	if _, err := ParseQuery(`"unterminated`); err == nil {
+		t.Fatal("expected unterminated phrase error")
+	}
+	if _, err := ParseQuery("  !! "); err == nil {
+		t.Fatal("expected empty query error")
+	}
+}
+
+func TestAddReplacesAndRemove(t *testing.T) {
+	ix := testIndex()
+	ix.Add(Doc{UID: "b", Text: "Nothing to see", EditTime: 50})
+	if got := searchUIDs(t, ix, "budget"); strings.Join(got, ",") != "a" {
+		t.Fatalf("expected only a after replacing b, got %v", got)
+	}
+	if ix.LastEditTime != 50 {
+		t.Fatalf("expected last edit 50, got %d", ix.LastEditTime)
+	}
+
+	removed := ix.Prune(map[string]bool{"a": true, "b": true})
+	if removed != 2 || ix.Len() != 2 {
+		t.Fatalf("expected 2 pruned and 2 left, got %d and %d", removed, ix.Len())
+	}
+	if _, ok := ix.Postings["launch"]; ok {
+		t.Fatal("expected postings for pruned blocks to be removed")
+	}
+	if _, ok := ix.Forms["launch"]; ok {
+		t.Fatal("expected forms of pruned terms to be removed")
+	}
+}
+
+func TestSetPageTitles(t *testing.T) {
+	ix := New("g")
+	ix.Add(Doc{UID: "a", Text: "one", PageTitle: "Old", PageUID: "p1"})
+	ix.Add(Doc{UID: "b", Text: "two", PageTitle: "Old", PageUID: "p1"})
+	ix.Add(Doc{UID: "c", Text: "three", PageTitle: "Other", PageUID: "p2"})
+	if got := strings.Join(ix.PageUIDs(), ","); got != "p1,p2" {
+		t.Fatalf("unexpected page uids %q", got)
+	}
+	if n := ix.SetPageTitles(map[string]string{"p1": "New", "p2": "Other"}); n != 2 {
+		t.Fatalf("expected 2 blocks retitled, got %d", n)
+	}
+	if ix.Docs["a"].PageTitle != "New" || ix.Docs["c"].PageTitle != "Other" {
+		t.Fatalf("unexpected titles: %+v %+v", ix.Docs["a"], ix.Docs["c"])
+	}
+}
+
+func TestSnippetHighlightsAndTrims(t *testing.T) {
+	text := strings.Repeat("lorem ipsum ", 20) + "the budget\nreview " + strings.Repeat("dolor sit ", 20)
+	snippet := Snippet(text, map[string]bool{"budget": true}, 40, "**", "**")
+	if !strings.Contains(snippet, "**budget**") {
+		t.Fatalf("expected highlight, got %q", snippet)
+	}
+	if !strings.HasPrefix(snippet, "…") || !strings.HasSuffix(snippet, "…") {
+		t.Fatalf("expected ellipses on both sides, got %q", snippet)
+	}
+	if strings.Contains(snippet, "\n") {
+		t.Fatalf("expected newlines flattened, got %q", snippet)
+	}
+
+	short := Snippet("Budget day", map[string]bool{"budget": true}, 40, "[", "]")
+	if short != "[Budget] day" {
+		t.Fatalf("unexpected short snippet %q", short)
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "graph.gob")
+	if _, err := Load(path); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	ix := testIndex()
+	if err := ix.Save(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded.Len() != ix.Len() || loaded.LastEditTime != 40 || loaded.Graph != "test" {
+		t.Fatalf("unexpected loaded index: %+v", loaded)
+	}
+	if got := searchUIDs(t, loaded, `"quarterly budget"`); strings.Join(got, ",") != "a" {
+		t.Fatalf("expected phrase search after reload, got %v", got)
+	}
+}
+
+func TestDefaultPathSanitizesGraph(t *testing.T) {
+	path, err := DefaultPath("my/graph")
+	if err != nil {
+		t.Skipf("no cache dir: %v", err)
+	}
+	if filepath.Base(path) != "my_graph.gob" {
+		t.Fatalf("unexpected path %s", path)
+	}
+}
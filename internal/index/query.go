@@ -0,0 +1,281 @@
+package index
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// BM25 parameters.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Clause is one required part of a query.
+type Clause struct {
+	// Terms are stemmed terms. Phrases hold several terms that must appear
+	// consecutively.
+	Terms []string
+	// Prefix matches any word starting with Terms[0] (unstemmed).
+	Prefix bool
+}
+
+// Query is a parsed search query. Every clause must match.
+type Query struct {
+	Clauses []Clause
+}
+
+// ParseQuery parses a search string. Bare words must all appear (in any
+// order), "quoted phrases" must appear verbatim, and word* matches any word
+// with that prefix. Punctuated words such as "e-mail" are treated as phrases.
+func ParseQuery(input string) (Query, error) {
+	var q Query
+	rest := strings.TrimSpace(input)
+	for rest != "" {
+		if rest[0] == '"' {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return Query{}, fmt.Errorf("unterminated phrase in query: %s", input)
+			}
+			if terms := Terms(rest[1 : end+1]); len(terms) > 0 {
+				q.Clauses = append(q.Clauses, Clause{Terms: terms})
+			}
+			rest = strings.TrimSpace(rest[end+2:])
+			continue
+		}
+
+		word := rest
+		if i := strings.IndexAny(rest, " \t\n\""); i >= 0 {
+			word = rest[:i]
+		}
+		rest = strings.TrimSpace(rest[len(word):])
+
+		if strings.HasSuffix(word, "*") {
+			tokens := Tokenize(strings.TrimRight(word, "*"))
+			if len(tokens) == 0 {
+				continue
+			}
+			for _, tok := range tokens[:len(tokens)-1] {
+				q.Clauses = append(q.Clauses, Clause{Terms: []string{tok.Term}})
+			}
+			q.Clauses = append(q.Clauses, Clause{Terms: []string{tokens[len(tokens)-1].Word}, Prefix: true})
+			continue
+		}
+		if terms := Terms(word); len(terms) > 0 {
+			q.Clauses = append(q.Clauses, Clause{Terms: terms})
+		}
+	}
+
+	if len(q.Clauses) == 0 {
+		return Query{}, fmt.Errorf("empty search query")
+	}
+	return q, nil
+}
+
+// Hit is a ranked search result.
+type Hit struct {
+	Doc   *Doc
+	Score float64
+	// Terms are the matched terms, used for highlighting.
+	Terms map[string]bool
+}
+
+// Search returns the blocks matching every clause of q, best first.
+func (ix *Index) Search(q Query) []Hit {
+	if len(ix.Docs) == 0 || len(q.Clauses) == 0 {
+		return nil
+	}
+
+	avgLen := float64(ix.TotalLength) / float64(len(ix.Docs))
+	if avgLen == 0 {
+		avgLen = 1
+	}
+
+	var hits map[string]*Hit
+	for _, clause := range q.Clauses {
+		matched := ix.matchClause(clause, avgLen)
+		if hits == nil {
+			hits = matched
+		} else {
+			for uid, hit := range hits {
+				other, ok := matched[uid]
+				if !ok {
+					delete(hits, uid)
+					continue
+				}
+				hit.Score += other.Score
+				for term := range other.Terms {
+					hit.Terms[term] = true
+				}
+			}
+		}
+		if len(hits) == 0 {
+			return nil
+		}
+	}
+
+	results := make([]Hit, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, *hit)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].Doc.EditTime != results[j].Doc.EditTime {
+			return results[i].Doc.EditTime > results[j].Doc.EditTime
+		}
+		return results[i].Doc.UID < results[j].Doc.UID
+	})
+	return results
+}
+
+func (ix *Index) matchClause(clause Clause, avgLen float64) map[string]*Hit {
+	hits := make(map[string]*Hit)
+	add := func(uid, term string, tf int) {
+		hit, ok := hits[uid]
+		if !ok {
+			hit = &Hit{Doc: ix.Docs[uid], Terms: make(map[string]bool)}
+			hits[uid] = hit
+		}
+		hit.Score += ix.bm25(term, tf, hit.Doc.Length, avgLen)
+		hit.Terms[term] = true
+	}
+
+	switch {
+	case clause.Prefix:
+		for _, term := range ix.expandPrefix(clause.Terms[0]) {
+			for uid, positions := range ix.Postings[term] {
+				add(uid, term, len(positions))
+			}
+		}
+	case len(clause.Terms) == 1:
+		term := clause.Terms[0]
+		for uid, positions := range ix.Postings[term] {
+			add(uid, term, len(positions))
+		}
+	default:
+		first := ix.Postings[clause.Terms[0]]
+		for uid := range first {
+			if ix.phraseCount(uid, clause.Terms) == 0 {
+				continue
+			}
+			for _, term := range clause.Terms {
+				add(uid, term, len(ix.Postings[term][uid]))
+			}
+		}
+	}
+	return hits
+}
+
+// phraseCount returns how often terms occur consecutively in block uid.
+func (ix *Index) phraseCount(uid string, terms []string) int {
+	positions := make([]map[int]bool, len(terms))
+	for i, term := range terms {
+		list := ix.Postings[term][uid]
+		if len(list) == 0 {
+			return 0
+		}
+		positions[i] = make(map[int]bool, len(list))
+		for _, pos := range list {
+			positions[i][pos] = true
+		}
+	}
+
+	count := 0
+	for _, start := range ix.Postings[terms[0]][uid] {
+		match := true
+		for i := 1; i < len(terms); i++ {
+			if !positions[i][start+i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			count++
+		}
+	}
+	return count
+}
+
+func (ix *Index) bm25(term string, tf, docLen int, avgLen float64) float64 {
+	n := float64(len(ix.Docs))
+	df := float64(len(ix.Postings[term]))
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+	f := float64(tf)
+	return idf * f * (bm25K1 + 1) / (f + bm25K1*(1-bm25B+bm25B*float64(docLen)/avgLen))
+}
+
+// Snippet returns up to width runes of the block text around the first
+// match, with matched words wrapped in hlOpen and hlClose.
+func (h Hit) Snippet(width int, hlOpen, hlClose string) string {
+	return Snippet(h.Doc.Text, h.Terms, width, hlOpen, hlClose)
+}
+
+// Snippet returns up to width runes of text centred on the first token whose
+// term is in terms, wrapping every matched token in hlOpen and hlClose. Newlines
+// are flattened to spaces and trimmed edges are marked with an ellipsis.
+func Snippet(text string, terms map[string]bool, width int, hlOpen, hlClose string) string {
+	tokens := Tokenize(text)
+	var matches []Token
+	for _, tok := range tokens {
+		if terms[tok.Term] {
+			matches = append(matches, tok)
+		}
+	}
+
+	start, end := 0, len(text)
+	if width > 0 && utf8.RuneCountInString(text) > width {
+		center := 0
+		if len(matches) > 0 {
+			center = matches[0].Start
+		}
+		start = backRunes(text, center, width/3)
+		end = forwardRunes(text, start, width)
+		if end == len(text) {
+			start = backRunes(text, end, width)
+		}
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("…")
+	}
+	pos := start
+	for _, tok := range matches {
+		if tok.Start < start || tok.End > end {
+			continue
+		}
+		sb.WriteString(text[pos:tok.Start])
+		sb.WriteString(hlOpen)
+		sb.WriteString(text[tok.Start:tok.End])
+		sb.WriteString(hlClose)
+		pos = tok.End
+	}
+	sb.WriteString(text[pos:end])
+	if end < len(text) {
+		sb.WriteString("…")
+	}
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// backRunes returns the byte offset n runes before offset.
+func backRunes(text string, offset, n int) int {
+	for ; n > 0 && offset > 0; n-- {
+		_, size := utf8.DecodeLastRuneInString(text[:offset])
+		offset -= size
+	}
+	return offset
+}
+
+// forwardRunes returns the byte offset n runes after offset.
+func forwardRunes(text string, offset, n int) int {
+	for ; n > 0 && offset < len(text); n-- {
+		_, size := utf8.DecodeRuneInString(text[offset:])
+		offset += size
+	}
+	return offset
+}
@@ -0,0 +1,65 @@
+package index
+
+import "testing"
+
+func TestStem(t *testing.T) {
+	tests := map[string]string{
+		"caresses":        "caress",
+		"ponies":          "poni",
+		"ties":            "ti",
+		"cats":            "cat",
+		"feed":            "feed",
+		"agreed":          "agre",
+		"plastered":       "plaster",
+		"motoring":        "motor",
+		"sing":            "sing",
+		"hopping":         "hop",
+		"falling":         "fall",
+		"filing":          "file",
+		"happy":           "happi",
+		"relational":      "relat",
+		"conditional":     "condit",
+		"digitizer":       "digit",
+		"triplicate":      "triplic",
+		"adjustment":      "adjust",
+		"adoption":        "adopt",
+		"generalizations": "gener",
+		"running":         "run",
+		"go":              "go",
+		"v2":              "v2",
+		"café":            "café",
+	}
+	for word, want := range tests {
+		if got := Stem(word); got != want {
+			t.Errorf("Stem(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	text := "Meeting with #[[Project X]] about {{[[TODO]]}} don't"
+	tokens := Tokenize(text)
+
+	words := make([]string, len(tokens))
+	for i, tok := range tokens {
+		words[i] = tok.Word
+		if tok.Pos != i {
+			t.Fatalf("token %d has position %d", i, tok.Pos)
+		}
+		if text[tok.Start:tok.End] == "" {
+			t.Fatalf("token %q has empty span", tok.Word)
+		}
+	}
+	want := []string{"meeting", "with", "project", "x", "about", "todo", "don't"}
+	if len(words) != len(want) {
+		t.Fatalf("got %v, want %v", words, want)
+	}
+	for i := range want {
+		if words[i] != want[i] {
+			t.Fatalf("got %v, want %v", words, want)
+		}
+	}
+	if tokens[0].Term != "meet" {
+		t.Fatalf("expected stemmed term, got %q", tokens[0].Term)
+	}
+}
@@ -0,0 +1,92 @@
+package index
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned by Load when no index has been built yet.
+var ErrNotFound = errors.New("search index not found")
+
+// DefaultPath returns the index file for graph under the user cache
+// directory, e.g. ~/.cache/roam/index/<graph>.gob.
+func DefaultPath(graph string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("getting cache directory: %w", err)
+	}
+	return filepath.Join(dir, "roam", "index", safeName(graph)+".gob"), nil
+}
+
+func safeName(graph string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, strings.TrimSpace(graph))
+	if name == "" || strings.Trim(name, ".") == "" {
+		return "default"
+	}
+	return name
+}
+
+// Load reads the index at path.
+func Load(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+		}
+		return nil, fmt.Errorf("opening index: %w", err)
+	}
+	defer f.Close()
+
+	var ix Index
+	if err := gob.NewDecoder(f).Decode(&ix); err != nil {
+		return nil, fmt.Errorf("reading index %s: %w", path, err)
+	}
+	if ix.Version != FormatVersion {
+		return nil, fmt.Errorf("index %s has format version %d (want %d); rebuild it", path, ix.Version, FormatVersion)
+	}
+	if ix.Docs == nil {
+		ix.Docs = make(map[string]*Doc)
+	}
+	if ix.Postings == nil {
+		ix.Postings = make(map[string]map[string][]int)
+	}
+	if ix.Forms == nil {
+		ix.Forms = make(map[string]string)
+	}
+	return &ix, nil
+}
+
+// Save writes the index to path atomically, creating parent directories.
+func (ix *Index) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("creating index directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".index-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating index file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := gob.NewEncoder(tmp).Encode(ix); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing index: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("saving index: %w", err)
+	}
+	return nil
+}
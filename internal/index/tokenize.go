@@ -0,0 +1,72 @@
+package index
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token is a word found in a block string.
+type Token struct {
+	// Word is the lower-cased surface form.
+	Word string
+	// Term is the stemmed form stored in the index.
+	Term string
+	// Pos is the word position, used for phrase matching.
+	Pos int
+	// Start and End are byte offsets into the original text.
+	Start int
+	End   int
+}
+
+// Tokenize splits text into lower-cased, stemmed word tokens. Runs of letters
+// and digits form words; Roam markup such as [[ ]], #, (( )) and {{ }} acts as
+// a separator, so "#[[Project X]]" yields "project" and "x".
+func Tokenize(text string) []Token {
+	var tokens []Token
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		word := strings.ToLower(text[start:end])
+		tokens = append(tokens, Token{
+			Word:  word,
+			Term:  Stem(word),
+			Pos:   len(tokens),
+			Start: start,
+			End:   end,
+		})
+		start = -1
+	}
+
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+		} else if r == '\'' && start >= 0 && i+size < len(text) {
+			// Keep apostrophes inside words ("don't") but not at the edges.
+			next, _ := utf8.DecodeRuneInString(text[i+size:])
+			if !unicode.IsLetter(next) {
+				flush(i)
+			}
+		} else {
+			flush(i)
+		}
+		i += size
+	}
+	flush(len(text))
+	return tokens
+}
+
+// Terms returns the stemmed terms for text in order.
+func Terms(text string) []string {
+	tokens := Tokenize(text)
+	terms := make([]string, len(tokens))
+	for i, tok := range tokens {
+		terms[i] = tok.Term
+	}
+	return terms
+}
@@ -0,0 +1,203 @@
+// Package markup parses Roam's inline block syntax ([[links]], #tags,
+// ((block refs)), {{macros}}, attribute:: values, formatting, code and
+// LaTeX) into a typed tree, and renders trees back to block strings.
+//
+// Parsing never fails: text that does not form a complete construct is kept
+// as plain text, and Render(Parse(s)) == s for every s.
+package markup
+
+import "strings"
+
+// Kind identifies a node type.
+type Kind string
+
+// Node kinds.
+const (
+	// KindText is literal text.
+	KindText Kind = "text"
+	// KindPageRef is [[Title]]. Children hold the title's own markup (titles
+	// may nest refs, as in [[a [[b]]]]).
+	KindPageRef Kind = "page_ref"
+	// KindTag is #tag or #[[Multi Word]] (Bracketed).
+	KindTag Kind = "tag"
+	// KindBlockRef is ((uid)).
+	KindBlockRef Kind = "block_ref"
+	// KindMacro is {{...}}, such as {{embed: ((uid))}} or {{[[TODO]]}}.
+	KindMacro Kind = "macro"
+	// KindAttribute is "Name:: value" at the start of a block. Target is the
+	// name without formatting; Children hold the value.
+	KindAttribute Kind = "attribute"
+	// KindAlias is [label]([[Page]]), [label](((uid))) or [label](url).
+	// Children hold the label.
+	KindAlias Kind = "alias"
+	// KindBold, KindItalic, KindHighlight and KindStrike are **b**, __i__,
+	// ^^h^^ and ~~s~~.
+	KindBold      Kind = "bold"
+	KindItalic    Kind = "italic"
+	KindHighlight Kind = "highlight"
+	KindStrike    Kind = "strike"
+	// KindCode is `inline code`.
+	KindCode Kind = "code"
+	// KindCodeBlock is a ``` fenced block.
+	KindCodeBlock Kind = "code_block"
+	// KindLatex is $$math$$.
+	KindLatex Kind = "latex"
+)
+
+// AliasTarget kinds for KindAlias nodes.
+const (
+	AliasPage  = "page"
+	AliasBlock = "block"
+	AliasURL   = "url"
+)
+
+// Node is a parsed piece of a block string.
+type Node struct {
+	Kind Kind `json:"kind"`
+	// Start and End are byte offsets of the node in the parsed string.
+	Start int `json:"start"`
+	End   int `json:"end"`
+	// Text is the literal content of text, code, code block and LaTeX nodes.
+	// For code blocks it is everything between the fences, including the
+	// language line. For an attribute whose name is formatted, it is the
+	// name as written.
+	Text string `json:"text,omitempty"`
+	// Target is the page title (page refs, tags, page aliases), block UID
+	// (block refs, block aliases), URL (URL aliases) or attribute name.
+	Target string `json:"target,omitempty"`
+	// Name is a macro's name ("embed", "TODO", "query", ...).
+	Name string `json:"name,omitempty"`
+	// Lang is a code block's language, if given on the opening fence.
+	Lang string `json:"lang,omitempty"`
+	// AliasTo is AliasPage, AliasBlock or AliasURL for alias nodes.
+	AliasTo string `json:"alias_to,omitempty"`
+	// Bracketed marks #[[tags]].
+	Bracketed bool `json:"bracketed,omitempty"`
+	// Children are nested nodes: a ref's title, a macro's arguments, an
+	// alias label, an attribute value or formatted text.
+	Children []*Node `json:"children,omitempty"`
+}
+
+// Walk calls fn for each node in document order, descending into children
+// while fn returns true.
+func Walk(nodes []*Node, fn func(*Node) bool) {
+	for _, n := range nodes {
+		if fn(n) {
+			Walk(n.Children, fn)
+		}
+	}
+}
+
+// PageRefs returns the distinct page titles referenced by page refs, tags,
+// attribute names and page aliases, in document order.
+func PageRefs(nodes []*Node) []string {
+	var titles []string
+	seen := make(map[string]bool)
+	Walk(nodes, func(n *Node) bool {
+		switch n.Kind {
+		case KindPageRef, KindTag, KindAttribute:
+		case KindAlias:
+			if n.AliasTo != AliasPage {
+				return true
+			}
+		default:
+			return true
+		}
+		if !seen[n.Target] {
+			seen[n.Target] = true
+			titles = append(titles, n.Target)
+		}
+		return true
+	})
+	return titles
+}
+
+// BlockRefs returns the distinct block UIDs referenced by block refs
+// (including those inside embeds) and block aliases, in document order.
+func BlockRefs(nodes []*Node) []string {
+	var uids []string
+	seen := make(map[string]bool)
+	Walk(nodes, func(n *Node) bool {
+		if n.Kind == KindBlockRef || (n.Kind == KindAlias && n.AliasTo == AliasBlock) {
+			if !seen[n.Target] {
+				seen[n.Target] = true
+				uids = append(uids, n.Target)
+			}
+		}
+		return true
+	})
+	return uids
+}
+
+// RemapBlockRefs returns s with block refs (including those inside embeds)
+// and block aliases to the UIDs in remap pointed at their new UIDs.
+func RemapBlockRefs(s string, remap map[string]string) string {
+	if len(remap) == 0 || !strings.Contains(s, "((") {
+		return s
+	}
+	nodes := Parse(s)
+	changed := false
+	Walk(nodes, func(n *Node) bool {
+		if n.Kind == KindBlockRef || (n.Kind == KindAlias && n.AliasTo == AliasBlock) {
+			if uid, ok := remap[n.Target]; ok {
+				n.Target = uid
+				changed = true
+			}
+		}
+		return true
+	})
+	if !changed {
+		return s
+	}
+	return Render(nodes)
+}
+
+// RenamePageRefs returns s with page refs, tags, attribute names and page
+// aliases that point at the page titled from pointed at to instead,
+// including refs nested in other titles. A bare #tag becomes #[[to]] when
+// to cannot be written as a bare tag.
+func RenamePageRefs(s, from, to string) string {
+	if from == "" || !strings.Contains(s, from) {
+		return s
+	}
+	nodes := Parse(s)
+	changed := false
+	Walk(nodes, func(n *Node) bool {
+		switch n.Kind {
+		case KindPageRef, KindTag, KindAttribute:
+		case KindAlias:
+			if n.AliasTo != AliasPage {
+				return true
+			}
+		default:
+			return true
+		}
+		if n.Target != from {
+			return true
+		}
+		n.Target = to
+		changed = true
+		if n.Kind == KindAttribute {
+			n.Text = ""
+		}
+		if n.Kind == KindAttribute || n.Kind == KindAlias {
+			// Their children are the value and the label, not the title.
+			return true
+		}
+		n.Children = nil
+		if n.Kind == KindTag && !n.Bracketed && !isBareTag(to) {
+			n.Bracketed = true
+		}
+		return false
+	})
+	if !changed {
+		return s
+	}
+	return Render(nodes)
+}
+
+// isBareTag reports whether #title parses back as a tag for title.
+func isBareTag(title string) bool {
+	nodes := Parse("#" + title)
+	return len(nodes) == 1 && nodes[0].Kind == KindTag && !nodes[0].Bracketed && nodes[0].Target == title
+}
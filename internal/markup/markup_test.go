@@ -0,0 +1,159 @@
+package markup
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// describe renders nodes compactly for comparisons: kind(target){children}.
+func describe(nodes []*Node) string {
+	parts := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		s := string(n.Kind)
+		switch n.Kind {
+		case KindText, KindCode, KindCodeBlock, KindLatex:
+			s += fmt.Sprintf("%q", n.Text)
+		case KindMacro:
+			s += "(" + n.Name + ")"
+		default:
+			s += "(" + n.Target + ")"
+		}
+		if len(n.Children) > 0 && n.Kind != KindMacro {
+			s += "{" + describe(n.Children) + "}"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain text", `text"plain text"`},
+		{"see [[Page One]] now", `text"see " page_ref(Page One){text"Page One"} text" now"`},
+		{"[[a [[b]]]]", `page_ref(a [[b]]){text"a " page_ref(b){text"b"}}`},
+		{"#tag and #[[multi word]].", `tag(tag) text" and " tag(multi word){text"multi word"} text"."`},
+		{"end of #sentence.", `text"end of " tag(sentence) text"."`},
+		{"C# and http://x.io/#frag", `text"C# and http://x.io/#frag"`},
+		{"ref ((abc-12_X)) here", `text"ref " block_ref(abc-12_X) text" here"`},
+		{"(( not a ref ))", `text"(( not a ref ))"`},
+		{"{{embed: ((abc123))}}", `macro(embed)`},
+		{"{{[[TODO]]}} buy milk", `macro(TODO) text" buy milk"`},
+		{"Status:: [[Done]]", `attribute(Status){text" " page_ref(Done){text"Done"}}`},
+		{"[label]([[Target Page]])", `alias(Target Page){text"label"}`},
+		{"[see](((uid123)))", `alias(uid123){text"see"}`},
+		{"[site](https://example.com)", `alias(https://example.com){text"site"}`},
+		{"[not a link] (x)", `text"[not a link] (x)"`},
+		{"**bold [[a**b]]** __it__ ^^hi^^ ~~gone~~", `bold(){text"bold " page_ref(a**b){text"a**b"}} text" " italic(){text"it"} text" " highlight(){text"hi"} text" " strike(){text"gone"}`},
+		{"`[[not a ref]]` and $$x^2$$", `code"[[not a ref]]" text" and " latex"x^2"`},
+		{"```go\nfmt.Println(\"[[x]]\")\n```", "code_block\"go\\nfmt.Println(\\\"[[x]]\\\")\\n\""},
+		{"unclosed [[ref and **bold", `text"unclosed [[ref and **bold"`},
+		{"a:: b:: c", `attribute(a){text" b:: c"}`},
+		{"not an attr [[x]]:: y", `text"not an attr " page_ref(x){text"x"} text":: y"`},
+		{"Note that C++::operator", `text"Note that C++::operator"`},
+		{"Due date::soon", `text"Due date::soon"`},
+		{"Type::Book", `attribute(Type){text"Book"}`},
+		{"**Bold**:: x", `attribute(Bold){text" x"}`},
+	}
+	for _, tt := range tests {
+		nodes := Parse(tt.in)
+		if got := describe(nodes); got != tt.want {
+			t.Errorf("Parse(%q):\n got  %s\n want %s", tt.in, got, tt.want)
+		}
+		if got := Render(nodes); got != tt.in {
+			t.Errorf("Render(Parse(%q)) = %q", tt.in, got)
+		}
+	}
+}
+
+func TestParsePositions(t *testing.T) {
+	in := "x [[a [[b]]]] #t"
+	nodes := Parse(in)
+	var spans []string
+	Walk(nodes, func(n *Node) bool {
+		spans = append(spans, in[n.Start:n.End])
+		return true
+	})
+	want := []string{"x ", "[[a [[b]]]]", "a ", "[[b]]", "b", " ", "#t"}
+	if strings.Join(spans, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected spans %q", spans)
+	}
+}
+
+func TestParseCodeBlockLang(t *testing.T) {
+	nodes := Parse("```clojure\n(+ 1 2)```")
+	if len(nodes) != 1 || nodes[0].Lang != "clojure" {
+		t.Fatalf("expected clojure code block, got %s", describe(nodes))
+	}
+}
+
+func TestRoundTripOddInput(t *testing.T) {
+	inputs := []string{
+		"", "[[", "]]", "[[]]", "#", "# heading", "{{}}", "(())", "****", "``", "$$$$",
+		"[[a]]]", "[[[a]]", "#[[", "[x](", "[x](((y)", "**a __b** c__", "日本 #タグ [[ページ]]",
+	}
+	for _, in := range inputs {
+		if got := Render(Parse(in)); got != in {
+			t.Errorf("Render(Parse(%q)) = %q", in, got)
+		}
+	}
+}
+
+func TestRefs(t *testing.T) {
+	nodes := Parse("[[A]] #B #[[C [[A]]]] [x]([[D]]) ((uid1)) {{embed: ((uid2))}} [y](((uid1)))")
+	if got := strings.Join(PageRefs(nodes), ","); got != "A,B,C [[A]],D" {
+		t.Fatalf("unexpected page refs %q", got)
+	}
+	if got := strings.Join(BlockRefs(nodes), ","); got != "uid1,uid2" {
+		t.Fatalf("unexpected block refs %q", got)
+	}
+	if got := strings.Join(PageRefs(Parse("Type:: [[Book]]")), ","); got != "Type,Book" {
+		t.Fatalf("unexpected attribute refs %q", got)
+	}
+}
+
+func TestRenderEditedTarget(t *testing.T) {
+	nodes := Parse("see [[Old]] and #Old")
+	Walk(nodes, func(n *Node) bool {
+		if (n.Kind == KindPageRef || n.Kind == KindTag) && n.Target == "Old" {
+			n.Target, n.Children = "New Name", nil
+			n.Bracketed = n.Kind == KindTag
+		}
+		return true
+	})
+	if got := Render(nodes); got != "see [[New Name]] and #[[New Name]]" {
+		t.Fatalf("unexpected render %q", got)
+	}
+}
+
+func TestRemapBlockRefs(t *testing.T) {
+	remap := map[string]string{"old": "new"}
+	got := RemapBlockRefs("((old)) {{embed: ((old))}} [x](((old))) ((keep)) `((old))`", remap)
+	if got != "((new)) {{embed: ((new))}} [x](((new))) ((keep)) `((old))`" {
+		t.Fatalf("unexpected remap %q", got)
+	}
+}
+
+func TestRenamePageRefs(t *testing.T) {
+	got := RenamePageRefs("ML:: [[ML]] #ML #[[ML]] [x]([[ML]]) [[ML/sub]] [[a [[ML]]]] `[[ML]]` #MLOps", "ML", "Machine Learning")
+	want := "Machine Learning:: [[Machine Learning]] #[[Machine Learning]] #[[Machine Learning]] [x]([[Machine Learning]]) [[ML/sub]] [[a [[Machine Learning]]]] `[[ML]]` #MLOps"
+	if got != want {
+		t.Fatalf("unexpected rename\n got %q\nwant %q", got, want)
+	}
+	if got := RenamePageRefs("#ml and [[other]]", "ml", "ai"); got != "#ai and [[other]]" {
+		t.Fatalf("unexpected bare tag rename %q", got)
+	}
+	if got := RenamePageRefs("**Bold**:: x", "Bold", "Strong"); got != "Strong:: x" {
+		t.Fatalf("unexpected formatted attribute rename %q", got)
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	got := PlainText(Parse("**Read** [[Dune]] by [Frank](https://x.io) #[[sci fi]] `code`"))
+	if got != "Read Dune by Frank #sci fi code" {
+		t.Fatalf("unexpected plain text %q", got)
+	}
+}
@@ -0,0 +1,330 @@
+package markup
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Parse parses a block string. Offsets in the returned nodes index s.
+func Parse(s string) []*Node {
+	p := &parser{src: s}
+	if n := p.attribute(); n != nil {
+		return []*Node{n}
+	}
+	return p.inline(0, len(s))
+}
+
+type parser struct {
+	src string
+}
+
+// attribute parses "Name:: value" spanning the whole block.
+func (p *parser) attribute() *Node {
+	idx := strings.Index(p.src, "::")
+	if idx <= 0 {
+		return nil
+	}
+	name := p.src[:idx]
+	if name != strings.TrimSpace(name) || strings.ContainsAny(name, "\n`[]{}()") {
+		return nil
+	}
+	// A :: inside a word ends an attribute name only when the name is a
+	// single word, so "Note that C++::operator" stays a sentence.
+	if rest := p.src[idx+2:]; rest != "" && !unicode.IsSpace(rune(rest[0])) && strings.ContainsAny(name, " \t") {
+		return nil
+	}
+	n := &Node{
+		Kind:     KindAttribute,
+		Start:    0,
+		End:      len(p.src),
+		Target:   name,
+		Children: p.inline(idx+2, len(p.src)),
+	}
+	// Roam names the attribute by its text; the formatting is kept for
+	// rendering.
+	if plain := PlainText(p.inline(0, idx)); plain != name {
+		n.Target, n.Text = plain, name
+	}
+	return n
+}
+
+// inline parses src[lo:hi] into a sequence of nodes.
+func (p *parser) inline(lo, hi int) []*Node {
+	var nodes []*Node
+	textStart := lo
+	flush := func(at int) {
+		if at > textStart {
+			nodes = append(nodes, &Node{Kind: KindText, Start: textStart, End: at, Text: p.src[textStart:at]})
+		}
+	}
+
+	for i := lo; i < hi; {
+		n := p.construct(i, hi, true)
+		if n == nil {
+			_, size := utf8.DecodeRuneInString(p.src[i:hi])
+			i += size
+			continue
+		}
+		flush(i)
+		nodes = append(nodes, n)
+		i = n.End
+		textStart = i
+	}
+	flush(hi)
+	return nodes
+}
+
+// construct parses the construct starting at i, if any, ending by hi.
+// Formatting is only tried when format is set.
+func (p *parser) construct(i, hi int, format bool) *Node {
+	rest := p.src[i:hi]
+	switch rest[0] {
+	case '`':
+		if strings.HasPrefix(rest, "```") {
+			return p.codeBlock(i, hi)
+		}
+		return p.delimited(i, hi, "`", KindCode)
+	case '$':
+		if strings.HasPrefix(rest, "$$") {
+			return p.delimited(i, hi, "$$", KindLatex)
+		}
+	case '[':
+		if strings.HasPrefix(rest, "[[") {
+			if n := p.pageRef(i, hi); n != nil {
+				return n
+			}
+		}
+		return p.alias(i, hi)
+	case '(':
+		if strings.HasPrefix(rest, "((") {
+			return p.blockRef(i, hi)
+		}
+	case '{':
+		if strings.HasPrefix(rest, "{{") {
+			return p.macro(i, hi)
+		}
+	case '#':
+		return p.tag(i, hi)
+	case '*', '_', '^', '~':
+		if format && len(rest) >= 2 && rest[1] == rest[0] {
+			return p.format(i, hi, rest[:2])
+		}
+	}
+	return nil
+}
+
+// delimited parses open...close pairs with literal content, like `code`.
+func (p *parser) delimited(i, hi int, delim string, kind Kind) *Node {
+	start := i + len(delim)
+	closeAt := strings.Index(p.src[start:hi], delim)
+	if closeAt <= 0 {
+		return nil
+	}
+	end := start + closeAt + len(delim)
+	return &Node{Kind: kind, Start: i, End: end, Text: p.src[start : start+closeAt]}
+}
+
+func (p *parser) codeBlock(i, hi int) *Node {
+	n := p.delimited(i, hi, "```", KindCodeBlock)
+	if n == nil {
+		return nil
+	}
+	if nl := strings.IndexByte(n.Text, '\n'); nl > 0 {
+		if lang := strings.TrimSpace(n.Text[:nl]); lang != "" && !strings.ContainsAny(lang, " \t") {
+			n.Lang = lang
+		}
+	}
+	return n
+}
+
+// matchPair returns the offset just past the closeDelim matching the
+// openDelim at i, counting nested opens, or -1.
+func (p *parser) matchPair(i, hi int, openDelim, closeDelim string) int {
+	depth := 0
+	for j := i; j < hi; {
+		switch {
+		case strings.HasPrefix(p.src[j:hi], openDelim):
+			depth++
+			j += len(openDelim)
+		case strings.HasPrefix(p.src[j:hi], closeDelim):
+			depth--
+			j += len(closeDelim)
+			if depth == 0 {
+				return j
+			}
+		default:
+			j++
+		}
+	}
+	return -1
+}
+
+func (p *parser) pageRef(i, hi int) *Node {
+	end := p.matchPair(i, hi, "[[", "]]")
+	if end < 0 || end-i <= 4 {
+		return nil
+	}
+	return &Node{
+		Kind:     KindPageRef,
+		Start:    i,
+		End:      end,
+		Target:   p.src[i+2 : end-2],
+		Children: p.inline(i+2, end-2),
+	}
+}
+
+func (p *parser) blockRef(i, hi int) *Node {
+	start := i + 2
+	closeAt := strings.Index(p.src[start:hi], "))")
+	if closeAt <= 0 || !isUID(p.src[start:start+closeAt]) {
+		return nil
+	}
+	return &Node{Kind: KindBlockRef, Start: i, End: start + closeAt + 2, Target: p.src[start : start+closeAt]}
+}
+
+func (p *parser) macro(i, hi int) *Node {
+	end := p.matchPair(i, hi, "{{", "}}")
+	if end < 0 || end-i <= 4 {
+		return nil
+	}
+	inner := p.src[i+2 : end-2]
+	name := inner
+	if colon := strings.IndexByte(name, ':'); colon >= 0 {
+		name = name[:colon]
+	}
+	name = strings.TrimSpace(name)
+	if strings.HasPrefix(name, "[[") && strings.HasSuffix(name, "]]") {
+		name = name[2 : len(name)-2]
+	}
+	return &Node{
+		Kind:     KindMacro,
+		Start:    i,
+		End:      end,
+		Name:     strings.TrimSpace(name),
+		Children: p.inline(i+2, end-2),
+	}
+}
+
+// alias parses [label]([[Page]]), [label](((uid))) and [label](url).
+func (p *parser) alias(i, hi int) *Node {
+	labelEnd := p.matchPair(i, hi, "[", "]")
+	if labelEnd < 0 || labelEnd-i <= 2 || labelEnd >= hi || p.src[labelEnd] != '(' {
+		return nil
+	}
+	n := &Node{Kind: KindAlias, Start: i, Children: p.inline(i+1, labelEnd-1)}
+
+	dest := labelEnd + 1
+	rest := p.src[dest:hi]
+	switch {
+	case strings.HasPrefix(rest, "[["):
+		end := p.matchPair(dest, hi, "[[", "]]")
+		if end < 0 || end >= hi || p.src[end] != ')' {
+			return nil
+		}
+		n.AliasTo, n.Target, n.End = AliasPage, p.src[dest+2:end-2], end+1
+	case strings.HasPrefix(rest, "(("):
+		closeAt := strings.Index(rest, ")))")
+		if closeAt <= 2 || !isUID(rest[2:closeAt]) {
+			return nil
+		}
+		n.AliasTo, n.Target, n.End = AliasBlock, rest[2:closeAt], dest+closeAt+3
+	default:
+		closeAt := strings.IndexByte(rest, ')')
+		if closeAt <= 0 || strings.ContainsAny(rest[:closeAt], " \t\n") {
+			return nil
+		}
+		n.AliasTo, n.Target, n.End = AliasURL, rest[:closeAt], dest+closeAt+1
+	}
+	return n
+}
+
+// tag parses #tag and #[[multi word tag]]. A tag must start the text or
+// follow whitespace or an opening bracket or quote, so URL fragments and
+// words like C# are left alone.
+func (p *parser) tag(i, hi int) *Node {
+	if i > 0 {
+		prev, _ := utf8.DecodeLastRuneInString(p.src[:i])
+		if !unicode.IsSpace(prev) && !strings.ContainsRune(`([{"'`, prev) {
+			return nil
+		}
+	}
+	start := i + 1
+	if strings.HasPrefix(p.src[start:hi], "[[") {
+		end := p.matchPair(start, hi, "[[", "]]")
+		if end < 0 || end-start <= 4 {
+			return nil
+		}
+		return &Node{
+			Kind:      KindTag,
+			Start:     i,
+			End:       end,
+			Target:    p.src[start+2 : end-2],
+			Bracketed: true,
+			Children:  p.inline(start+2, end-2),
+		}
+	}
+
+	end := start
+	for end < hi {
+		r, size := utf8.DecodeRuneInString(p.src[end:hi])
+		if unicode.IsSpace(r) || strings.ContainsRune(`#,;!?"()[]{}<>`+"`", r) {
+			break
+		}
+		end += size
+	}
+	// Trailing punctuation ends a sentence rather than the tag.
+	for end > start && strings.ContainsRune(".:'", rune(p.src[end-1])) {
+		end--
+	}
+	if end == start {
+		return nil
+	}
+	return &Node{Kind: KindTag, Start: i, End: end, Target: p.src[start:end]}
+}
+
+// format parses **bold**, __italic__, ^^highlight^^ and ~~strike~~. The
+// closing delimiter is searched past refs, macros, code and LaTeX, so a
+// delimiter inside [[a**b]] does not close the span.
+func (p *parser) format(i, hi int, delim string) *Node {
+	start := i + len(delim)
+	for j := start; j < hi; {
+		if strings.HasPrefix(p.src[j:hi], delim) {
+			if j == start {
+				return nil
+			}
+			return &Node{
+				Kind:     formatKinds[delim],
+				Start:    i,
+				End:      j + len(delim),
+				Children: p.inline(start, j),
+			}
+		}
+		if n := p.construct(j, hi, false); n != nil {
+			j = n.End
+			continue
+		}
+		j++
+	}
+	return nil
+}
+
+var formatKinds = map[string]Kind{
+	"**": KindBold,
+	"__": KindItalic,
+	"^^": KindHighlight,
+	"~~": KindStrike,
+}
+
+// isUID reports whether s looks like a Roam block UID.
+func isUID(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r == '-' || r == '_' || (r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)))) {
+			return false
+		}
+	}
+	return true
+}
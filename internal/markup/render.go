@@ -0,0 +1,113 @@
+package markup
+
+import "strings"
+
+// Render converts nodes back to a block string. Nodes produced by Parse
+// render to exactly the parsed text.
+//
+// Refs, tags, macros and attributes render their Children when present and
+// fall back to Target (or Name) otherwise, so code that edits a node's
+// Target should clear its Children.
+func Render(nodes []*Node) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		n.render(&sb)
+	}
+	return sb.String()
+}
+
+// String renders the node as Roam markup.
+func (n *Node) String() string {
+	var sb strings.Builder
+	n.render(&sb)
+	return sb.String()
+}
+
+func (n *Node) render(sb *strings.Builder) {
+	switch n.Kind {
+	case KindText:
+		sb.WriteString(n.Text)
+	case KindPageRef:
+		sb.WriteString("[[" + n.inner(n.Target) + "]]")
+	case KindTag:
+		if n.Bracketed {
+			sb.WriteString("#[[" + n.inner(n.Target) + "]]")
+		} else {
+			sb.WriteString("#" + n.Target)
+		}
+	case KindBlockRef:
+		sb.WriteString("((" + n.Target + "))")
+	case KindMacro:
+		sb.WriteString("{{" + n.inner(n.Name) + "}}")
+	case KindAttribute:
+		name := n.Target
+		if n.Text != "" {
+			name = n.Text
+		}
+		sb.WriteString(name + "::" + Render(n.Children))
+	case KindAlias:
+		sb.WriteString("[" + Render(n.Children) + "](")
+		switch n.AliasTo {
+		case AliasPage:
+			sb.WriteString("[[" + n.Target + "]]")
+		case AliasBlock:
+			sb.WriteString("((" + n.Target + "))")
+		default:
+			sb.WriteString(n.Target)
+		}
+		sb.WriteString(")")
+	case KindBold, KindItalic, KindHighlight, KindStrike:
+		delim := formatDelims[n.Kind]
+		sb.WriteString(delim + Render(n.Children) + delim)
+	case KindCode:
+		sb.WriteString("`" + n.Text + "`")
+	case KindCodeBlock:
+		sb.WriteString("```" + n.Text + "```")
+	case KindLatex:
+		sb.WriteString("$$" + n.Text + "$$")
+	}
+}
+
+func (n *Node) inner(fallback string) string {
+	if len(n.Children) > 0 {
+		return Render(n.Children)
+	}
+	return fallback
+}
+
+var formatDelims = map[Kind]string{
+	KindBold:      "**",
+	KindItalic:    "__",
+	KindHighlight: "^^",
+	KindStrike:    "~~",
+}
+
+// PlainText renders nodes as the text Roam displays, without markup: refs
+// show their titles, aliases their labels, formatting its content. Block
+// refs and macros, whose display depends on other blocks, stay as written.
+func PlainText(nodes []*Node) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		switch n.Kind {
+		case KindText:
+			sb.WriteString(n.Text)
+		case KindPageRef:
+			sb.WriteString(PlainText(n.Children))
+		case KindTag:
+			if n.Bracketed {
+				sb.WriteString("#" + PlainText(n.Children))
+			} else {
+				sb.WriteString("#" + n.Target)
+			}
+		case KindAttribute:
+			sb.WriteString(n.Target + ":" + PlainText(n.Children))
+		case KindAlias, KindBold, KindItalic, KindHighlight, KindStrike:
+			sb.WriteString(PlainText(n.Children))
+		case KindCode, KindCodeBlock, KindLatex:
+			sb.WriteString(n.Text)
+		default:
+			sb.WriteString(n.String())
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,111 @@
+// Package mdblocks converts Markdown into Roam block trees.
+//
+// Headings become heading blocks (levels 4-6 are written as level 3) with
+// the content that follows them nested underneath, up to the next heading
+// of the same or a higher level. List items nest by indentation, numbered
+// lists set their parent's view type to "numbered", and "- [ ]" / "- [x]"
+// items become {{[[TODO]]}} / {{[[DONE]]}}. A "- ## " item is a heading
+// block, as Roam's own Markdown writes them. A paragraph, blockquote or
+// fenced code block becomes one block, as does a list item whose text runs
+// into a fence, and a table becomes a {{table}} block with one child chain
+// per row, as Roam lays out tables.
+//
+// In outline mode every line is a block of its own, nested under the
+// nearest less-indented line above it: the plain indented text that the
+// append command accepts.
+package mdblocks
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// DefaultIndent is the number of columns a tab counts as by default.
+const DefaultIndent = 4
+
+// Block is a block to create, with its children.
+type Block struct {
+	// UID is the uid to create the block with; the parser leaves it empty
+	// for callers such as the vault loader to fill in.
+	UID      string
+	String   string
+	Heading  int
+	ViewType string
+	// ID is the Obsidian block id (" ^id") the block was marked with; it is
+	// only read when Options.BlockIDs is set.
+	ID       string
+	Children []*Block
+	// Assets are local files the block links to, still written as local
+	// paths in String until they are uploaded. The parser does not set
+	// them.
+	Assets []Asset
+}
+
+// Asset is a local file linked from a block.
+type Asset struct {
+	// File is the absolute path of the linked file.
+	File string
+	// Text is the link as written into the block string.
+	Text string
+	// Alt is the link text, if any.
+	Alt string
+	// Title is the link's title with its quotes, as in ![alt](x.png "Title"),
+	// if any.
+	Title string
+}
+
+// IsImage reports whether the asset is an image by its extension.
+func (a Asset) IsImage() bool {
+	switch strings.ToLower(filepath.Ext(a.File)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".svg", ".bmp", ".avif":
+		return true
+	}
+	return false
+}
+
+// Link returns the Roam markup linking to the uploaded asset at url.
+func (a Asset) Link(url string) string {
+	if a.Title != "" && !strings.EqualFold(filepath.Ext(a.File), ".pdf") {
+		url += " " + a.Title
+	}
+	switch {
+	case a.IsImage():
+		return "![" + a.Alt + "](" + url + ")"
+	case strings.EqualFold(filepath.Ext(a.File), ".pdf"):
+		return "{{pdf: " + url + "}}"
+	}
+	alt := a.Alt
+	if alt == "" {
+		alt = filepath.Base(a.File)
+	}
+	return "[" + alt + "](" + url + ")"
+}
+
+// Options configure parsing.
+type Options struct {
+	// Indent is the number of columns a tab counts as when comparing
+	// indentation. Zero means DefaultIndent.
+	Indent int
+	// Outline makes every non-blank line its own block, nested by
+	// indentation, instead of joining lines into paragraphs.
+	Outline bool
+	// BlockIDs takes Obsidian " ^id" markers off blocks into Block.ID.
+	BlockIDs bool
+}
+
+// Count returns the number of blocks in a tree.
+func Count(blocks []*Block) int {
+	n := len(blocks)
+	for _, b := range blocks {
+		n += Count(b.Children)
+	}
+	return n
+}
+
+// Walk calls fn for every block in a tree, parents before children.
+func Walk(blocks []*Block, fn func(*Block)) {
+	for _, b := range blocks {
+		fn(b)
+		Walk(b.Children, fn)
+	}
+}
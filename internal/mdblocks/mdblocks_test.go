@@ -0,0 +1,98 @@
+package mdblocks
+
+import "testing"
+
+func TestParseHeadingsNestContent(t *testing.T) {
+	blocks, _ := Parse("# Title\nIntro\n## Part\n- item\n#### Deep\n# Next\n", Options{})
+	if len(blocks) != 2 || blocks[0].String != "Title" || blocks[0].Heading != 1 {
+		t.Fatalf("unexpected roots: %+v", blocks)
+	}
+	title := blocks[0]
+	if len(title.Children) != 2 || title.Children[0].String != "Intro" {
+		t.Fatalf("unexpected section: %+v", title.Children)
+	}
+	part := title.Children[1]
+	if part.Heading != 2 || len(part.Children) != 2 || part.Children[0].String != "item" {
+		t.Fatalf("unexpected subsection: %+v", part)
+	}
+	if deep := part.Children[1]; deep.Heading != 3 || deep.String != "Deep" {
+		t.Fatalf("level 4 heading not clamped: %+v", deep)
+	}
+}
+
+func TestParseListsTasksAndFences(t *testing.T) {
+	text := "1. one\n\t- [ ] todo\n\t- [x] done\n2. two\n\n```go\nfunc f() {\n\n\treturn\n}\n```\n"
+	blocks, viewType := Parse(text, Options{})
+	if viewType != "numbered" || len(blocks) != 3 {
+		t.Fatalf("unexpected blocks: %q %+v", viewType, blocks)
+	}
+	one := blocks[0]
+	if len(one.Children) != 2 || one.Children[0].String != "{{[[TODO]]}} todo" || one.Children[1].String != "{{[[DONE]]}} done" {
+		t.Fatalf("unexpected tasks: %+v", one.Children)
+	}
+	if want := "```go\nfunc f() {\n\n\treturn\n}\n```"; blocks[2].String != want {
+		t.Fatalf("fence not kept whole: %q", blocks[2].String)
+	}
+}
+
+func TestParseTableAndQuote(t *testing.T) {
+	text := "| A | B |\n|---|:-:|\n| 1 | `x\\|y` |\n| 2 |\n\n> quoted\n> more\n>\n> again\n"
+	blocks, _ := Parse(text, Options{})
+	if len(blocks) != 3 || blocks[0].String != "{{table}}" || len(blocks[0].Children) != 3 {
+		t.Fatalf("unexpected blocks: %+v", blocks)
+	}
+	row := blocks[0].Children[1]
+	if row.String != "1" || len(row.Children) != 1 || row.Children[0].String != "`x|y`" {
+		t.Fatalf("unexpected row: %+v", row)
+	}
+	if short := blocks[0].Children[2]; len(short.Children) != 1 || short.Children[0].String != "" {
+		t.Fatalf("short row not padded: %+v", short)
+	}
+	if blocks[1].String != "> quoted\nmore" || blocks[2].String != "> again" {
+		t.Fatalf("unexpected quotes: %q, %q", blocks[1].String, blocks[2].String)
+	}
+}
+
+func TestParseOutline(t *testing.T) {
+	text := "Parent\n  Child\n\tTabbed\n- ## Item\n    Nested\n"
+	blocks, _ := Parse(text, Options{Outline: true, Indent: 2})
+	if len(blocks) != 2 || len(blocks[0].Children) != 2 {
+		t.Fatalf("unexpected outline: %+v", blocks)
+	}
+	if blocks[0].Children[1].String != "Tabbed" {
+		t.Fatalf("tab not counted as indent: %+v", blocks[0].Children)
+	}
+	item := blocks[1]
+	if item.String != "## Item" || len(item.Children) != 1 || item.Children[0].String != "Nested" {
+		t.Fatalf("unexpected item: %+v", item)
+	}
+	if blocks, _ := Parse("# Head\nLine", Options{Outline: true}); len(blocks) != 2 || blocks[0].Heading != 1 {
+		t.Fatalf("unexpected outline heading: %+v", blocks)
+	}
+}
+
+func TestParseBlockIDs(t *testing.T) {
+	text := "Para ^p1\n\n- item\n  ^i1\n"
+	blocks, _ := Parse(text, Options{BlockIDs: true})
+	if blocks[0].String != "Para" || blocks[0].ID != "p1" || blocks[1].ID != "i1" {
+		t.Fatalf("unexpected ids: %+v %+v", blocks[0], blocks[1])
+	}
+	if blocks, _ := Parse("Para ^p1", Options{}); blocks[0].String != "Para ^p1" {
+		t.Fatalf("id read without BlockIDs: %+v", blocks[0])
+	}
+	if n := Count(blocks); n != 2 {
+		t.Fatalf("Count = %d", n)
+	}
+}
+
+func TestParseRoamMarkdownItems(t *testing.T) {
+	text := "- ## Agenda\n  - ```js\n    x()\n\n    ```\n  - see\n    ```\n    - not an item\n    ```\n- after\n"
+	blocks, _ := Parse(text, Options{})
+	if len(blocks) != 2 || blocks[0].Heading != 2 || blocks[0].String != "Agenda" || blocks[1].String != "after" {
+		t.Fatalf("unexpected roots: %+v", blocks)
+	}
+	kids := blocks[0].Children
+	if len(kids) != 2 || kids[0].String != "```js\nx()\n\n```" || kids[1].String != "see\n```\n- not an item\n```" {
+		t.Fatalf("unexpected fenced items: %+v", kids)
+	}
+}
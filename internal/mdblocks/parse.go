@@ -0,0 +1,480 @@
+package mdblocks
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	mdHeading  = regexp.MustCompile(`^(#{1,6})\s+(.*?)(?:\s+#+)?\s*$`)
+	mdListItem = regexp.MustCompile(`^([ \t]*)([-*+]|\d+[.)])(?:\s+(.*))?$`)
+	mdFence    = regexp.MustCompile("^([ \t]*)(```+|~~~+)")
+	mdRule     = regexp.MustCompile(`^ {0,3}([-*_])(\s*[-*_]){2,}\s*$`)
+	mdSetext   = regexp.MustCompile(`^ {0,3}(=+|-+)\s*$`)
+	mdQuote    = regexp.MustCompile(`^ {0,3}> ?(.*)$`)
+	mdTask     = regexp.MustCompile(`^\[([ xX])\]\s+`)
+	mdTableSep = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	// Obsidian block ids: " ^id" at the end of a line, or alone on the
+	// line after the block.
+	mdBlockID     = regexp.MustCompile(`\s+\^([A-Za-z0-9-]+)$`)
+	mdBlockIDLine = regexp.MustCompile(`^\s*\^([A-Za-z0-9-]+)\s*$`)
+)
+
+// parser builds a block tree line by line. Headings open sections that nest
+// by level; list items nest by indentation under the current section.
+type parser struct {
+	opts     Options
+	roots    []*Block
+	viewType string
+
+	sections []*Block
+	levels   []int
+	list     []*Block
+	indents  []int
+
+	para  *Block
+	quote *Block
+	last  *Block
+	fence *Block
+	// fenceMark and fenceIndent describe the open code fence.
+	fenceMark   string
+	fenceIndent int
+	// lazy is set while a list item can take continuation lines.
+	lazy bool
+}
+
+// Parse returns the block tree for a Markdown document and the view type
+// for its top-level blocks ("numbered" when they form a numbered list).
+func Parse(text string, opts Options) ([]*Block, string) {
+	if opts.Indent <= 0 {
+		opts.Indent = DefaultIndent
+	}
+	p := &parser{opts: opts}
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		if p.fence == nil {
+			if n := p.table(lines[i:]); n > 0 {
+				i += n - 1
+				continue
+			}
+		}
+		if opts.Outline {
+			p.outlineLine(lines[i])
+		} else {
+			p.line(lines[i])
+		}
+	}
+	return p.roots, p.viewType
+}
+
+// fenceLine adds a line to the open code fence, closing it on the closing
+// fence.
+func (p *parser) fenceLine(line string) {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, p.fenceMark) && strings.Trim(trimmed, p.fenceMark[:1]) == "" {
+		p.fence.String += "\n" + p.fenceMark
+		p.fence = nil
+		return
+	}
+	p.fence.String += "\n" + StripIndent(line, p.fenceIndent, p.opts.Indent)
+}
+
+func (p *parser) line(line string) {
+	if p.fence != nil {
+		p.fenceLine(line)
+		return
+	}
+
+	trimmed := strings.TrimSpace(line)
+	indent := p.width(Leading(line))
+	switch {
+	case trimmed == "":
+		p.para, p.quote, p.lazy = nil, nil, false
+		return
+
+	case p.opts.BlockIDs && mdBlockIDLine.MatchString(line) && p.last != nil:
+		p.last.ID = mdBlockIDLine.FindStringSubmatch(line)[1]
+		return
+
+	case mdFence.MatchString(line):
+		m := mdFence.FindStringSubmatch(line)
+		if len(p.list) > 0 && p.lazy && indent > p.indents[len(p.indents)-1] {
+			// A fence straight after a list item's text continues the item.
+			item := p.list[len(p.list)-1]
+			item.String += "\n" + trimmed
+			p.fence, p.fenceMark, p.fenceIndent = item, m[2], p.width(m[1])
+			return
+		}
+		b := p.newBlock(trimmed)
+		p.attachIndented(b, indent)
+		p.fence, p.fenceMark, p.fenceIndent = b, m[2], p.width(m[1])
+		p.para, p.quote = nil, nil
+		return
+
+	case p.para != nil && mdSetext.MatchString(line) && len(p.list) == 0:
+		// Setext heading: the paragraph above becomes the heading.
+		level := 2
+		if strings.HasPrefix(trimmed, "=") {
+			level = 1
+		}
+		p.promoteToHeading(p.para, level)
+		p.para = nil
+		return
+
+	case mdRule.MatchString(line):
+		p.endList()
+		p.add(p.newBlock("---"))
+		p.para, p.quote = nil, nil
+		return
+	}
+
+	if m := mdQuote.FindStringSubmatch(line); m != nil && (indent < 4 || len(p.list) > 0) {
+		p.para = nil
+		text := strings.TrimRight(m[1], " \t")
+		switch {
+		case text == "":
+			// An empty quote line separates quoted paragraphs.
+			p.quote = nil
+		case p.quote != nil:
+			p.quote.String += "\n" + text
+			p.takeBlockID(p.quote)
+		default:
+			b := p.newBlock("> " + text)
+			p.attachIndented(b, indent)
+			p.quote = b
+		}
+		return
+	}
+	p.quote = nil
+
+	if m := mdHeading.FindStringSubmatch(line); m != nil && indent < 4 {
+		p.endList()
+		p.para = nil
+		level := len(m[1])
+		p.popSections(level)
+		b := p.newBlock(m[2])
+		b.Heading = min(level, 3)
+		p.add(b)
+		p.sections = append(p.sections, b)
+		p.levels = append(p.levels, level)
+		return
+	}
+
+	if m := mdListItem.FindStringSubmatch(line); m != nil {
+		p.para = nil
+		p.listItem(p.width(m[1]), m[2], m[3])
+		p.lazy = true
+		if h := mdHeading.FindStringSubmatch(m[3]); h != nil {
+			// "- ## Text" is how Roam writes a heading block in a list.
+			item := p.list[len(p.list)-1]
+			item.String, item.Heading = h[2], min(len(h[1]), 3)
+			p.takeBlockID(item)
+		}
+		if f := mdFence.FindStringSubmatch(m[3]); f != nil {
+			// The item's text opens a code fence; its lines follow at the
+			// item's content column.
+			p.fence, p.fenceMark = p.list[len(p.list)-1], f[2]
+			p.fenceIndent = p.width(Leading(line)) + len(line) - len(Leading(line)) - len(m[3])
+		}
+		return
+	}
+
+	if len(p.list) > 0 && (p.lazy || indent > p.indents[0]) {
+		// Continuation of the current list item.
+		item := p.list[len(p.list)-1]
+		if p.lazy {
+			item.String += "\n" + trimmed
+		} else {
+			item.String += "\n\n" + trimmed
+		}
+		p.lazy = true
+		p.last = item
+		p.takeBlockID(item)
+		return
+	}
+
+	if p.para != nil {
+		p.para.String += "\n" + trimmed
+		p.takeBlockID(p.para)
+		return
+	}
+	p.endList()
+	b := p.newBlock(trimmed)
+	p.add(b)
+	p.para = b
+}
+
+// outlineLine adds one line of outline text: a block nested under the
+// nearest less-indented line above it.
+func (p *parser) outlineLine(line string) {
+	if p.fence != nil {
+		p.fenceLine(line)
+		return
+	}
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return
+	}
+	indent := p.width(Leading(line))
+	if p.opts.BlockIDs && mdBlockIDLine.MatchString(line) && p.last != nil {
+		p.last.ID = mdBlockIDLine.FindStringSubmatch(line)[1]
+		return
+	}
+	if m := mdFence.FindStringSubmatch(line); m != nil {
+		b := p.newBlock(trimmed)
+		p.nest(b, indent)
+		p.fence, p.fenceMark, p.fenceIndent = b, m[2], p.width(m[1])
+		return
+	}
+	if m := mdListItem.FindStringSubmatch(line); m != nil {
+		p.listItem(indent, m[2], m[3])
+		return
+	}
+	b := p.newBlock(trimmed)
+	if m := mdHeading.FindStringSubmatch(trimmed); m != nil {
+		b.String = m[2]
+		p.takeBlockID(b)
+		b.Heading = min(len(m[1]), 3)
+	}
+	p.nest(b, indent)
+}
+
+// listItem adds a list item at indent.
+func (p *parser) listItem(indent int, marker, content string) {
+	for len(p.indents) > 0 && p.indents[len(p.indents)-1] >= indent {
+		p.list = p.list[:len(p.list)-1]
+		p.indents = p.indents[:len(p.indents)-1]
+	}
+	if t := mdTask.FindStringSubmatch(content); t != nil {
+		prefix := "{{[[TODO]]}} "
+		if t[1] != " " {
+			prefix = "{{[[DONE]]}} "
+		}
+		content = prefix + content[len(t[0]):]
+	}
+	b := p.newBlock(content)
+	numbered := marker != "-" && marker != "*" && marker != "+"
+	if len(p.list) > 0 {
+		parent := p.list[len(p.list)-1]
+		parent.Children = append(parent.Children, b)
+		if numbered {
+			parent.ViewType = "numbered"
+		}
+	} else {
+		p.add(b)
+		if numbered {
+			p.setContainerViewType("numbered")
+		}
+	}
+	p.list = append(p.list, b)
+	p.indents = append(p.indents, indent)
+}
+
+// nest adds an outline block under the nearest less-indented block.
+func (p *parser) nest(b *Block, indent int) {
+	for len(p.indents) > 0 && p.indents[len(p.indents)-1] >= indent {
+		p.list = p.list[:len(p.list)-1]
+		p.indents = p.indents[:len(p.indents)-1]
+	}
+	if len(p.list) > 0 {
+		parent := p.list[len(p.list)-1]
+		parent.Children = append(parent.Children, b)
+	} else {
+		p.add(b)
+	}
+	p.list = append(p.list, b)
+	p.indents = append(p.indents, indent)
+}
+
+// table reads a table at the start of lines: a header row, a delimiter row
+// and body rows. It returns the number of lines read, or 0 when lines do
+// not start with a table.
+func (p *parser) table(lines []string) int {
+	if len(lines) < 2 || !strings.Contains(lines[0], "|") || !mdTableSep.MatchString(lines[1]) || !strings.Contains(lines[1], "-") {
+		return 0
+	}
+	header := tableCells(lines[0])
+	if len(header) != len(tableCells(lines[1])) {
+		return 0
+	}
+	t := p.newBlock("{{table}}")
+	rows := [][]string{header}
+	n := 2
+	for ; n < len(lines); n++ {
+		if strings.TrimSpace(lines[n]) == "" || !strings.Contains(lines[n], "|") {
+			break
+		}
+		rows = append(rows, tableCells(lines[n]))
+	}
+	for _, cells := range rows {
+		var parent *Block
+		for i := range header {
+			cell := &Block{}
+			if i < len(cells) {
+				cell.String = cells[i]
+			}
+			if parent == nil {
+				t.Children = append(t.Children, cell)
+			} else {
+				parent.Children = append(parent.Children, cell)
+			}
+			parent = cell
+		}
+	}
+
+	if p.opts.Outline {
+		p.nest(t, p.width(Leading(lines[0])))
+	} else {
+		p.attachIndented(t, p.width(Leading(lines[0])))
+	}
+	p.para, p.quote, p.lazy = nil, nil, false
+	return n
+}
+
+// tableCells splits a table row into trimmed cells, honouring \| escapes
+// and pipes inside code spans.
+func tableCells(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+	if strings.HasSuffix(row, "|") && !strings.HasSuffix(row, `\|`) {
+		row = row[:len(row)-1]
+	}
+	var cells []string
+	var cell strings.Builder
+	inCode := false
+	for i := 0; i < len(row); i++ {
+		c := row[i]
+		switch {
+		case c == '\\' && i+1 < len(row) && row[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case c == '`':
+			inCode = !inCode
+			cell.WriteByte(c)
+		case c == '|' && !inCode:
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(c)
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// newBlock returns a block for a new line of content, taking a trailing
+// ^block-id off it.
+func (p *parser) newBlock(text string) *Block {
+	b := &Block{String: text}
+	p.takeBlockID(b)
+	p.last = b
+	return b
+}
+
+func (p *parser) takeBlockID(b *Block) {
+	if !p.opts.BlockIDs {
+		return
+	}
+	if m := mdBlockID.FindStringSubmatchIndex(b.String); m != nil {
+		b.ID = b.String[m[2]:m[3]]
+		b.String = b.String[:m[0]]
+	}
+}
+
+// add appends a block to the current section, or the page.
+func (p *parser) add(b *Block) {
+	if len(p.sections) > 0 {
+		parent := p.sections[len(p.sections)-1]
+		parent.Children = append(parent.Children, b)
+		return
+	}
+	p.roots = append(p.roots, b)
+}
+
+// attachIndented adds a block under the deepest list item it is indented
+// beneath, or to the current section.
+func (p *parser) attachIndented(b *Block, indent int) {
+	for i := len(p.list) - 1; i >= 0; i-- {
+		if p.indents[i] < indent {
+			p.list[i].Children = append(p.list[i].Children, b)
+			return
+		}
+	}
+	p.endList()
+	p.add(b)
+}
+
+func (p *parser) setContainerViewType(viewType string) {
+	if len(p.sections) > 0 {
+		p.sections[len(p.sections)-1].ViewType = viewType
+		return
+	}
+	p.viewType = viewType
+}
+
+func (p *parser) popSections(level int) {
+	for len(p.levels) > 0 && p.levels[len(p.levels)-1] >= level {
+		p.sections = p.sections[:len(p.sections)-1]
+		p.levels = p.levels[:len(p.levels)-1]
+	}
+}
+
+func (p *parser) promoteToHeading(b *Block, level int) {
+	b.Heading = level
+	p.popSections(level)
+	p.sections = append(p.sections, b)
+	p.levels = append(p.levels, level)
+}
+
+func (p *parser) endList() {
+	p.list, p.indents, p.lazy = nil, nil, false
+}
+
+// width returns the column width of leading whitespace.
+func (p *parser) width(ws string) int {
+	return Width(ws, p.opts.Indent)
+}
+
+// Leading returns the whitespace a line starts with.
+func Leading(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// Width returns the column width of leading whitespace, with tabs as tab
+// columns.
+func Width(ws string, tab int) int {
+	n := 0
+	for _, r := range ws {
+		if r == '\t' {
+			n += tab
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// StripIndent removes up to n columns of leading whitespace, with tabs as
+// tab columns.
+func StripIndent(line string, n, tab int) string {
+	col := 0
+	for i, r := range line {
+		if col >= n || (r != ' ' && r != '\t') {
+			return line[i:]
+		}
+		if r == '\t' {
+			col += tab
+		} else {
+			col++
+		}
+	}
+	return ""
+}
+
+// FenceMark returns the ``` or ~~~ run that opens or closes a code fence on
+// line, or "" when the line is not a fence.
+func FenceMark(line string) string {
+	if m := mdFence.FindStringSubmatch(line); m != nil {
+		return m[2]
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package mdsync
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/salmonumbrella/roam-cli/internal/mdblocks"
+	"github.com/salmonumbrella/roam-cli/internal/render"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+	"github.com/salmonumbrella/roam-cli/internal/treediff"
+)
+
+// Conflict markers wrap the two versions of a block both sides changed.
+const (
+	markerFile = "<<<<<<< file"
+	markerSep  = "======="
+	markerRoam = ">>>>>>> roam"
+)
+
+var conflictLine = regexp.MustCompile(`(?m)^[ \t]*(?:[-*+][ \t]+)?` + markerFile + `[ \t]*$`)
+
+// HasConflictMarkers reports whether a file still has conflict markers from
+// an earlier sync.
+func HasConflictMarkers(text string) bool {
+	return conflictLine.MatchString(text)
+}
+
+// conflictText is the block text that shows both versions of a conflict.
+func conflictText(file, roam *treediff.Node) string {
+	return markerFile + "\n" + headingText(file) + "\n" + markerSep + "\n" + headingText(roam) + "\n" + markerRoam
+}
+
+func headingText(n *treediff.Node) string {
+	if n.Heading > 0 {
+		return strings.Repeat("#", n.Heading) + " " + n.String
+	}
+	return n.String
+}
+
+// Render writes a tree as Markdown with the Markdown renderer.
+func Render(nodes []*treediff.Node) string {
+	var sb strings.Builder
+	r, _ := render.Lookup("markdown")
+	_ = r.Render(&sb, render.Document{Blocks: roamBlocks(nodes)})
+	return sb.String()
+}
+
+func roamBlocks(nodes []*treediff.Node) []roamdb.Block {
+	blocks := make([]roamdb.Block, len(nodes))
+	for i, n := range nodes {
+		blocks[i] = roamdb.Block{UID: n.UID, String: n.String, Heading: n.Heading, Children: roamBlocks(n.Children)}
+	}
+	return blocks
+}
+
+// fileHash returns the hash a block will have when it is read back from
+// the file, which is not always the hash of the text it was written from.
+func fileHash(n *treediff.Node) string {
+	parsed, _ := mdblocks.Parse(Render([]*treediff.Node{{String: n.String, Heading: n.Heading}}), mdblocks.Options{})
+	if len(parsed) == 0 {
+		return Hash(n.String, n.Heading)
+	}
+	return Hash(parsed[0].String, parsed[0].Heading)
+}
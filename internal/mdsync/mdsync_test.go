@@ -0,0 +1,178 @@
+package mdsync
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/roam-cli/internal/mdblocks"
+	"github.com/salmonumbrella/roam-cli/internal/treediff"
+)
+
+func parseFile(text string) []*treediff.Node {
+	parsed, _ := mdblocks.Parse(text, mdblocks.Options{})
+	var convert func(blocks []*mdblocks.Block) []*treediff.Node
+	convert = func(blocks []*mdblocks.Block) []*treediff.Node {
+		nodes := make([]*treediff.Node, len(blocks))
+		for i, b := range blocks {
+			nodes[i] = &treediff.Node{String: b.String, Heading: b.Heading, Children: convert(b.Children)}
+		}
+		return nodes
+	}
+	return convert(parsed)
+}
+
+func page() []*treediff.Node {
+	return []*treediff.Node{
+		{UID: "a", String: "Agenda", Heading: 2},
+		{UID: "b", String: "Notes __with__ italics", Children: []*treediff.Node{{UID: "b1", String: "first"}}},
+		{UID: "c", String: "Actions"},
+		{UID: "e", String: "Decision"},
+	}
+}
+
+// initial syncs a page into an empty file and returns the file and state.
+func initial(t *testing.T) (string, *State) {
+	t.Helper()
+	plan, err := Merge(nil, "p", nil, page(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ops := treediff.Diff("p", page(), plan.Tree); len(ops) != 0 {
+		t.Fatalf("initial pull wrote to Roam: %+v", ops)
+	}
+	return plan.Markdown(), plan.State("Meeting", "p")
+}
+
+func TestMergeUnchangedIsNoop(t *testing.T) {
+	text, state := initial(t)
+	if !strings.Contains(text, "- ## Agenda\n") || !strings.Contains(text, "  - first\n") {
+		t.Fatalf("unexpected file:\n%s", text)
+	}
+	plan, err := Merge(state, "p", parseFile(text), page(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.FileChanged() || len(plan.Conflicts) != 0 || len(plan.Pulled) != 0 {
+		t.Fatalf("unchanged sides changed: %+v", plan)
+	}
+	if ops := treediff.Diff("p", page(), plan.Tree); len(ops) != 0 {
+		t.Fatalf("unchanged sides produced ops: %+v", ops)
+	}
+}
+
+func TestMergeBothSides(t *testing.T) {
+	text, state := initial(t)
+
+	// The file edits the agenda, drops "first", swaps two blocks and adds
+	// one; Roam edits "Actions", adds a child and edits the decision too.
+	text = strings.Replace(text, "## Agenda", "## Agenda for Monday", 1)
+	text = strings.Replace(text, "  - first\n", "", 1)
+	text = strings.Replace(text, "- Actions\n- Decision\n", "- Decision, file\n- Actions\n- New from file\n", 1)
+	roam := page()
+	roam[1].Children = append(roam[1].Children, &treediff.Node{UID: "b2", String: "from Roam"})
+	roam[2].String = "Actions, roam"
+	roam[3].String = "Decision, roam"
+
+	plan, err := Merge(state, "p", parseFile(text), roam, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops := treediff.Diff("p", roam, plan.Tree)
+
+	got := treediff.Format(plan.Tree)
+	want := treediff.Format([]*treediff.Node{
+		{UID: "a", String: "Agenda for Monday", Heading: 2},
+		{UID: "b", String: "Notes __with__ italics", Children: []*treediff.Node{{UID: "b2", String: "from Roam"}}},
+		{UID: "e", String: "Decision, roam"},
+		{UID: "c", String: "Actions, roam"},
+		{UID: plan.Tree[4].UID, String: "New from file"},
+	})
+	if got != want {
+		t.Fatalf("unexpected merge:\n%s\nwant:\n%s", got, want)
+	}
+	counts := map[string]int{}
+	for _, op := range ops {
+		counts[op.Kind]++
+	}
+	if counts[treediff.OpUpdate] != 1 || counts[treediff.OpCreate] != 1 || counts[treediff.OpDelete] != 1 || counts[treediff.OpMove] != 1 {
+		t.Fatalf("unexpected ops: %+v", ops)
+	}
+	if !reflect.DeepEqual(plan.Pulled, map[string]int{"update": 1, "create": 1}) {
+		t.Fatalf("unexpected pulled counts: %v", plan.Pulled)
+	}
+	if len(plan.Conflicts) != 1 || plan.Conflicts[0].UID != "e" || plan.Conflicts[0].File != "Decision, file" {
+		t.Fatalf("unexpected conflicts: %+v", plan.Conflicts)
+	}
+
+	out := plan.Markdown()
+	if !HasConflictMarkers(out) || !strings.Contains(out, "- Notes *with* italics\n  - from Roam\n") {
+		t.Fatalf("unexpected file:\n%s", out)
+	}
+
+	// Resolving the conflict in the file pushes the resolution.
+	next := plan.State("Meeting", "p")
+	resolved := strings.Replace(out, "- <<<<<<< file\n  Decision, file\n  =======\n  Decision, roam\n  >>>>>>> roam\n", "- Decision, both\n", 1)
+	if HasConflictMarkers(resolved) {
+		t.Fatalf("markers not resolved:\n%s", out)
+	}
+	plan, err = Merge(next, "p", parseFile(resolved), plan.Tree, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.FileChanged() || len(plan.Conflicts) != 0 || plan.Tree[2].String != "Decision, both" || plan.Tree[2].UID != "e" {
+		t.Fatalf("resolution not pushed: %s", treediff.Format(plan.Tree))
+	}
+}
+
+func TestMergeKeepsChangesOverDeletes(t *testing.T) {
+	text, state := initial(t)
+	text = strings.Replace(text, "- ## Agenda\n", "- ## Agenda, revised\n", 1)
+	text = strings.Replace(text, "- Decision\n", "", 1)
+	roam := page()[1:]
+	roam[2].String = "Decision, roam"
+
+	plan, err := Merge(state, "p", parseFile(text), roam, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Tree) != 4 || plan.Tree[0].UID != "" || plan.Tree[0].String != "Agenda, revised" || plan.Tree[3].UID != "e" {
+		t.Fatalf("unexpected merge: %s", treediff.Format(plan.Tree))
+	}
+	kept := map[string]string{}
+	for _, c := range plan.Conflicts {
+		kept[c.UID] = c.Kept
+	}
+	if kept["a"] != FromFile || kept["e"] != FromRoam {
+		t.Fatalf("unexpected conflicts: %+v", plan.Conflicts)
+	}
+}
+
+func TestMergeNeedsStartingSide(t *testing.T) {
+	if _, err := Merge(nil, "p", parseFile("- local\n"), page(), ""); err == nil {
+		t.Fatal("expected an error when both sides have blocks")
+	}
+	plan, err := Merge(nil, "p", parseFile("- local\n"), page(), FromFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Tree) != 1 || plan.FileChanged() {
+		t.Fatalf("unexpected plan: %s", treediff.Format(plan.Tree))
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.md.roam-sync.json")
+	if s, err := LoadState(path); s != nil || err != nil {
+		t.Fatalf("missing state: %v %v", s, err)
+	}
+	_, state := initial(t)
+	if err := state.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadState(path)
+	if err != nil || !reflect.DeepEqual(loaded, state) {
+		t.Fatalf("state changed on disk: %+v %v", loaded, err)
+	}
+}
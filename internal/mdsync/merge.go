@@ -0,0 +1,490 @@
+package mdsync
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/salmonumbrella/roam-cli/internal/treediff"
+)
+
+// Sides a sync can be told to take wholesale.
+const (
+	FromFile = "file"
+	FromRoam = "roam"
+)
+
+// Conflict is a block both sides changed since the last sync. Content
+// conflicts keep Roam's text in Roam and both versions, between conflict
+// markers, in the file. When one side deleted a block the other changed,
+// the change wins and Kept names the side it came from.
+type Conflict struct {
+	UID  string `json:"uid"`
+	File string `json:"file,omitempty"`
+	Roam string `json:"roam,omitempty"`
+	Kept string `json:"kept,omitempty"`
+}
+
+// Plan is the outcome of a merge: the tree both sides should hold.
+type Plan struct {
+	// Tree is the page as Roam should have it; treediff.Diff against the
+	// current page gives the writes. Diff assigns uids to new blocks,
+	// which State then records.
+	Tree      []*treediff.Node
+	Conflicts []Conflict
+	// Pulled counts the Roam changes taken into the file.
+	Pulled map[string]int
+
+	file  []*treediff.Node
+	views map[*treediff.Node]*view
+}
+
+// view is a block as the file should show it.
+type view struct {
+	String  string
+	Heading int
+	// local is the file block it is unchanged from, if any.
+	local *treediff.Node
+}
+
+// FileChanged reports whether the file has to be rewritten.
+func (p *Plan) FileChanged() bool {
+	var same func(a, b []*treediff.Node) bool
+	same = func(a, b []*treediff.Node) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if v := p.views[a[i]]; v.local != b[i] || !same(a[i].Children, b[i].Children) {
+				return false
+			}
+		}
+		return true
+	}
+	return !same(p.Tree, p.file)
+}
+
+// Markdown returns the file's new text.
+func (p *Plan) Markdown() string {
+	var convert func(nodes []*treediff.Node) []*treediff.Node
+	convert = func(nodes []*treediff.Node) []*treediff.Node {
+		out := make([]*treediff.Node, len(nodes))
+		for i, n := range nodes {
+			v := p.views[n]
+			out[i] = &treediff.Node{String: v.String, Heading: v.Heading, Children: convert(n.Children)}
+		}
+		return out
+	}
+	return Render(convert(p.Tree))
+}
+
+// State returns the state to save once both sides hold the plan's tree.
+func (p *Plan) State(page, pageUID string) *State {
+	s := &State{Version: StateVersion, Page: page, PageUID: pageUID, SyncedAt: time.Now().UTC().Format(time.RFC3339)}
+	var walk func(parent string, nodes []*treediff.Node)
+	walk = func(parent string, nodes []*treediff.Node) {
+		for _, n := range nodes {
+			v := p.views[n]
+			e := Entry{UID: n.UID, Parent: parent, Roam: Hash(n.String, n.Heading)}
+			if v.local != nil {
+				e.File = Hash(v.local.String, v.local.Heading)
+			} else {
+				e.File = fileHash(&treediff.Node{String: v.String, Heading: v.Heading})
+			}
+			s.Blocks = append(s.Blocks, e)
+			walk(n.UID, n.Children)
+		}
+	}
+	walk(pageUID, p.Tree)
+	return s
+}
+
+// Merge merges the blocks of the file and of the page, as they are now,
+// with the state of the last sync. File nodes carry no uids; they are
+// matched to the blocks of the last sync by content and place. With from
+// set, that side is taken as it is. Without a state, one side must be
+// empty or from must be set.
+func Merge(state *State, pageUID string, file, roam []*treediff.Node, from string) (*Plan, error) {
+	m := newMerger(state, pageUID, file, roam)
+	switch {
+	case from == FromRoam:
+		m.takeRoam()
+	case from == FromFile:
+		m.takeFile()
+	case state != nil:
+		m.merge()
+	case len(file) == 0:
+		m.takeRoam()
+	case len(roam) == 0:
+		m.takeFile()
+	default:
+		return nil, fmt.Errorf("both the file and the page have blocks and there is no sync state yet; choose the side to start from")
+	}
+	m.plan.Tree = m.root.Children
+	m.plan.file = file
+	return m.plan, nil
+}
+
+type merger struct {
+	// baseRoot is the page uid at the last sync.
+	baseRoot string
+	base     map[string]*Entry
+	kids     map[string][]string
+	file     []*treediff.Node
+	// fileOf and roamOf are the blocks of each side by uid; fileParent
+	// maps file nodes to their parents (nil at the top).
+	fileOf     map[string]*treediff.Node
+	fileParent map[*treediff.Node]*treediff.Node
+	roamOf     map[string]*treediff.Node
+	roam       []*treediff.Node
+
+	// root is the merged page; mergedOf and parent index its blocks.
+	root     *treediff.Node
+	mergedOf map[string]*treediff.Node
+	parent   map[*treediff.Node]*treediff.Node
+	plan     *Plan
+}
+
+func newMerger(state *State, pageUID string, file, roam []*treediff.Node) *merger {
+	m := &merger{
+		baseRoot: pageUID, base: map[string]*Entry{}, kids: map[string][]string{},
+		file: file, fileOf: map[string]*treediff.Node{}, fileParent: map[*treediff.Node]*treediff.Node{},
+		roam: roam, roamOf: map[string]*treediff.Node{},
+		root: &treediff.Node{UID: pageUID}, mergedOf: map[string]*treediff.Node{}, parent: map[*treediff.Node]*treediff.Node{},
+		plan: &Plan{Pulled: map[string]int{}, views: map[*treediff.Node]*view{}},
+	}
+	if state != nil {
+		m.baseRoot = state.PageUID
+		for i := range state.Blocks {
+			e := &state.Blocks[i]
+			m.base[e.UID] = e
+			m.kids[e.Parent] = append(m.kids[e.Parent], e.UID)
+		}
+	}
+	var index func(parent *treediff.Node, nodes []*treediff.Node)
+	index = func(parent *treediff.Node, nodes []*treediff.Node) {
+		for _, n := range nodes {
+			m.fileParent[n] = parent
+			index(n, n.Children)
+		}
+	}
+	index(nil, file)
+	walk(roam, func(n *treediff.Node) { m.roamOf[n.UID] = n })
+	m.matchFile()
+	return m
+}
+
+// matchFile gives file nodes the uids of the blocks they were at the last
+// sync: first blocks whose content is unchanged, preferring the same
+// parent, then changed blocks that stayed in place.
+func (m *merger) matchFile() {
+	if len(m.base) == 0 {
+		return
+	}
+	used := map[string]bool{}
+	byHash := map[string][]string{}
+	var order []string
+	var list func(parent string)
+	list = func(parent string) {
+		for _, uid := range m.kids[parent] {
+			order = append(order, uid)
+			list(uid)
+		}
+	}
+	list(m.baseRoot)
+	for _, uid := range order {
+		h := m.base[uid].File
+		byHash[h] = append(byHash[h], uid)
+	}
+
+	walk(m.file, func(n *treediff.Node) {
+		pu := m.fileParentUID(n)
+		var pick string
+		for _, uid := range byHash[Hash(n.String, n.Heading)] {
+			if used[uid] {
+				continue
+			}
+			if pick == "" || (m.base[uid].Parent == pu && m.base[pick].Parent != pu) {
+				pick = uid
+			}
+		}
+		if pick != "" {
+			n.UID, used[pick] = pick, true
+		}
+	})
+
+	walk(m.file, func(n *treediff.Node) {
+		pu := m.fileParentUID(n)
+		if n.UID != "" || pu == "" {
+			return
+		}
+		// The block expected here is the first one not matched yet after
+		// the previous sibling's block in the last sync.
+		var prev string
+		for _, s := range m.siblings(n) {
+			if s == n {
+				break
+			}
+			if s.UID != "" && m.base[s.UID] != nil && m.base[s.UID].Parent == pu {
+				prev = s.UID
+			}
+		}
+		kids := m.kids[pu]
+		next := 0
+		for i, uid := range kids {
+			if uid == prev {
+				next = i + 1
+			}
+		}
+		for ; next < len(kids); next++ {
+			if !used[kids[next]] {
+				n.UID, used[kids[next]] = kids[next], true
+				return
+			}
+		}
+	})
+	walk(m.file, func(n *treediff.Node) { m.fileOf[n.UID] = n })
+	delete(m.fileOf, "")
+}
+
+// fileParentUID returns the uid of a file node's parent, or "" when the
+// parent is new.
+func (m *merger) fileParentUID(n *treediff.Node) string {
+	if p := m.fileParent[n]; p != nil {
+		return p.UID
+	}
+	return m.baseRoot
+}
+
+func (m *merger) siblings(n *treediff.Node) []*treediff.Node {
+	if p := m.fileParent[n]; p != nil {
+		return p.Children
+	}
+	return m.file
+}
+
+// takeRoam makes the file a copy of the page.
+func (m *merger) takeRoam() {
+	m.root.Children = m.clone(m.root, m.roam)
+	m.plan.Pulled["create"] = len(m.plan.views)
+}
+
+// takeFile makes the page a copy of the file, keeping the uids of blocks
+// matched to the last sync.
+func (m *merger) takeFile() {
+	var copyFile func(parent *treediff.Node, nodes []*treediff.Node) []*treediff.Node
+	copyFile = func(parent *treediff.Node, nodes []*treediff.Node) []*treediff.Node {
+		out := make([]*treediff.Node, len(nodes))
+		for i, n := range nodes {
+			c := &treediff.Node{UID: n.UID, String: n.String, Heading: n.Heading}
+			m.plan.views[c] = &view{String: n.String, Heading: n.Heading, local: n}
+			m.parent[c] = parent
+			c.Children = copyFile(c, n.Children)
+			out[i] = c
+		}
+		return out
+	}
+	m.root.Children = copyFile(m.root, m.file)
+}
+
+// clone copies Roam blocks into the merged tree, showing them in the file
+// as Roam has them.
+func (m *merger) clone(parent *treediff.Node, nodes []*treediff.Node) []*treediff.Node {
+	out := make([]*treediff.Node, len(nodes))
+	for i, n := range nodes {
+		c := &treediff.Node{UID: n.UID, String: n.String, Heading: n.Heading}
+		m.plan.views[c] = &view{String: n.String, Heading: n.Heading}
+		m.mergedOf[c.UID] = c
+		m.parent[c] = parent
+		c.Children = m.clone(c, n.Children)
+		out[i] = c
+	}
+	return out
+}
+
+// merge applies the file's changes since the last sync to a copy of the
+// page, taking Roam's changes into the file.
+func (m *merger) merge() {
+	m.root.Children = m.clone(m.root, m.roam)
+
+	// Content.
+	for uid, c := range m.mergedOf {
+		f, r, e := m.fileOf[uid], m.roamOf[uid], m.base[uid]
+		v := m.plan.views[c]
+		fileChanged, roamChanged := m.fileChanged(uid), m.roamChanged(uid)
+		switch {
+		case e == nil:
+			m.plan.Pulled["create"]++
+		case f == nil:
+		case !roamChanged:
+			if fileChanged {
+				c.String, c.Heading = f.String, f.Heading
+			}
+			v.String, v.Heading, v.local = f.String, f.Heading, f
+		case !fileChanged:
+			m.plan.Pulled["update"]++
+		case f.String == r.String && f.Heading == r.Heading:
+			v.local = f
+		default:
+			v.String, v.Heading = conflictText(f, r), 0
+			m.plan.Conflicts = append(m.plan.Conflicts, Conflict{UID: uid, File: headingText(f), Roam: headingText(r)})
+		}
+	}
+
+	// Blocks deleted in Roam go from the file, unless the file changed them
+	// or added to them, in which case they are created again.
+	dropped := map[*treediff.Node]bool{}
+	walk(m.file, func(n *treediff.Node) {
+		if n.UID == "" || m.roamOf[n.UID] != nil || dropped[m.fileParent[n]] {
+			dropped[n] = dropped[m.fileParent[n]]
+			return
+		}
+		if m.fileSubtreeChanged(n) {
+			m.plan.Conflicts = append(m.plan.Conflicts, Conflict{UID: n.UID, File: headingText(n), Kept: FromFile})
+			n.UID = ""
+			return
+		}
+		dropped[n] = true
+		m.plan.Pulled["delete"]++
+	})
+
+	// Blocks deleted in the file go from Roam, unless Roam changed them or
+	// added to them.
+	var deletes []*treediff.Node
+	walk(m.roam, func(r *treediff.Node) {
+		if m.base[r.UID] == nil || m.fileOf[r.UID] != nil {
+			return
+		}
+		if m.roamSubtreeChanged(r) {
+			m.plan.Conflicts = append(m.plan.Conflicts, Conflict{UID: r.UID, Roam: headingText(r), Kept: FromRoam})
+			return
+		}
+		deletes = append(deletes, m.mergedOf[r.UID])
+	})
+
+	// Structure: new file blocks go in after their previous sibling, and
+	// blocks the file moved follow them.
+	common := map[string]bool{}
+	for uid := range m.base {
+		common[uid] = m.fileOf[uid] != nil && m.roamOf[uid] != nil
+	}
+	var place func(parent *treediff.Node, nodes []*treediff.Node)
+	place = func(parent *treediff.Node, nodes []*treediff.Node) {
+		var prev *treediff.Node
+		for _, n := range nodes {
+			if dropped[n] {
+				continue
+			}
+			var c *treediff.Node
+			switch {
+			case n.UID == "":
+				c = &treediff.Node{String: n.String, Heading: n.Heading}
+				m.plan.views[c] = &view{String: n.String, Heading: n.Heading, local: n}
+				m.insert(parent, prev, c)
+			case m.mergedOf[n.UID] != nil:
+				c = m.mergedOf[n.UID]
+				if m.movedInFile(n, common) {
+					m.detach(c)
+					m.insert(parent, prev, c)
+				}
+			default:
+				continue
+			}
+			if m.parent[c] == parent {
+				prev = c
+			}
+			place(c, n.Children)
+		}
+	}
+	place(m.root, m.file)
+
+	for _, c := range deletes {
+		m.detach(c)
+	}
+}
+
+func (m *merger) fileChanged(uid string) bool {
+	f, e := m.fileOf[uid], m.base[uid]
+	return f != nil && e != nil && Hash(f.String, f.Heading) != e.File
+}
+
+func (m *merger) roamChanged(uid string) bool {
+	r, e := m.roamOf[uid], m.base[uid]
+	return r != nil && e != nil && Hash(r.String, r.Heading) != e.Roam
+}
+
+// fileSubtreeChanged reports whether the file changed a block or added or
+// changed anything under it.
+func (m *merger) fileSubtreeChanged(n *treediff.Node) bool {
+	changed := false
+	walk([]*treediff.Node{n}, func(c *treediff.Node) {
+		changed = changed || c.UID == "" || m.base[c.UID] == nil || m.fileChanged(c.UID)
+	})
+	return changed
+}
+
+func (m *merger) roamSubtreeChanged(r *treediff.Node) bool {
+	changed := false
+	walk([]*treediff.Node{r}, func(c *treediff.Node) {
+		changed = changed || m.base[c.UID] == nil || m.roamChanged(c.UID)
+	})
+	return changed
+}
+
+// movedInFile reports whether the file moved a block: gave it another
+// parent, or another previous sibling among the blocks both sides kept.
+func (m *merger) movedInFile(n *treediff.Node, common map[string]bool) bool {
+	e := m.base[n.UID]
+	if e == nil || m.fileParentUID(n) != e.Parent {
+		return true
+	}
+	var filePrev, basePrev string
+	for _, s := range m.siblings(n) {
+		if s == n {
+			break
+		}
+		if common[s.UID] {
+			filePrev = s.UID
+		}
+	}
+	for _, uid := range m.kids[e.Parent] {
+		if uid == n.UID {
+			break
+		}
+		if common[uid] {
+			basePrev = uid
+		}
+	}
+	return filePrev != basePrev
+}
+
+// insert adds c under parent right after prev, or first when prev is nil.
+func (m *merger) insert(parent, prev, c *treediff.Node) {
+	at := 0
+	for i, s := range parent.Children {
+		if s == prev {
+			at = i + 1
+		}
+	}
+	parent.Children = append(parent.Children, nil)
+	copy(parent.Children[at+1:], parent.Children[at:])
+	parent.Children[at] = c
+	m.parent[c] = parent
+}
+
+func (m *merger) detach(c *treediff.Node) {
+	p := m.parent[c]
+	for i, s := range p.Children {
+		if s == c {
+			p.Children = append(p.Children[:i:i], p.Children[i+1:]...)
+			break
+		}
+	}
+	delete(m.parent, c)
+}
+
+func walk(nodes []*treediff.Node, fn func(*treediff.Node)) {
+	for _, n := range nodes {
+		fn(n)
+		walk(n.Children, fn)
+	}
+}
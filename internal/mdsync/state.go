@@ -0,0 +1,74 @@
+// Package mdsync keeps a Markdown file and a Roam page in step. A sidecar
+// state file records, for every block as of the last sync, its uid, its
+// place in the tree and a hash of its content on each side; the next sync
+// compares both sides with it to tell which side changed what, merges the
+// changes, and flags blocks both sides changed differently.
+package mdsync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// StateVersion is the version of the state file format.
+const StateVersion = 1
+
+// State is what a sync remembers about the page and the file.
+type State struct {
+	Version  int     `json:"version"`
+	Page     string  `json:"page"`
+	PageUID  string  `json:"page_uid"`
+	SyncedAt string  `json:"synced_at"`
+	Blocks   []Entry `json:"blocks"`
+}
+
+// Entry is a block as of the last sync. Entries are in page order, parents
+// before children, so an entry's place among its siblings is its order in
+// the list. File and Roam hash the block's content as each side holds it,
+// which may differ where Markdown and Roam write the same text differently.
+type Entry struct {
+	UID    string `json:"uid"`
+	Parent string `json:"parent"`
+	File   string `json:"file"`
+	Roam   string `json:"roam"`
+}
+
+// LoadState reads a state file. It returns nil, and no error, when the file
+// does not exist.
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid sync state %s: %w", path, err)
+	}
+	if s.Version != StateVersion {
+		return nil, fmt.Errorf("unsupported sync state version %d in %s", s.Version, path)
+	}
+	return &s, nil
+}
+
+// Save writes the state file.
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Hash returns the content hash of a block.
+func Hash(s string, heading int) string {
+	sum := sha256.Sum256([]byte(strconv.Itoa(heading) + "\x00" + s))
+	return hex.EncodeToString(sum[:16])
+}
@@ -13,6 +13,8 @@ import (
 
 	"github.com/itchyny/gojq"
 	"gopkg.in/yaml.v3"
+
+	"github.com/salmonumbrella/roam-cli/internal/edn"
 )
 
 // Format represents the output format type.
@@ -29,6 +31,8 @@ const (
 	FormatTable Format = "table"
 	// FormatYAML is YAML format.
 	FormatYAML Format = "yaml"
+	// FormatEDN is pretty-printed EDN, the notation Roam itself uses.
+	FormatEDN Format = "edn"
 )
 
 // ParseFormat converts a string to a Format type.
@@ -46,15 +50,17 @@ func ParseFormat(s string) (Format, error) {
 		return FormatTable, nil
 	case FormatYAML:
 		return FormatYAML, nil
+	case FormatEDN:
+		return FormatEDN, nil
 	default:
-		return "", errors.New("invalid --output format (expected text|json|ndjson|table|yaml)")
+		return "", errors.New("invalid --output format (expected text|json|ndjson|table|yaml|edn)")
 	}
 }
 
 // IsStructured reports whether the format is machine-readable structured output.
 func IsStructured(format Format) bool {
 	switch format {
-	case FormatJSON, FormatNDJSON, FormatYAML:
+	case FormatJSON, FormatNDJSON, FormatYAML, FormatEDN:
 		return true
 	default:
 		return false
@@ -92,6 +98,8 @@ func (p *Printer) Print(ctx context.Context, data interface{}) error {
 		return p.printNDJSON(ctx, data)
 	case FormatYAML:
 		return p.printYAML(data)
+	case FormatEDN:
+		return p.printEDN(ctx, data)
 	case FormatTable:
 		return p.printTable(data)
 	case FormatText:
@@ -206,6 +214,50 @@ func (p *Printer) printYAML(data interface{}) error {
 	return enc.Encode(data)
 }
 
+// printEDN outputs data as pretty-printed EDN, with JSON object keys as
+// keywords. If a jq query is present in the context, each result is
+// printed as its own EDN value.
+func (p *Printer) printEDN(ctx context.Context, data interface{}) error {
+	query := QueryFromContext(ctx)
+	if query == "" {
+		_, err := fmt.Fprintln(p.w, edn.Pretty(data))
+		return err
+	}
+
+	parsed, err := gojq.Parse(query)
+	if err != nil {
+		return fmt.Errorf("invalid --query: %w", err)
+	}
+	code, err := gojq.Compile(parsed)
+	if err != nil {
+		return fmt.Errorf("invalid --query: %w", err)
+	}
+
+	// gojq works on plain JSON values, so round-trip structs first.
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return err
+	}
+
+	iter := code.Run(value)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			return nil
+		}
+		if err, isErr := v.(error); isErr {
+			return fmt.Errorf("query error: %w", err)
+		}
+		if _, err := fmt.Fprintln(p.w, edn.Pretty(v)); err != nil {
+			return err
+		}
+	}
+}
+
 // printText outputs data as human-readable text.
 // For maps and structs: key-value pairs.
 // For slices: one item per line.
@@ -0,0 +1,69 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/itchyny/gojq"
+)
+
+// Stream writes newline-delimited JSON one item at a time, so long result
+// sets reach the reader as they are produced instead of after the last page.
+// When a jq query is present in the context it is applied to each item.
+type Stream struct {
+	enc  *json.Encoder
+	code *gojq.Code
+}
+
+// NewStream creates a Stream writing to w.
+func NewStream(ctx context.Context, w io.Writer) (*Stream, error) {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	s := &Stream{enc: enc}
+
+	if query := QueryFromContext(ctx); query != "" {
+		parsed, err := gojq.Parse(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --query: %w", err)
+		}
+		code, err := gojq.Compile(parsed)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --query: %w", err)
+		}
+		s.code = code
+	}
+	return s, nil
+}
+
+// Write encodes item as one line (or one line per jq result).
+func (s *Stream) Write(item interface{}) error {
+	if s.code == nil {
+		return s.enc.Encode(item)
+	}
+
+	// gojq works on plain JSON values, so round-trip structs first.
+	raw, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return err
+	}
+
+	iter := s.code.Run(value)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			return nil
+		}
+		if err, isErr := v.(error); isErr {
+			return fmt.Errorf("query error: %w", err)
+		}
+		if err := s.enc.Encode(v); err != nil {
+			return err
+		}
+	}
+}
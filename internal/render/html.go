@@ -0,0 +1,139 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/salmonumbrella/roam-cli/internal/markup"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// htmlRenderer writes a standalone HTML document: nested <ul> (or <ol> for
+// numbered views) lists with the block uid on each item, headings as
+// <h1>-<h3>, tasks as checkboxes, and refs as spans carrying their target
+// in data attributes.
+type htmlRenderer struct{}
+
+const htmlStyle = `body{font-family:system-ui,sans-serif;max-width:48rem;margin:2rem auto;line-height:1.5}
+li h1,li h2,li h3{display:inline;margin:0}
+.page-ref,.tag{color:#106ba3}
+.block-ref{border-bottom:1px solid #ccc}
+pre{background:#f5f5f5;padding:.5rem;overflow:auto}`
+
+func (r htmlRenderer) Render(w io.Writer, doc Document) error {
+	var sb strings.Builder
+	title := html.EscapeString(doc.Title)
+	if title == "" {
+		title = html.EscapeString(doc.UID)
+	}
+	sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	sb.WriteString("<title>" + title + "</title>\n<style>\n" + htmlStyle + "\n</style>\n</head>\n<body>\n")
+	if doc.Title != "" {
+		sb.WriteString("<h1>" + title + "</h1>\n")
+	}
+	r.blocks(&sb, doc.Blocks, doc.ViewType, "")
+	if len(doc.Footnotes) > 0 {
+		sb.WriteString("<section class=\"footnotes\">\n<ol>\n")
+		for _, note := range doc.Footnotes {
+			sb.WriteString(fmt.Sprintf("  <li id=\"fn-%d\">%s</li>\n", note.N, r.inline(markup.Parse(note.Text))))
+		}
+		sb.WriteString("</ol>\n</section>\n")
+	}
+	sb.WriteString("</body>\n</html>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (r htmlRenderer) blocks(sb *strings.Builder, blocks []roamdb.Block, viewType, indent string) {
+	if len(blocks) == 0 {
+		return
+	}
+	list := "ul"
+	if numbered(viewType) {
+		list = "ol"
+	}
+	sb.WriteString(indent + "<" + list + ">\n")
+	for _, b := range blocks {
+		state, nodes := task(markup.Parse(b.String))
+		content := r.inline(nodes)
+		switch {
+		case state == TaskTodo:
+			content = `<input type="checkbox" disabled> ` + content
+		case state == TaskDone:
+			content = `<input type="checkbox" checked disabled> ` + content
+		case b.Heading > 0:
+			content = fmt.Sprintf("<h%d>%s</h%d>", b.Heading, content, b.Heading)
+		}
+
+		sb.WriteString(indent + "  <li")
+		if b.UID != "" {
+			sb.WriteString(` id="` + html.EscapeString(b.UID) + `"`)
+		}
+		sb.WriteString(">" + content)
+		if len(b.Children) > 0 {
+			sb.WriteString("\n")
+			r.blocks(sb, b.Children, b.ViewType, indent+"    ")
+			sb.WriteString(indent + "  ")
+		}
+		sb.WriteString("</li>\n")
+	}
+	sb.WriteString(indent + "</" + list + ">\n")
+}
+
+func (r htmlRenderer) inline(nodes []*markup.Node) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		switch n.Kind {
+		case markup.KindText:
+			sb.WriteString(strings.ReplaceAll(html.EscapeString(n.Text), "\n", "<br>\n"))
+		case markup.KindPageRef:
+			sb.WriteString(`<span class="page-ref" data-page="` + html.EscapeString(n.Target) + `">` + r.inline(n.Children) + `</span>`)
+		case markup.KindTag:
+			label := html.EscapeString(n.Target)
+			if n.Bracketed {
+				label = r.inline(n.Children)
+			}
+			sb.WriteString(`<span class="tag" data-page="` + html.EscapeString(n.Target) + `">#` + label + `</span>`)
+		case markup.KindBlockRef:
+			uid := html.EscapeString(n.Target)
+			sb.WriteString(`<a class="block-ref" href="#` + uid + `">((` + uid + `))</a>`)
+		case markup.KindAttribute:
+			sb.WriteString(`<strong class="attribute">` + html.EscapeString(n.Target) + `:</strong>` + r.inline(n.Children))
+		case markup.KindAlias:
+			label := r.inline(n.Children)
+			target := html.EscapeString(n.Target)
+			switch n.AliasTo {
+			case markup.AliasPage:
+				sb.WriteString(`<span class="page-ref" data-page="` + target + `">` + label + `</span>`)
+			case markup.AliasBlock:
+				sb.WriteString(`<a class="block-ref" href="#` + target + `">` + label + `</a>`)
+			default:
+				sb.WriteString(`<a href="` + target + `">` + label + `</a>`)
+			}
+		case markup.KindBold:
+			sb.WriteString("<strong>" + r.inline(n.Children) + "</strong>")
+		case markup.KindItalic:
+			sb.WriteString("<em>" + r.inline(n.Children) + "</em>")
+		case markup.KindHighlight:
+			sb.WriteString("<mark>" + r.inline(n.Children) + "</mark>")
+		case markup.KindStrike:
+			sb.WriteString("<del>" + r.inline(n.Children) + "</del>")
+		case markup.KindCode:
+			sb.WriteString("<code>" + html.EscapeString(n.Text) + "</code>")
+		case markup.KindCodeBlock:
+			lang, body := codeParts(n)
+			class := ""
+			if lang != "" {
+				class = ` class="language-` + html.EscapeString(lang) + `"`
+			}
+			sb.WriteString("<pre><code" + class + ">" + html.EscapeString(body) + "</code></pre>")
+		case markup.KindLatex:
+			sb.WriteString(`<span class="latex">\(` + html.EscapeString(n.Text) + `\)</span>`)
+		default:
+			sb.WriteString(`<span class="macro">` + html.EscapeString(n.String()) + `</span>`)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,76 @@
+package render
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/salmonumbrella/roam-cli/internal/markup"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// Tree is the normalized JSON form of a document: the same shape whether
+// the page came from the cloud or the Local API, with children in order.
+type Tree struct {
+	Title     string     `json:"title,omitempty"`
+	UID       string     `json:"uid"`
+	ViewType  string     `json:"view_type,omitempty"`
+	Children  []Node     `json:"children"`
+	Footnotes []Footnote `json:"footnotes,omitempty"`
+}
+
+// Node is one block of a Tree.
+type Node struct {
+	UID        string   `json:"uid"`
+	String     string   `json:"string"`
+	Text       string   `json:"text"`
+	Heading    int      `json:"heading,omitempty"`
+	Task       string   `json:"task,omitempty"`
+	ViewType   string   `json:"view_type,omitempty"`
+	PageRefs   []string `json:"page_refs,omitempty"`
+	BlockRefs  []string `json:"block_refs,omitempty"`
+	CreateTime int64    `json:"create_time,omitempty"`
+	EditTime   int64    `json:"edit_time,omitempty"`
+	Children   []Node   `json:"children,omitempty"`
+}
+
+// NewTree builds the normalized tree for a document.
+func NewTree(doc Document) Tree {
+	return Tree{
+		Title:     doc.Title,
+		UID:       doc.UID,
+		ViewType:  doc.ViewType,
+		Children:  treeNodes(doc.Blocks),
+		Footnotes: doc.Footnotes,
+	}
+}
+
+func treeNodes(blocks []roamdb.Block) []Node {
+	nodes := make([]Node, 0, len(blocks))
+	for _, b := range blocks {
+		parsed := markup.Parse(b.String)
+		state, rest := task(parsed)
+		nodes = append(nodes, Node{
+			UID:        b.UID,
+			String:     b.String,
+			Text:       markup.PlainText(rest),
+			Heading:    b.Heading,
+			Task:       state,
+			ViewType:   b.ViewType,
+			PageRefs:   markup.PageRefs(parsed),
+			BlockRefs:  markup.BlockRefs(parsed),
+			CreateTime: b.CreateTime,
+			EditTime:   b.EditTime,
+			Children:   treeNodes(b.Children),
+		})
+	}
+	return nodes
+}
+
+type jsonRenderer struct{}
+
+func (jsonRenderer) Render(w io.Writer, doc Document) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(NewTree(doc))
+}
@@ -0,0 +1,152 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/salmonumbrella/roam-cli/internal/markup"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// markdownRenderer writes nested Markdown lists. With logseq set it writes
+// the dialect Logseq stores pages in: tab indentation, TODO/DONE markers,
+// Roam-style refs and formatting, and order-list properties for numbered
+// children. With uids set each block's uid follows its text as an HTML
+// comment, so an exported file can be matched back to the graph.
+type markdownRenderer struct {
+	logseq bool
+	uids   bool
+}
+
+// MarkdownWithUIDs returns the Markdown renderer that keeps block uids as
+// <!-- uid: ... --> comments.
+func MarkdownWithUIDs() Renderer {
+	return markdownRenderer{uids: true}
+}
+
+// UIDComment is the comment MarkdownWithUIDs writes for a block uid.
+func UIDComment(uid string) string {
+	return "<!-- uid: " + uid + " -->"
+}
+
+func (r markdownRenderer) Render(w io.Writer, doc Document) error {
+	var sb strings.Builder
+	if doc.Title != "" {
+		if r.logseq {
+			sb.WriteString("title:: " + doc.Title + "\n\n")
+		} else {
+			sb.WriteString("# " + doc.Title + "\n\n")
+		}
+	}
+	r.blocks(&sb, doc.Blocks, "", doc.ViewType)
+	if len(doc.Footnotes) > 0 {
+		sb.WriteString("\n")
+		for _, note := range doc.Footnotes {
+			sb.WriteString(note.Marker() + ": " + strings.ReplaceAll(note.Text, "\n", "\n    ") + "\n")
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (r markdownRenderer) blocks(sb *strings.Builder, blocks []roamdb.Block, indent, viewType string) {
+	for i, b := range blocks {
+		state, nodes := task(markup.Parse(b.String))
+
+		marker := "- "
+		if numbered(viewType) && !r.logseq {
+			marker = fmt.Sprintf("%d. ", i+1)
+		}
+		cont := indent + strings.Repeat(" ", len(marker))
+		childIndent := cont
+		if r.logseq {
+			cont = indent + "  "
+			childIndent = indent + "\t"
+		}
+
+		var prefix string
+		switch {
+		case state == TaskTodo && r.logseq:
+			prefix = "TODO "
+		case state == TaskDone && r.logseq:
+			prefix = "DONE "
+		case state == TaskTodo:
+			prefix = "[ ] "
+		case state == TaskDone:
+			prefix = "[x] "
+		case b.Heading > 0:
+			prefix = strings.Repeat("#", b.Heading) + " "
+		}
+
+		text := prefix + r.inline(nodes)
+		if r.logseq && numbered(viewType) {
+			text += "\nlogseq.order-list-type:: number"
+		}
+		lines := strings.Split(text, "\n")
+		if r.uids && b.UID != "" {
+			// A comment after a code fence would become its info string, so
+			// it gets the bullet line to itself.
+			if strings.HasPrefix(lines[0], "```") {
+				lines = append([]string{UIDComment(b.UID)}, lines...)
+			} else {
+				lines[0] += " " + UIDComment(b.UID)
+			}
+		}
+		sb.WriteString(strings.TrimRight(indent+marker+lines[0], " ") + "\n")
+		for _, line := range lines[1:] {
+			if line == "" {
+				sb.WriteString("\n")
+				continue
+			}
+			sb.WriteString(cont + line + "\n")
+		}
+
+		r.blocks(sb, b.Children, childIndent, b.ViewType)
+	}
+}
+
+func (r markdownRenderer) inline(nodes []*markup.Node) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		switch n.Kind {
+		case markup.KindText:
+			sb.WriteString(n.Text)
+		case markup.KindAttribute:
+			if r.logseq {
+				sb.WriteString(n.Target + "::" + r.inline(n.Children))
+			} else {
+				sb.WriteString("**" + n.Target + ":**" + r.inline(n.Children))
+			}
+		case markup.KindAlias:
+			sb.WriteString("[" + r.inline(n.Children) + "](" + aliasDest(n) + ")")
+		case markup.KindBold:
+			sb.WriteString("**" + r.inline(n.Children) + "**")
+		case markup.KindItalic:
+			if r.logseq {
+				sb.WriteString("_" + r.inline(n.Children) + "_")
+			} else {
+				sb.WriteString("*" + r.inline(n.Children) + "*")
+			}
+		case markup.KindHighlight:
+			if r.logseq {
+				sb.WriteString("^^" + r.inline(n.Children) + "^^")
+			} else {
+				sb.WriteString("==" + r.inline(n.Children) + "==")
+			}
+		case markup.KindStrike:
+			sb.WriteString("~~" + r.inline(n.Children) + "~~")
+		case markup.KindCodeBlock:
+			lang, body := codeParts(n)
+			if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n") {
+				sb.WriteString("\n")
+			}
+			sb.WriteString("```" + lang + "\n" + body + "\n```")
+		default:
+			// Refs, tags, macros, inline code and LaTeX keep Roam's syntax,
+			// which Markdown tools that understand wiki links read as is.
+			sb.WriteString(n.String())
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,56 @@
+package render
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// opmlRenderer writes OPML 2.0 with one outline per block. Block strings
+// keep their Roam markup, which outliners importing OPML expect.
+type opmlRenderer struct{}
+
+func (r opmlRenderer) Render(w io.Writer, doc Document) error {
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString("<opml version=\"2.0\">\n  <head>\n")
+	sb.WriteString("    <title>" + xmlEscape(doc.Title) + "</title>\n  </head>\n  <body>\n")
+	r.blocks(&sb, doc.Blocks, "    ")
+	if len(doc.Footnotes) > 0 {
+		sb.WriteString("    <outline text=\"Footnotes\">\n")
+		for _, note := range doc.Footnotes {
+			sb.WriteString("      <outline text=\"" + xmlEscape(note.Marker()+" "+note.Text) + "\"/>\n")
+		}
+		sb.WriteString("    </outline>\n")
+	}
+	sb.WriteString("  </body>\n</opml>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (r opmlRenderer) blocks(sb *strings.Builder, blocks []roamdb.Block, indent string) {
+	for _, b := range blocks {
+		sb.WriteString(indent + `<outline text="` + xmlEscape(b.String) + `"`)
+		if b.UID != "" {
+			sb.WriteString(` _uid="` + xmlEscape(b.UID) + `"`)
+		}
+		if b.Heading > 0 {
+			sb.WriteString(` _heading="` + strings.Repeat("#", b.Heading) + `"`)
+		}
+		if len(b.Children) == 0 {
+			sb.WriteString("/>\n")
+			continue
+		}
+		sb.WriteString(">\n")
+		r.blocks(sb, b.Children, indent+"  ")
+		sb.WriteString(indent + "</outline>\n")
+	}
+}
+
+func xmlEscape(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
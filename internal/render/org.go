@@ -0,0 +1,104 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/salmonumbrella/roam-cli/internal/markup"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// orgRenderer writes an Org outline: every block is a headline one level
+// below its parent, TODO/DONE become Org keywords, further lines of a block
+// become its body and code blocks become SRC blocks.
+type orgRenderer struct{}
+
+func (r orgRenderer) Render(w io.Writer, doc Document) error {
+	var sb strings.Builder
+	if doc.Title != "" {
+		sb.WriteString("#+TITLE: " + doc.Title + "\n\n")
+	}
+	r.blocks(&sb, doc.Blocks, 1, doc.ViewType)
+	if len(doc.Footnotes) > 0 {
+		sb.WriteString("* Footnotes\n")
+		for _, note := range doc.Footnotes {
+			sb.WriteString("- " + note.Marker() + " " + r.inline(markup.Parse(note.Text)) + "\n")
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (r orgRenderer) blocks(sb *strings.Builder, blocks []roamdb.Block, level int, viewType string) {
+	stars := strings.Repeat("*", level)
+	for i, b := range blocks {
+		state, nodes := task(markup.Parse(b.String))
+
+		headline := stars
+		if state != TaskNone {
+			headline += " " + state
+		}
+		if numbered(viewType) {
+			headline += fmt.Sprintf(" %d.", i+1)
+		}
+
+		lines := strings.Split(r.inline(nodes), "\n")
+		sb.WriteString(headline + " " + strings.TrimRight(lines[0], " ") + "\n")
+		if b.UID != "" {
+			sb.WriteString(":PROPERTIES:\n:ID: " + b.UID + "\n:END:\n")
+		}
+		for _, line := range lines[1:] {
+			sb.WriteString(line + "\n")
+		}
+
+		r.blocks(sb, b.Children, level+1, b.ViewType)
+	}
+}
+
+func (r orgRenderer) inline(nodes []*markup.Node) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		switch n.Kind {
+		case markup.KindText:
+			sb.WriteString(n.Text)
+		case markup.KindPageRef:
+			sb.WriteString("[[" + n.Target + "]]")
+		case markup.KindTag:
+			sb.WriteString("[[" + n.Target + "][#" + n.Target + "]]")
+		case markup.KindBlockRef:
+			sb.WriteString("[[id:" + n.Target + "]]")
+		case markup.KindAttribute:
+			sb.WriteString("*" + n.Target + ":*" + r.inline(n.Children))
+		case markup.KindAlias:
+			dest := n.Target
+			if n.AliasTo == markup.AliasBlock {
+				dest = "id:" + n.Target
+			}
+			sb.WriteString("[[" + dest + "][" + r.inline(n.Children) + "]]")
+		case markup.KindBold:
+			sb.WriteString("*" + r.inline(n.Children) + "*")
+		case markup.KindItalic:
+			sb.WriteString("/" + r.inline(n.Children) + "/")
+		case markup.KindHighlight:
+			sb.WriteString("_" + r.inline(n.Children) + "_")
+		case markup.KindStrike:
+			sb.WriteString("+" + r.inline(n.Children) + "+")
+		case markup.KindCode:
+			sb.WriteString("~" + n.Text + "~")
+		case markup.KindCodeBlock:
+			// SRC blocks cannot sit in a headline, so they always start
+			// the body.
+			lang, body := codeParts(n)
+			if !strings.HasSuffix(sb.String(), "\n") {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(strings.TrimRight("#+BEGIN_SRC "+lang, " ") + "\n" + body + "\n#+END_SRC")
+		case markup.KindLatex:
+			sb.WriteString("\\(" + n.Text + "\\)")
+		default:
+			sb.WriteString(n.String())
+		}
+	}
+	return sb.String()
+}
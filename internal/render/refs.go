@@ -0,0 +1,243 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/salmonumbrella/roam-cli/internal/markup"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// Reference styles for Resolver.
+const (
+	// RefsInline replaces ((uid)) with the referenced block's text.
+	RefsInline = "inline"
+	// RefsFootnote replaces ((uid)) with a [^n] marker and collects the
+	// referenced text in Footnotes.
+	RefsFootnote = "footnote"
+)
+
+// DefaultRefDepth is how many levels of refs within refs (and embeds within
+// embeds) a Resolver expands when Depth is unset.
+const DefaultRefDepth = 3
+
+// Footnote is the text of a block referenced in footnote style.
+type Footnote struct {
+	N    int    `json:"n"`
+	UID  string `json:"uid"`
+	Text string `json:"text"`
+}
+
+// Marker returns the footnote marker written in place of the reference.
+func (f Footnote) Marker() string {
+	return fmt.Sprintf("[^%d]", f.N)
+}
+
+// Resolver inlines block references and expands {{embed: ((uid))}} macros
+// into copies of the embedded block and its children. Referenced blocks are
+// fetched in batches, one round per level of nesting.
+//
+// References that cannot be resolved are left as written: blocks that do
+// not exist, refs nested deeper than Depth, and refs that would loop back
+// to a block already being expanded.
+type Resolver struct {
+	// Fetch returns the blocks for uids, with children for embeds. Missing
+	// uids are left out of the result.
+	Fetch func(uids []string) (map[string]roamdb.Block, error)
+	Depth int
+	Style string
+
+	// Footnotes holds the referenced blocks in footnote style, in the order
+	// their markers were assigned.
+	Footnotes []Footnote
+
+	blocks    map[string]*roamdb.Block
+	footnotes map[string]int
+}
+
+// ResolveBlocks returns copies of blocks with references resolved.
+func (r *Resolver) ResolveBlocks(blocks []roamdb.Block) ([]roamdb.Block, error) {
+	if err := r.prefetch(blocks); err != nil {
+		return nil, err
+	}
+	out := make([]roamdb.Block, len(blocks))
+	for i := range blocks {
+		out[i] = r.resolveBlock(blocks[i], r.depth(), nil)
+	}
+	return out, nil
+}
+
+func (r *Resolver) depth() int {
+	if r.Depth > 0 {
+		return r.Depth
+	}
+	return DefaultRefDepth
+}
+
+// prefetch pulls every block reachable through refs and embeds within the
+// depth limit, breadth first.
+func (r *Resolver) prefetch(blocks []roamdb.Block) error {
+	if r.blocks == nil {
+		r.blocks = map[string]*roamdb.Block{}
+	}
+	pending := blocks
+	for round := 0; round < r.depth() && len(pending) > 0; round++ {
+		var uids []string
+		seen := map[string]bool{}
+		walkBlocks(pending, func(b *roamdb.Block) {
+			for _, uid := range markup.BlockRefs(markup.Parse(b.String)) {
+				if _, fetched := r.blocks[uid]; !fetched && !seen[uid] {
+					seen[uid] = true
+					uids = append(uids, uid)
+				}
+			}
+		})
+		if len(uids) == 0 {
+			return nil
+		}
+		fetched, err := r.Fetch(uids)
+		if err != nil {
+			return fmt.Errorf("failed to fetch referenced blocks: %w", err)
+		}
+		pending = pending[:0:0]
+		for _, uid := range uids {
+			b, ok := fetched[uid]
+			if !ok {
+				r.blocks[uid] = nil
+				continue
+			}
+			r.blocks[uid] = &b
+			pending = append(pending, b)
+		}
+	}
+	return nil
+}
+
+func walkBlocks(blocks []roamdb.Block, fn func(*roamdb.Block)) {
+	for i := range blocks {
+		fn(&blocks[i])
+		walkBlocks(blocks[i].Children, fn)
+	}
+}
+
+// resolveBlock resolves b's string and children. stack holds the uids
+// being expanded, for cycle detection.
+func (r *Resolver) resolveBlock(b roamdb.Block, depth int, stack []string) roamdb.Block {
+	if b.UID != "" {
+		stack = append(stack[:len(stack):len(stack)], b.UID)
+	}
+
+	nodes := markup.Parse(b.String)
+	var embedded []roamdb.Block
+	markup.Walk(nodes, func(n *markup.Node) bool {
+		if n.Kind != markup.KindMacro || !isEmbed(n.Name) {
+			return true
+		}
+		uid := embedTarget(n)
+		target := r.lookup(uid, depth, stack)
+		if target == nil {
+			return false
+		}
+		inner := r.resolveBlock(*target, depth-1, stack)
+		setText(n, inner.String)
+		embedded = append(embedded, inner.Children...)
+		return false
+	})
+	r.replaceRefs(nodes, depth, stack)
+
+	b.String = markup.Render(nodes)
+	children := make([]roamdb.Block, 0, len(embedded)+len(b.Children))
+	children = append(children, embedded...)
+	for _, child := range b.Children {
+		children = append(children, r.resolveBlock(child, depth, stack))
+	}
+	if len(children) > 0 {
+		b.Children = children
+	}
+	return b
+}
+
+// resolveString resolves the refs in a referenced block's text.
+func (r *Resolver) resolveString(s string, depth int, stack []string) string {
+	nodes := markup.Parse(s)
+	r.replaceRefs(nodes, depth, stack)
+	return markup.Render(nodes)
+}
+
+func (r *Resolver) replaceRefs(nodes []*markup.Node, depth int, stack []string) {
+	markup.Walk(nodes, func(n *markup.Node) bool {
+		switch {
+		case n.Kind == markup.KindBlockRef:
+			if text, ok := r.refText(n.Target, depth, stack); ok {
+				setText(n, text)
+			}
+			return false
+		case n.Kind == markup.KindAlias && n.AliasTo == markup.AliasBlock:
+			if text, ok := r.refText(n.Target, depth, stack); ok {
+				label := markup.Render(n.Children)
+				if r.Style == RefsFootnote {
+					label += text
+				}
+				setText(n, label)
+			}
+			return false
+		}
+		return true
+	})
+}
+
+// refText returns what replaces a reference to uid: the resolved text, or
+// a footnote marker.
+func (r *Resolver) refText(uid string, depth int, stack []string) (string, bool) {
+	target := r.lookup(uid, depth, stack)
+	if target == nil {
+		return "", false
+	}
+	text := r.resolveString(target.String, depth-1, append(stack[:len(stack):len(stack)], uid))
+	if r.Style != RefsFootnote {
+		return text, true
+	}
+	if r.footnotes == nil {
+		r.footnotes = map[string]int{}
+	}
+	idx, ok := r.footnotes[uid]
+	if !ok {
+		idx = len(r.Footnotes)
+		r.footnotes[uid] = idx
+		r.Footnotes = append(r.Footnotes, Footnote{N: idx + 1, UID: uid, Text: text})
+	}
+	return r.Footnotes[idx].Marker(), true
+}
+
+// lookup returns the block for uid if it may be expanded here.
+func (r *Resolver) lookup(uid string, depth int, stack []string) *roamdb.Block {
+	if depth <= 0 || uid == "" {
+		return nil
+	}
+	for _, seen := range stack {
+		if seen == uid {
+			return nil
+		}
+	}
+	return r.blocks[uid]
+}
+
+func isEmbed(name string) bool {
+	name = strings.ToLower(name)
+	return name == "embed" || name == "embed-path"
+}
+
+func embedTarget(n *markup.Node) string {
+	var uid string
+	markup.Walk(n.Children, func(c *markup.Node) bool {
+		if c.Kind == markup.KindBlockRef && uid == "" {
+			uid = c.Target
+		}
+		return uid == ""
+	})
+	return uid
+}
+
+func setText(n *markup.Node, text string) {
+	n.Kind, n.Text, n.Target, n.Children = markup.KindText, text, "", nil
+}
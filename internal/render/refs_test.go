@@ -0,0 +1,118 @@
+package render
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+func fakeFetch(db map[string]roamdb.Block, calls *[][]string) func([]string) (map[string]roamdb.Block, error) {
+	return func(uids []string) (map[string]roamdb.Block, error) {
+		batch := append([]string(nil), uids...)
+		sort.Strings(batch)
+		*calls = append(*calls, batch)
+		out := map[string]roamdb.Block{}
+		for _, uid := range uids {
+			if b, ok := db[uid]; ok {
+				out[uid] = b
+			}
+		}
+		return out, nil
+	}
+}
+
+func TestResolveInlineRefs(t *testing.T) {
+	db := map[string]roamdb.Block{
+		"a": {UID: "a", String: "alpha ((b))"},
+		"b": {UID: "b", String: "beta"},
+	}
+	var calls [][]string
+	r := &Resolver{Fetch: fakeFetch(db, &calls), Style: RefsInline}
+	got, err := r.ResolveBlocks([]roamdb.Block{
+		{UID: "x", String: "see ((a)) and ((missing))"},
+		{UID: "y", String: "[label](((b)))"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].String != "see alpha beta and ((missing))" {
+		t.Fatalf("unexpected inline text %q", got[0].String)
+	}
+	if got[1].String != "label" {
+		t.Fatalf("unexpected alias text %q", got[1].String)
+	}
+	if len(calls) != 1 || strings.Join(calls[0], ",") != "a,b,missing" {
+		t.Fatalf("expected one batched fetch, got %v", calls)
+	}
+}
+
+func TestResolveEmbedAndCycle(t *testing.T) {
+	db := map[string]roamdb.Block{
+		"e":    {UID: "e", String: "embedded", Children: []roamdb.Block{{UID: "e1", String: "child ((self))"}}},
+		"self": {UID: "self", String: "loops to ((self))"},
+	}
+	var calls [][]string
+	r := &Resolver{Fetch: fakeFetch(db, &calls)}
+	got, err := r.ResolveBlocks([]roamdb.Block{
+		{UID: "x", String: "{{embed: ((e))}}", Children: []roamdb.Block{{UID: "x1", String: "own"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := got[0]
+	if b.String != "embedded" || len(b.Children) != 2 {
+		t.Fatalf("unexpected embed expansion: %+v", b)
+	}
+	if b.Children[0].String != "child loops to ((self))" || b.Children[1].String != "own" {
+		t.Fatalf("unexpected embedded children: %+v", b.Children)
+	}
+}
+
+func TestResolveDepthLimit(t *testing.T) {
+	db := map[string]roamdb.Block{
+		"a": {UID: "a", String: "A ((b))"},
+		"b": {UID: "b", String: "B ((c))"},
+		"c": {UID: "c", String: "C"},
+	}
+	var calls [][]string
+	r := &Resolver{Fetch: fakeFetch(db, &calls), Depth: 2}
+	got, err := r.ResolveBlocks([]roamdb.Block{{UID: "x", String: "((a))"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].String != "A B ((c))" {
+		t.Fatalf("unexpected depth-limited text %q", got[0].String)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("expected one fetch per level, got %v", calls)
+	}
+}
+
+func TestResolveFootnotes(t *testing.T) {
+	db := map[string]roamdb.Block{
+		"a": {UID: "a", String: "alpha"},
+		"b": {UID: "b", String: "beta"},
+	}
+	var calls [][]string
+	r := &Resolver{Fetch: fakeFetch(db, &calls), Style: RefsFootnote}
+	got, err := r.ResolveBlocks([]roamdb.Block{
+		{UID: "x", String: "((b)) then ((a))"},
+		{UID: "y", String: "again ((b))"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].String != "[^1] then [^2]" || got[1].String != "again [^1]" {
+		t.Fatalf("unexpected footnote markers: %q, %q", got[0].String, got[1].String)
+	}
+	if len(r.Footnotes) != 2 || r.Footnotes[0].UID != "b" || r.Footnotes[1].Text != "alpha" {
+		t.Fatalf("unexpected footnotes: %+v", r.Footnotes)
+	}
+
+	doc := Document{Title: "T", Blocks: got, Footnotes: r.Footnotes}
+	if out := renderString(t, "markdown", doc); !strings.HasSuffix(out, "\n[^1]: beta\n[^2]: alpha\n") {
+		t.Fatalf("markdown footnotes missing:\n%s", out)
+	}
+}
@@ -0,0 +1,124 @@
+// Package render turns pulled Roam pages and blocks into documents in other
+// formats: Markdown, Org, HTML, OPML, Logseq Markdown and a normalized JSON
+// tree. Block strings are parsed with the markup package, so refs,
+// formatting and code blocks are translated rather than copied as text.
+package render
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/salmonumbrella/roam-cli/internal/markup"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// Document is the tree a renderer writes: a page, or a single block and its
+// children (Title is then empty).
+type Document struct {
+	Title    string
+	UID      string
+	ViewType string
+	Blocks   []roamdb.Block
+	// Footnotes are written after the blocks, for references resolved in
+	// footnote style.
+	Footnotes []Footnote
+}
+
+// FromPage returns the document for a page.
+func FromPage(p *roamdb.Page) Document {
+	return Document{Title: p.Title, UID: p.UID, ViewType: p.ViewType, Blocks: p.Children}
+}
+
+// FromBlock returns the document for a block, rendered as the only
+// top-level item.
+func FromBlock(b *roamdb.Block) Document {
+	return Document{UID: b.UID, Blocks: []roamdb.Block{*b}}
+}
+
+// Renderer writes a document in one format.
+type Renderer interface {
+	Render(w io.Writer, doc Document) error
+}
+
+var renderers = map[string]Renderer{
+	"markdown": markdownRenderer{},
+	"logseq":   markdownRenderer{logseq: true},
+	"org":      orgRenderer{},
+	"html":     htmlRenderer{},
+	"opml":     opmlRenderer{},
+	"json":     jsonRenderer{},
+}
+
+// Lookup returns the renderer for a format name.
+func Lookup(format string) (Renderer, error) {
+	r, ok := renderers[strings.ToLower(strings.TrimSpace(format))]
+	if !ok {
+		return nil, fmt.Errorf("unknown render format %q (expected %s)", format, strings.Join(Formats(), "|"))
+	}
+	return r, nil
+}
+
+// Formats lists the registered format names.
+func Formats() []string {
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Task states a block can start with.
+const (
+	TaskNone = ""
+	TaskTodo = "TODO"
+	TaskDone = "DONE"
+)
+
+// task splits a leading {{[[TODO]]}} or {{[[DONE]]}} macro off a block's
+// nodes.
+func task(nodes []*markup.Node) (string, []*markup.Node) {
+	if len(nodes) == 0 || nodes[0].Kind != markup.KindMacro {
+		return TaskNone, nodes
+	}
+	state := strings.ToUpper(nodes[0].Name)
+	if state != TaskTodo && state != TaskDone {
+		return TaskNone, nodes
+	}
+	rest := nodes[1:]
+	if len(rest) > 0 && rest[0].Kind == markup.KindText {
+		trimmed := *rest[0]
+		trimmed.Text = strings.TrimLeft(trimmed.Text, " ")
+		rest = append([]*markup.Node{&trimmed}, rest[1:]...)
+	}
+	return state, rest
+}
+
+// codeParts returns the language and body of a code block node.
+func codeParts(n *markup.Node) (string, string) {
+	body := n.Text
+	if n.Lang != "" {
+		body = body[strings.IndexByte(body, '\n')+1:]
+	} else {
+		body = strings.TrimPrefix(body, "\n")
+	}
+	return n.Lang, strings.TrimRight(body, "\n")
+}
+
+// numbered reports whether a view type lists children as a numbered list.
+func numbered(viewType string) bool {
+	return viewType == "numbered"
+}
+
+// aliasDest returns an alias destination as Roam writes it.
+func aliasDest(n *markup.Node) string {
+	switch n.AliasTo {
+	case markup.AliasPage:
+		return "[[" + n.Target + "]]"
+	case markup.AliasBlock:
+		return "((" + n.Target + "))"
+	}
+	return n.Target
+}
@@ -0,0 +1,196 @@
+package render
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+func samplePage(t *testing.T) *roamdb.Page {
+	t.Helper()
+	page, err := roamdb.ParsePage(json.RawMessage(`{
+		":node/title": "Plan",
+		":block/uid": "p1",
+		":block/children": [
+			{":block/uid": "h", ":block/string": "Goals", ":block/heading": 2, ":block/order": 0,
+			 ":children/view-type": ":numbered",
+			 ":block/children": [
+				{":block/uid": "g2", ":block/string": "Second __goal__", ":block/order": 1},
+				{":block/uid": "g1", ":block/string": "First **goal** with [[Dune]]", ":block/order": 0}
+			 ]},
+			{":block/uid": "t1", ":block/string": "{{[[TODO]]}} buy ^^milk^^", ":block/order": 1},
+			{":block/uid": "t2", ":block/string": "{{[[DONE]]}} ship <it> #release", ":block/order": 2},
+			{":block/uid": "c1", ":block/string": "` + "```go\\nfmt.Println(1)\\n```" + `", ":block/order": 3},
+			{":block/uid": "m", ":block/string": "line one\nsee ((g1)) and [docs](https://x.io)", ":block/order": 4}
+		]}`))
+	if err != nil {
+		t.Fatalf("parse page: %v", err)
+	}
+	return page
+}
+
+func renderString(t *testing.T, format string, doc Document) string {
+	t.Helper()
+	r, err := Lookup(format)
+	if err != nil {
+		t.Fatalf("Lookup(%q): %v", format, err)
+	}
+	var sb strings.Builder
+	if err := r.Render(&sb, doc); err != nil {
+		t.Fatalf("render %s: %v", format, err)
+	}
+	return sb.String()
+}
+
+func TestMarkdown(t *testing.T) {
+	got := renderString(t, "markdown", FromPage(samplePage(t)))
+	want := "# Plan\n\n" +
+		"- ## Goals\n" +
+		"  1. First **goal** with [[Dune]]\n" +
+		"  2. Second *goal*\n" +
+		"- [ ] buy ==milk==\n" +
+		"- [x] ship <it> #release\n" +
+		"- ```go\n" +
+		"  fmt.Println(1)\n" +
+		"  ```\n" +
+		"- line one\n" +
+		"  see ((g1)) and [docs](https://x.io)\n"
+	if got != want {
+		t.Fatalf("unexpected markdown:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestLogseq(t *testing.T) {
+	got := renderString(t, "logseq", FromPage(samplePage(t)))
+	for _, want := range []string{
+		"title:: Plan\n\n",
+		"- ## Goals\n",
+		"\t- First **goal** with [[Dune]]\n\t  logseq.order-list-type:: number\n",
+		"\t- Second _goal_\n",
+		"- TODO buy ^^milk^^\n",
+		"- DONE ship <it> #release\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("logseq output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestOrg(t *testing.T) {
+	got := renderString(t, "org", FromPage(samplePage(t)))
+	for _, want := range []string{
+		"#+TITLE: Plan\n\n",
+		"* Goals\n:PROPERTIES:\n:ID: h\n:END:\n",
+		"** 1. First *goal* with [[Dune]]\n",
+		"** 2. Second /goal/\n",
+		"* TODO buy _milk_\n",
+		"* DONE ship <it> [[release][#release]]\n",
+		"* \n:PROPERTIES:\n:ID: c1\n:END:\n#+BEGIN_SRC go\nfmt.Println(1)\n#+END_SRC\n",
+		"* line one\n:PROPERTIES:\n:ID: m\n:END:\nsee [[id:g1]] and [[https://x.io][docs]]\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("org output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestHTML(t *testing.T) {
+	got := renderString(t, "html", FromPage(samplePage(t)))
+	for _, want := range []string{
+		"<title>Plan</title>",
+		"<h1>Plan</h1>",
+		`<li id="h"><h2>Goals</h2>`,
+		"<ol>",
+		`First <strong>goal</strong> with <span class="page-ref" data-page="Dune">Dune</span>`,
+		`<input type="checkbox" disabled> buy <mark>milk</mark>`,
+		`<input type="checkbox" checked disabled> ship &lt;it&gt; <span class="tag" data-page="release">#release</span>`,
+		`<pre><code class="language-go">fmt.Println(1)</code></pre>`,
+		`line one<br>` + "\n" + `see <a class="block-ref" href="#g1">((g1))</a> and <a href="https://x.io">docs</a>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("html output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestOPML(t *testing.T) {
+	got := renderString(t, "opml", FromPage(samplePage(t)))
+	var doc struct {
+		Title    string `xml:"head>title"`
+		Outlines []struct {
+			Text     string `xml:"text,attr"`
+			UID      string `xml:"_uid,attr"`
+			Children []struct {
+				Text string `xml:"text,attr"`
+			} `xml:"outline"`
+		} `xml:"body>outline"`
+	}
+	if err := xml.Unmarshal([]byte(got), &doc); err != nil {
+		t.Fatalf("invalid OPML: %v\n%s", err, got)
+	}
+	if doc.Title != "Plan" || len(doc.Outlines) != 5 || doc.Outlines[0].UID != "h" {
+		t.Fatalf("unexpected OPML structure: %+v", doc)
+	}
+	if doc.Outlines[0].Children[0].Text != "First **goal** with [[Dune]]" {
+		t.Fatalf("unexpected child order: %+v", doc.Outlines[0].Children)
+	}
+	if doc.Outlines[4].Text != "line one\nsee ((g1)) and [docs](https://x.io)" {
+		t.Fatalf("multi-line text not preserved: %q", doc.Outlines[4].Text)
+	}
+}
+
+func TestJSONTree(t *testing.T) {
+	got := renderString(t, "json", FromPage(samplePage(t)))
+	var tree Tree
+	if err := json.Unmarshal([]byte(got), &tree); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if tree.Title != "Plan" || len(tree.Children) != 5 {
+		t.Fatalf("unexpected tree: %+v", tree)
+	}
+	goals := tree.Children[0]
+	if goals.Heading != 2 || goals.ViewType != "numbered" || goals.Children[0].UID != "g1" {
+		t.Fatalf("unexpected goals node: %+v", goals)
+	}
+	first := goals.Children[0]
+	if first.Text != "First goal with Dune" || len(first.PageRefs) != 1 || first.PageRefs[0] != "Dune" {
+		t.Fatalf("unexpected first node: %+v", first)
+	}
+	if todo := tree.Children[1]; todo.Task != TaskTodo || todo.Text != "buy milk" {
+		t.Fatalf("unexpected task node: %+v", todo)
+	}
+}
+
+func TestFromBlock(t *testing.T) {
+	block := roamdb.Block{UID: "b", String: "Root", Children: []roamdb.Block{{UID: "c", String: "Child"}}}
+	if got := renderString(t, "markdown", FromBlock(&block)); got != "- Root\n  - Child\n" {
+		t.Fatalf("unexpected block markdown %q", got)
+	}
+}
+
+func TestLookupUnknown(t *testing.T) {
+	if _, err := Lookup("docx"); err == nil || !strings.Contains(err.Error(), "html|json|logseq|markdown|opml|org") {
+		t.Fatalf("expected unknown format error, got %v", err)
+	}
+}
+
+func TestMarkdownWithUIDs(t *testing.T) {
+	var sb strings.Builder
+	if err := MarkdownWithUIDs().Render(&sb, FromPage(samplePage(t))); err != nil {
+		t.Fatal(err)
+	}
+	got := sb.String()
+	for _, want := range []string{
+		"- ## Goals <!-- uid: h -->\n",
+		"  1. First **goal** with [[Dune]] <!-- uid: g1 -->\n",
+		"- <!-- uid: c1 -->\n  ```go\n  fmt.Println(1)\n  ```\n",
+		"- line one <!-- uid: m -->\n  see ((g1))",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("markdown output missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package roamdb
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// DailyNoteTitle returns the title of the daily note page for t, e.g.
+// "January 2nd, 2025".
+func DailyNoteTitle(t time.Time) string {
+	day := t.Day()
+	suffix := "th"
+	if day < 11 || day > 13 {
+		switch day % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return fmt.Sprintf("%s %d%s, %d", t.Month().String(), day, suffix, t.Year())
+}
+
+// DailyNoteUID returns the uid of the daily note page for t (MM-DD-YYYY).
+func DailyNoteUID(t time.Time) string {
+	return t.Format("01-02-2006")
+}
+
+var dailyNoteTitle = regexp.MustCompile(`^([A-Z][a-z]+) (\d{1,2})(?:st|nd|rd|th), (\d{4})$`)
+
+// ParseDailyNoteTitle returns the date of a daily note page title, and
+// false when title is not one.
+func ParseDailyNoteTitle(title string) (time.Time, bool) {
+	m := dailyNoteTitle.FindStringSubmatch(title)
+	if m == nil {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("January 2 2006", m[1]+" "+m[2]+" "+m[3])
+	if err != nil || DailyNoteTitle(t) != title {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// NewUID returns a random nine-character uid in the alphabet Roam uses.
+func NewUID() string {
+	var b [7]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("reading random bytes: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(b[:])[:9]
+}
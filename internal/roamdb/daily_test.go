@@ -0,0 +1,44 @@
+package roamdb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDailyNoteTitleAndUID(t *testing.T) {
+	cases := map[string]string{
+		"2025-01-01": "January 1st, 2025",
+		"2025-03-12": "March 12th, 2025",
+		"2025-05-22": "May 22nd, 2025",
+		"2025-08-23": "August 23rd, 2025",
+	}
+	for date, want := range cases {
+		d, _ := time.Parse("2006-01-02", date)
+		if got := DailyNoteTitle(d); got != want {
+			t.Errorf("DailyNoteTitle(%s) = %q, want %q", date, got, want)
+		}
+	}
+	d, _ := time.Parse("2006-01-02", "2025-01-15")
+	if got := DailyNoteUID(d); got != "01-15-2025" {
+		t.Fatalf("unexpected daily note uid %q", got)
+	}
+}
+
+func TestParseDailyNoteTitle(t *testing.T) {
+	d, ok := ParseDailyNoteTitle("August 23rd, 2025")
+	if !ok || DailyNoteUID(d) != "08-23-2025" {
+		t.Fatalf("unexpected parse: %v, %v", d, ok)
+	}
+	for _, title := range []string{"August 23th, 2025", "Agenda 1st, 2025", "Notes"} {
+		if _, ok := ParseDailyNoteTitle(title); ok {
+			t.Errorf("%q parsed as a daily note", title)
+		}
+	}
+}
+
+func TestNewUID(t *testing.T) {
+	a, b := NewUID(), NewUID()
+	if len(a) != 9 || a == b {
+		t.Fatalf("unexpected uids %q, %q", a, b)
+	}
+}
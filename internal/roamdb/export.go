@@ -0,0 +1,200 @@
+package roamdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/salmonumbrella/roam-cli/internal/edn"
+)
+
+// exportKeys maps the keys of Roam's JSON export to pull attributes.
+var exportKeys = map[string]string{
+	"title":              "node/title",
+	"uid":                "block/uid",
+	"string":             "block/string",
+	"children":           "block/children",
+	"order":              "block/order",
+	"heading":            "block/heading",
+	"open":               "block/open",
+	"props":              "block/props",
+	"view-type":          "children/view-type",
+	"children-view-type": "children/view-type",
+	"block-view-type":    "block/view-type",
+	"text-align":         "block/text-align",
+	"create-time":        "create/time",
+	"edit-time":          "edit/time",
+}
+
+// exportAttrs are the attributes read from the datoms of an EDN export.
+var exportAttrs = []string{
+	"node/title", "block/uid", "block/string", "block/order", "block/heading",
+	"block/open", "block/props", "children/view-type", "block/view-type",
+	"block/text-align", "create/time", "edit/time",
+}
+
+// ParseExport reads the pages of a Roam graph export. It accepts the JSON
+// export (an array of pages with title, children, string and uid keys),
+// the EDN export (a #datascript/DB value holding the graph's datoms), and
+// arrays of pulled pages in JSON or EDN. Children are returned in order.
+func ParseExport(data []byte) ([]Page, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("export is empty")
+	}
+	var items []interface{}
+	if trimmed[0] == '[' && json.Valid(trimmed) {
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, fmt.Errorf("parse export: %w", err)
+		}
+		return exportPages(items)
+	}
+
+	v, err := edn.Parse(string(trimmed))
+	if err != nil {
+		return nil, fmt.Errorf("parse export: %w", err)
+	}
+	if db, ok := v.(edn.Tagged); ok && db.Tag == "datascript/DB" {
+		return datomPages(db.Value)
+	}
+	converted, ok := edn.ToJSON(v).([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("parse export: expected a list of pages or a #datascript/DB")
+	}
+	return exportPages(converted)
+}
+
+// exportPages decodes export page maps into pages.
+func exportPages(items []interface{}) ([]Page, error) {
+	pages := make([]Page, 0, len(items))
+	for i, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("parse export: item %d is not a page", i)
+		}
+		raw, err := json.Marshal(normalizeExportEntity(m))
+		if err != nil {
+			return nil, fmt.Errorf("parse export: %w", err)
+		}
+		page, err := ParsePage(raw)
+		if err != nil {
+			return nil, err
+		}
+		if page.Title == "" {
+			continue
+		}
+		pages = append(pages, *page)
+	}
+	return pages, nil
+}
+
+// normalizeExportEntity renames export keys to pull attributes, recursing
+// into children. Children without an order get their position, since the
+// JSON export lists them in order without one.
+func normalizeExportEntity(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		name := strings.TrimPrefix(key, ":")
+		if mapped, ok := exportKeys[name]; ok {
+			name = mapped
+		} else if !strings.Contains(name, "/") {
+			continue
+		}
+		out[name] = value
+	}
+	if children, ok := out["block/children"].([]interface{}); ok {
+		normalized := make([]interface{}, 0, len(children))
+		for i, child := range children {
+			cm, ok := child.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			cm = normalizeExportEntity(cm)
+			if _, ok := cm["block/order"]; !ok {
+				cm["block/order"] = i
+			}
+			normalized = append(normalized, cm)
+		}
+		out["block/children"] = normalized
+	}
+	return out
+}
+
+// datomPages rebuilds the page trees of a #datascript/DB export from its
+// [e a v tx] datoms.
+func datomPages(db interface{}) ([]Page, error) {
+	m, ok := db.(*edn.Map)
+	if !ok {
+		return nil, fmt.Errorf("parse export: #datascript/DB is not a map")
+	}
+	raw, _ := m.Get(edn.Keyword("datoms"))
+	datoms, ok := raw.(edn.Vector)
+	if !ok {
+		return nil, fmt.Errorf("parse export: #datascript/DB has no :datoms")
+	}
+
+	attrs := map[int64]map[string]interface{}{}
+	children := map[int64][]int64{}
+	var pageIDs []int64
+	wanted := map[string]bool{}
+	for _, a := range exportAttrs {
+		wanted[a] = true
+	}
+	for _, d := range datoms {
+		datom, ok := d.(edn.Vector)
+		if !ok || len(datom) < 3 {
+			continue
+		}
+		e, ok := datom[0].(int64)
+		attr, isKeyword := datom[1].(edn.Keyword)
+		if !ok || !isKeyword {
+			continue
+		}
+		if attr == "block/children" {
+			if child, ok := datom[2].(int64); ok {
+				children[e] = append(children[e], child)
+			}
+			continue
+		}
+		if !wanted[string(attr)] {
+			continue
+		}
+		if attrs[e] == nil {
+			attrs[e] = map[string]interface{}{}
+		}
+		attrs[e][string(attr)] = edn.ToJSON(datom[2])
+		if attr == "node/title" {
+			pageIDs = append(pageIDs, e)
+		}
+	}
+
+	var build func(e int64, seen map[int64]bool) map[string]interface{}
+	build = func(e int64, seen map[int64]bool) map[string]interface{} {
+		entity := make(map[string]interface{}, len(attrs[e])+1)
+		for k, v := range attrs[e] {
+			entity[k] = v
+		}
+		seen[e] = true
+		var kids []interface{}
+		for _, child := range children[e] {
+			if seen[child] {
+				continue
+			}
+			kids = append(kids, build(child, seen))
+		}
+		delete(seen, e)
+		if len(kids) > 0 {
+			entity["block/children"] = kids
+		}
+		return entity
+	}
+
+	sort.Slice(pageIDs, func(i, j int) bool { return pageIDs[i] < pageIDs[j] })
+	items := make([]interface{}, len(pageIDs))
+	for i, e := range pageIDs {
+		items[i] = build(e, map[int64]bool{})
+	}
+	return exportPages(items)
+}
@@ -0,0 +1,69 @@
+package roamdb
+
+import "testing"
+
+func TestParseExportJSON(t *testing.T) {
+	data := `[{"title":"Page","uid":"p1","create-time":5,"children":[
+		{"string":"first","uid":"b1","heading":2,"children-view-type":"numbered","children":[{"string":"nested","uid":"b3"}]},
+		{"string":"second","uid":"b2","props":{"key":"v"}}]}]`
+	pages, err := ParseExport([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseExport failed: %v", err)
+	}
+	if len(pages) != 1 || pages[0].Title != "Page" || pages[0].UID != "p1" || pages[0].CreateTime != 5 {
+		t.Fatalf("unexpected pages: %+v", pages)
+	}
+	kids := pages[0].Children
+	if len(kids) != 2 || kids[0].UID != "b1" || kids[1].UID != "b2" {
+		t.Fatalf("children out of order: %+v", kids)
+	}
+	if kids[0].Heading != 2 || kids[0].ViewType != "numbered" || kids[0].Children[0].String != "nested" {
+		t.Fatalf("block attributes not read: %+v", kids[0])
+	}
+	if kids[1].Props["key"] != "v" {
+		t.Fatalf("props not read: %+v", kids[1].Props)
+	}
+}
+
+func TestParseExportEDN(t *testing.T) {
+	data := `#datascript/DB {:schema {:block/uid {:db/unique :db.unique/identity}}
+	 :datoms [[1 :node/title "Page" 536870913]
+	          [1 :block/uid "p1" 536870913]
+	          [1 :block/children 2 536870913]
+	          [1 :block/children 3 536870913]
+	          [2 :block/uid "b2" 536870913]
+	          [2 :block/string "second" 536870913]
+	          [2 :block/order 1 536870913]
+	          [3 :block/uid "b1" 536870913]
+	          [3 :block/string "first" 536870913]
+	          [3 :block/order 0 536870913]
+	          [3 :children/view-type :numbered 536870913]
+	          [3 :block/props {:tags #{"x"}} 536870913]]}`
+	pages, err := ParseExport([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseExport failed: %v", err)
+	}
+	if len(pages) != 1 || pages[0].UID != "p1" {
+		t.Fatalf("unexpected pages: %+v", pages)
+	}
+	kids := pages[0].Children
+	if len(kids) != 2 || kids[0].String != "first" || kids[1].String != "second" {
+		t.Fatalf("children not ordered: %+v", kids)
+	}
+	if kids[0].ViewType != "numbered" || kids[0].Props["tags"] == nil {
+		t.Fatalf("block attributes not read: %+v", kids[0])
+	}
+
+	pulled, err := ParseExport([]byte(`[{:node/title "Other" :block/uid "p2" :block/children [{:block/string "x" :block/uid "c"}]}]`))
+	if err != nil || len(pulled) != 1 || pulled[0].Children[0].UID != "c" {
+		t.Fatalf("unexpected pulled pages: %+v, %v", pulled, err)
+	}
+}
+
+func TestParseExportErrors(t *testing.T) {
+	for _, data := range []string{"", `{"title":"x"}`, "[1 2]"} {
+		if _, err := ParseExport([]byte(data)); err == nil {
+			t.Errorf("expected an error for %q", data)
+		}
+	}
+}
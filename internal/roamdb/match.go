@@ -0,0 +1,97 @@
+package roamdb
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TextMatch describes how a search term is matched against a string attribute.
+// The zero value of the mode flags is a case-insensitive substring match.
+type TextMatch struct {
+	// Term is the text (or regular expression when Regex is set) to find.
+	Term string
+	// CaseSensitive disables the (?i) flag.
+	CaseSensitive bool
+	// Regex treats Term as a regular expression instead of literal text.
+	Regex bool
+	// Word only matches Term at word boundaries.
+	Word bool
+}
+
+// Expr returns the regular expression source for the term without flags.
+// Literal terms are escaped and whole-word matches are wrapped in \b anchors.
+func (m TextMatch) Expr() string {
+	expr := m.Term
+	if !m.Regex {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if m.Word {
+		expr = `\b(?:` + expr + `)\b`
+	}
+	return expr
+}
+
+// Pattern returns the full regular expression, including the case-insensitive
+// flag when needed. The (?i) prefix is understood by Go, the JVM and
+// ClojureScript's re-pattern, so the same source works client-side, on the
+// cloud API and on the Local API.
+func (m TextMatch) Pattern() string {
+	if m.CaseSensitive {
+		return m.Expr()
+	}
+	return "(?i)" + m.Expr()
+}
+
+// UsesRegex reports whether the match needs re-find rather than a plain
+// clojure.string/includes? predicate.
+func (m TextMatch) UsesRegex() bool {
+	return m.Regex || m.Word || !m.CaseSensitive
+}
+
+// Compile compiles the match into a Go regexp for client-side filtering and
+// highlighting of query results.
+func (m TextMatch) Compile() (*regexp.Regexp, error) {
+	re, err := regexp.Compile(m.Pattern())
+	if err != nil {
+		return nil, fmt.Errorf("invalid search pattern %q: %w", m.Term, err)
+	}
+	return re, nil
+}
+
+// Clauses returns Datalog :where clauses constraining stringVar to values
+// matching the term. reVar names the intermediate pattern binding and must be
+// unique within the query.
+func (m TextMatch) Clauses(stringVar, reVar string) string {
+	if !m.UsesRegex() {
+		return fmt.Sprintf(`[(clojure.string/includes? %s %s)]`, stringVar, QuoteString(m.Term))
+	}
+	return fmt.Sprintf(`[(re-pattern %s) %s]
+		[(re-find %s %s)]`, QuoteString(m.Pattern()), reVar, reVar, stringVar)
+}
+
+// QuoteString returns s as a double-quoted EDN string literal.
+// Unlike EscapeString it also escapes backslashes, which regex sources need.
+func QuoteString(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s) + 2)
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '\\':
+			sb.WriteString(`\\`)
+		case '"':
+			sb.WriteString(`\"`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\r':
+			sb.WriteString(`\r`)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,80 @@
+package roamdb
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTextMatchPlainCaseSensitiveUsesIncludes(t *testing.T) {
+	m := TextMatch{Term: `say "hi"`, CaseSensitive: true}
+	if m.UsesRegex() {
+		t.Fatalf("expected plain case-sensitive match to avoid regex")
+	}
+	clauses := m.Clauses("?string", "?re")
+	if !strings.Contains(clauses, `clojure.string/includes? ?string "say \"hi\""`) {
+		t.Fatalf("unexpected clauses: %s", clauses)
+	}
+}
+
+func TestTextMatchCaseInsensitiveUsesRePattern(t *testing.T) {
+	m := TextMatch{Term: "a.b"}
+	clauses := m.Clauses("?string", "?re")
+	if !strings.Contains(clauses, `[(re-pattern "(?i)a\\.b") ?re]`) {
+		t.Fatalf("expected escaped case-insensitive pattern, got: %s", clauses)
+	}
+	if !strings.Contains(clauses, `[(re-find ?re ?string)]`) {
+		t.Fatalf("expected re-find clause, got: %s", clauses)
+	}
+
+	re, err := m.Compile()
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if !re.MatchString("xA.By") || re.MatchString("aXb") {
+		t.Fatalf("unexpected match semantics for %s", re)
+	}
+}
+
+func TestTextMatchWord(t *testing.T) {
+	re, err := TextMatch{Term: "go", Word: true}.Compile()
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if !re.MatchString("Let's Go now") {
+		t.Fatalf("expected whole word match")
+	}
+	if re.MatchString("going") {
+		t.Fatalf("did not expect partial word match")
+	}
+}
+
+func TestTextMatchRegex(t *testing.T) {
+	re, err := TextMatch{Term: `v[0-9]+`, Regex: true, CaseSensitive: true}.Compile()
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if !re.MatchString("release v12") || re.MatchString("V12") {
+		t.Fatalf("unexpected regex semantics")
+	}
+
+	if _, err := (TextMatch{Term: "(", Regex: true}).Compile(); err == nil {
+		t.Fatalf("expected error for invalid regex")
+	}
+}
+
+func TestQuoteString(t *testing.T) {
+	got := QuoteString("a\\b\"c\nd")
+	if got != `"a\\b\"c\nd"` {
+		t.Fatalf("unexpected quoted string: %s", got)
+	}
+}
+
+func TestQuerySearchBlocks(t *testing.T) {
+	query := QuerySearchBlocks(`[(re-find ?re ?string)]`, "")
+	if !strings.HasPrefix(query, "[:find ?uid ?string ?page-title ?page-uid") {
+		t.Fatalf("unexpected find clause: %s", query)
+	}
+	if !strings.Contains(query, "[(re-find ?re ?string)]") {
+		t.Fatalf("expected extra clause in query: %s", query)
+	}
+}
@@ -35,22 +35,284 @@ func QuerySearchBlocksContains(text string) string {
 		[?page :node/title ?page-title]]`, escaped)
 }
 
-// QueryListPages builds a query for listing pages, optionally filtered by today.
-func QueryListPages(modifiedToday bool, now time.Time) string {
-	if modifiedToday {
+// QuerySearchBlocks builds a query returning [uid string page-title page-uid]
+// rows for blocks satisfying the given :where clauses. Clauses may refer to
+// ?b and ?string. Use BlockSearch directly to add filters.
+func QuerySearchBlocks(clauses ...string) string {
+	return BlockSearch{Clauses: clauses}.Query()
+}
+
+// dailyNoteUIDPattern matches the MM-DD-YYYY uids of daily note pages.
+const dailyNoteUIDPattern = `^\d{2}-\d{2}-\d{4}$`
+
+// PageFilter narrows QueryListPages. Zero values disable a filter.
+type PageFilter struct {
+	// ModifiedToday keeps pages edited since the start of the current day.
+	ModifiedToday bool
+	// Namespace keeps pages whose title starts with "Namespace/".
+	Namespace string
+	// CreatedSince/EditedSince bound the page's :create/time and :edit/time
+	// (milliseconds since epoch).
+	CreatedSince int64
+	EditedSince  int64
+	// Orphans keeps pages no block references.
+	Orphans bool
+	// Empty keeps pages without children.
+	Empty bool
+	// Daily, when set, keeps only daily note pages (true) or only other
+	// pages (false). Daily notes are recognized by their MM-DD-YYYY uid.
+	Daily *bool
+}
+
+// clauses returns clauses constraining ?p, ?title, ?uid and ?edit-time.
+func (f PageFilter) clauses(now time.Time) []string {
+	var clauses []string
+	if f.ModifiedToday {
 		startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-		timestamp := startOfDay.UnixMilli()
+		clauses = append(clauses, fmt.Sprintf(`[(> ?edit-time %d)]`, startOfDay.UnixMilli()))
+	}
+	if f.EditedSince != 0 {
+		clauses = append(clauses, fmt.Sprintf(`[(>= ?edit-time %d)]`, f.EditedSince))
+	}
+	if f.CreatedSince != 0 {
+		clauses = append(clauses,
+			`[?p :create/time ?create-time]`,
+			fmt.Sprintf(`[(>= ?create-time %d)]`, f.CreatedSince))
+	}
+	if ns := strings.TrimSuffix(strings.TrimSpace(f.Namespace), "/"); ns != "" {
+		clauses = append(clauses, fmt.Sprintf(`[(clojure.string/starts-with? ?title %s)]`, QuoteString(ns+"/")))
+	}
+	if f.Daily != nil {
+		clauses = append(clauses, fmt.Sprintf(`[(re-pattern %s) ?daily-re]`, QuoteString(dailyNoteUIDPattern)))
+		if *f.Daily {
+			clauses = append(clauses, `[(re-find ?daily-re ?uid)]`)
+		} else {
+			clauses = append(clauses, `(not [(re-find ?daily-re ?uid)])`)
+		}
+	}
+	if f.Orphans {
+		clauses = append(clauses, `(not [_ :block/refs ?p])`)
+	}
+	if f.Empty {
+		clauses = append(clauses, `(not [?p :block/children _])`)
+	}
+	return clauses
+}
 
-		return fmt.Sprintf(`[:find ?title ?uid ?edit-time
-			:where
-			[?p :node/title ?title]
-			[?p :block/uid ?uid]
-			[?p :edit/time ?edit-time]
-			[(> ?edit-time %d)]]`, timestamp)
+// QueryListPages builds a query returning [title uid edit-time] rows for the
+// pages matching f; edit-time is 0 when unset.
+func QueryListPages(f PageFilter, now time.Time) string {
+	clauses := []string{
+		`[?p :node/title ?title]`,
+		`[?p :block/uid ?uid]`,
+		`[(get-else $ ?p :edit/time 0) ?edit-time]`,
 	}
+	clauses = append(clauses, f.clauses(now)...)
+	return "[:find ?title ?uid ?edit-time\n\t\t:where\n\t\t" + strings.Join(clauses, "\n\t\t") + "]"
+}
 
+// QueryPageBlockStrings builds a query returning [page-uid block-eid string]
+// rows for every block on the pages whose uids are the single query argument.
+func QueryPageBlockStrings() string {
+	return `[:find ?page-uid ?b ?string
+		:in $ [?page-uid ...]
+		:where
+		[?p :block/uid ?page-uid]
+		[?b :block/page ?p]
+		[?b :block/string ?string]]`
+}
+
+// QueryPageRefCounts builds a query returning [page-uid count] rows with the
+// number of blocks referencing each page whose uid is in the single query
+// argument. Pages nothing references are missing from the result.
+func QueryPageRefCounts() string {
+	return `[:find ?page-uid (count ?r)
+		:in $ [?page-uid ...]
+		:where
+		[?p :block/uid ?page-uid]
+		[?r :block/refs ?p]]`
+}
+
+// QueryMaxEID builds a query returning the largest entity id that has a
+// :block/uid (pages and blocks).
+func QueryMaxEID() string {
+	return `[:find (max ?e) :where [?e :block/uid _]]`
+}
+
+// QueryListPagesRange is QueryListPages restricted to page entity ids in r.
+// Rows are [title uid edit-time eid]; edit-time is 0 when unset.
+func QueryListPagesRange(f PageFilter, now time.Time, r EIDRange) string {
+	clauses := []string{`[?p :node/title ?title]`}
+	clauses = append(clauses, r.Clauses("?p")...)
+	clauses = append(clauses,
+		`[?p :block/uid ?uid]`,
+		`[(get-else $ ?p :edit/time 0) ?edit-time]`)
+	clauses = append(clauses, f.clauses(now)...)
+	return "[:find ?title ?uid ?edit-time ?p\n\t\t:where\n\t\t" + strings.Join(clauses, "\n\t\t") + "]"
+}
+
+// QueryIndexBlocks builds a query returning [uid string page-title page-uid
+// edit-time] rows for blocks edited at or after since (0 for every block).
+func QueryIndexBlocks(since int64) string {
+	filter := ""
+	if since > 0 {
+		filter = fmt.Sprintf("\n\t\t[(>= ?edit-time %d)]", since)
+	}
+	return fmt.Sprintf(`[:find ?uid ?string ?page-title ?page-uid ?edit-time
+		:where
+		[?b :block/uid ?uid]
+		[?b :block/string ?string]
+		[?b :block/page ?page]
+		[?page :node/title ?page-title]
+		[?page :block/uid ?page-uid]
+		[(get-else $ ?b :edit/time 0) ?edit-time]%s]`, filter)
+}
+
+// QueryPagesEditTime builds a query returning [title uid edit-time] rows for
+// every page; edit-time is 0 when unset.
+func QueryPagesEditTime() string {
+	return `[:find ?title ?uid ?edit-time
+		:where
+		[?p :node/title ?title]
+		[?p :block/uid ?uid]
+		[(get-else $ ?p :edit/time 0) ?edit-time]]`
+}
+
+// QueryPageBlocks builds a query returning [page-uid uid parent-uid order
+// edit-time] rows for every block on every page; edit-time is 0 when
+// unset. A page's own :edit/time does not change when its blocks are
+// edited, and deleting or moving a block changes no edit time at all.
+func QueryPageBlocks() string {
+	return `[:find ?page-uid ?uid ?parent-uid ?order ?edit-time
+		:where
+		[?b :block/page ?page]
+		[?page :block/uid ?page-uid]
+		[?b :block/uid ?uid]
+		[?parent :block/children ?b]
+		[?parent :block/uid ?parent-uid]
+		[(get-else $ ?b :block/order 0) ?order]
+		[(get-else $ ?b :edit/time 0) ?edit-time]]`
+}
+
+// QueryPageUIDsByTitle builds a query returning [title uid] rows for the
+// pages whose titles are in the input collection. Pass the titles as the
+// single query argument.
+func QueryPageUIDsByTitle() string {
 	return `[:find ?title ?uid
+		:in $ [?title ...]
 		:where
 		[?p :node/title ?title]
 		[?p :block/uid ?uid]]`
 }
+
+// QueryPageTitlesByUID builds a query returning [uid title] rows for the
+// pages whose uids are in the input collection. Pass the uids as the single
+// query argument.
+func QueryPageTitlesByUID() string {
+	return `[:find ?uid ?title
+		:in $ [?uid ...]
+		:where
+		[?p :block/uid ?uid]
+		[?p :node/title ?title]]`
+}
+
+// QueryExistingUIDs builds a query returning the UIDs from the input
+// collection that belong to an entity. Pass the UIDs as the single query
+// argument.
+func QueryExistingUIDs() string {
+	return `[:find ?uid
+		:in $ [?uid ...]
+		:where
+		[_ :block/uid ?uid]]`
+}
+
+// QueryBlockUIDs builds a query returning the UID of every block.
+func QueryBlockUIDs() string {
+	return `[:find ?uid
+		:where
+		[?b :block/uid ?uid]
+		[?b :block/string _]]`
+}
+
+// QueryBreadcrumbEdges builds a query returning [uid child-uid parent-uid
+// parent-string] rows: every parent/child link on the path from each block
+// in the input collection up to its page. Pass the block UIDs as the single
+// query argument. Pages have an empty parent-string.
+func QueryBreadcrumbEdges() string {
+	return `[:find ?uid ?child-uid ?parent-uid ?parent-string
+		:in $ [?uid ...]
+		:where
+		[?b :block/uid ?uid]
+		[?b :block/parents ?parent]
+		[?parent :block/children ?child]
+		(or [(= ?child ?b)]
+			[?b :block/parents ?child])
+		[?child :block/uid ?child-uid]
+		[?parent :block/uid ?parent-uid]
+		[(get-else $ ?parent :block/string "") ?parent-string]]`
+}
+
+// QueryChildBlocks builds a query returning [uid child-uid child-string order]
+// rows for the direct children of each block in the input collection. Pass
+// the block UIDs as the single query argument.
+func QueryChildBlocks() string {
+	return `[:find ?uid ?child-uid ?child-string ?order
+		:in $ [?uid ...]
+		:where
+		[?b :block/uid ?uid]
+		[?b :block/children ?child]
+		[?child :block/uid ?child-uid]
+		[?child :block/string ?child-string]
+		[(get-else $ ?child :block/order 0) ?order]]`
+}
+
+// RoamTemplatesPage is the page Roam's own templates are filed under.
+const RoamTemplatesPage = "roam/templates"
+
+// QueryRoamTemplates builds a query returning [uid name page-title] rows for
+// Roam's templates: every child of a block that references
+// [[roam/templates]] is a template named by its text.
+func QueryRoamTemplates() string {
+	return fmt.Sprintf(`[:find ?uid ?name ?page-title
+		:where
+		[?t :node/title %s]
+		[?h :block/refs ?t]
+		[?h :block/children ?b]
+		[?b :block/uid ?uid]
+		[?b :block/string ?name]
+		[?b :block/page ?p]
+		[?p :node/title ?page-title]]`, QuoteString(RoamTemplatesPage))
+}
+
+// QueryNamespacePages builds a query returning [title uid] rows for the
+// pages inside a namespace: those whose title starts with prefix + "/".
+func QueryNamespacePages(prefix string) string {
+	return fmt.Sprintf(`[:find ?title ?uid
+		:where
+		[?p :node/title ?title]
+		[(clojure.string/starts-with? ?title %s)]
+		[?p :block/uid ?uid]]`, QuoteString(strings.TrimSuffix(prefix, "/")+"/"))
+}
+
+// QueryReferencingBlocks builds a query returning [uid] rows for the blocks
+// whose :block/refs point at a page or block with a uid in the single query
+// argument.
+func QueryReferencingBlocks() string {
+	return `[:find ?uid
+		:in $ [?target ...]
+		:where
+		[?t :block/uid ?target]
+		[?r :block/refs ?t]
+		[?r :block/uid ?uid]]`
+}
+
+// QueryBlockParent builds a query returning the [parent-uid order] row of
+// the block with the given uid.
+func QueryBlockParent(uid string) string {
+	return fmt.Sprintf(`[:find ?parent-uid ?order
+		:where
+		[?b :block/uid %s]
+		[?parent :block/children ?b]
+		[?parent :block/uid ?parent-uid]
+		[(get-else $ ?b :block/order 0) ?order]]`, QuoteString(uid))
+}
@@ -29,8 +29,69 @@ func TestQueryBlockByUID(t *testing.T) {
 
 func TestQueryListPages(t *testing.T) {
 	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
-	query := QueryListPages(true, now)
+	query := QueryListPages(PageFilter{ModifiedToday: true}, now)
 	if !strings.Contains(query, ":edit/time") {
 		t.Fatalf("expected edit time filter in query: %s", query)
 	}
 }
+
+func TestQueryListPagesFilters(t *testing.T) {
+	daily := false
+	query := QueryListPages(PageFilter{
+		Namespace:    "Project/",
+		CreatedSince: 100,
+		EditedSince:  200,
+		Orphans:      true,
+		Empty:        true,
+		Daily:        &daily,
+	}, time.Now())
+	for _, want := range []string{
+		`[(clojure.string/starts-with? ?title "Project/")]`,
+		`[?p :create/time ?create-time]`,
+		`[(>= ?create-time 100)]`,
+		`[(>= ?edit-time 200)]`,
+		`(not [_ :block/refs ?p])`,
+		`(not [?p :block/children _])`,
+		`[(re-pattern "^\\d{2}-\\d{2}-\\d{4}$") ?daily-re]`,
+		`(not [(re-find ?daily-re ?uid)])`,
+	} {
+		if !strings.Contains(query, want) {
+			t.Fatalf("missing %q in %s", want, query)
+		}
+	}
+	if q := QueryListPages(PageFilter{}, time.Now()); strings.Contains(q, "re-find") || strings.Contains(q, "not") {
+		t.Fatalf("unexpected filters without options: %s", q)
+	}
+}
+
+func TestQueryIndexBlocks(t *testing.T) {
+	full := QueryIndexBlocks(0)
+	if strings.Contains(full, ">=") {
+		t.Fatalf("expected no edit filter for full scan: %s", full)
+	}
+	if !strings.Contains(full, "get-else $ ?b :edit/time 0") {
+		t.Fatalf("expected edit time binding: %s", full)
+	}
+	incremental := QueryIndexBlocks(1234)
+	if !strings.Contains(incremental, "[(>= ?edit-time 1234)]") {
+		t.Fatalf("expected edit filter: %s", incremental)
+	}
+}
+
+func TestQueryPageEditTimes(t *testing.T) {
+	if q := QueryPagesEditTime(); !strings.Contains(q, "get-else $ ?p :edit/time 0") {
+		t.Fatalf("expected page edit time binding: %s", q)
+	}
+	if q := QueryPageBlocks(); !strings.Contains(q, "?parent :block/children ?b") || !strings.Contains(q, ":block/page") {
+		t.Fatalf("expected every block with its parent per page: %s", q)
+	}
+}
+
+func TestQueryCollectionInputs(t *testing.T) {
+	if q := QueryPageUIDsByTitle(); !strings.Contains(q, ":in $ [?title ...]") {
+		t.Fatalf("expected title collection input: %s", q)
+	}
+	if q := QueryExistingUIDs(); !strings.Contains(q, ":in $ [?uid ...]") {
+		t.Fatalf("expected uid collection input: %s", q)
+	}
+}
@@ -0,0 +1,96 @@
+package roamdb
+
+import "fmt"
+
+// AliasAttribute is the attribute Roam uses to list a page's aliases.
+const AliasAttribute = "Aliases"
+
+// RefScope is appended to the variables introduced by the ref clause
+// builders so several sets of clauses can share one query. The zero value
+// uses the plain names (?ref-page, ?ref-target, ...).
+type RefScope string
+
+func (s RefScope) v(name string) string {
+	return "?" + name + string(s)
+}
+
+// PageRefClauses returns clauses binding ?b to blocks whose :block/refs
+// include a page with a title matching title (tags, [[links]], #[[links]] and
+// attribute:: names all land in :block/refs). With aliases set, references to
+// pages listed in the matching page's "Aliases::" block count too.
+func PageRefClauses(title TextMatch, aliases bool) []string {
+	return RefScope("").PageRefClauses(title, aliases)
+}
+
+// BlockRefClauses returns clauses binding ?b to blocks whose :block/refs
+// include a block with a UID matching uid (((uid)) references and embeds).
+func BlockRefClauses(uid TextMatch) []string {
+	return RefScope("").BlockRefClauses(uid)
+}
+
+// PageRefClauses is PageRefClauses with variables suffixed by s.
+func (s RefScope) PageRefClauses(title TextMatch, aliases bool) []string {
+	page, target := s.v("ref-page"), s.v("ref-target")
+
+	var clauses []string
+	if title.UsesRegex() {
+		clauses = append(clauses,
+			fmt.Sprintf(`[%s :node/title %s]`, page, s.v("ref-title")),
+			title.Clauses(s.v("ref-title"), s.v("ref-re")))
+	} else {
+		clauses = append(clauses, fmt.Sprintf(`[%s :node/title %s]`, page, QuoteString(title.Term)))
+	}
+
+	if aliases {
+		block, str, aliasTitle := s.v("alias-block"), s.v("alias-string"), s.v("alias-title")
+		clauses = append(clauses, fmt.Sprintf(`(or-join [%[1]s %[2]s]
+			[(identity %[1]s) %[2]s]
+			(and [%[3]s :block/page %[1]s]
+				[%[3]s :block/string %[4]s]
+				[(clojure.string/starts-with? %[4]s %[6]s)]
+				[%[3]s :block/refs %[2]s]
+				[%[2]s :node/title %[5]s]
+				[(not= %[5]s %[7]s)]))`,
+			page, target, block, str, aliasTitle,
+			QuoteString(AliasAttribute+"::"), QuoteString(AliasAttribute)))
+	} else {
+		clauses = append(clauses, fmt.Sprintf(`[(identity %s) %s]`, page, target))
+	}
+
+	return append(clauses, fmt.Sprintf(`[?b :block/refs %s]`, target))
+}
+
+// BlockRefClauses is BlockRefClauses with variables suffixed by s.
+func (s RefScope) BlockRefClauses(uid TextMatch) []string {
+	target := s.v("ref-target")
+	if uid.UsesRegex() {
+		return []string{
+			fmt.Sprintf(`[?b :block/refs %s]`, target),
+			fmt.Sprintf(`[%s :block/uid %s]`, target, s.v("ref-uid")),
+			uid.Clauses(s.v("ref-uid"), s.v("ref-re")),
+		}
+	}
+	return []string{
+		fmt.Sprintf(`[%s :block/uid %s]`, target, QuoteString(uid.Term)),
+		fmt.Sprintf(`[?b :block/refs %s]`, target),
+	}
+}
+
+// UnlinkedRefClauses returns clauses binding ?b to blocks whose text matches
+// mention but that do not reference the page titled title.
+func UnlinkedRefClauses(title string, mention TextMatch) []string {
+	return []string{
+		mention.Clauses("?string", "?mention-re"),
+		fmt.Sprintf(`[?ref-page :node/title %s]`, QuoteString(title)),
+		`(not [?b :block/refs ?ref-page])`,
+	}
+}
+
+// QueryPageUIDByTitle builds a query returning the UID of the page with
+// exactly this title.
+func QueryPageUIDByTitle(title string) string {
+	return fmt.Sprintf(`[:find ?uid
+		:where
+		[?p :node/title %s]
+		[?p :block/uid ?uid]]`, QuoteString(title))
+}
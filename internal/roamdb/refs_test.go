@@ -0,0 +1,69 @@
+package roamdb
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPageRefClausesExactWithAliases(t *testing.T) {
+	query := QuerySearchBlocks(PageRefClauses(TextMatch{Term: "Project", CaseSensitive: true}, true)...)
+
+	for _, want := range []string{
+		`[?ref-page :node/title "Project"]`,
+		"(or-join [?ref-page ?ref-target]",
+		`[(clojure.string/starts-with? ?alias-string "Aliases::")]`,
+		`[(not= ?alias-title "Aliases")]`,
+		"[?b :block/refs ?ref-target]",
+	} {
+		if !strings.Contains(query, want) {
+			t.Fatalf("missing %q in query: %s", want, query)
+		}
+	}
+}
+
+func TestPageRefClausesPatternWithoutAliases(t *testing.T) {
+	clauses := strings.Join(PageRefClauses(TextMatch{Term: "^proj$", Regex: true}, false), "\n")
+
+	if !strings.Contains(clauses, `(re-pattern "(?i)^proj$") ?ref-re`) || !strings.Contains(clauses, "(re-find ?ref-re ?ref-title)") {
+		t.Fatalf("expected title pattern clauses: %s", clauses)
+	}
+	if strings.Contains(clauses, "or-join") {
+		t.Fatalf("unexpected alias clause: %s", clauses)
+	}
+}
+
+func TestBlockRefClauses(t *testing.T) {
+	exact := strings.Join(BlockRefClauses(TextMatch{Term: "abc", CaseSensitive: true}), "\n")
+	if !strings.Contains(exact, `[?ref-target :block/uid "abc"]`) {
+		t.Fatalf("unexpected exact clauses: %s", exact)
+	}
+	pattern := strings.Join(BlockRefClauses(TextMatch{Term: "ab.", CaseSensitive: true, Regex: true}), "\n")
+	if !strings.Contains(pattern, "(re-find ?ref-re ?ref-uid)") {
+		t.Fatalf("unexpected pattern clauses: %s", pattern)
+	}
+}
+
+func TestUnlinkedRefClauses(t *testing.T) {
+	clauses := strings.Join(UnlinkedRefClauses("Alpha", TextMatch{Term: "Alpha", Word: true}), "\n")
+	for _, want := range []string{
+		`(re-pattern "(?i)\\b(?:Alpha)\\b") ?mention-re`,
+		`[?ref-page :node/title "Alpha"]`,
+		"(not [?b :block/refs ?ref-page])",
+	} {
+		if !strings.Contains(clauses, want) {
+			t.Fatalf("missing %q in clauses: %s", want, clauses)
+		}
+	}
+}
+
+func TestRefScopeSuffixesVariables(t *testing.T) {
+	clauses := strings.Join(RefScope("-2").PageRefClauses(TextMatch{Term: "x", CaseSensitive: true}, true), "\n")
+	if strings.Contains(clauses, "?ref-page ") || strings.Contains(clauses, "?ref-target]") {
+		t.Fatalf("expected scoped variables only: %s", clauses)
+	}
+	for _, want := range []string{"?ref-page-2", "?ref-target-2", "?alias-block-2", "[?b :block/refs ?ref-target-2]"} {
+		if !strings.Contains(clauses, want) {
+			t.Fatalf("missing %q in clauses: %s", want, clauses)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package roamdb
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AncestorRules defines the recursive (ancestor ?b ?a) rule, true when ?a is
+// a parent, grandparent, ... of ?b. Pass it as the % input of a query.
+const AncestorRules = `[[(ancestor ?b ?a) [?a :block/children ?b]] [(ancestor ?b ?a) [?parent :block/children ?b] (ancestor ?parent ?a)]]`
+
+// SearchFilters scopes a block search. Zero values disable a filter.
+type SearchFilters struct {
+	// PageTitle restricts results to blocks on the page with this exact title.
+	PageTitle string
+	// ExcludePages drops results on pages with these exact titles.
+	ExcludePages []string
+	// Under restricts results to descendants of the block or page with this UID.
+	Under string
+	// Namespace restricts results to pages whose title starts with "Namespace/".
+	Namespace string
+	// EditedSince/EditedBefore bound :edit/time (milliseconds since epoch).
+	EditedSince  int64
+	EditedBefore int64
+	// CreatedSince/CreatedBefore bound :create/time (milliseconds since epoch).
+	CreatedSince  int64
+	CreatedBefore int64
+	// Author matches the :user/display-name of :create/user, case-insensitively.
+	Author string
+}
+
+// NeedsRules reports whether the filters use AncestorRules.
+func (f SearchFilters) NeedsRules() bool {
+	return f.Under != ""
+}
+
+// blockClauses returns clauses constraining ?b itself.
+func (f SearchFilters) blockClauses() []string {
+	var clauses []string
+	if f.Under != "" {
+		clauses = append(clauses,
+			fmt.Sprintf(`[?ancestor :block/uid %s]`, QuoteString(f.Under)),
+			`(ancestor ?b ?ancestor)`)
+	}
+	clauses = append(clauses, timeClauses(":edit/time", "?edit-time", f.EditedSince, f.EditedBefore)...)
+	clauses = append(clauses, timeClauses(":create/time", "?create-time", f.CreatedSince, f.CreatedBefore)...)
+	if f.Author != "" {
+		author := TextMatch{Term: "^" + regexp.QuoteMeta(strings.TrimSpace(f.Author)) + "$", Regex: true}
+		clauses = append(clauses,
+			`[?b :create/user ?user]`,
+			`[?user :user/display-name ?author]`,
+			author.Clauses("?author", "?author-re"))
+	}
+	return clauses
+}
+
+// pageClauses returns clauses constraining ?page and ?page-title.
+func (f SearchFilters) pageClauses() []string {
+	var clauses []string
+	if ns := strings.TrimSuffix(strings.TrimSpace(f.Namespace), "/"); ns != "" {
+		clauses = append(clauses, fmt.Sprintf(`[(clojure.string/starts-with? ?page-title %s)]`, QuoteString(ns+"/")))
+	}
+	for _, title := range f.ExcludePages {
+		if title == "" {
+			continue
+		}
+		clauses = append(clauses, fmt.Sprintf(`[(not= ?page-title %s)]`, QuoteString(title)))
+	}
+	return clauses
+}
+
+func timeClauses(attr, variable string, since, before int64) []string {
+	if since == 0 && before == 0 {
+		return nil
+	}
+	clauses := []string{fmt.Sprintf(`[?b %s %s]`, attr, variable)}
+	if since != 0 {
+		clauses = append(clauses, fmt.Sprintf(`[(>= %s %d)]`, variable, since))
+	}
+	if before != 0 {
+		clauses = append(clauses, fmt.Sprintf(`[(< %s %d)]`, variable, before))
+	}
+	return clauses
+}
+
+// EIDRange selects entities with After < eid <= Upto. Entity ids grow as
+// entities are created, so consecutive ranges page through a graph in a
+// stable order without re-running the whole query.
+type EIDRange struct {
+	After int64
+	Upto  int64
+}
+
+// Clauses returns predicates bounding variable (an entity) to the range.
+func (r EIDRange) Clauses(variable string) []string {
+	return []string{
+		fmt.Sprintf(`[(> %s %d)]`, variable, r.After),
+		fmt.Sprintf(`[(<= %s %d)]`, variable, r.Upto),
+	}
+}
+
+// BlockSearch builds a query returning [uid string page-title page-uid eid]
+// rows, where eid is the block's entity id.
+type BlockSearch struct {
+	// Clauses constrain ?b and ?string (for example TextMatch clauses).
+	Clauses []string
+	// Filters scope the search to pages, subtrees, authors and time ranges.
+	Filters SearchFilters
+	// Range, when set, restricts the scan to a window of block entity ids.
+	Range *EIDRange
+}
+
+// Query returns the Datalog query string.
+func (s BlockSearch) Query() string {
+	var sb strings.Builder
+	sb.WriteString("[:find ?uid ?string ?page-title ?page-uid ?b\n")
+	if s.Filters.NeedsRules() {
+		sb.WriteString("\t\t:in $ %\n")
+	}
+	sb.WriteString("\t\t:where")
+
+	write := func(clause string) {
+		if strings.TrimSpace(clause) == "" {
+			return
+		}
+		sb.WriteString("\n\t\t")
+		sb.WriteString(clause)
+	}
+
+	// Bind the page first when it is known so the scan starts small.
+	if s.Filters.PageTitle != "" {
+		write(fmt.Sprintf(`[?page :node/title %s]`, QuoteString(s.Filters.PageTitle)))
+		write(`[?b :block/page ?page]`)
+	}
+	write(`[?b :block/uid ?uid]`)
+	if s.Range != nil {
+		for _, clause := range s.Range.Clauses("?b") {
+			write(clause)
+		}
+	}
+	write(`[?b :block/string ?string]`)
+	for _, clause := range s.Clauses {
+		write(clause)
+	}
+	for _, clause := range s.Filters.blockClauses() {
+		write(clause)
+	}
+	if s.Filters.PageTitle == "" {
+		write(`[?b :block/page ?page]`)
+	}
+	write(`[?page :node/title ?page-title]`)
+	write(`[?page :block/uid ?page-uid]`)
+	for _, clause := range s.Filters.pageClauses() {
+		write(clause)
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// Args returns the query inputs that accompany Query.
+func (s BlockSearch) Args() []interface{} {
+	if s.Filters.NeedsRules() {
+		return []interface{}{AncestorRules}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package roamdb
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBlockSearchQueryWithoutFilters(t *testing.T) {
+	search := BlockSearch{Clauses: []string{`[(clojure.string/includes? ?string "x")]`}}
+	query := search.Query()
+
+	if strings.Contains(query, ":in") {
+		t.Fatalf("unexpected :in clause: %s", query)
+	}
+	if search.Args() != nil {
+		t.Fatalf("expected no args, got %v", search.Args())
+	}
+	if !strings.Contains(query, `[(clojure.string/includes? ?string "x")]`) {
+		t.Fatalf("missing match clause: %s", query)
+	}
+}
+
+func TestBlockSearchUnderUsesRules(t *testing.T) {
+	search := BlockSearch{Filters: SearchFilters{Under: "abc123"}}
+	query := search.Query()
+
+	if !strings.Contains(query, ":in $ %") {
+		t.Fatalf("expected rules input: %s", query)
+	}
+	if !strings.Contains(query, `[?ancestor :block/uid "abc123"]`) || !strings.Contains(query, "(ancestor ?b ?ancestor)") {
+		t.Fatalf("missing ancestor clauses: %s", query)
+	}
+	args := search.Args()
+	if len(args) != 1 || args[0] != AncestorRules {
+		t.Fatalf("expected ancestor rules arg, got %v", args)
+	}
+}
+
+func TestBlockSearchPageAndNamespaceFilters(t *testing.T) {
+	search := BlockSearch{Filters: SearchFilters{
+		PageTitle:    `Q3 "Plan"`,
+		Namespace:    "Project/",
+		ExcludePages: []string{"Archive", ""},
+	}}
+	query := search.Query()
+
+	if !strings.Contains(query, `[?page :node/title "Q3 \"Plan\""]`) {
+		t.Fatalf("missing page binding: %s", query)
+	}
+	if strings.Index(query, `[?page :node/title "Q3`) > strings.Index(query, "[?b :block/uid ?uid]") {
+		t.Fatalf("expected page to be bound first: %s", query)
+	}
+	if !strings.Contains(query, `[(clojure.string/starts-with? ?page-title "Project/")]`) {
+		t.Fatalf("missing namespace clause: %s", query)
+	}
+	if !strings.Contains(query, `[(not= ?page-title "Archive")]`) {
+		t.Fatalf("missing exclusion clause: %s", query)
+	}
+	if strings.Count(query, "not=") != 1 {
+		t.Fatalf("expected empty exclusions to be skipped: %s", query)
+	}
+}
+
+func TestBlockSearchTimeAndAuthorFilters(t *testing.T) {
+	search := BlockSearch{Filters: SearchFilters{
+		EditedSince:   100,
+		CreatedBefore: 200,
+		Author:        "Ada L.",
+	}}
+	query := search.Query()
+
+	for _, want := range []string{
+		"[?b :edit/time ?edit-time]",
+		"[(>= ?edit-time 100)]",
+		"[?b :create/time ?create-time]",
+		"[(< ?create-time 200)]",
+		"[?b :create/user ?user]",
+		"[?user :user/display-name ?author]",
+		`(re-pattern "(?i)^Ada L\\.$")`,
+	} {
+		if !strings.Contains(query, want) {
+			t.Fatalf("missing %q in query: %s", want, query)
+		}
+	}
+	if strings.Contains(query, "(< ?edit-time") || strings.Contains(query, "(>= ?create-time") {
+		t.Fatalf("unexpected unset bound: %s", query)
+	}
+}
+
+func TestBlockSearchRange(t *testing.T) {
+	search := BlockSearch{Range: &EIDRange{After: 100, Upto: 200}}
+	query := search.Query()
+
+	if !strings.HasPrefix(query, "[:find ?uid ?string ?page-title ?page-uid ?b\n") {
+		t.Fatalf("expected eid column: %s", query)
+	}
+	uid := strings.Index(query, "[?b :block/uid ?uid]")
+	lower := strings.Index(query, "[(> ?b 100)]")
+	upper := strings.Index(query, "[(<= ?b 200)]")
+	str := strings.Index(query, "[?b :block/string ?string]")
+	if uid < 0 || lower < uid || upper < lower || str < upper {
+		t.Fatalf("expected range right after the uid clause: %s", query)
+	}
+}
+
+func TestQueryListPagesRange(t *testing.T) {
+	now := time.Date(2026, 3, 4, 15, 0, 0, 0, time.UTC)
+	query := QueryListPagesRange(PageFilter{ModifiedToday: true}, now, EIDRange{After: 0, Upto: 50})
+
+	for _, want := range []string{
+		"[:find ?title ?uid ?edit-time ?p",
+		"[(> ?p 0)]",
+		"[(<= ?p 50)]",
+		"[(get-else $ ?p :edit/time 0) ?edit-time]",
+		"[(> ?edit-time 1772582400000)]",
+	} {
+		if !strings.Contains(query, want) {
+			t.Fatalf("missing %q in %s", want, query)
+		}
+	}
+	if strings.Contains(QueryListPagesRange(PageFilter{}, now, EIDRange{Upto: 50}), "(> ?edit-time") {
+		t.Fatal("unexpected edit-time bound without modifiedToday")
+	}
+}
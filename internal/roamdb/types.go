@@ -3,100 +3,150 @@ package roamdb
 import (
 	"encoding/json"
 	"sort"
+	"strings"
 )
 
-// Page represents a Roam page as returned by pull.
-type Page struct {
-	Title    string  `json:"node/title"`
-	UID      string  `json:"block/uid"`
-	EditTime int64   `json:"edit/time,omitempty"`
-	Children []Block `json:"block/children,omitempty"`
+// EntityRef is a reference to another entity in a pull result. Depending on
+// the pull pattern it carries only the entity id or some of its attributes.
+type EntityRef struct {
+	ID          int64  `json:"db/id,omitempty"`
+	UID         string `json:"block/uid,omitempty"`
+	Title       string `json:"node/title,omitempty"`
+	UserUID     string `json:"user/uid,omitempty"`
+	DisplayName string `json:"user/display-name,omitempty"`
 }
 
-// UnmarshalJSON handles both standard API keys (node/title) and Local API keys (:node/title).
-func (p *Page) UnmarshalJSON(data []byte) error {
-	// Try with colon-prefixed keys first (Local API format)
-	type colonPage struct {
-		Title    string  `json:":node/title"`
-		UID      string  `json:":block/uid"`
-		EditTime int64   `json:":edit/time,omitempty"`
-		Children []Block `json:":block/children,omitempty"`
-	}
-
-	var cp colonPage
-	if err := json.Unmarshal(data, &cp); err == nil && (cp.Title != "" || cp.UID != "") {
-		p.Title = cp.Title
-		p.UID = cp.UID
-		p.EditTime = cp.EditTime
-		p.Children = cp.Children
+// UnmarshalJSON accepts both API key shapes, and a bare entity id.
+func (r *EntityRef) UnmarshalJSON(data []byte) error {
+	var id int64
+	if err := json.Unmarshal(data, &id); err == nil {
+		*r = EntityRef{ID: id}
 		return nil
 	}
-
-	// Fall back to standard API format (no colon prefix)
-	type standardPage struct {
-		Title    string  `json:"node/title"`
-		UID      string  `json:"block/uid"`
-		EditTime int64   `json:"edit/time,omitempty"`
-		Children []Block `json:"block/children,omitempty"`
+	type plain EntityRef
+	var v plain
+	if err := unmarshalNormalized(data, &v); err != nil {
+		return err
 	}
+	*r = EntityRef(v)
+	return nil
+}
+
+// Page represents a Roam page as returned by pull.
+type Page struct {
+	ID         int64                  `json:"db/id,omitempty"`
+	Title      string                 `json:"node/title"`
+	UID        string                 `json:"block/uid"`
+	CreateTime int64                  `json:"create/time,omitempty"`
+	EditTime   int64                  `json:"edit/time,omitempty"`
+	CreateUser *EntityRef             `json:"create/user,omitempty"`
+	EditUser   *EntityRef             `json:"edit/user,omitempty"`
+	ViewType   string                 `json:"children/view-type,omitempty"`
+	Props      map[string]interface{} `json:"block/props,omitempty"`
+	Refs       []EntityRef            `json:"block/refs,omitempty"`
+	Children   []Block                `json:"block/children,omitempty"`
+}
 
-	var sp standardPage
-	if err := json.Unmarshal(data, &sp); err != nil {
+// UnmarshalJSON handles both standard API keys (node/title) and Local API keys (:node/title).
+func (p *Page) UnmarshalJSON(data []byte) error {
+	type plain Page
+	var v plain
+	if err := unmarshalNormalized(data, &v); err != nil {
 		return err
 	}
-
-	p.Title = sp.Title
-	p.UID = sp.UID
-	p.EditTime = sp.EditTime
-	p.Children = sp.Children
+	*p = Page(v)
+	p.ViewType = strings.TrimPrefix(p.ViewType, ":")
+	p.Props = normalizeProps(p.Props)
 	return nil
 }
 
 // Block represents a Roam block as returned by pull.
 type Block struct {
-	String   string  `json:"block/string"`
-	UID      string  `json:"block/uid"`
-	Order    int     `json:"block/order,omitempty"`
-	Children []Block `json:"block/children,omitempty"`
+	ID       int64  `json:"db/id,omitempty"`
+	String   string `json:"block/string"`
+	UID      string `json:"block/uid"`
+	Order    int    `json:"block/order,omitempty"`
+	Heading  int    `json:"block/heading,omitempty"`
+	Open     *bool  `json:"block/open,omitempty"`
+	ViewType string `json:"children/view-type,omitempty"`
+	// BlockViewType is how the block itself is shown (:block/view-type).
+	BlockViewType string                 `json:"block/view-type,omitempty"`
+	TextAlign     string                 `json:"block/text-align,omitempty"`
+	Props         map[string]interface{} `json:"block/props,omitempty"`
+	Refs          []EntityRef            `json:"block/refs,omitempty"`
+	Page          *EntityRef             `json:"block/page,omitempty"`
+	Parents       []EntityRef            `json:"block/parents,omitempty"`
+	CreateTime    int64                  `json:"create/time,omitempty"`
+	EditTime      int64                  `json:"edit/time,omitempty"`
+	CreateUser    *EntityRef             `json:"create/user,omitempty"`
+	EditUser      *EntityRef             `json:"edit/user,omitempty"`
+	Children      []Block                `json:"block/children,omitempty"`
 }
 
 // UnmarshalJSON handles both standard API keys (block/string) and Local API keys (:block/string).
 func (b *Block) UnmarshalJSON(data []byte) error {
-	// Try with colon-prefixed keys first (Local API format)
-	type colonBlock struct {
-		String   string  `json:":block/string"`
-		UID      string  `json:":block/uid"`
-		Order    int     `json:":block/order,omitempty"`
-		Children []Block `json:":block/children,omitempty"`
+	type plain Block
+	var v plain
+	if err := unmarshalNormalized(data, &v); err != nil {
+		return err
 	}
+	*b = Block(v)
+	b.ViewType = strings.TrimPrefix(b.ViewType, ":")
+	b.BlockViewType = strings.TrimPrefix(b.BlockViewType, ":")
+	b.TextAlign = strings.TrimPrefix(b.TextAlign, ":")
+	b.Props = normalizeProps(b.Props)
+	return nil
+}
 
-	var cb colonBlock
-	if err := json.Unmarshal(data, &cb); err == nil && (cb.String != "" || cb.UID != "") {
-		b.String = cb.String
-		b.UID = cb.UID
-		b.Order = cb.Order
-		b.Children = cb.Children
-		return nil
+// unmarshalNormalized decodes a JSON object into v after dropping the
+// leading colon the Local API puts on attribute keys (":block/uid" becomes
+// "block/uid"), so one set of struct tags reads both API shapes. Nested
+// values are left to their own decoders.
+func unmarshalNormalized(data []byte, v interface{}) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
 	}
-
-	// Fall back to standard API format (no colon prefix)
-	type standardBlock struct {
-		String   string  `json:"block/string"`
-		UID      string  `json:"block/uid"`
-		Order    int     `json:"block/order,omitempty"`
-		Children []Block `json:"block/children,omitempty"`
+	normalized := make(map[string]json.RawMessage, len(fields))
+	for key, value := range fields {
+		name := strings.TrimPrefix(key, ":")
+		// If both shapes are present, the colon-prefixed key wins.
+		if _, seen := normalized[name]; seen && !strings.HasPrefix(key, ":") {
+			continue
+		}
+		normalized[name] = value
 	}
-
-	var sb standardBlock
-	if err := json.Unmarshal(data, &sb); err != nil {
+	raw, err := json.Marshal(normalized)
+	if err != nil {
 		return err
 	}
+	return json.Unmarshal(raw, v)
+}
 
-	b.String = sb.String
-	b.UID = sb.UID
-	b.Order = sb.Order
-	b.Children = sb.Children
-	return nil
+// normalizeProps strips colons from keyword keys throughout a props map.
+func normalizeProps(props map[string]interface{}) map[string]interface{} {
+	if props == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(props))
+	for key, value := range props {
+		out[strings.TrimPrefix(key, ":")] = normalizePropValue(value)
+	}
+	return out
+}
+
+func normalizePropValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return normalizeProps(v)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = normalizePropValue(item)
+		}
+		return out
+	}
+	return value
 }
 
 // NormalizeBlocks sorts blocks by order and recurses into children.
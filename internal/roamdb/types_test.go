@@ -152,3 +152,109 @@ func TestBlock_UnmarshalJSON_EmptyBlock(t *testing.T) {
 		t.Errorf("expected empty String, got %q", block.String)
 	}
 }
+
+func TestBlock_UnmarshalJSON_AllAttributes(t *testing.T) {
+	shapes := map[string]string{
+		"local": `{
+			":db/id": 42,
+			":block/string": "Heading",
+			":block/uid": "blk",
+			":block/order": 1,
+			":block/heading": 2,
+			":block/open": false,
+			":children/view-type": ":numbered",
+			":block/props": {":ah-level": {":level": 3}, ":tags": [{":x": 1}]},
+			":block/refs": [{":db/id": 7}, {":node/title": "Topic", ":block/uid": "topic"}],
+			":block/page": {":db/id": 5},
+			":block/parents": [{":db/id": 5}],
+			":create/time": 100,
+			":edit/time": 200,
+			":create/user": {":user/uid": "u1", ":user/display-name": "Ada"},
+			":edit/user": {":db/id": 9}
+		}`,
+		"cloud": `{
+			"db/id": 42,
+			"block/string": "Heading",
+			"block/uid": "blk",
+			"block/order": 1,
+			"block/heading": 2,
+			"block/open": false,
+			"children/view-type": "numbered",
+			"block/props": {"ah-level": {"level": 3}, "tags": [{"x": 1}]},
+			"block/refs": [{"db/id": 7}, {"node/title": "Topic", "block/uid": "topic"}],
+			"block/page": {"db/id": 5},
+			"block/parents": [{"db/id": 5}],
+			"create/time": 100,
+			"edit/time": 200,
+			"create/user": {"user/uid": "u1", "user/display-name": "Ada"},
+			"edit/user": {"db/id": 9}
+		}`,
+	}
+	for name, data := range shapes {
+		var block Block
+		if err := json.Unmarshal([]byte(data), &block); err != nil {
+			t.Fatalf("%s: unmarshal failed: %v", name, err)
+		}
+		if block.ID != 42 || block.String != "Heading" || block.Order != 1 || block.Heading != 2 {
+			t.Errorf("%s: unexpected basics %+v", name, block)
+		}
+		if block.Open == nil || *block.Open {
+			t.Errorf("%s: expected open=false, got %v", name, block.Open)
+		}
+		if block.ViewType != "numbered" {
+			t.Errorf("%s: expected numbered view type, got %q", name, block.ViewType)
+		}
+		level, _ := block.Props["ah-level"].(map[string]interface{})
+		if level["level"] != float64(3) {
+			t.Errorf("%s: props keys not normalized: %#v", name, block.Props)
+		}
+		if tags, _ := block.Props["tags"].([]interface{}); len(tags) != 1 || tags[0].(map[string]interface{})["x"] != float64(1) {
+			t.Errorf("%s: nested props not normalized: %#v", name, block.Props)
+		}
+		if len(block.Refs) != 2 || block.Refs[0].ID != 7 || block.Refs[1].Title != "Topic" || block.Refs[1].UID != "topic" {
+			t.Errorf("%s: unexpected refs %+v", name, block.Refs)
+		}
+		if block.Page == nil || block.Page.ID != 5 || len(block.Parents) != 1 {
+			t.Errorf("%s: unexpected page/parents %+v %+v", name, block.Page, block.Parents)
+		}
+		if block.CreateTime != 100 || block.EditTime != 200 {
+			t.Errorf("%s: unexpected times %d %d", name, block.CreateTime, block.EditTime)
+		}
+		if block.CreateUser == nil || block.CreateUser.DisplayName != "Ada" || block.EditUser == nil || block.EditUser.ID != 9 {
+			t.Errorf("%s: unexpected users %+v %+v", name, block.CreateUser, block.EditUser)
+		}
+	}
+}
+
+func TestPage_UnmarshalJSON_AllAttributes(t *testing.T) {
+	data := []byte(`{
+		":node/title": "P",
+		":block/uid": "p",
+		":create/time": 10,
+		":edit/time": 20,
+		":create/user": {":db/id": 3},
+		":children/view-type": ":document",
+		":block/children": [{":block/uid": "c", ":block/heading": 1}]
+	}`)
+
+	var page Page
+	if err := json.Unmarshal(data, &page); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if page.CreateTime != 10 || page.EditTime != 20 || page.CreateUser == nil || page.CreateUser.ID != 3 {
+		t.Errorf("unexpected metadata %+v", page)
+	}
+	if page.ViewType != "document" || len(page.Children) != 1 || page.Children[0].Heading != 1 {
+		t.Errorf("unexpected view type or children %+v", page)
+	}
+}
+
+func TestEntityRef_UnmarshalJSON_BareID(t *testing.T) {
+	var refs []EntityRef
+	if err := json.Unmarshal([]byte(`[12, {":db/id": 13}]`), &refs); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if len(refs) != 2 || refs[0].ID != 12 || refs[1].ID != 13 {
+		t.Fatalf("unexpected refs %+v", refs)
+	}
+}
@@ -0,0 +1,127 @@
+// Package searchql parses the boolean search syntax accepted by
+// 'roam search --boolean' and compiles it to Datalog :where clauses.
+package searchql
+
+import (
+	"strings"
+)
+
+// Node operators.
+const (
+	OpAnd    = "and"
+	OpOr     = "or"
+	OpNot    = "not"
+	OpTerm   = "term"
+	OpPhrase = "phrase"
+	OpField  = "field"
+)
+
+// Fields understood in field:value terms.
+var Fields = []string{"tag", "status", "ref", "page", "after", "before"}
+
+func isField(name string) bool {
+	for _, f := range Fields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Node is a query AST node. It marshals to and from JSON as-is, so tools can
+// build or inspect queries without parsing the text syntax.
+type Node struct {
+	Op string `json:"op"`
+	// Field is set for field nodes (tag, status, ...).
+	Field string `json:"field,omitempty"`
+	// Value is the term, phrase or field value.
+	Value string `json:"value,omitempty"`
+	// Args are the operands of and, or and not.
+	Args []*Node `json:"args,omitempty"`
+	// Pos is the 1-based column the node starts at in the query text.
+	Pos int `json:"pos,omitempty"`
+}
+
+// String renders the node in query syntax. Parsing the result yields an
+// equivalent tree.
+func (n *Node) String() string {
+	switch n.Op {
+	case OpAnd:
+		parts := make([]string, len(n.Args))
+		for i, arg := range n.Args {
+			parts[i] = arg.String()
+			if arg.Op == OpOr {
+				parts[i] = "(" + parts[i] + ")"
+			}
+		}
+		return strings.Join(parts, " ")
+	case OpOr:
+		parts := make([]string, len(n.Args))
+		for i, arg := range n.Args {
+			parts[i] = arg.String()
+		}
+		return strings.Join(parts, " OR ")
+	case OpNot:
+		if len(n.Args) == 0 {
+			return "-()"
+		}
+		inner := n.Args[0].String()
+		if n.Args[0].Op == OpAnd || n.Args[0].Op == OpOr {
+			inner = "(" + inner + ")"
+		}
+		return "-" + inner
+	case OpPhrase:
+		return quote(n.Value)
+	case OpField:
+		return n.Field + ":" + quoteIfNeeded(n.Value)
+	default:
+		value := quoteIfNeeded(n.Value)
+		if value == n.Value && (isKeyword(value) || fieldPrefix(value) != "") {
+			value = quote(value)
+		}
+		return value
+	}
+}
+
+// Equal reports whether two trees are the same, ignoring positions.
+func (n *Node) Equal(other *Node) bool {
+	if n == nil || other == nil {
+		return n == other
+	}
+	if n.Op != other.Op || n.Field != other.Field || n.Value != other.Value || len(n.Args) != len(other.Args) {
+		return false
+	}
+	for i := range n.Args {
+		if !n.Args[i].Equal(other.Args[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func quoteIfNeeded(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"()\\") || strings.HasPrefix(s, "-") {
+		return quote(s)
+	}
+	return s
+}
+
+func isKeyword(s string) bool {
+	return s == "AND" || s == "OR" || s == "NOT"
+}
+
+// fieldPrefix returns the field name if s looks like field:value.
+func fieldPrefix(s string) string {
+	i := strings.IndexByte(s, ':')
+	if i <= 0 {
+		return ""
+	}
+	if name := strings.ToLower(s[:i]); isField(name) {
+		return name
+	}
+	return ""
+}
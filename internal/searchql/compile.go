@@ -0,0 +1,201 @@
+package searchql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// Options control how leaf terms are compiled.
+type Options struct {
+	// CaseSensitive, Word and Regex apply to bare terms and phrases, as the
+	// --case-sensitive, --word and --regex flags do for plain searches.
+	CaseSensitive bool
+	Word          bool
+	Regex         bool
+	// ParseTime converts after:/before: values to epoch milliseconds.
+	ParseTime func(string) (int64, error)
+}
+
+// CompileError reports a semantic error (such as a bad date) in a node.
+type CompileError struct {
+	Column int
+	Msg    string
+}
+
+func (e *CompileError) Error() string {
+	if e.Column > 0 {
+		return fmt.Sprintf("column %d: %s", e.Column, e.Msg)
+	}
+	return e.Msg
+}
+
+// Compile translates the tree into Datalog :where clauses constraining ?b,
+// suitable for roamdb.BlockSearch.Clauses. Every leaf introduces its own
+// variables, and OR/NOT use or-join/not-join on ?b, so the whole query runs
+// as a single Datalog query.
+func Compile(node *Node, opts Options) ([]string, error) {
+	if err := node.Validate(); err != nil {
+		return nil, err
+	}
+	c := &compiler{opts: opts}
+	return c.compile(node)
+}
+
+type compiler struct {
+	opts Options
+	n    int
+}
+
+func (c *compiler) fresh(name string) string {
+	c.n++
+	return fmt.Sprintf("?%s-%d", name, c.n)
+}
+
+func (c *compiler) compile(node *Node) ([]string, error) {
+	switch node.Op {
+	case OpAnd:
+		var clauses []string
+		for _, arg := range node.Args {
+			sub, err := c.compile(arg)
+			if err != nil {
+				return nil, err
+			}
+			clauses = append(clauses, sub...)
+		}
+		return clauses, nil
+
+	case OpOr:
+		branches := make([]string, 0, len(node.Args))
+		for _, arg := range node.Args {
+			sub, err := c.compile(arg)
+			if err != nil {
+				return nil, err
+			}
+			branches = append(branches, "(and "+strings.Join(sub, "\n\t\t\t")+")")
+		}
+		return []string{"(or-join [?b]\n\t\t\t" + strings.Join(branches, "\n\t\t\t") + ")"}, nil
+
+	case OpNot:
+		sub, err := c.compile(node.Args[0])
+		if err != nil {
+			return nil, err
+		}
+		return []string{"(not-join [?b]\n\t\t\t" + strings.Join(sub, "\n\t\t\t") + ")"}, nil
+
+	case OpTerm, OpPhrase:
+		match := roamdb.TextMatch{
+			Term:          node.Value,
+			CaseSensitive: c.opts.CaseSensitive,
+			Word:          c.opts.Word,
+			Regex:         c.opts.Regex,
+		}
+		if _, err := match.Compile(); err != nil {
+			return nil, &CompileError{Column: node.Pos, Msg: err.Error()}
+		}
+		return c.textClauses(match), nil
+
+	case OpField:
+		return c.compileField(node)
+	}
+	return nil, fmt.Errorf("unknown op %q", node.Op)
+}
+
+func (c *compiler) textClauses(match roamdb.TextMatch) []string {
+	str := c.fresh("s")
+	return []string{
+		fmt.Sprintf("[?b :block/string %s]", str),
+		match.Clauses(str, c.fresh("re")),
+	}
+}
+
+func (c *compiler) compileField(node *Node) ([]string, error) {
+	value := node.Value
+	switch node.Field {
+	case "tag":
+		tag := strings.TrimPrefix(value, "#")
+		tag = strings.TrimSuffix(strings.TrimPrefix(tag, "[["), "]]")
+		title := roamdb.TextMatch{Term: "^" + regexp.QuoteMeta(tag) + "$", Regex: true}
+		scope := roamdb.RefScope(strings.TrimPrefix(c.fresh(""), "?"))
+		return scope.PageRefClauses(title, true), nil
+
+	case "status":
+		status := strings.ToUpper(value)
+		if status != "TODO" && status != "DONE" {
+			return nil, &CompileError{Column: node.Pos, Msg: fmt.Sprintf("invalid status %q (must be TODO or DONE)", value)}
+		}
+		marker := roamdb.TextMatch{Term: fmt.Sprintf("{{[[%s]]}}", status), CaseSensitive: true}
+		return c.textClauses(marker), nil
+
+	case "ref":
+		uid := strings.TrimSuffix(strings.TrimPrefix(value, "(("), "))")
+		scope := roamdb.RefScope(strings.TrimPrefix(c.fresh(""), "?"))
+		return scope.BlockRefClauses(roamdb.TextMatch{Term: uid, CaseSensitive: true}), nil
+
+	case "page":
+		page := c.fresh("page")
+		if !strings.Contains(value, "*") {
+			return []string{
+				fmt.Sprintf("[%s :node/title %s]", page, roamdb.QuoteString(value)),
+				fmt.Sprintf("[?b :block/page %s]", page),
+			}, nil
+		}
+		parts := strings.Split(value, "*")
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+		title := c.fresh("page-title")
+		match := roamdb.TextMatch{Term: "^" + strings.Join(parts, ".*") + "$", Regex: true}
+		return []string{
+			fmt.Sprintf("[?b :block/page %s]", page),
+			fmt.Sprintf("[%s :node/title %s]", page, title),
+			match.Clauses(title, c.fresh("re")),
+		}, nil
+
+	case "after", "before":
+		if c.opts.ParseTime == nil {
+			return nil, &CompileError{Column: node.Pos, Msg: node.Field + ": is not supported here"}
+		}
+		ms, err := c.opts.ParseTime(value)
+		if err != nil {
+			return nil, &CompileError{Column: node.Pos, Msg: fmt.Sprintf("invalid %s: value: %v", node.Field, err)}
+		}
+		edit := c.fresh("edit-time")
+		op := ">="
+		if node.Field == "before" {
+			op = "<"
+		}
+		return []string{
+			fmt.Sprintf("[?b :edit/time %s]", edit),
+			fmt.Sprintf("[(%s %s %d)]", op, edit, ms),
+		}, nil
+	}
+	return nil, &CompileError{Column: node.Pos, Msg: fmt.Sprintf("unknown field %q", node.Field)}
+}
+
+// Highlights returns the positive text terms and phrases in the tree (those
+// not under a NOT), for highlighting matches in results.
+func Highlights(node *Node) []string {
+	var terms []string
+	var walk func(n *Node, negated bool)
+	walk = func(n *Node, negated bool) {
+		switch n.Op {
+		case OpNot:
+			for _, arg := range n.Args {
+				walk(arg, !negated)
+			}
+		case OpAnd, OpOr:
+			for _, arg := range n.Args {
+				walk(arg, negated)
+			}
+		case OpTerm, OpPhrase:
+			if !negated {
+				terms = append(terms, n.Value)
+			}
+		}
+	}
+	walk(node, false)
+	return terms
+}
@@ -0,0 +1,94 @@
+package searchql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+func compileQuery(t *testing.T, input string) string {
+	t.Helper()
+	node, err := Parse(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	clauses, err := Compile(node, Options{ParseTime: func(s string) (int64, error) {
+		if s == "bad" {
+			return 0, fmt.Errorf("not a date")
+		}
+		return 1000, nil
+	}})
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	return roamdb.BlockSearch{Clauses: clauses}.Query()
+}
+
+func TestCompileExample(t *testing.T) {
+	query := compileQuery(t, `"exact phrase" tag:meeting -status:DONE page:"Weekly*" after:2026-01-01 (foo OR bar)`)
+
+	for _, want := range []string{
+		`(re-pattern "(?i)exact phrase")`,
+		`(re-pattern "(?i)^meeting$")`,
+		"[?b :block/refs ?ref-target-",
+		"(not-join [?b]",
+		`(clojure.string/includes? ?s-`,
+		`"{{[[DONE]]}}"`,
+		`(re-pattern "(?i)^Weekly.*$")`,
+		"[(>= ?edit-time-",
+		"(or-join [?b]",
+		`(re-pattern "(?i)foo")`,
+		`(re-pattern "(?i)bar")`,
+	} {
+		if !strings.Contains(query, want) {
+			t.Fatalf("missing %q in query:\n%s", want, query)
+		}
+	}
+	if strings.Count(query, "[:find") != 1 {
+		t.Fatalf("expected a single query:\n%s", query)
+	}
+}
+
+func TestCompileUsesFreshVariables(t *testing.T) {
+	query := compileQuery(t, "tag:a tag:b")
+	if !strings.Contains(query, "?ref-page-1") || !strings.Contains(query, "?ref-page-2") {
+		t.Fatalf("expected distinct ref variables:\n%s", query)
+	}
+}
+
+func TestCompileExactPage(t *testing.T) {
+	query := compileQuery(t, `page:"Weekly Review" before:2026-02-01`)
+	if !strings.Contains(query, `[?page-1 :node/title "Weekly Review"]`) || !strings.Contains(query, "[(< ?edit-time-2 1000)]") {
+		t.Fatalf("unexpected query:\n%s", query)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for input, column := range map[string]int{
+		"foo status:LATER": 5,
+		"after:bad":        1,
+	} {
+		node, err := Parse(input)
+		if err != nil {
+			t.Fatalf("parse %q failed: %v", input, err)
+		}
+		_, err = Compile(node, Options{ParseTime: func(string) (int64, error) { return 0, fmt.Errorf("nope") }})
+		var cerr *CompileError
+		if !errors.As(err, &cerr) || cerr.Column != column {
+			t.Fatalf("%q: expected compile error at column %d, got %v", input, column, err)
+		}
+	}
+}
+
+func TestHighlights(t *testing.T) {
+	node, err := Parse(`foo -bar "a b" (baz OR -qux) tag:x`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if got := strings.Join(Highlights(node), ","); got != "foo,a b,baz" {
+		t.Fatalf("unexpected highlights %q", got)
+	}
+}
@@ -0,0 +1,412 @@
+package searchql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Syntax documents the query grammar for --help-syntax.
+const Syntax = `Boolean search syntax
+
+  word              block text contains word (case-insensitive)
+  "exact phrase"    block text contains the phrase
+  a b               both (AND is implied; "a AND b" also works)
+  a OR b            either
+  -a, NOT a         not
+  ( ... )           grouping; AND binds tighter than OR
+
+Fields (values may be quoted):
+  tag:name          references the page (#name, [[name]], #[[name]], name::,
+                    or one of its aliases); matched ignoring case
+  status:TODO       {{[[TODO]]}} or status:DONE
+  ref:uid           references the block ((uid))
+  page:"Title"      on the page with this title; * is a wildcard
+  after:DATE        edited at or after DATE (2026-01-01, or an age: 7d, 12h)
+  before:DATE       edited before DATE
+
+Examples:
+  "exact phrase" tag:meeting -status:DONE
+  page:"Weekly*" after:2026-01-01 (foo OR bar)
+  tag:project NOT (status:DONE OR tag:archive)
+
+A query may also be given as its JSON syntax tree (see --explain), e.g.
+  {"op":"and","args":[{"op":"term","value":"foo"},{"op":"field","field":"tag","value":"x"}]}`
+
+// ParseError reports a syntax error and where it occurred.
+type ParseError struct {
+	Input string
+	// Column is 1-based and counts runes.
+	Column int
+	Msg    string
+}
+
+func (e *ParseError) Error() string {
+	caret := strings.Repeat(" ", max(e.Column-1, 0)) + "^"
+	return fmt.Sprintf("syntax error at column %d: %s\n  %s\n  %s", e.Column, e.Msg, e.Input, caret)
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokQuoted
+	tokField
+	tokLParen
+	tokRParen
+	tokMinus
+	tokAnd
+	tokOr
+	tokNot
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	field string
+	pos   int // 1-based rune column
+}
+
+type lexer struct {
+	input string
+	off   int
+}
+
+func (l *lexer) column(off int) int {
+	return utf8.RuneCountInString(l.input[:off]) + 1
+}
+
+func (l *lexer) errorf(off int, format string, args ...interface{}) error {
+	return &ParseError{Input: l.input, Column: l.column(off), Msg: fmt.Sprintf(format, args...)}
+}
+
+func (l *lexer) tokens() ([]token, error) {
+	var toks []token
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		toks = append(toks, tok)
+		if tok.kind == tokEOF {
+			return toks, nil
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	for l.off < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.off:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		l.off += size
+	}
+	start := l.off
+	pos := l.column(start)
+	if l.off >= len(l.input) {
+		return token{kind: tokEOF, pos: pos}, nil
+	}
+
+	switch l.input[l.off] {
+	case '(':
+		l.off++
+		return token{kind: tokLParen, pos: pos}, nil
+	case ')':
+		l.off++
+		return token{kind: tokRParen, pos: pos}, nil
+	case '"':
+		text, err := l.quoted()
+		if err != nil {
+			return token{}, err
+		}
+		return token{kind: tokQuoted, text: text, pos: pos}, nil
+	case '-':
+		l.off++
+		if l.off >= len(l.input) || strings.ContainsRune(" \t\n)", rune(l.input[l.off])) {
+			return token{}, l.errorf(start, "expected a term after '-'")
+		}
+		return token{kind: tokMinus, pos: pos}, nil
+	}
+
+	for l.off < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.off:])
+		if unicode.IsSpace(r) || r == '(' || r == ')' || r == '"' {
+			break
+		}
+		l.off += size
+	}
+	word := l.input[start:l.off]
+
+	switch word {
+	case "AND":
+		return token{kind: tokAnd, pos: pos}, nil
+	case "OR":
+		return token{kind: tokOr, pos: pos}, nil
+	case "NOT":
+		return token{kind: tokNot, pos: pos}, nil
+	}
+
+	if field := fieldPrefix(word); field != "" {
+		value := word[len(field)+1:]
+		if value == "" && l.off < len(l.input) && l.input[l.off] == '"' {
+			quoted, err := l.quoted()
+			if err != nil {
+				return token{}, err
+			}
+			value = quoted
+		} else if value == "" {
+			return token{}, l.errorf(start, "missing value for %s:", field)
+		}
+		return token{kind: tokField, field: field, text: value, pos: pos}, nil
+	}
+
+	return token{kind: tokWord, text: word, pos: pos}, nil
+}
+
+// quoted reads a double-quoted string starting at l.off. Backslash escapes
+// the next character.
+func (l *lexer) quoted() (string, error) {
+	start := l.off
+	l.off++
+	var sb strings.Builder
+	for l.off < len(l.input) {
+		c := l.input[l.off]
+		switch {
+		case c == '\\' && l.off+1 < len(l.input):
+			sb.WriteByte(l.input[l.off+1])
+			l.off += 2
+		case c == '"':
+			l.off++
+			return sb.String(), nil
+		default:
+			sb.WriteByte(c)
+			l.off++
+		}
+	}
+	return "", l.errorf(start, "unterminated quoted string")
+}
+
+type parser struct {
+	lex  *lexer
+	toks []token
+	i    int
+}
+
+// Parse parses a query in the boolean syntax, or a JSON syntax tree when the
+// input starts with '{'.
+func Parse(input string) (*Node, error) {
+	if strings.HasPrefix(strings.TrimSpace(input), "{") {
+		return ParseJSON([]byte(input))
+	}
+
+	lex := &lexer{input: input}
+	toks, err := lex.tokens()
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{lex: lex, toks: toks}
+	if p.peek().kind == tokEOF {
+		return nil, p.errorf(p.peek(), "empty query")
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		if tok.kind == tokRParen {
+			return nil, p.errorf(tok, "unexpected ')'")
+		}
+		return nil, p.errorf(tok, "unexpected input")
+	}
+	return node, nil
+}
+
+// ParseJSON decodes and validates a JSON syntax tree.
+func ParseJSON(data []byte) (*Node, error) {
+	var node Node
+	if err := json.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("invalid query JSON: %w", err)
+	}
+	if err := node.Validate(); err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+// Validate checks that a tree (typically decoded from JSON) is well formed.
+func (n *Node) Validate() error {
+	switch n.Op {
+	case OpAnd, OpOr:
+		if len(n.Args) == 0 {
+			return fmt.Errorf("%s node needs at least one argument", n.Op)
+		}
+	case OpNot:
+		if len(n.Args) != 1 {
+			return fmt.Errorf("not node needs exactly one argument")
+		}
+	case OpTerm, OpPhrase:
+		if n.Value == "" {
+			return fmt.Errorf("%s node needs a value", n.Op)
+		}
+		return nil
+	case OpField:
+		if !isField(n.Field) {
+			return fmt.Errorf("unknown field %q (want one of %s)", n.Field, strings.Join(Fields, ", "))
+		}
+		if n.Value == "" {
+			return fmt.Errorf("missing value for %s:", n.Field)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown op %q", n.Op)
+	}
+	for _, arg := range n.Args {
+		if arg == nil {
+			return fmt.Errorf("%s node has a null argument", n.Op)
+		}
+		if err := arg.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.i]
+}
+
+func (p *parser) advance() token {
+	tok := p.toks[p.i]
+	if tok.kind != tokEOF {
+		p.i++
+	}
+	return tok
+}
+
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	return &ParseError{Input: p.lex.input, Column: tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parseOr() (*Node, error) {
+	first := p.peek()
+	if first.kind == tokOr || first.kind == tokAnd {
+		return nil, p.errorf(first, "expected a term before %s", map[tokenKind]string{tokOr: "OR", tokAnd: "AND"}[first.kind])
+	}
+
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	args := []*Node{left}
+	for p.peek().kind == tokOr {
+		orTok := p.advance()
+		if k := p.peek().kind; k == tokEOF || k == tokRParen || k == tokOr {
+			return nil, p.errorf(orTok, "expected a term after OR")
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, right)
+	}
+	return combine(OpOr, args, first.pos), nil
+}
+
+func (p *parser) parseAnd() (*Node, error) {
+	start := p.peek()
+	var args []*Node
+	for {
+		tok := p.peek()
+		switch tok.kind {
+		case tokEOF, tokRParen, tokOr:
+			if len(args) == 0 {
+				return nil, p.errorf(tok, "expected a term")
+			}
+			return combine(OpAnd, args, start.pos), nil
+		case tokAnd:
+			andTok := p.advance()
+			if len(args) == 0 {
+				return nil, p.errorf(andTok, "expected a term before AND")
+			}
+			if k := p.peek().kind; k == tokEOF || k == tokRParen || k == tokOr || k == tokAnd {
+				return nil, p.errorf(andTok, "expected a term after AND")
+			}
+			continue
+		}
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, node)
+	}
+}
+
+func (p *parser) parseUnary() (*Node, error) {
+	tok := p.peek()
+	if tok.kind == tokMinus || tok.kind == tokNot {
+		p.advance()
+		if k := p.peek().kind; k == tokEOF || k == tokRParen || k == tokOr || k == tokAnd {
+			return nil, p.errorf(tok, "expected a term after negation")
+		}
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Node{Op: OpNot, Args: []*Node{operand}, Pos: tok.pos}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (*Node, error) {
+	tok := p.advance()
+	switch tok.kind {
+	case tokLParen:
+		if p.peek().kind == tokRParen {
+			return nil, p.errorf(p.peek(), "empty group")
+		}
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, p.errorf(p.peek(), "expected ')' to close '(' at column %d", tok.pos)
+		}
+		p.advance()
+		return node, nil
+	case tokWord:
+		return &Node{Op: OpTerm, Value: tok.text, Pos: tok.pos}, nil
+	case tokQuoted:
+		if tok.text == "" {
+			return nil, p.errorf(tok, "empty phrase")
+		}
+		return &Node{Op: OpPhrase, Value: tok.text, Pos: tok.pos}, nil
+	case tokField:
+		return &Node{Op: OpField, Field: tok.field, Value: tok.text, Pos: tok.pos}, nil
+	case tokRParen:
+		return nil, p.errorf(tok, "unexpected ')'")
+	default:
+		return nil, p.errorf(tok, "expected a term")
+	}
+}
+
+// combine returns the single arg or an op node, flattening nested nodes of
+// the same op.
+func combine(op string, args []*Node, pos int) *Node {
+	if len(args) == 1 {
+		return args[0]
+	}
+	node := &Node{Op: op, Pos: pos}
+	for _, arg := range args {
+		if arg.Op == op {
+			node.Args = append(node.Args, arg.Args...)
+			continue
+		}
+		node.Args = append(node.Args, arg)
+	}
+	return node
+}
@@ -0,0 +1,138 @@
+package searchql
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseExample(t *testing.T) {
+	node, err := Parse(`"exact phrase" tag:meeting -status:DONE page:"Weekly*" after:2026-01-01 (foo OR bar)`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if node.Op != OpAnd || len(node.Args) != 6 {
+		t.Fatalf("expected and of 6, got %s", mustJSON(t, node))
+	}
+	want := []string{"phrase", "field", "not", "field", "field", "or"}
+	for i, op := range want {
+		if node.Args[i].Op != op {
+			t.Fatalf("arg %d: expected %s, got %s", i, op, node.Args[i].Op)
+		}
+	}
+	if node.Args[3].Field != "page" || node.Args[3].Value != "Weekly*" {
+		t.Fatalf("unexpected page field: %+v", node.Args[3])
+	}
+	if node.Args[2].Args[0].Field != "status" || node.Args[2].Pos != 28 {
+		t.Fatalf("unexpected negation: %+v", node.Args[2])
+	}
+}
+
+func TestParsePrecedence(t *testing.T) {
+	node, err := Parse("a b OR c AND NOT d")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if node.String() != "a b OR c -d" {
+		t.Fatalf("unexpected tree: %s", node.String())
+	}
+	if node.Op != OpOr || node.Args[0].Op != OpAnd || node.Args[1].Op != OpAnd {
+		t.Fatalf("expected AND to bind tighter: %s", mustJSON(t, node))
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	queries := []string{
+		`"exact phrase" tag:meeting -status:DONE page:"Weekly*" after:2026-01-01 (foo OR bar)`,
+		`a OR (b c) OR -(d OR e)`,
+		`tag:"Project X" ref:abc123 "say \"hi\""`,
+		`NOT x`,
+	}
+	for _, q := range queries {
+		node, err := Parse(q)
+		if err != nil {
+			t.Fatalf("parse %q failed: %v", q, err)
+		}
+
+		again, err := Parse(node.String())
+		if err != nil {
+			t.Fatalf("reparse %q (from %q) failed: %v", node.String(), q, err)
+		}
+		if !node.Equal(again) {
+			t.Fatalf("string round trip changed %q: %q", q, node.String())
+		}
+
+		data, err := json.Marshal(node)
+		if err != nil {
+			t.Fatalf("marshal failed: %v", err)
+		}
+		decoded, err := Parse(string(data))
+		if err != nil {
+			t.Fatalf("JSON parse failed: %v", err)
+		}
+		if !node.Equal(decoded) {
+			t.Fatalf("JSON round trip changed %q: %s", q, data)
+		}
+	}
+}
+
+func TestParseErrorsPointAtColumn(t *testing.T) {
+	tests := []struct {
+		input  string
+		column int
+		msg    string
+	}{
+		{`foo (bar`, 9, "expected ')'"},
+		{`foo bar)`, 8, "unexpected ')'"},
+		{`"open`, 1, "unterminated"},
+		{`OR foo`, 1, "before OR"},
+		{`foo OR`, 5, "after OR"},
+		{`foo tag:`, 5, "missing value"},
+		{`foo - bar`, 5, "after '-'"},
+		{`()`, 2, "empty group"},
+		{`   `, 4, "empty query"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Fatalf("%q: expected ParseError, got %v", tt.input, err)
+		}
+		if perr.Column != tt.column || !strings.Contains(perr.Msg, tt.msg) {
+			t.Fatalf("%q: got column %d %q, want column %d containing %q", tt.input, perr.Column, perr.Msg, tt.column, tt.msg)
+		}
+		lines := strings.Split(perr.Error(), "\n")
+		if len(lines) != 3 || strings.Index(lines[2], "^") != tt.column+1 {
+			t.Fatalf("%q: caret misplaced:\n%s", tt.input, perr.Error())
+		}
+	}
+}
+
+func TestParseJSONValidates(t *testing.T) {
+	if _, err := Parse(`{"op":"field","field":"colour","value":"red"}`); err == nil {
+		t.Fatal("expected unknown field error")
+	}
+	if _, err := Parse(`{"op":"not","args":[]}`); err == nil {
+		t.Fatal("expected not arity error")
+	}
+}
+
+func TestUnknownFieldIsTerm(t *testing.T) {
+	node, err := Parse("https://example.com")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if node.Op != OpTerm || node.Value != "https://example.com" {
+		t.Fatalf("expected plain term, got %+v", node)
+	}
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,135 @@
+// Package templates instantiates block trees used as templates. A copy gets
+// fresh uids, has its placeholders filled in, and has block refs between
+// its own blocks pointed at the copies, so a template's internal links keep
+// working in every instance.
+//
+// Placeholders are {{date}}, {{title}} and {{var:name}}. Other {{...}}
+// components, such as {{[[TODO]]}} or {{embed: ...}}, are left alone.
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/salmonumbrella/roam-cli/internal/markup"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// Values are what placeholders expand to.
+type Values struct {
+	// Date fills {{date}}, usually a daily note title.
+	Date string
+	// Title fills {{title}}, the title of the page the copy goes to.
+	Title string
+	// Vars fills {{var:name}}.
+	Vars map[string]string
+}
+
+var placeholder = regexp.MustCompile(`\{\{\s*(date|title|var:\s*([^{}]*?))\s*\}\}`)
+
+// Expand fills in the placeholders in s. It returns the names of the
+// placeholders it had no value for, which are left as they are.
+func (v Values) Expand(s string) (string, []string) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	var missing []string
+	out := placeholder.ReplaceAllStringFunc(s, func(m string) string {
+		sub := placeholder.FindStringSubmatch(m)
+		var value string
+		var ok bool
+		switch sub[1] {
+		case "date":
+			value, ok = v.Date, v.Date != ""
+		case "title":
+			value, ok = v.Title, v.Title != ""
+		default:
+			name := strings.TrimSpace(sub[2])
+			value, ok = v.Vars[name]
+			sub[1] = "var:" + name
+		}
+		if !ok {
+			missing = append(missing, sub[1])
+			return m
+		}
+		return value
+	})
+	return out, missing
+}
+
+// Instantiate returns a copy of blocks ready to be created: every block has
+// a fresh uid, its placeholders filled in and ((refs)) to other blocks in
+// the tree remapped to their copies. Headings, props and view types are
+// kept; orders follow the blocks' positions. It fails, naming them, when
+// placeholders have no value.
+func Instantiate(blocks []roamdb.Block, v Values) ([]roamdb.Block, error) {
+	remap := map[string]string{}
+	var assign func([]roamdb.Block)
+	assign = func(blocks []roamdb.Block) {
+		for _, b := range blocks {
+			if b.UID != "" {
+				remap[b.UID] = roamdb.NewUID()
+			}
+			assign(b.Children)
+		}
+	}
+	assign(blocks)
+
+	missing := map[string]bool{}
+	var copyBlocks func([]roamdb.Block) []roamdb.Block
+	copyBlocks = func(blocks []roamdb.Block) []roamdb.Block {
+		if len(blocks) == 0 {
+			return nil
+		}
+		out := make([]roamdb.Block, len(blocks))
+		for i, b := range blocks {
+			uid := remap[b.UID]
+			if uid == "" {
+				uid = roamdb.NewUID()
+			}
+			text, unset := v.Expand(markup.RemapBlockRefs(b.String, remap))
+			for _, name := range unset {
+				missing[name] = true
+			}
+			out[i] = roamdb.Block{
+				UID:           uid,
+				String:        text,
+				Order:         i,
+				Heading:       b.Heading,
+				Open:          b.Open,
+				ViewType:      b.ViewType,
+				BlockViewType: b.BlockViewType,
+				TextAlign:     b.TextAlign,
+				Props:         b.Props,
+				Children:      copyBlocks(b.Children),
+			}
+		}
+		return out
+	}
+	out := copyBlocks(blocks)
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, "{{"+name+"}}")
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("no value for %s (set variables with --var name=value)", strings.Join(names, ", "))
+	}
+	return out, nil
+}
+
+// ParseVars reads name=value pairs, as given to --var.
+func ParseVars(pairs []string) (map[string]string, error) {
+	vars := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --var %q (expected name=value)", pair)
+		}
+		vars[name] = value
+	}
+	return vars, nil
+}
@@ -0,0 +1,66 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+func TestExpand(t *testing.T) {
+	v := Values{Date: "October 16th, 2026", Title: "1:1 Alice", Vars: map[string]string{"person": "Alice"}}
+	got, missing := v.Expand("[[{{date}}]] {{title}} with {{ var:person }} {{[[TODO]]}} {{var:room}}")
+	if got != "[[October 16th, 2026]] 1:1 Alice with Alice {{[[TODO]]}} {{var:room}}" {
+		t.Fatalf("unexpected expansion: %q", got)
+	}
+	if len(missing) != 1 || missing[0] != "var:room" {
+		t.Fatalf("unexpected missing: %v", missing)
+	}
+}
+
+func TestInstantiate(t *testing.T) {
+	open := false
+	tmpl := []roamdb.Block{
+		{UID: "h1", String: "Agenda for {{var:person}}", Heading: 2, ViewType: "numbered", Children: []roamdb.Block{
+			{UID: "c1", String: "see ((n1)) and ((outside))", Open: &open, Props: map[string]interface{}{"k": "v"}},
+		}},
+		{UID: "n1", String: "Notes", BlockViewType: "document", TextAlign: "center"},
+	}
+	out, err := Instantiate(tmpl, Values{Vars: map[string]string{"person": "Alice"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 2 || len(out[0].Children) != 1 {
+		t.Fatalf("unexpected tree: %+v", out)
+	}
+	head, child, notes := out[0], out[0].Children[0], out[1]
+	if head.UID == "h1" || child.UID == "c1" || notes.UID == "n1" || head.UID == "" {
+		t.Fatalf("uids not replaced: %+v", out)
+	}
+	if head.String != "Agenda for Alice" || head.Heading != 2 || head.ViewType != "numbered" {
+		t.Fatalf("unexpected heading block: %+v", head)
+	}
+	if child.String != "see (("+notes.UID+")) and ((outside))" || child.Open == nil || *child.Open || child.Props["k"] != "v" {
+		t.Fatalf("unexpected child: %+v", child)
+	}
+	if notes.Order != 1 || notes.BlockViewType != "document" || notes.TextAlign != "center" {
+		t.Fatalf("unexpected notes block: %+v", notes)
+	}
+	if tmpl[0].String != "Agenda for {{var:person}}" {
+		t.Fatal("template was modified")
+	}
+
+	if _, err := Instantiate(tmpl, Values{}); err == nil || !strings.Contains(err.Error(), "{{var:person}}") {
+		t.Fatalf("expected missing variable error, got %v", err)
+	}
+}
+
+func TestParseVars(t *testing.T) {
+	vars, err := ParseVars([]string{"person=Alice", "topic=a=b"})
+	if err != nil || vars["person"] != "Alice" || vars["topic"] != "a=b" {
+		t.Fatalf("unexpected vars: %v %v", vars, err)
+	}
+	if _, err := ParseVars([]string{"nope"}); err == nil {
+		t.Fatal("expected an error")
+	}
+}
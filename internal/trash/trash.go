@@ -0,0 +1,159 @@
+// Package trash keeps deleted pages and blocks on disk so they can be
+// recreated later with their original uids.
+package trash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FormatVersion is the version of the entry format written by Save.
+const FormatVersion = 1
+
+// Entry kinds.
+const (
+	KindPage  = "page"
+	KindBlock = "block"
+)
+
+// RefsUnknown is an Entry's Refs when the references could not be counted.
+const RefsUnknown = -1
+
+// ErrNotFound is returned by Load when no entry has the given id.
+var ErrNotFound = errors.New("trash entry not found")
+
+// Entry is a deleted page or block with its full pulled subtree.
+type Entry struct {
+	Version int    `json:"version"`
+	ID      string `json:"id"`
+	Graph   string `json:"graph,omitempty"`
+	Kind    string `json:"kind"`
+	UID     string `json:"uid"`
+	// Title is the page title, or the text of a deleted block.
+	Title string `json:"title"`
+	// ParentUID and Order place a deleted block back where it was.
+	ParentUID string `json:"parent_uid,omitempty"`
+	Order     int    `json:"order,omitempty"`
+	// ViewType and Props are a deleted page's own children view type and
+	// props, which restore sets on the page again.
+	ViewType string                 `json:"view_type,omitempty"`
+	Props    map[string]interface{} `json:"props,omitempty"`
+	// Blocks counts the deleted blocks, including a deleted block itself.
+	Blocks int `json:"blocks"`
+	// Refs counts the blocks elsewhere that referenced the deleted content,
+	// or is RefsUnknown when they could not be counted.
+	Refs      int       `json:"refs"`
+	DeletedAt time.Time `json:"deleted_at"`
+	// Data is the page or block as pulled, with its children.
+	Data json.RawMessage `json:"data"`
+}
+
+// Dir returns the trash directory for graph under base.
+func Dir(base, graph string) string {
+	name := filepath.Base(strings.TrimSpace(graph))
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		name = "default"
+	}
+	return filepath.Join(base, "trash", name)
+}
+
+// NewID returns an entry id that sorts by deletion time, e.g.
+// "20261018-153012-abc123".
+func NewID(deletedAt time.Time, uid string) string {
+	return deletedAt.UTC().Format("20060102-150405") + "-" + uid
+}
+
+// Save writes e to dir, creating it if needed.
+func Save(dir string, e *Entry) error {
+	if e.ID == "" {
+		return fmt.Errorf("trash entry has no id")
+	}
+	e.Version = FormatVersion
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding trash entry: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("creating trash directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".entry-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating trash entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing trash entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing trash entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), entryPath(dir, e.ID)); err != nil {
+		return fmt.Errorf("saving trash entry: %w", err)
+	}
+	return nil
+}
+
+// Load reads the entry with the given id from dir.
+func Load(dir, id string) (*Entry, error) {
+	data, err := os.ReadFile(entryPath(dir, id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		return nil, fmt.Errorf("reading trash entry: %w", err)
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("reading trash entry %s: %w", id, err)
+	}
+	return &e, nil
+}
+
+// List returns the entries in dir, most recently deleted first. A missing
+// directory holds no entries.
+func List(dir string) ([]Entry, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading trash directory: %w", err)
+	}
+	var entries []Entry
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		e, err := Load(dir, strings.TrimSuffix(f.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].DeletedAt.After(entries[j].DeletedAt)
+	})
+	return entries, nil
+}
+
+// Remove deletes the entry with the given id from dir.
+func Remove(dir, id string) error {
+	if err := os.Remove(entryPath(dir, id)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		return fmt.Errorf("removing trash entry: %w", err)
+	}
+	return nil
+}
+
+func entryPath(dir, id string) string {
+	return filepath.Join(dir, filepath.Base(id)+".json")
+}
@@ -0,0 +1,57 @@
+package trash
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveListLoadRemove(t *testing.T) {
+	dir := Dir(t.TempDir(), "my-graph")
+	if filepath.Base(dir) != "my-graph" {
+		t.Fatalf("unexpected dir %s", dir)
+	}
+
+	entries, err := List(dir)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected an empty trash, got %v, %v", entries, err)
+	}
+
+	older := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	for _, e := range []*Entry{
+		{ID: NewID(older, "a1"), Kind: KindBlock, UID: "a1", ParentUID: "p", Order: 2, DeletedAt: older, Data: json.RawMessage(`{"block/uid":"a1"}`)},
+		{ID: NewID(newer, "pg"), Kind: KindPage, UID: "pg", Title: "Old Page", DeletedAt: newer, Data: json.RawMessage(`{"node/title":"Old Page"}`)},
+	} {
+		if err := Save(dir, e); err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+	}
+
+	entries, err = List(dir)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].UID != "pg" || entries[1].ID != "20261017-090000-a1" || entries[1].Version != FormatVersion {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+
+	e, err := Load(dir, "20261017-090000-a1")
+	if err != nil || e.ParentUID != "p" || e.Order != 2 {
+		t.Fatalf("unexpected entry %+v, %v", e, err)
+	}
+	var data bytes.Buffer
+	if err := json.Compact(&data, e.Data); err != nil || data.String() != `{"block/uid":"a1"}` {
+		t.Fatalf("unexpected data %s", e.Data)
+	}
+
+	if err := Remove(dir, e.ID); err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+	if _, err := Load(dir, e.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,332 @@
+// Package treediff edits block trees as text. Format writes a tree as an
+// outline with hidden uid markers, Parse reads the edited outline back, and
+// Diff computes the operations that turn the old tree into the new one.
+package treediff
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// Node is a block in a tree being edited.
+type Node struct {
+	UID      string
+	String   string
+	Heading  int
+	Children []*Node
+}
+
+// FromBlocks converts blocks, with their children, to nodes.
+func FromBlocks(blocks []roamdb.Block) []*Node {
+	nodes := make([]*Node, len(blocks))
+	for i, b := range blocks {
+		nodes[i] = &Node{UID: b.UID, String: b.String, Heading: b.Heading, Children: FromBlocks(b.Children)}
+	}
+	return nodes
+}
+
+// Op kinds.
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpMove   = "move"
+	OpDelete = "delete"
+)
+
+// Op is one write. Creates and moves place the block at Order among its
+// new parent's children as they are when the op runs, so ops must run in
+// the order Diff returns them.
+type Op struct {
+	Kind    string `json:"op"`
+	UID     string `json:"uid"`
+	Parent  string `json:"parent,omitempty"`
+	Order   int    `json:"order"`
+	String  string `json:"string,omitempty"`
+	Heading int    `json:"heading,omitempty"`
+	// Old is the block's previous string, for updates and deletes.
+	Old string `json:"old,omitempty"`
+}
+
+// similarity is the minimum content similarity for an edited block without
+// a uid marker to be matched with a block that lost its marker.
+const similarity = 0.6
+
+// maxSimilarityPairs bounds the candidate pairs compared by content.
+const maxSimilarityPairs = 250000
+
+// oldNode is a block of the tree before editing.
+type oldNode struct {
+	node    *Node
+	parent  string
+	matched *Node
+}
+
+// Diff returns the ops that turn the children of root from old into edited.
+// Edited nodes are matched to old blocks by uid, then unmatched ones by
+// content similarity; matched nodes are given the old uid. New nodes are
+// given fresh uids so that their children can be created in the same
+// batch. Blocks keep their place when they can: only the blocks outside
+// the longest run of siblings still in their old relative order are moved.
+func Diff(root string, old, edited []*Node) []Op {
+	olds := map[string]*oldNode{}
+	var order []string
+	var index func(parent string, nodes []*Node)
+	index = func(parent string, nodes []*Node) {
+		for _, n := range nodes {
+			olds[n.UID] = &oldNode{node: n, parent: parent}
+			order = append(order, n.UID)
+			index(n.UID, n.Children)
+		}
+	}
+	index(root, old)
+
+	// Match by uid; a uid used twice only matches the first time.
+	var unmatched []*Node
+	walk(edited, func(n *Node) {
+		if o, ok := olds[n.UID]; ok && o.matched == nil {
+			o.matched = n
+			return
+		}
+		n.UID = ""
+		unmatched = append(unmatched, n)
+	})
+	matchBySimilarity(olds, order, edited, root, unmatched)
+	walk(edited, func(n *Node) {
+		if n.UID == "" {
+			n.UID = roamdb.NewUID()
+		}
+	})
+
+	d := &differ{olds: olds, children: map[string][]string{}, parents: map[string]string{}}
+	d.children[root] = uids(old)
+	walk(old, func(n *Node) {
+		d.children[n.UID] = uids(n.Children)
+		for _, c := range n.Children {
+			d.parents[c.UID] = n.UID
+		}
+	})
+	for _, n := range old {
+		d.parents[n.UID] = root
+	}
+	d.place(root, edited)
+
+	// Delete the topmost unmatched blocks; anything kept under them has
+	// been moved out by now.
+	for _, uid := range order {
+		o := olds[uid]
+		if o.matched == nil && (o.parent == root || olds[o.parent].matched != nil) {
+			d.ops = append(d.ops, Op{Kind: OpDelete, UID: uid, Old: o.node.String})
+		}
+	}
+	return d.ops
+}
+
+// matchBySimilarity pairs edited nodes without a uid with old blocks that
+// were not matched, most similar first. Blocks under the same parent as
+// before are preferred.
+func matchBySimilarity(olds map[string]*oldNode, order []string, edited []*Node, root string, unmatched []*Node) {
+	var free []*oldNode
+	for _, uid := range order {
+		if olds[uid].matched == nil {
+			free = append(free, olds[uid])
+		}
+	}
+	if len(free) == 0 || len(unmatched) == 0 {
+		return
+	}
+	parents := map[*Node]string{}
+	var record func(parent string, nodes []*Node)
+	record = func(parent string, nodes []*Node) {
+		for _, n := range nodes {
+			parents[n] = parent
+			record(n.UID, n.Children)
+		}
+	}
+	record(root, edited)
+
+	type pair struct {
+		n     *Node
+		o     *oldNode
+		score float64
+	}
+	exactOnly := len(free)*len(unmatched) > maxSimilarityPairs
+	var pairs []pair
+	for _, n := range unmatched {
+		for _, o := range free {
+			var score float64
+			switch {
+			case n.String == o.node.String:
+				score = 1
+			case exactOnly:
+				continue
+			default:
+				score = dice(n.String, o.node.String)
+			}
+			if score < similarity {
+				continue
+			}
+			if p := parents[n]; p != "" && p == o.parent {
+				score += 0.5
+			}
+			pairs = append(pairs, pair{n, o, score})
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].score > pairs[j].score })
+	for _, p := range pairs {
+		if p.n.UID != "" || p.o.matched != nil {
+			continue
+		}
+		p.n.UID = p.o.node.UID
+		p.o.matched = p.n
+	}
+}
+
+// differ emits ops while tracking each parent's children as the ops
+// change them.
+type differ struct {
+	olds     map[string]*oldNode
+	children map[string][]string
+	parents  map[string]string
+	ops      []Op
+}
+
+// place emits the ops that put nodes, in order, under parent, then does the
+// same for their children.
+func (d *differ) place(parent string, nodes []*Node) {
+	keep := d.inPlace(parent, nodes)
+	for i, n := range nodes {
+		prev := ""
+		if i > 0 {
+			prev = nodes[i-1].UID
+		}
+		o, existed := d.olds[n.UID]
+		switch {
+		case !existed:
+			order := d.insert(parent, prev, n.UID)
+			d.ops = append(d.ops, Op{Kind: OpCreate, UID: n.UID, Parent: parent, Order: order, String: n.String, Heading: n.Heading})
+		case !keep[n.UID]:
+			d.remove(n.UID)
+			order := d.insert(parent, prev, n.UID)
+			d.ops = append(d.ops, Op{Kind: OpMove, UID: n.UID, Parent: parent, Order: order})
+		}
+		if existed && (n.String != strings.TrimRight(o.node.String, "\n") || n.Heading != o.node.Heading) {
+			d.ops = append(d.ops, Op{Kind: OpUpdate, UID: n.UID, String: n.String, Heading: n.Heading, Old: o.node.String})
+		}
+	}
+	for _, n := range nodes {
+		d.place(n.UID, n.Children)
+	}
+}
+
+// inPlace returns the nodes that can stay where they are: the longest run
+// of nodes that were already under parent, in their old order.
+func (d *differ) inPlace(parent string, nodes []*Node) map[string]bool {
+	pos := map[string]int{}
+	for i, uid := range d.children[parent] {
+		pos[uid] = i
+	}
+	var seq []string
+	for _, n := range nodes {
+		if _, ok := pos[n.UID]; ok && d.parents[n.UID] == parent {
+			seq = append(seq, n.UID)
+		}
+	}
+	// Longest increasing subsequence of old positions.
+	tails := []int{}
+	prev := make([]int, len(seq))
+	for i, uid := range seq {
+		j := sort.Search(len(tails), func(k int) bool { return pos[seq[tails[k]]] >= pos[uid] })
+		if j > 0 {
+			prev[i] = tails[j-1]
+		} else {
+			prev[i] = -1
+		}
+		if j == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[j] = i
+		}
+	}
+	keep := map[string]bool{}
+	if len(tails) > 0 {
+		for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+			keep[seq[i]] = true
+		}
+	}
+	return keep
+}
+
+// insert adds uid to parent's children right after prev (first when prev
+// is empty) and returns its position.
+func (d *differ) insert(parent, prev, uid string) int {
+	list := d.children[parent]
+	at := 0
+	if prev != "" {
+		for i, c := range list {
+			if c == prev {
+				at = i + 1
+				break
+			}
+		}
+	}
+	list = append(list, "")
+	copy(list[at+1:], list[at:])
+	list[at] = uid
+	d.children[parent] = list
+	d.parents[uid] = parent
+	return at
+}
+
+func (d *differ) remove(uid string) {
+	parent := d.parents[uid]
+	list := d.children[parent]
+	for i, c := range list {
+		if c == uid {
+			d.children[parent] = append(list[:i:i], list[i+1:]...)
+			break
+		}
+	}
+	delete(d.parents, uid)
+}
+
+func walk(nodes []*Node, fn func(*Node)) {
+	for _, n := range nodes {
+		fn(n)
+		walk(n.Children, fn)
+	}
+}
+
+func uids(nodes []*Node) []string {
+	list := make([]string, len(nodes))
+	for i, n := range nodes {
+		list[i] = n.UID
+	}
+	return list
+}
+
+// dice returns the Sørensen–Dice coefficient of the character bigrams of
+// two strings.
+func dice(a, b string) float64 {
+	ra, rb := []rune(strings.ToLower(a)), []rune(strings.ToLower(b))
+	if len(ra) < 2 || len(rb) < 2 {
+		if strings.EqualFold(a, b) {
+			return 1
+		}
+		return 0
+	}
+	grams := map[[2]rune]int{}
+	for i := 0; i+1 < len(ra); i++ {
+		grams[[2]rune{ra[i], ra[i+1]}]++
+	}
+	shared := 0
+	for i := 0; i+1 < len(rb); i++ {
+		g := [2]rune{rb[i], rb[i+1]}
+		if grams[g] > 0 {
+			grams[g]--
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(ra)+len(rb)-2)
+}
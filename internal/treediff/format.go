@@ -0,0 +1,135 @@
+package treediff
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/salmonumbrella/roam-cli/internal/mdblocks"
+)
+
+var (
+	itemLine = regexp.MustCompile(`^([ \t]*)[-*](?:[ \t](.*))?$`)
+	uidMark  = regexp.MustCompile(` ?<!-- uid:([A-Za-z0-9_-]+) -->[ \t]*$`)
+	heading  = regexp.MustCompile(`^(#{1,3}) `)
+)
+
+// Format renders a tree as an outline for editing: one "- " item per block,
+// indented two spaces per level, with the block's uid in a trailing
+// <!-- uid:... --> marker. Further lines of a multi-line block follow at
+// the item's content column. Headings are written as "#" prefixes. A
+// backslash escapes lines that would otherwise read as an item or a
+// heading.
+func Format(nodes []*Node) string {
+	var sb strings.Builder
+	format(&sb, nodes, 0)
+	return sb.String()
+}
+
+func format(sb *strings.Builder, nodes []*Node, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, n := range nodes {
+		lines := strings.Split(n.String, "\n")
+		first := lines[0]
+		if n.Heading > 0 {
+			first = strings.Repeat("#", n.Heading) + " " + first
+		} else if heading.MatchString(first) || strings.HasPrefix(first, `\`) {
+			first = `\` + first
+		}
+		sb.WriteString(indent + "- " + first)
+		if n.UID != "" {
+			sb.WriteString(" <!-- uid:" + n.UID + " -->")
+		}
+		sb.WriteByte('\n')
+		for _, line := range lines[1:] {
+			if line == "" {
+				sb.WriteByte('\n')
+				continue
+			}
+			if itemLine.MatchString(line) || strings.HasPrefix(strings.TrimLeft(line, " \t"), `\`) {
+				line = `\` + line
+			}
+			sb.WriteString(indent + "  " + line + "\n")
+		}
+		format(sb, n.Children, depth+1)
+	}
+}
+
+// Parse reads an outline written by Format, after editing. Items nest under
+// the nearest less-indented item above them, and other lines continue the
+// item above. A leading <!-- ... --> comment is skipped. Items keep the uid
+// in their marker; new items have none.
+func Parse(text string) ([]*Node, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if trimmed := strings.TrimLeft(text, " \t\n"); strings.HasPrefix(trimmed, "<!--") {
+		end := strings.Index(trimmed, "-->")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated comment at the top")
+		}
+		text = trimmed[end+3:]
+	}
+
+	var roots []*Node
+	type open struct {
+		node   *Node
+		indent int
+	}
+	var stack []open
+	var cur *Node
+	var curCol int
+	var lines []string
+	finish := func() {
+		if cur != nil {
+			cur.String = strings.TrimRight(strings.Join(lines, "\n"), "\n")
+		}
+	}
+
+	for i, line := range strings.Split(text, "\n") {
+		if m := itemLine.FindStringSubmatch(line); m != nil {
+			finish()
+			indent := mdblocks.Width(m[1], mdblocks.DefaultIndent)
+			n := &Node{}
+			content := m[2]
+			if mark := uidMark.FindStringSubmatchIndex(content); mark != nil {
+				n.UID = content[mark[2]:mark[3]]
+				content = content[:mark[0]]
+			}
+			if h := heading.FindStringSubmatch(content); h != nil {
+				n.Heading = len(h[1])
+				content = content[len(h[0]):]
+			} else {
+				content = strings.TrimPrefix(content, `\`)
+			}
+			for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+				stack = stack[:len(stack)-1]
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1].node
+				parent.Children = append(parent.Children, n)
+			} else {
+				roots = append(roots, n)
+			}
+			stack = append(stack, open{n, indent})
+			cur, curCol, lines = n, indent+2, []string{content}
+			continue
+		}
+		if cur == nil {
+			if strings.TrimSpace(line) != "" {
+				return nil, fmt.Errorf("line %d: expected a \"- \" item", i+1)
+			}
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		line = mdblocks.StripIndent(line, curCol, mdblocks.DefaultIndent)
+		if strings.HasPrefix(strings.TrimLeft(line, " \t"), `\`) {
+			k := strings.IndexByte(line, '\\')
+			line = line[:k] + line[k+1:]
+		}
+		lines = append(lines, line)
+	}
+	finish()
+	return roots, nil
+}
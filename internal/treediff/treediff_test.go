@@ -0,0 +1,156 @@
+package treediff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func sample() []*Node {
+	return []*Node{
+		{UID: "a", String: "Alpha", Heading: 2, Children: []*Node{
+			{UID: "a1", String: "first child"},
+			{UID: "a2", String: "code:\n```\n- not an item\n\\ backslash\n```"},
+		}},
+		{UID: "b", String: "# not a heading"},
+		{UID: "c", String: "", Children: []*Node{{UID: "c1", String: "trailing space "}}},
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	text := Format(sample())
+	parsed, err := Parse("<!-- header\n-->\n" + text)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, sample()) {
+		t.Fatalf("round trip changed the tree:\n%s", text)
+	}
+	if ops := Diff("page", sample(), parsed); len(ops) != 0 {
+		t.Fatalf("unchanged tree produced ops: %+v", ops)
+	}
+}
+
+func TestParseNewItemsAndContinuations(t *testing.T) {
+	nodes, err := Parse("- one <!-- uid:x -->\n\tnext line\n\t- ## nested\n* star\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 2 || nodes[0].UID != "x" || nodes[0].String != "one\nnext line" {
+		t.Fatalf("unexpected nodes: %+v", nodes)
+	}
+	if c := nodes[0].Children; len(c) != 1 || c[0].Heading != 2 || c[0].String != "nested" || c[0].UID != "" {
+		t.Fatalf("unexpected child: %+v", c)
+	}
+	if _, err := Parse("stray text\n- item"); err == nil {
+		t.Fatal("expected an error for text before the first item")
+	}
+}
+
+// apply runs ops against a tree, the way Roam would.
+func apply(t *testing.T, root string, tree []*Node, ops []Op) []*Node {
+	t.Helper()
+	byUID := map[string]*Node{root: {UID: root, Children: tree}}
+	parent := map[string]string{}
+	var index func(p *Node)
+	index = func(p *Node) {
+		for _, c := range p.Children {
+			byUID[c.UID] = c
+			parent[c.UID] = p.UID
+			index(c)
+		}
+	}
+	index(byUID[root])
+	detach := func(uid string) {
+		p := byUID[parent[uid]]
+		for i, c := range p.Children {
+			if c.UID == uid {
+				p.Children = append(p.Children[:i:i], p.Children[i+1:]...)
+				return
+			}
+		}
+	}
+	attach := func(uid, to string, order int) {
+		p := byUID[to]
+		if p == nil || order > len(p.Children) {
+			t.Fatalf("bad location %s/%d for %s", to, order, uid)
+		}
+		p.Children = append(p.Children[:order], append([]*Node{byUID[uid]}, p.Children[order:]...)...)
+		parent[uid] = to
+	}
+	for _, op := range ops {
+		switch op.Kind {
+		case OpCreate:
+			byUID[op.UID] = &Node{UID: op.UID, String: op.String, Heading: op.Heading}
+			attach(op.UID, op.Parent, op.Order)
+		case OpMove:
+			detach(op.UID)
+			attach(op.UID, op.Parent, op.Order)
+		case OpUpdate:
+			byUID[op.UID].String, byUID[op.UID].Heading = op.String, op.Heading
+		case OpDelete:
+			detach(op.UID)
+		}
+	}
+	return byUID[root].Children
+}
+
+func TestDiffRestructure(t *testing.T) {
+	edited, err := Parse(strings.Join([]string{
+		"- first child, edited <!-- uid:a1 -->",
+		"- ## Alpha <!-- uid:a -->",
+		"  - brand new",
+		"    - deeper new",
+		"  - trailing space  <!-- uid:c1 -->",
+		"- first child <!-- uid:a1 -->",
+	}, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops := Diff("page", sample(), edited)
+
+	counts := map[string]int{}
+	for _, op := range ops {
+		counts[op.Kind]++
+	}
+	// a1 moves and changes; the duplicated a1 line is new; a2, b and c go.
+	if counts[OpCreate] != 3 || counts[OpUpdate] != 1 || counts[OpMove] != 2 || counts[OpDelete] != 3 {
+		t.Fatalf("unexpected ops: %+v", ops)
+	}
+
+	got := apply(t, "page", sample(), ops)
+	if !reflect.DeepEqual(got, edited) {
+		t.Fatalf("ops do not produce the edited tree:\ngot:\n%s\nwant:\n%s", Format(got), Format(edited))
+	}
+}
+
+func TestDiffMatchesLostMarkersByContent(t *testing.T) {
+	old := []*Node{{UID: "a", String: "Buy milk and eggs"}, {UID: "b", String: "Call the bank"}}
+	edited := []*Node{{String: "Call the bank today"}, {String: "Buy milk and eggs"}}
+	ops := Diff("page", old, edited)
+	if edited[0].UID != "b" || edited[1].UID != "a" {
+		t.Fatalf("blocks not matched by content: %+v", edited)
+	}
+	for _, op := range ops {
+		if op.Kind == OpCreate || op.Kind == OpDelete {
+			t.Fatalf("expected moves and updates only: %+v", ops)
+		}
+	}
+	if got := apply(t, "page", old, ops); !reflect.DeepEqual(got, edited) {
+		t.Fatalf("unexpected result: %s", Format(got))
+	}
+}
+
+func TestDiffMinimalMoves(t *testing.T) {
+	var old, edited []*Node
+	for _, uid := range []string{"a", "b", "c", "d", "e"} {
+		old = append(old, &Node{UID: uid, String: uid})
+	}
+	for _, uid := range []string{"b", "c", "d", "e", "a"} {
+		edited = append(edited, &Node{UID: uid, String: uid})
+	}
+	ops := Diff("page", old, edited)
+	if len(ops) != 1 || ops[0].Kind != OpMove || ops[0].UID != "a" || ops[0].Order != 4 {
+		t.Fatalf("expected a single move of a, got %+v", ops)
+	}
+}
@@ -0,0 +1,92 @@
+package upload
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CacheFile is the name of the upload cache within the config directory.
+const CacheFile = "upload-cache.json"
+
+// cacheFile is the upload cache on disk: uploaded URLs by content hash, per
+// upload target (a graph, or an upload endpoint).
+type cacheFile struct {
+	Version int                          `json:"version"`
+	Targets map[string]map[string]string `json:"targets"`
+}
+
+// Cache maps the content hashes of uploaded files to their URLs for one
+// upload target. A nil *Cache caches nothing.
+type Cache struct {
+	path   string
+	target string
+	file   cacheFile
+	dirty  bool
+}
+
+// LoadCache reads the cache at path for a target. A missing file yields an
+// empty cache.
+func LoadCache(path, target string) (*Cache, error) {
+	c := &Cache{path: path, target: target, file: cacheFile{Version: 1}}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading upload cache: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &c.file); err != nil {
+			return nil, fmt.Errorf("reading upload cache %s: %w", path, err)
+		}
+	}
+	if c.file.Targets == nil {
+		c.file.Targets = map[string]map[string]string{}
+	}
+	return c, nil
+}
+
+// Get returns the URL a file with the given hash was uploaded to.
+func (c *Cache) Get(hash string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	u, ok := c.file.Targets[c.target][hash]
+	return u, ok
+}
+
+// Put records the URL a file with the given hash was uploaded to.
+func (c *Cache) Put(hash, url string) {
+	if c == nil {
+		return
+	}
+	urls := c.file.Targets[c.target]
+	if urls == nil {
+		urls = map[string]string{}
+		c.file.Targets[c.target] = urls
+	}
+	urls[hash] = url
+	c.dirty = true
+}
+
+// Save writes the cache back if anything was added.
+func (c *Cache) Save() error {
+	if c == nil || !c.dirty {
+		return nil
+	}
+	data, err := json.MarshalIndent(c.file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding upload cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return fmt.Errorf("writing upload cache: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("writing upload cache: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("writing upload cache: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
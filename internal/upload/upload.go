@@ -0,0 +1,221 @@
+// Package upload uploads the local files that Markdown links to and
+// rewrites the links to the uploaded URLs. Uploads are cached by content
+// hash, so importing the same file again reuses its URL instead of
+// uploading a copy.
+package upload
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Uploader uploads a file and returns its URL.
+type Uploader interface {
+	UploadFile(filename string, data []byte) (string, error)
+}
+
+// mdLink matches [text](dest) and ![alt](dest), with the destination
+// optionally in <angle brackets> and followed by a "title".
+var mdLink = regexp.MustCompile(`(!?)\[([^\]]*)\]\((?:<([^>\n]+)>|([^)\s]+))(?:[ \t]+("[^"\n]*"|'[^'\n]*'))?[ \t]*\)`)
+
+// Session uploads the files linked from Markdown documents in one
+// directory.
+type Session struct {
+	uploader Uploader
+	cache    *Cache
+	dir      string
+	// files maps resolved paths to their URLs.
+	files map[string]string
+
+	// Uploaded and Reused count files uploaded and files whose URL came from
+	// the cache.
+	Uploaded int
+	Reused   int
+	// Missing lists link targets that are not files.
+	Missing []string
+}
+
+// NewSession returns a session that resolves relative links against dir.
+// A nil cache disables caching.
+func NewSession(uploader Uploader, cache *Cache, dir string) *Session {
+	return &Session{uploader: uploader, cache: cache, dir: dir, files: map[string]string{}}
+}
+
+// Rewrite uploads the local files linked from a Markdown document and
+// returns it with the links pointing at the uploaded URLs. Links in code
+// are left alone, as are links to files that don't exist, which are
+// reported in Missing.
+func (s *Session) Rewrite(markdown string) (string, error) {
+	var err error
+	out := mapMarkdown(markdown, func(text string) string {
+		return mdLink.ReplaceAllStringFunc(text, func(m string) string {
+			if err != nil {
+				return m
+			}
+			g := mdLink.FindStringSubmatch(m)
+			dest := g[3] + g[4]
+			if !isLocal(dest) {
+				return m
+			}
+			var u string
+			var ok bool
+			u, ok, err = s.upload(dest)
+			if !ok {
+				return m
+			}
+			if g[5] != "" {
+				u += " " + g[5]
+			}
+			return g[1] + "[" + g[2] + "](" + u + ")"
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// upload returns the URL for a link target, uploading the file unless it
+// was uploaded before. ok is false when the target is not a file.
+func (s *Session) upload(dest string) (string, bool, error) {
+	if unescaped, err := url.PathUnescape(dest); err == nil {
+		dest = unescaped
+	}
+	file := strings.TrimPrefix(dest, "file://")
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(s.dir, filepath.FromSlash(file))
+	}
+	return s.file(file, dest)
+}
+
+// File returns the URL for the file at path, relative to the session's
+// directory unless absolute, uploading it unless it was uploaded before.
+// ok is false when there is no such file.
+func (s *Session) File(path string) (string, bool, error) {
+	file := path
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(s.dir, file)
+	}
+	return s.file(file, path)
+}
+
+// Save writes the URLs of the files uploaded so far to the cache.
+func (s *Session) Save() error {
+	return s.cache.Save()
+}
+
+// file uploads file, named dest in errors and in Missing.
+func (s *Session) file(file, dest string) (string, bool, error) {
+	if u, ok := s.files[file]; ok {
+		return u, true, nil
+	}
+	info, err := os.Stat(file)
+	if err != nil || info.IsDir() {
+		s.Missing = append(s.Missing, dest)
+		return "", false, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s: %w", dest, err)
+	}
+
+	sum := Hash(data)
+	if u, ok := s.cache.Get(sum); ok {
+		s.files[file] = u
+		s.Reused++
+		return u, true, nil
+	}
+	u, err := s.uploader.UploadFile(filepath.Base(file), data)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to upload %s: %w", dest, err)
+	}
+	s.cache.Put(sum, u)
+	s.files[file] = u
+	s.Uploaded++
+	return u, true, nil
+}
+
+// isLocal reports whether a link target is a local path rather than a URL,
+// an anchor or a Roam ref.
+func isLocal(dest string) bool {
+	if strings.HasPrefix(dest, "file://") {
+		return true
+	}
+	if strings.Contains(dest, "://") || strings.HasPrefix(dest, "#") || strings.HasPrefix(dest, "[[") || strings.HasPrefix(dest, "((") {
+		return false
+	}
+	for _, scheme := range []string{"data:", "mailto:", "tel:"} {
+		if strings.HasPrefix(dest, scheme) {
+			return false
+		}
+	}
+	return true
+}
+
+// Hash returns the content hash files are cached under.
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// mapMarkdown applies fn to the parts of a Markdown document outside fenced
+// code blocks and inline code spans.
+func mapMarkdown(markdown string, fn func(string) string) string {
+	var sb strings.Builder
+	var fence string
+	var text []string
+	flush := func() {
+		if len(text) > 0 {
+			sb.WriteString(mapInline(strings.Join(text, ""), fn))
+			text = nil
+		}
+	}
+	for _, line := range strings.SplitAfter(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence != "":
+			sb.WriteString(line)
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			flush()
+			fence = trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, trimmed[:1]))]
+			sb.WriteString(line)
+		default:
+			text = append(text, line)
+		}
+	}
+	flush()
+	return sb.String()
+}
+
+// mapInline applies fn to the parts of s outside `code spans`.
+func mapInline(s string, fn func(string) string) string {
+	var sb strings.Builder
+	for s != "" {
+		i := strings.IndexByte(s, '`')
+		if i < 0 {
+			sb.WriteString(fn(s))
+			break
+		}
+		sb.WriteString(fn(s[:i]))
+		s = s[i:]
+		ticks := len(s) - len(strings.TrimLeft(s, "`"))
+		end := strings.Index(s[ticks:], s[:ticks])
+		if end < 0 {
+			sb.WriteString(s)
+			break
+		}
+		end += 2 * ticks
+		sb.WriteString(s[:end])
+		s = s[end:]
+	}
+	return sb.String()
+}
@@ -0,0 +1,79 @@
+package upload
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type fakeUploader struct{ uploads []string }
+
+func (f *fakeUploader) UploadFile(filename string, data []byte) (string, error) {
+	f.uploads = append(f.uploads, filename)
+	return "https://files.example/" + filename, nil
+}
+
+func TestSessionRewrite(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "img"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"img/a b.png": "A", "img/copy.png": "A", "doc.pdf": "PDF"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	up := &fakeUploader{}
+	s := NewSession(up, nil, dir)
+	markdown := "![pic](./img/a%20b.png) and [doc](<doc.pdf>) and ![again](img/copy.png \"Caption\")\n" +
+		"`![code](doc.pdf)` [web](https://x.example/a.png) ![gone](missing.png)\n" +
+		"```\n![fenced](doc.pdf)\n```\n"
+	got, err := s.Rewrite(markdown)
+	if err != nil {
+		t.Fatalf("Rewrite failed: %v", err)
+	}
+	want := "![pic](https://files.example/a b.png) and [doc](https://files.example/doc.pdf) and ![again](https://files.example/copy.png \"Caption\")\n" +
+		"`![code](doc.pdf)` [web](https://x.example/a.png) ![gone](missing.png)\n" +
+		"```\n![fenced](doc.pdf)\n```\n"
+	if got != want {
+		t.Fatalf("unexpected rewrite:\n%s", got)
+	}
+	if s.Uploaded != 3 || len(s.Missing) != 1 || s.Missing[0] != "missing.png" {
+		t.Fatalf("unexpected counts: %d uploaded, missing %v", s.Uploaded, s.Missing)
+	}
+}
+
+func TestSessionUsesCache(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.png"), []byte("A"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cachePath := filepath.Join(dir, "cache", CacheFile)
+
+	up := &fakeUploader{}
+	for i := 0; i < 2; i++ {
+		cache, err := LoadCache(cachePath, "graph")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := NewSession(up, cache, dir)
+		if _, err := s.Rewrite("![](a.png)"); err != nil {
+			t.Fatal(err)
+		}
+		if err := cache.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(up.uploads) != 1 {
+		t.Fatalf("expected one upload, got %v", up.uploads)
+	}
+
+	other, err := LoadCache(cachePath, "other-graph")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := other.Get(Hash([]byte("A"))); ok {
+		t.Fatal("cache shared between targets")
+	}
+}
@@ -0,0 +1,406 @@
+package vault
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salmonumbrella/roam-cli/internal/mdblocks"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// loader turns vault files into pages. It indexes every file's title first,
+// so links can be resolved to the titles the pages are created with.
+type loader struct {
+	v     *Vault
+	files vaultFiles
+
+	titles  map[string]string   // file -> title
+	byPath  map[string]string   // lower-case path without .md -> title
+	byBase  map[string][]string // lower-case base name without .md -> titles
+	byTitle map[string]string   // lower-case title -> title
+}
+
+func newLoader(v *Vault, files vaultFiles, contents map[string]string) *loader {
+	l := &loader{
+		v:       v,
+		files:   files,
+		titles:  map[string]string{},
+		byPath:  map[string]string{},
+		byBase:  map[string][]string{},
+		byTitle: map[string]string{},
+	}
+	for _, rel := range files.markdown {
+		title, _, daily := fileTitle(rel, v.Flavor)
+		if v.Flavor == Logseq && !daily {
+			props, _ := splitLogseqProperties(contents[rel])
+			for _, f := range props {
+				if strings.EqualFold(f.Key, "title") && f.Value != "" {
+					title = f.Value
+				}
+			}
+		}
+		l.titles[rel] = title
+		key := strings.ToLower(strings.TrimSuffix(rel, path.Ext(rel)))
+		l.byPath[key] = title
+		base := path.Base(key)
+		l.byBase[base] = append(l.byBase[base], title)
+		l.byTitle[strings.ToLower(title)] = title
+	}
+	return l
+}
+
+// page reads one file into a page.
+func (l *loader) page(rel, content string) *Page {
+	title := l.titles[rel]
+	_, date, daily := fileTitle(rel, l.v.Flavor)
+	p := &Page{File: rel, Title: title, Daily: daily}
+	if daily {
+		p.UID = roamdb.DailyNoteUID(date)
+	} else {
+		p.UID = pageUID(title)
+	}
+
+	var attrs []Field
+	var body []*mdblocks.Block
+	if l.v.Flavor == Obsidian {
+		var front string
+		front, content = splitFrontMatter(content)
+		attrs = l.frontMatter(rel, front)
+		body, p.ViewType = mdblocks.Parse(content, mdblocks.Options{BlockIDs: true})
+	} else {
+		var props []Field
+		props, content = splitLogseqProperties(content)
+		for _, f := range props {
+			if !strings.EqualFold(f.Key, "title") && !strings.HasPrefix(f.Key, "logseq.") {
+				attrs = append(attrs, f)
+			}
+		}
+		body, p.ViewType = parseLogseq(content)
+	}
+
+	for _, f := range attrs {
+		p.Blocks = append(p.Blocks, &mdblocks.Block{UID: f.UID, String: f.Key + ":: " + f.Value})
+	}
+	p.Blocks = append(p.Blocks, body...)
+
+	conv := &converter{l: l, page: p, dir: path.Dir(rel)}
+	var assign func(blocks []*mdblocks.Block, position string)
+	assign = func(blocks []*mdblocks.Block, position string) {
+		for i, b := range blocks {
+			pos := position + strconv.Itoa(i)
+			switch {
+			case b.ID != "":
+				b.UID = obsidianBlockUID(title, b.ID)
+			case b.UID == "":
+				b.UID = blockUID(title, pos)
+			}
+			b.String = conv.convert(b, b.String)
+			assign(b.Children, pos+".")
+		}
+	}
+	assign(p.Blocks, "")
+	return p
+}
+
+func obsidianBlockUID(title, id string) string {
+	return DeriveUID("obsidian\x00" + title + "\x00" + id)
+}
+
+func (l *loader) warn(rel, format string, args ...interface{}) {
+	l.v.Warnings = append(l.v.Warnings, rel+": "+fmt.Sprintf(format, args...))
+}
+
+// splitFrontMatter takes a leading --- delimited YAML block off a file.
+func splitFrontMatter(content string) (string, string) {
+	if !strings.HasPrefix(content, "---\n") {
+		return "", content
+	}
+	rest := content[4:]
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return "", content
+	}
+	after := rest[end+4:]
+	if nl := strings.IndexByte(after, '\n'); nl >= 0 {
+		if strings.TrimSpace(after[:nl]) != "" {
+			return "", content
+		}
+		after = after[nl+1:]
+	} else if strings.TrimSpace(after) != "" {
+		return "", content
+	}
+	return rest[:end], after
+}
+
+// frontMatter returns the attribute fields for a YAML front matter block.
+// Lists become comma-separated values; tags and aliases are written as page
+// refs. A uids map, as written by roam export, gives the attribute blocks
+// their uids.
+func (l *loader) frontMatter(rel, front string) []Field {
+	if strings.TrimSpace(front) == "" {
+		return nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(front), &doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		l.warn(rel, "front matter is not a YAML mapping; skipped")
+		return nil
+	}
+	m := doc.Content[0]
+	var fields []Field
+	uids := map[string]string{}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		key, value := m.Content[i].Value, m.Content[i+1]
+		if key == "uids" && value.Kind == yaml.MappingNode {
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				uids[value.Content[j].Value] = value.Content[j+1].Value
+			}
+			continue
+		}
+		refs := false
+		switch strings.ToLower(key) {
+		case "tags", "tag", "aliases", "alias":
+			refs = true
+		}
+		var parts []string
+		switch value.Kind {
+		case yaml.ScalarNode:
+			if refs {
+				for _, part := range strings.FieldsFunc(value.Value, func(r rune) bool { return r == ',' || r == ' ' }) {
+					parts = append(parts, part)
+				}
+			} else if value.Value != "" {
+				parts = []string{value.Value}
+			}
+		case yaml.SequenceNode:
+			for _, item := range value.Content {
+				if item.Kind == yaml.ScalarNode && item.Value != "" {
+					parts = append(parts, item.Value)
+				}
+			}
+		default:
+			l.warn(rel, "front matter key %q has a nested value; skipped", key)
+			continue
+		}
+		if refs {
+			for i, part := range parts {
+				parts[i] = "[[" + strings.TrimPrefix(part, "#") + "]]"
+			}
+		}
+		if len(parts) == 0 {
+			continue
+		}
+		fields = append(fields, Field{Key: key, Value: strings.Join(parts, ", ")})
+	}
+	for i := range fields {
+		fields[i].UID = uids[fields[i].Key]
+	}
+	return fields
+}
+
+// converter rewrites one page's block strings to Roam syntax.
+type converter struct {
+	l    *loader
+	page *Page
+	dir  string
+	b    *mdblocks.Block
+}
+
+var (
+	wikilink     = regexp.MustCompile(`(!?)\[\[([^\]|]*?)(#[^\]|]*)?(?:\|([^\]]*))?\]\]`)
+	mdLink       = regexp.MustCompile(`(!?)\[([^\]]*)\]\(([^)\s]+)(?:[ \t]+("[^"\n]*"|'[^'\n]*'))?[ \t]*\)`)
+	logseqMacro  = regexp.MustCompile(`\{\{(embed|video|youtube|tweet|pdf)\s+([^}:][^}]*)\}\}`)
+	logseqPage   = regexp.MustCompile(`\[\[([^\[\]]+)\]\]`)
+	logseqDate   = regexp.MustCompile(`^([A-Z][a-z]{2}) (\d{1,2})(?:st|nd|rd|th), (\d{4})$`)
+	isoDate      = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	mdBoldUnder  = regexp.MustCompile(`__([^_\s](?:[^_]*[^_\s])?)__`)
+	mdItalUnder  = regexp.MustCompile(`(^|[^_\w])_([^_\s](?:[^_]*[^_\s])?)_([^_\w]|$)`)
+	mdItalStar   = regexp.MustCompile(`(^|[^*\w])\*([^*\s](?:[^*]*[^*\s])?)\*([^*\w]|$)`)
+	mdHighlight  = regexp.MustCompile(`==([^=\s](?:[^=]*[^=\s])?)==`)
+	obsidianNote = regexp.MustCompile(`%%[\s\S]*?%%`)
+)
+
+func (c *converter) convert(b *mdblocks.Block, s string) string {
+	c.b = b
+	return mapText(s, func(text string) string {
+		// Markdown links go first: the links they produce are not Markdown
+		// links to vault files, so they are not rewritten twice.
+		text = mdLink.ReplaceAllStringFunc(text, c.mdLink)
+		if c.l.v.Flavor == Obsidian {
+			text = obsidianNote.ReplaceAllString(text, "")
+			text = wikilink.ReplaceAllStringFunc(text, c.wikilink)
+		} else {
+			text = logseqMacro.ReplaceAllString(text, "{{$1: $2}}")
+			text = logseqPage.ReplaceAllStringFunc(text, func(m string) string {
+				return "[[" + c.l.pageTitle(m[2:len(m)-2]) + "]]"
+			})
+		}
+		return convertEmphasis(text)
+	})
+}
+
+// wikilink rewrites an Obsidian [[link]] or ![[embed]].
+func (c *converter) wikilink(m string) string {
+	g := wikilink.FindStringSubmatch(m)
+	embed, target, anchor, alias := g[1] == "!", strings.TrimSpace(g[2]), g[3], g[4]
+
+	if ext := path.Ext(target); ext != "" && !strings.EqualFold(ext, ".md") {
+		if !embed && alias == "" {
+			alias = path.Base(target)
+		}
+		return c.asset(target, alias, "", m)
+	}
+
+	title := c.page.Title
+	if target != "" {
+		title = c.l.resolve(target)
+	}
+	if strings.HasPrefix(anchor, "#^") {
+		ref := "((" + obsidianBlockUID(title, anchor[2:]) + "))"
+		switch {
+		case embed:
+			return "{{embed: " + ref + "}}"
+		case alias != "":
+			return "[" + alias + "](" + ref + ")"
+		}
+		return ref
+	}
+	switch {
+	case embed:
+		return "{{embed: [[" + title + "]]}}"
+	case alias != "":
+		return "[" + alias + "]([[" + title + "]])"
+	}
+	return "[[" + title + "]]"
+}
+
+// mdLink rewrites Markdown links and images that point into the vault:
+// notes become page refs and other files are uploaded.
+func (c *converter) mdLink(m string) string {
+	g := mdLink.FindStringSubmatch(m)
+	embed, text, dest, title := g[1] == "!", g[2], g[3], g[4]
+	if strings.Contains(dest, "://") || strings.HasPrefix(dest, "data:") || strings.HasPrefix(dest, "#") ||
+		strings.HasPrefix(dest, "mailto:") || strings.HasPrefix(dest, "[[") || strings.HasPrefix(dest, "((") {
+		return m
+	}
+	if unescaped, err := url.PathUnescape(dest); err == nil {
+		dest = unescaped
+	}
+	if !embed && strings.EqualFold(path.Ext(dest), ".md") {
+		title := c.l.resolve(path.Clean(path.Join(c.dir, dest)))
+		return "[" + text + "]([[" + title + "]])"
+	}
+	return c.asset(dest, text, title, m)
+}
+
+// asset records a link to a local file. The link keeps its local path
+// until the file is uploaded; files that can't be found are left as
+// written.
+func (c *converter) asset(target, alt, title, original string) string {
+	file, ok := c.l.findFile(c.dir, target)
+	if !ok {
+		c.l.warn(c.page.File, "linked file not found: %s", target)
+		return original
+	}
+	a := mdblocks.Asset{File: filepath.Join(c.l.v.Dir, filepath.FromSlash(file)), Alt: alt, Title: title}
+	a.Text = a.Link(file)
+	c.b.Assets = append(c.b.Assets, a)
+	return a.Text
+}
+
+// findFile resolves a link target to a file in the vault: relative to the
+// linking file, relative to the vault root, then by name anywhere in the
+// vault, as Obsidian does.
+func (l *loader) findFile(dir, target string) (string, bool) {
+	target = strings.TrimPrefix(target, "/")
+	for _, candidate := range []string{path.Join(dir, target), path.Clean(target)} {
+		if strings.HasPrefix(candidate, "../") {
+			continue
+		}
+		for _, rel := range l.files.byName[strings.ToLower(path.Base(candidate))] {
+			if strings.EqualFold(rel, candidate) {
+				return rel, true
+			}
+		}
+	}
+	if matches := l.files.byName[strings.ToLower(path.Base(target))]; len(matches) > 0 {
+		return matches[0], true
+	}
+	return "", false
+}
+
+// resolve returns the title an Obsidian link target refers to: a note by
+// path, then by name, then an ISO date as a daily note. Links to notes not
+// in the vault keep their target, so Roam creates the page.
+func (l *loader) resolve(target string) string {
+	key := strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(target, "/"), ".md"))
+	if title, ok := l.byPath[key]; ok {
+		return title
+	}
+	if titles := l.byBase[path.Base(key)]; len(titles) > 0 {
+		return titles[0]
+	}
+	if isoDate.MatchString(target) {
+		if t, err := time.Parse("2006-01-02", target); err == nil {
+			return roamdb.DailyNoteTitle(t)
+		}
+	}
+	return strings.TrimSuffix(target, ".md")
+}
+
+// pageTitle returns the title a Logseq [[ref]] refers to. Logseq page names
+// are case-insensitive and journal refs use "Jan 2nd, 2006", so both are
+// mapped to the titles pages are created with.
+func (l *loader) pageTitle(ref string) string {
+	if title, ok := l.byTitle[strings.ToLower(ref)]; ok {
+		return title
+	}
+	if m := logseqDate.FindStringSubmatch(ref); m != nil {
+		if t, err := time.Parse("Jan 2 2006", m[1]+" "+m[2]+" "+m[3]); err == nil {
+			return roamdb.DailyNoteTitle(t)
+		}
+	}
+	return ref
+}
+
+// convertEmphasis rewrites Markdown emphasis to Roam's: __bold__ becomes
+// **bold**, _italic_ and *italic* become __italic__, and ==highlight==
+// becomes ^^highlight^^.
+func convertEmphasis(s string) string {
+	s = mdBoldUnder.ReplaceAllString(s, "**$1**")
+	s = mdItalUnder.ReplaceAllString(s, "${1}__${2}__$3")
+	s = mdItalStar.ReplaceAllString(s, "${1}__${2}__$3")
+	return mdHighlight.ReplaceAllString(s, "^^$1^^")
+}
+
+// mapText applies fn to the parts of s outside code spans and fenced code
+// blocks.
+func mapText(s string, fn func(string) string) string {
+	var sb strings.Builder
+	for s != "" {
+		i := strings.IndexByte(s, '`')
+		if i < 0 {
+			sb.WriteString(fn(s))
+			break
+		}
+		sb.WriteString(fn(s[:i]))
+		s = s[i:]
+		ticks := len(s) - len(strings.TrimLeft(s, "`"))
+		end := strings.Index(s[ticks:], s[:ticks])
+		if end < 0 {
+			sb.WriteString(s)
+			break
+		}
+		end += 2 * ticks
+		sb.WriteString(s[:end])
+		s = s[end:]
+	}
+	return sb.String()
+}
@@ -0,0 +1,209 @@
+package vault
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/salmonumbrella/roam-cli/internal/mdblocks"
+)
+
+// logseqTab is the number of columns a tab counts as in a Logseq outline.
+const logseqTab = 4
+
+var (
+	logseqProperty = regexp.MustCompile(`^([A-Za-z0-9_.\-]+):: ?(.*)$`)
+	logseqHeading  = regexp.MustCompile(`^(#{1,6})\s+`)
+	logseqMarker   = regexp.MustCompile(`^(TODO|DOING|NOW|LATER|WAIT|WAITING|IN-PROGRESS|DONE)\s+`)
+)
+
+// Field is a property or front matter entry, written as an attribute
+// block.
+type Field struct {
+	Key   string
+	Value string
+	// UID is the uid to create the attribute block with, when the front
+	// matter's uids map names one.
+	UID string
+}
+
+// splitLogseqProperties takes the page properties (key:: value lines before
+// the first block) off a Logseq page.
+func splitLogseqProperties(text string) ([]Field, string) {
+	lines := strings.Split(text, "\n")
+	var props []Field
+	i := 0
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+		m := logseqProperty.FindStringSubmatch(line)
+		if m == nil {
+			break
+		}
+		props = append(props, Field{Key: m[1], Value: strings.TrimSpace(m[2])})
+	}
+	return props, strings.Join(lines[i:], "\n")
+}
+
+// logseqParser builds a block tree from a Logseq outline: "- " items nested
+// by tab (or two-space) indentation, with continuation lines and block
+// properties indented under them.
+type logseqParser struct {
+	roots    []*mdblocks.Block
+	viewType string
+	stack    []*mdblocks.Block
+	levels   []int
+
+	cur *mdblocks.Block
+	// props collects the current block's attribute properties, which become
+	// child blocks once the block ends.
+	props      map[*mdblocks.Block][]*mdblocks.Block
+	propsOpen  bool
+	inFence    bool
+	fenceMark  string
+	contentCol int
+}
+
+// parseLogseq returns the block tree for a Logseq page body and the view
+// type for its top-level blocks.
+func parseLogseq(text string) ([]*mdblocks.Block, string) {
+	p := &logseqParser{props: map[*mdblocks.Block][]*mdblocks.Block{}}
+	for _, line := range strings.Split(text, "\n") {
+		p.line(line)
+	}
+	p.finish()
+	// Attribute properties go first among a block's children, as Roam
+	// writes attributes.
+	mdblocks.Walk(p.roots, func(b *mdblocks.Block) {
+		if attrs := p.props[b]; len(attrs) > 0 {
+			b.Children = append(attrs, b.Children...)
+		}
+	})
+	return p.roots, p.viewType
+}
+
+func (p *logseqParser) line(line string) {
+	ws := mdblocks.Leading(line)
+	rest := line[len(ws):]
+
+	if !p.inFence && (rest == "-" || strings.HasPrefix(rest, "- ")) {
+		level := strings.Count(ws, "\t") + strings.Count(ws, " ")/2
+		content := strings.TrimPrefix(strings.TrimPrefix(rest, "-"), " ")
+		p.start(level, content, mdblocks.Width(ws, logseqTab)+2)
+		return
+	}
+
+	if p.cur == nil {
+		if strings.TrimSpace(line) == "" {
+			return
+		}
+		// Text before the first bullet: a top-level block of its own.
+		p.start(0, strings.TrimSpace(line), mdblocks.Width(ws, logseqTab))
+		return
+	}
+
+	text := mdblocks.StripIndent(line, p.contentCol, logseqTab)
+	if p.inFence {
+		p.cur.String += "\n" + text
+		if strings.TrimSpace(text) == p.fenceMark {
+			p.inFence = false
+		}
+		return
+	}
+	if p.propsOpen {
+		if m := logseqProperty.FindStringSubmatch(strings.TrimSpace(text)); m != nil {
+			p.property(m[1], strings.TrimSpace(m[2]))
+			return
+		}
+	}
+	p.propsOpen = false
+	if strings.TrimSpace(text) == "" {
+		p.cur.String += "\n"
+		return
+	}
+	p.cur.String += "\n" + text
+	p.checkFence(text)
+}
+
+func (p *logseqParser) start(level int, content string, contentCol int) {
+	p.finish()
+	for len(p.levels) > 0 && p.levels[len(p.levels)-1] >= level {
+		p.stack = p.stack[:len(p.stack)-1]
+		p.levels = p.levels[:len(p.levels)-1]
+	}
+
+	b := &mdblocks.Block{}
+	if m := logseqHeading.FindStringSubmatch(content); m != nil {
+		b.Heading = min(len(m[1]), 3)
+		content = content[len(m[0]):]
+	}
+	if m := logseqMarker.FindStringSubmatch(content); m != nil {
+		marker := "{{[[TODO]]}} "
+		if m[1] == "DONE" {
+			marker = "{{[[DONE]]}} "
+		}
+		content = marker + content[len(m[0]):]
+	}
+	b.String = content
+
+	if len(p.stack) > 0 {
+		parent := p.stack[len(p.stack)-1]
+		parent.Children = append(parent.Children, b)
+	} else {
+		p.roots = append(p.roots, b)
+	}
+	p.stack = append(p.stack, b)
+	p.levels = append(p.levels, level)
+	p.cur = b
+	p.contentCol = contentCol
+	p.propsOpen = true
+	p.checkFence(content)
+}
+
+// finish trims the trailing blank lines off the block that just ended.
+func (p *logseqParser) finish() {
+	if p.cur != nil {
+		p.cur.String = strings.TrimRight(p.cur.String, "\n")
+	}
+	p.inFence = false
+}
+
+func (p *logseqParser) checkFence(text string) {
+	if mark := mdblocks.FenceMark(text); mark != "" {
+		p.inFence, p.fenceMark = true, mark
+		p.propsOpen = false
+	}
+}
+
+// property applies a block property: id:: becomes the uid, heading:: and
+// logseq.order-list-type:: set the heading and the parent's view type, and
+// other properties become attribute blocks. Logseq's own bookkeeping
+// properties are dropped.
+func (p *logseqParser) property(key, value string) {
+	switch key {
+	case "id":
+		p.cur.UID = value
+	case "heading":
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			p.cur.Heading = min(n, 3)
+		} else if value == "true" && p.cur.Heading == 0 {
+			p.cur.Heading = 2
+		}
+	case "logseq.order-list-type":
+		if value == "number" {
+			if len(p.stack) > 1 {
+				p.stack[len(p.stack)-2].ViewType = "numbered"
+			} else {
+				p.viewType = "numbered"
+			}
+		}
+	case "collapsed":
+	default:
+		if strings.HasPrefix(key, "logseq.") {
+			return
+		}
+		p.props[p.cur] = append(p.props[p.cur], &mdblocks.Block{String: key + ":: " + value})
+	}
+}
@@ -0,0 +1,210 @@
+// Package vault reads an Obsidian or Logseq vault directory into Roam pages:
+// one page per Markdown file, with folders or namespace file names mapped
+// to namespaced titles, journal files mapped to daily notes, front matter
+// and page properties turned into attribute blocks, and wikilinks, embeds
+// and block references rewritten to Roam syntax.
+//
+// Block uids are derived from the page title and the block's position, so
+// importing the same vault twice addresses the same blocks. Logseq blocks
+// with an id:: property keep that id as their uid, so ((refs)) to them
+// still resolve.
+package vault
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/salmonumbrella/roam-cli/internal/mdblocks"
+	"github.com/salmonumbrella/roam-cli/internal/roamdb"
+)
+
+// Vault flavors.
+const (
+	Obsidian = "obsidian"
+	Logseq   = "logseq"
+)
+
+// Page is a Roam page read from one vault file.
+type Page struct {
+	// File is the file's path relative to the vault, with forward slashes.
+	File  string
+	Title string
+	// UID is the uid to create the page with: MM-DD-YYYY for daily notes,
+	// derived from the title otherwise.
+	UID      string
+	Daily    bool
+	ViewType string
+	Blocks   []*mdblocks.Block
+}
+
+// Vault is the result of reading a vault directory.
+type Vault struct {
+	Dir    string
+	Flavor string
+	Pages  []*Page
+	// Warnings lists links that could not be resolved and files that were
+	// skipped.
+	Warnings []string
+}
+
+// Load reads every Markdown file in dir as flavor.
+func Load(dir, flavor string) (*Vault, error) {
+	flavor = strings.ToLower(strings.TrimSpace(flavor))
+	if flavor != Obsidian && flavor != Logseq {
+		return nil, fmt.Errorf("unknown vault flavor %q (expected obsidian|logseq)", flavor)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving vault directory: %w", err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, fmt.Errorf("reading vault: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("vault %s is not a directory", dir)
+	}
+
+	files, err := listFiles(abs, flavor)
+	if err != nil {
+		return nil, err
+	}
+	contents := make(map[string]string, len(files.markdown))
+	for _, rel := range files.markdown {
+		data, err := os.ReadFile(filepath.Join(abs, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", rel, err)
+		}
+		contents[rel] = strings.ReplaceAll(string(data), "\r\n", "\n")
+	}
+
+	v := &Vault{Dir: abs, Flavor: flavor}
+	l := newLoader(v, files, contents)
+	for _, rel := range files.markdown {
+		v.Pages = append(v.Pages, l.page(rel, contents[rel]))
+	}
+	return v, nil
+}
+
+// vaultFiles lists a vault's Markdown files and, for link resolution, all
+// other files by base name.
+type vaultFiles struct {
+	markdown []string
+	byName   map[string][]string
+}
+
+// skipDirs are directories that hold app state rather than notes.
+var skipDirs = map[string]bool{
+	".obsidian": true, ".trash": true, ".git": true, "node_modules": true,
+	"logseq": true, "bak": true, ".recycle": true,
+}
+
+func listFiles(root, flavor string) (vaultFiles, error) {
+	files := vaultFiles{byName: map[string][]string{}}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if p != root && (skipDirs[d.Name()] || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		if strings.EqualFold(path.Ext(rel), ".md") {
+			// Logseq keeps notes in pages/ and journals/; other Markdown
+			// files (such as a README at the root) aren't part of the graph.
+			if flavor == Logseq && !strings.HasPrefix(rel, "pages/") && !strings.HasPrefix(rel, "journals/") {
+				return nil
+			}
+			files.markdown = append(files.markdown, rel)
+		}
+		name := strings.ToLower(d.Name())
+		files.byName[name] = append(files.byName[name], rel)
+		return nil
+	})
+	if err != nil {
+		return files, fmt.Errorf("reading vault: %w", err)
+	}
+	sort.Strings(files.markdown)
+	return files, nil
+}
+
+var (
+	obsidianJournal = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})$`)
+	logseqJournal   = regexp.MustCompile(`^(\d{4})_(\d{2})_(\d{2})$`)
+)
+
+// fileTitle returns the page title for a vault file, and the date when the
+// file is a daily note.
+func fileTitle(rel, flavor string) (string, time.Time, bool) {
+	base := strings.TrimSuffix(path.Base(rel), path.Ext(rel))
+	if flavor == Logseq {
+		if m := logseqJournal.FindStringSubmatch(base); m != nil && strings.HasPrefix(rel, "journals/") {
+			if t, err := time.Parse("2006-01-02", m[1]+"-"+m[2]+"-"+m[3]); err == nil {
+				return roamdb.DailyNoteTitle(t), t, true
+			}
+		}
+		// Logseq writes namespace separators as ___ (or %2F in older
+		// graphs) and percent-escapes characters filesystems reject.
+		title := strings.ReplaceAll(base, "___", "/")
+		if unescaped, err := url.PathUnescape(title); err == nil {
+			title = unescaped
+		}
+		return title, time.Time{}, false
+	}
+
+	if obsidianJournal.MatchString(base) {
+		if t, err := time.Parse("2006-01-02", base); err == nil {
+			return roamdb.DailyNoteTitle(t), t, true
+		}
+	}
+	return strings.TrimSuffix(rel, path.Ext(rel)), time.Time{}, false
+}
+
+// DeriveUID returns a uid for seed: nine characters from its hash, drawn
+// from the alphabet Roam uses.
+func DeriveUID(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return base64.RawURLEncoding.EncodeToString(sum[:])[:9]
+}
+
+func pageUID(title string) string {
+	return DeriveUID("page\x00" + title)
+}
+
+func blockUID(title, position string) string {
+	return DeriveUID("block\x00" + title + "\x00" + position)
+}
+
+// RewriteAssets replaces links to uploaded files with their URLs. uploaded
+// maps absolute file paths to URLs; links to other files stay local.
+func RewriteAssets(blocks []*mdblocks.Block, uploaded map[string]string) {
+	mdblocks.Walk(blocks, func(b *mdblocks.Block) {
+		kept := b.Assets[:0]
+		for _, a := range b.Assets {
+			u, ok := uploaded[a.File]
+			if !ok {
+				kept = append(kept, a)
+				continue
+			}
+			b.String = strings.Replace(b.String, a.Text, a.Link(u), 1)
+		}
+		b.Assets = kept
+	})
+}
@@ -0,0 +1,225 @@
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/salmonumbrella/roam-cli/internal/mdblocks"
+)
+
+func writeVault(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func pageByTitle(t *testing.T, v *Vault, title string) *Page {
+	t.Helper()
+	for _, p := range v.Pages {
+		if p.Title == title {
+			return p
+		}
+	}
+	var titles []string
+	for _, p := range v.Pages {
+		titles = append(titles, p.Title)
+	}
+	t.Fatalf("no page %q in %v", title, titles)
+	return nil
+}
+
+func blockStrings(blocks []*mdblocks.Block) []string {
+	var out []string
+	mdblocks.Walk(blocks, func(b *mdblocks.Block) { out = append(out, b.String) })
+	return out
+}
+
+func TestLoadObsidian(t *testing.T) {
+	dir := writeVault(t, map[string]string{
+		".obsidian/app.json": "{}",
+		"Projects/Roam.md": `---
+status: active
+tags: [cli, go]
+uids:
+  status: a1
+---
+# Goals
+
+- Ship **import** and ==export==
+  - [ ] write _tests_
+1. first
+
+See [[Ideas|my ideas]] and ![[Ideas#^key]].
+
+![[diagram.png]]
+`,
+		"Ideas.md":                "An idea ^key\n\n![[Projects/Roam]]\n\n![chart](attachments/diagram.png \"Q3\")\n",
+		"2025-01-15.md":           "- met [[Ideas]]\n",
+		"attachments/diagram.png": "PNG",
+	})
+
+	v, err := Load(dir, "obsidian")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(v.Pages) != 3 {
+		t.Fatalf("expected 3 pages, got %d", len(v.Pages))
+	}
+
+	roam := pageByTitle(t, v, "Projects/Roam")
+	got := blockStrings(roam.Blocks)
+	want := []string{
+		"status:: active",
+		"tags:: [[cli]], [[go]]",
+		"Goals",
+		"Ship **import** and ^^export^^",
+		"{{[[TODO]]}} write __tests__",
+		"first",
+	}
+	if strings.Join(got[:len(want)], "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected blocks:\n%q", got)
+	}
+	if roam.Blocks[0].UID != "a1" || roam.Blocks[1].UID == "" {
+		t.Fatalf("front matter uids not applied: %q, %q", roam.Blocks[0].UID, roam.Blocks[1].UID)
+	}
+	if roam.Blocks[2].Heading != 1 || roam.Blocks[2].ViewType != "numbered" {
+		t.Fatalf("heading section not converted: %+v", roam.Blocks[2])
+	}
+
+	ideas := pageByTitle(t, v, "Ideas")
+	keyUID := ideas.Blocks[0].UID
+	if ideas.Blocks[0].String != "An idea" || keyUID != obsidianBlockUID("Ideas", "key") {
+		t.Fatalf("block id not applied: %+v", ideas.Blocks[0])
+	}
+	if ideas.Blocks[1].String != "{{embed: [[Projects/Roam]]}}" {
+		t.Fatalf("page embed not converted: %q", ideas.Blocks[1].String)
+	}
+
+	var links, image *mdblocks.Block
+	mdblocks.Walk(roam.Blocks, func(b *mdblocks.Block) {
+		if strings.HasPrefix(b.String, "See ") {
+			links = b
+		}
+		if len(b.Assets) > 0 {
+			image = b
+		}
+	})
+	if links == nil || links.String != "See [my ideas]([[Ideas]]) and {{embed: (("+keyUID+"))}}." {
+		t.Fatalf("links not converted: %+v", links)
+	}
+	if image == nil || image.Assets[0].File != filepath.Join(v.Dir, "attachments", "diagram.png") {
+		t.Fatalf("attachment not found: %+v", image)
+	}
+	RewriteAssets(roam.Blocks, map[string]string{image.Assets[0].File: "https://files/d.png"})
+	if image.String != "![](https://files/d.png)" || len(image.Assets) != 0 {
+		t.Fatalf("attachment not rewritten: %+v", image)
+	}
+
+	chart := ideas.Blocks[2]
+	RewriteAssets(ideas.Blocks, map[string]string{filepath.Join(v.Dir, "attachments", "diagram.png"): "https://files/d.png"})
+	if chart.String != `![chart](https://files/d.png "Q3")` {
+		t.Fatalf("titled image not rewritten: %q", chart.String)
+	}
+
+	daily := pageByTitle(t, v, "January 15th, 2025")
+	if !daily.Daily || daily.UID != "01-15-2025" || daily.Blocks[0].String != "met [[Ideas]]" {
+		t.Fatalf("daily note not converted: %+v", daily)
+	}
+}
+
+func TestLoadLogseq(t *testing.T) {
+	dir := writeVault(t, map[string]string{
+		"logseq/config.edn": "{}",
+		"pages/Projects___Roam.md": `alias:: roam-cli
+public:: true
+
+- TODO Ship it
+  id:: 6512bd43-d9ca-4c2e-9b3a-5c0f1e8a7d11
+  priority:: high
+	- ## Details
+	  more text
+- see {{embed [[Notes]]}}
+`,
+		"pages/Notes.md":          "- ref ((6512bd43-d9ca-4c2e-9b3a-5c0f1e8a7d11)) on [[Jan 15th, 2025]]\n",
+		"journals/2025_01_15.md":  "- DONE write\n",
+		"README.md":               "not part of the graph\n",
+		"logseq/bak/pages/old.md": "- backup\n",
+	})
+
+	v, err := Load(dir, "logseq")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(v.Pages) != 3 {
+		t.Fatalf("expected 3 pages, got %d", len(v.Pages))
+	}
+
+	roam := pageByTitle(t, v, "Projects/Roam")
+	got := blockStrings(roam.Blocks)
+	want := []string{
+		"alias:: roam-cli",
+		"public:: true",
+		"{{[[TODO]]}} Ship it",
+		"priority:: high",
+		"Details\nmore text",
+		"see {{embed: [[Notes]]}}",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected blocks:\n%q", got)
+	}
+	task := roam.Blocks[2]
+	if task.UID != "6512bd43-d9ca-4c2e-9b3a-5c0f1e8a7d11" {
+		t.Fatalf("id:: property not used as uid: %q", task.UID)
+	}
+	if task.Children[1].Heading != 2 {
+		t.Fatalf("heading not converted: %+v", task.Children[1])
+	}
+
+	notes := pageByTitle(t, v, "Notes")
+	if notes.Blocks[0].String != "ref ((6512bd43-d9ca-4c2e-9b3a-5c0f1e8a7d11)) on [[January 15th, 2025]]" {
+		t.Fatalf("refs not converted: %q", notes.Blocks[0].String)
+	}
+
+	daily := pageByTitle(t, v, "January 15th, 2025")
+	if daily.UID != "01-15-2025" || daily.Blocks[0].String != "{{[[DONE]]}} write" {
+		t.Fatalf("journal not converted: %+v", daily)
+	}
+}
+
+func TestLoadUIDsAreStable(t *testing.T) {
+	files := map[string]string{"Note.md": "# Title\n\n- one\n- two\n"}
+	first, err := Load(writeVault(t, files), Obsidian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Load(writeVault(t, files), Obsidian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var a, b []string
+	mdblocks.Walk(first.Pages[0].Blocks, func(x *mdblocks.Block) { a = append(a, x.UID) })
+	mdblocks.Walk(second.Pages[0].Blocks, func(x *mdblocks.Block) { b = append(b, x.UID) })
+	if strings.Join(a, ",") != strings.Join(b, ",") || len(a) != 3 {
+		t.Fatalf("uids differ between loads: %v vs %v", a, b)
+	}
+	if first.Pages[0].UID != second.Pages[0].UID || len(first.Pages[0].UID) != 9 {
+		t.Fatalf("unexpected page uid: %q", first.Pages[0].UID)
+	}
+}
+
+func TestLoadUnknownFlavor(t *testing.T) {
+	if _, err := Load(t.TempDir(), "notion"); err == nil {
+		t.Fatal("expected an error for an unknown flavor")
+	}
+}